@@ -1 +1,275 @@
 package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/grpcserver"
+	grepv1 "github.com/swarit-pandey/distributed-grep/api/proto/grep/v1"
+	api "github.com/swarit-pandey/distributed-grep/api/server"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/api/webhook"
+	"github.com/swarit-pandey/distributed-grep/common/config"
+	"github.com/swarit-pandey/distributed-grep/common/health"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/minio"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"google.golang.org/grpc"
+)
+
+// Config holds everything the API gateway needs to run
+type Config struct {
+	Addr    string               `mapstructure:"addr"`
+	BaseURL string               `mapstructure:"baseUrl"`
+	MinIO   minio.MinOptions     `mapstructure:"minio"`
+	Storage minio.StorageOptions `mapstructure:"storage"`
+	Redis   redis.RedisOptions   `mapstructure:"redis"`
+	NATS    nats.NatsOptions     `mapstructure:"nats"`
+	Auth    AuthConfig           `mapstructure:"auth"`
+	Limits  service.Limits       `mapstructure:"limits"`
+	Webhook webhook.Options      `mapstructure:"webhook"`
+
+	// IdempotencyTTL is how long repeated submissions return the original job
+	IdempotencyTTL time.Duration `mapstructure:"idempotencyTtl"`
+
+	// TombstoneTTL is how long deleted jobs are reported as deleted
+	TombstoneTTL time.Duration `mapstructure:"tombstoneTtl"`
+
+	// DrainTimeout bounds how long a delete waits for chunks in flight
+	DrainTimeout time.Duration `mapstructure:"drainTimeout"`
+
+	// ValidateResponses checks every JSON response against the spec and
+	// answers 500 for mismatches, for debugging only
+	ValidateResponses bool `mapstructure:"validateResponses"`
+
+	// ShutdownDelay is how long /readyz reports not ready before the
+	// listeners close, giving load balancers time to stop routing traffic
+	ShutdownDelay time.Duration `mapstructure:"shutdownDelay"`
+
+	// GRPCAddr is where the gRPC API listens, it is disabled when empty
+	GRPCAddr string `mapstructure:"grpcAddr"`
+}
+
+// AuthConfig lists the API keys of each tenant
+type AuthConfig struct {
+	Keys []service.APIKey `mapstructure:"keys"`
+
+	// Disabled runs the gateway without keys, requests are not authenticated
+	// and share one global namespace. Without it a missing key list is an error.
+	Disabled bool `mapstructure:"disabled"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Addr:     ":8080",
+		GRPCAddr: ":9090",
+		BaseURL:  "/api/v1",
+		MinIO: minio.MinOptions{
+			Endpoint: "localhost:9000",
+		},
+		Storage: minio.StorageOptions{
+			Buckets: []minio.BucketOptions{
+				{Name: "logs", Type: minio.TextType, Category: minio.LogStorage},
+				{Name: "chunks", Type: minio.TextType, Category: minio.ChunkStorage},
+				{Name: "results", Type: minio.JSONType, Category: minio.ResultStorage},
+			},
+		},
+		Redis: redis.RedisOptions{
+			Addr: "localhost:6379",
+		},
+		NATS: nats.NatsOptions{
+			URL:  "nats://localhost:4222",
+			Name: "api",
+		},
+		Webhook: webhook.Options{
+			MaxAttempts: webhook.DefaultMaxAttempts,
+			Timeout:     webhook.DefaultTimeout,
+			Backoff:     webhook.DefaultBackoff,
+		},
+		IdempotencyTTL: service.DefaultIdempotencyTTL,
+		TombstoneTTL:   service.DefaultTombstoneTTL,
+		DrainTimeout:   service.DefaultDrainTimeout,
+		ShutdownDelay:  5 * time.Second,
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML config file")
+	flag.Parse()
+
+	log := logger.New()
+
+	cfg := defaultConfig()
+	if err := config.Load(*configPath, "API", &cfg); err != nil {
+		log.Error("failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, log); err != nil {
+		log.Error("api exited with error", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg Config, log *logger.Logger) error {
+	storage, err := minio.New(cfg.MinIO.Endpoint, cfg.MinIO.AccessKeyID, cfg.MinIO.SecretAccessKey, cfg.MinIO.SSL, log, &cfg.Storage)
+	if err != nil {
+		return err
+	}
+	if err := storage.Instantiate(ctx); err != nil {
+		return err
+	}
+
+	store := redis.New(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, log)
+	if err := store.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer store.Close()
+
+	bus := nats.New(cfg.NATS.URL, cfg.NATS.Name, log)
+	if err := bus.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	svc := service.New(store, storage, bus, log)
+	svc.SetIdempotencyTTL(cfg.IdempotencyTTL)
+	svc.SetTombstoneTTL(cfg.TombstoneTTL)
+	svc.SetDrainTimeout(cfg.DrainTimeout)
+	svc.SetDefaultLimits(cfg.Limits)
+
+	sub, err := bus.Subscribe(nats.SubjectResults, svc.HandleResultMessage)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// Every finished job has to be acted upon once, by any of the gateways
+	finishedSub, err := bus.QueueSubscribe(nats.SubjectJobFinished, "api", svc.HandleJobFinishedMessage)
+	if err != nil {
+		return err
+	}
+	defer finishedSub.Unsubscribe()
+
+	go webhook.New(store, cfg.Webhook, log).Run(ctx)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	validator, err := api.SpecValidator(cfg.BaseURL, api.ValidatorOptions{Responses: cfg.ValidateResponses})
+	if err != nil {
+		return err
+	}
+	router.Use(gin.Recovery(), api.RequestID())
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	checker := health.New()
+	checker.Add("minio", storage)
+	checker.Add("redis", store)
+	checker.Add("nats", bus)
+	router.GET("/healthz", gin.WrapF(checker.Healthz))
+	router.GET("/readyz", gin.WrapF(checker.Readyz))
+
+	// Credentials are checked before requests are validated, so callers
+	// without them learn that rather than what is wrong with their request
+	var keyring *service.Keyring
+	var middlewares []gin.HandlerFunc
+	switch {
+	case cfg.Auth.Disabled:
+		log.Warn("authentication is disabled, every caller shares the global namespace")
+	case len(cfg.Auth.Keys) == 0:
+		return errors.New("no API keys configured, set auth.disabled to run without authentication")
+	default:
+		keyring, err = service.NewKeyring(cfg.Auth.Keys)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		middlewares = append(middlewares, gin.HandlerFunc(api.APIKeyAuth(keyring)))
+	}
+	middlewares = append(middlewares, validator)
+
+	api.RegisterHandlersWithOptions(router.Group("", middlewares...), api.NewHandler(svc, log), api.GinServerOptions{
+		BaseURL:      cfg.BaseURL,
+		ErrorHandler: api.ErrorHandler,
+	})
+
+	// Requests are served as usual while /readyz reports the shutdown, long
+	// lived job streams end once the listeners close
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
+
+	srv := &http.Server{
+		Addr:        cfg.Addr,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return serveCtx },
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		log.Info("api listening", "addr", cfg.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	var grpcSrv *grpc.Server
+	if cfg.GRPCAddr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", cfg.GRPCAddr, err)
+		}
+
+		grpcSrv = grpc.NewServer(grpcserver.ServerOptions(keyring)...)
+		grepv1.RegisterGrepServiceServer(grpcSrv, grpcserver.New(svc, log))
+
+		go func() {
+			log.Info("grpc api listening", "addr", cfg.GRPCAddr)
+			if err := grpcSrv.Serve(lis); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down api", "delay", cfg.ShutdownDelay)
+	checker.Shutdown()
+	time.Sleep(cfg.ShutdownDelay)
+	stopServing()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if grpcSrv != nil {
+		// Streams only end when their job does, cut them off at the deadline
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcSrv.Stop()
+		}
+	}
+
+	return srv.Shutdown(shutdownCtx)
+}
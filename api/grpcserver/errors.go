@@ -0,0 +1,86 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	api "github.com/swarit-pandey/distributed-grep/api/server"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain identifies the service in google.rpc.ErrorInfo details
+const errorDomain = "distributed-grep"
+
+// toStatus maps errors returned by the service onto gRPC statuses. Every
+// status carries an ErrorInfo whose reason is the code the REST API returns
+// for the same error.
+func toStatus(ctx context.Context, err error) error {
+	var limitErr *service.LimitError
+	var patternErr *pattern.Error
+	switch {
+	case errors.As(err, &limitErr):
+		return newStatus(codes.ResourceExhausted, limitErr.Code, err.Error(), nil,
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(limitErr.RetryAfter)})
+	case errors.As(err, &patternErr):
+		field := "pattern"
+		if patternErr.Field != "" {
+			field = patternErr.Field
+		}
+		return newStatus(codes.InvalidArgument, api.CodeInvalidPattern, patternErr.Reason,
+			map[string]string{"offset": strconv.Itoa(patternErr.Offset)},
+			&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: patternErr.Error()},
+			}})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return newStatus(codes.Unauthenticated, api.CodeUnauthorized, err.Error(), nil)
+	case errors.Is(err, service.ErrInvalidRequest):
+		return newStatus(codes.InvalidArgument, api.CodeInvalidRequest, err.Error(), nil)
+	case errors.Is(err, service.ErrFileNotFound):
+		return newStatus(codes.NotFound, api.CodeFileNotFound, err.Error(), nil)
+	case errors.Is(err, service.ErrJobDeleted):
+		return newStatus(codes.NotFound, api.CodeJobDeleted, err.Error(), nil)
+	case errors.Is(err, service.ErrJobExpired):
+		return newStatus(codes.NotFound, api.CodeJobExpired, err.Error(), nil)
+	case errors.Is(err, service.ErrJobNotFound):
+		return newStatus(codes.NotFound, api.CodeJobNotFound, err.Error(), nil)
+	case errors.Is(err, service.ErrJobFinished):
+		return newStatus(codes.FailedPrecondition, api.CodeJobFinished, err.Error(), nil)
+	case errors.Is(err, service.ErrJobIncomplete):
+		return newStatus(codes.FailedPrecondition, api.CodeJobNotComplete, err.Error(), nil)
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		return newStatus(codes.AlreadyExists, api.CodeIdempotency, err.Error(), nil)
+	case errors.Is(err, service.ErrRequestInProgress):
+		return newStatus(codes.Aborted, api.CodeInProgress, err.Error(), nil)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		log.Debug("call ended by client", "request_id", requestID(ctx), "err", err)
+		return status.FromContextError(err).Err()
+	default:
+		method, _ := grpc.Method(ctx)
+		log.Error("call failed", "request_id", requestID(ctx), "method", method, "err", err)
+		return newStatus(codes.Internal, api.CodeInternal, "internal server error", nil)
+	}
+}
+
+func newStatus(code codes.Code, reason, message string, metadata map[string]string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: metadata,
+	}}, details...)
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
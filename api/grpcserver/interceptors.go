@@ -0,0 +1,116 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys understood by the server, gRPC lowercases all keys
+const (
+	RequestIDMetadata = "x-request-id"
+	APIKeyMetadata    = "x-api-key"
+)
+
+type requestIDKey struct{}
+
+// ServerOptions returns the interceptors every GrepService server needs,
+// calls are authenticated against keyring unless it is nil
+func ServerOptions(keyring *service.Keyring) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{unaryRequestID}
+	stream := []grpc.StreamServerInterceptor{streamRequestID}
+
+	if keyring != nil {
+		unary = append(unary, unaryAuth(keyring))
+		stream = append(stream, streamAuth(keyring))
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+// withRequestID reuses the caller's x-request-id or assigns a new one, and
+// echoes it back in the response headers
+func withRequestID(ctx context.Context) context.Context {
+	id := metadataValue(ctx, RequestIDMetadata)
+	if id == "" {
+		id = ids.New("req")
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id)); err != nil {
+		log.Debug("failed to set request ID header", "request_id", id, "err", err)
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestID returns the ID assigned by the request ID interceptor
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func unaryRequestID(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func streamRequestID(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+}
+
+// unaryAuth rejects calls without a key known to keyring and scopes the call
+// context to the tenant of the key
+func unaryAuth(keyring *service.Keyring) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		authed, err := keyring.Authenticate(ctx, presentedKey(ctx))
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		return handler(authed, req)
+	}
+}
+
+func streamAuth(keyring *service.Keyring) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		authed, err := keyring.Authenticate(ss.Context(), presentedKey(ss.Context()))
+		if err != nil {
+			return toStatus(ss.Context(), err)
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: authed})
+	}
+}
+
+// presentedKey returns the API key sent with the call, if any
+func presentedKey(ctx context.Context) string {
+	if key := metadataValue(ctx, APIKeyMetadata); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(metadataValue(ctx, "authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,360 @@
+// Package grpcserver serves the GrepService of proto/grep/v1 on top of the
+// same job service as the REST API
+package grpcserver
+
+//go:generate protoc -I ../proto --go_out=../proto --go_opt=paths=source_relative --go-grpc_out=../proto --go-grpc_opt=paths=source_relative grep/v1/grep.proto
+
+import (
+	"context"
+
+	grepv1 "github.com/swarit-pandey/distributed-grep/api/proto/grep/v1"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Server implements grepv1.GrepServiceServer on top of the job service
+type Server struct {
+	grepv1.UnimplementedGrepServiceServer
+
+	svc *service.Service
+}
+
+var _ grepv1.GrepServiceServer = (*Server)(nil)
+
+// New returns a new Server
+func New(svc *service.Service, log *logger.Logger) *Server {
+	InitLogger(log)
+
+	return &Server{svc: svc}
+}
+
+// CreateGrepJob validates the request and submits a new job. Repeated
+// submissions with the same idempotency key, or client supplied request ID,
+// return the job created the first time.
+func (s *Server) CreateGrepJob(ctx context.Context, req *grepv1.CreateGrepJobRequest) (*grepv1.CreateGrepJobResponse, error) {
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		key = metadataValue(ctx, RequestIDMetadata)
+	}
+
+	job, replayed, err := s.svc.CreateJobIdempotent(ctx, key, toJob(req, requestID(ctx)))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &grepv1.CreateGrepJobResponse{
+		JobId:     job.ID,
+		RequestId: job.RequestID,
+		Status:    toJobState(job.Status),
+		CreatedAt: timestamppb.New(job.CreatedAt),
+		Replayed:  replayed,
+	}, nil
+}
+
+// GetGrepJob returns the job status with a page of its results
+func (s *Server) GetGrepJob(ctx context.Context, req *grepv1.GetGrepJobRequest) (*grepv1.GetGrepJobResponse, error) {
+	page, limit := 1, service.DefaultResultsLimit
+	if req.GetPage() != 0 {
+		page = int(req.GetPage())
+	}
+	if req.GetLimit() != 0 {
+		limit = int(req.GetLimit())
+	}
+
+	res, err := s.svc.GetJobResults(ctx, req.GetJobId(), page, limit)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	matches := make([]*grepv1.GrepMatch, 0, len(res.Matches))
+	for _, m := range res.Matches {
+		matches = append(matches, toGrepMatch(m))
+	}
+
+	counts := make([]*grepv1.FileCount, 0, len(res.Counts))
+	for _, c := range res.Counts {
+		counts = append(counts, &grepv1.FileCount{File: c.File, Count: int32(c.Count)})
+	}
+
+	resp := &grepv1.GetGrepJobResponse{
+		Job:        toJobStatus(res.Job, res.Stats),
+		Results:    matches,
+		FileCounts: counts,
+		Pagination: newPagination(page, limit, res.Total),
+	}
+	if res.QueuePosition != nil {
+		position := int32(*res.QueuePosition)
+		resp.QueuePosition = &position
+	}
+
+	return resp, nil
+}
+
+// CancelGrepJob cancels a job that has not finished yet
+func (s *Server) CancelGrepJob(ctx context.Context, req *grepv1.CancelGrepJobRequest) (*grepv1.JobStatus, error) {
+	job, err := s.svc.CancelJob(ctx, req.GetJobId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	_, stats, err := s.svc.GetJob(ctx, job.ID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toJobStatus(job, stats), nil
+}
+
+// StreamMatches streams matches and progress of a job until it finishes
+func (s *Server) StreamMatches(req *grepv1.StreamMatchesRequest, stream grpc.ServerStreamingServer[grepv1.StreamMatchesResponse]) error {
+	ctx := stream.Context()
+
+	emit := func(ev service.StreamEvent) error {
+		resp := &grepv1.StreamMatchesResponse{Id: ev.ID}
+		switch ev.Type {
+		case service.EventMatch:
+			resp.Event = &grepv1.StreamMatchesResponse_Match{Match: toGrepMatch(*ev.Match)}
+		case service.EventStats:
+			resp.Event = &grepv1.StreamMatchesResponse_Stats{Stats: toJobStatus(ev.Job, ev.Stats)}
+		default:
+			resp.Event = &grepv1.StreamMatchesResponse_Status{Status: toJobStatus(ev.Job, ev.Stats)}
+		}
+		return stream.Send(resp)
+	}
+
+	if err := s.svc.StreamJob(ctx, req.GetJobId(), req.GetLastEventId(), emit); err != nil {
+		return toStatus(ctx, err)
+	}
+	return nil
+}
+
+func newPagination(page, limit, total int) *grepv1.Pagination {
+	return &grepv1.Pagination{
+		CurrentPage:  int32(page),
+		PerPage:      int32(limit),
+		TotalPages:   int32((total + limit - 1) / limit),
+		TotalResults: int32(total),
+	}
+}
+
+// toJob maps a request onto a job, applying the same defaults as the REST API
+func toJob(req *grepv1.CreateGrepJobRequest, requestID string) models.Job {
+	job := models.Job{
+		RequestID:    requestID,
+		Pattern:      req.GetPattern(),
+		Files:        req.GetFiles(),
+		ContextLines: int(req.GetContextLines()),
+		Regex:        true,
+		InvertMatch:  req.GetInvertMatch(),
+		WordRegexp:   req.GetWordRegexp(),
+		LineRegexp:   req.GetLineRegexp(),
+		OnlyMatching: req.GetOnlyMatching(),
+		MaxCount:     int(req.GetMaxCount()),
+	}
+
+	if req.CaseSensitive != nil {
+		job.CaseSensitive = req.GetCaseSensitive()
+	}
+	if len(req.GetPatterns()) > 0 {
+		job.Patterns = req.GetPatterns()
+		job.Regex = false
+	}
+	if req.Regex != nil {
+		job.Regex = req.GetRegex()
+	}
+	if req.Query != nil {
+		job.Query = toQuery(req.GetQuery())
+	}
+	if req.Since != nil || req.Until != nil || len(req.GetTimestampFormats()) > 0 {
+		job.TimeRange = toTimeRange(req)
+	}
+	if req.Mode != grepv1.SearchMode_SEARCH_MODE_UNSPECIFIED {
+		job.Mode = toSearchMode(req.GetMode())
+	}
+	if req.Priority != grepv1.JobPriority_JOB_PRIORITY_UNSPECIFIED {
+		job.Priority = toJobPriority(req.GetPriority())
+	}
+	if req.Retention != nil {
+		job.Retention = req.GetRetention().AsDuration()
+	}
+	if req.Webhook != nil {
+		job.Webhook = &models.Webhook{URL: req.GetWebhook().GetUrl(), Secret: req.GetWebhook().GetSecret()}
+	}
+
+	return job
+}
+
+// toSearchMode maps a mode onto the model, unknown modes are passed on for the
+// service to reject
+func toSearchMode(mode grepv1.SearchMode) models.SearchMode {
+	switch mode {
+	case grepv1.SearchMode_SEARCH_MODE_LINES:
+		return models.SearchModeLines
+	case grepv1.SearchMode_SEARCH_MODE_COUNT:
+		return models.SearchModeCount
+	case grepv1.SearchMode_SEARCH_MODE_FILES_WITH_MATCHES:
+		return models.SearchModeFilesWithMatches
+	case grepv1.SearchMode_SEARCH_MODE_FILES_WITHOUT_MATCH:
+		return models.SearchModeFilesWithoutMatch
+	default:
+		return models.SearchMode(mode.String())
+	}
+}
+
+// toJobPriority maps a priority onto the model, unknown priorities are passed
+// on for the service to reject
+func toJobPriority(priority grepv1.JobPriority) models.JobPriority {
+	switch priority {
+	case grepv1.JobPriority_JOB_PRIORITY_INTERACTIVE:
+		return models.PriorityInteractive
+	case grepv1.JobPriority_JOB_PRIORITY_NORMAL:
+		return models.PriorityNormal
+	case grepv1.JobPriority_JOB_PRIORITY_BATCH:
+		return models.PriorityBatch
+	default:
+		return models.JobPriority(priority.String())
+	}
+}
+
+// toTimeRange maps the time range of a request onto the model. Unknown
+// formats are passed on for the service to reject.
+func toTimeRange(req *grepv1.CreateGrepJobRequest) *models.TimeRange {
+	r := &models.TimeRange{}
+	if req.Since != nil {
+		since := req.GetSince().AsTime()
+		r.Since = &since
+	}
+	if req.Until != nil {
+		until := req.GetUntil().AsTime()
+		r.Until = &until
+	}
+
+	for _, format := range req.GetTimestampFormats() {
+		switch format {
+		case grepv1.TimestampFormat_TIMESTAMP_FORMAT_RFC3339:
+			r.Formats = append(r.Formats, models.TimestampRFC3339)
+		case grepv1.TimestampFormat_TIMESTAMP_FORMAT_SYSLOG:
+			r.Formats = append(r.Formats, models.TimestampSyslog)
+		case grepv1.TimestampFormat_TIMESTAMP_FORMAT_NGINX:
+			r.Formats = append(r.Formats, models.TimestampNginx)
+		case grepv1.TimestampFormat_TIMESTAMP_FORMAT_EPOCH_MILLIS:
+			r.Formats = append(r.Formats, models.TimestampEpochMillis)
+		default:
+			r.Formats = append(r.Formats, models.TimestampFormat(format.String()))
+		}
+	}
+
+	return r
+}
+
+// toQuery maps a query onto the model, applying the same defaults as the REST
+// API. Unknown operators are passed on for the service to reject.
+func toQuery(q *grepv1.PatternQuery) *models.Query {
+	query := &models.Query{
+		ID:      q.GetId(),
+		Pattern: q.GetPattern(),
+		Regex:   true,
+	}
+
+	switch q.GetOp() {
+	case grepv1.QueryOp_QUERY_OP_UNSPECIFIED:
+	case grepv1.QueryOp_QUERY_OP_AND:
+		query.Op = models.QueryOpAnd
+	case grepv1.QueryOp_QUERY_OP_OR:
+		query.Op = models.QueryOpOr
+	case grepv1.QueryOp_QUERY_OP_NOT:
+		query.Op = models.QueryOpNot
+	default:
+		query.Op = models.QueryOp(q.GetOp().String())
+	}
+
+	if q.CaseSensitive != nil {
+		query.CaseSensitive = q.GetCaseSensitive()
+	}
+	if q.Regex != nil {
+		query.Regex = q.GetRegex()
+	}
+	for _, child := range q.GetChildren() {
+		query.Children = append(query.Children, *toQuery(child))
+	}
+
+	return query
+}
+
+func toJobState(status models.JobStatus) grepv1.JobState {
+	switch status {
+	case models.JobStatusPending:
+		return grepv1.JobState_JOB_STATE_PENDING
+	case models.JobStatusProcessing:
+		return grepv1.JobState_JOB_STATE_IN_PROGRESS
+	case models.JobStatusCompleted:
+		return grepv1.JobState_JOB_STATE_COMPLETED
+	case models.JobStatusFailed:
+		return grepv1.JobState_JOB_STATE_FAILED
+	case models.JobStatusCancelled:
+		return grepv1.JobState_JOB_STATE_CANCELLED
+	default:
+		return grepv1.JobState_JOB_STATE_UNSPECIFIED
+	}
+}
+
+// toJobStatus maps a job onto its message, stats are left out if nil
+func toJobStatus(job *models.Job, stats *models.JobStats) *grepv1.JobStatus {
+	status := &grepv1.JobStatus{
+		JobId:     job.ID,
+		RequestId: job.RequestID,
+		Status:    toJobState(job.Status),
+		Progress:  int32(job.Progress),
+		CreatedAt: timestamppb.New(job.CreatedAt),
+		Error:     job.Error,
+	}
+
+	if job.CompletedAt != nil {
+		status.CompletedAt = timestamppb.New(*job.CompletedAt)
+	}
+	if job.ExpiresAt != nil {
+		status.ExpiresAt = timestamppb.New(*job.ExpiresAt)
+	}
+
+	if stats != nil {
+		status.Stats = &grepv1.JobStats{
+			TotalFiles:     int32(stats.TotalFiles),
+			ProcessedFiles: int32(stats.ProcessedFiles),
+			TotalMatches:   int32(stats.TotalMatches),
+			BytesProcessed: stats.BytesProcessed,
+		}
+	}
+
+	return status
+}
+
+func toGrepMatch(m models.Match) *grepv1.GrepMatch {
+	match := &grepv1.GrepMatch{
+		File:            m.FileName,
+		LineNumber:      int32(m.LineNumber),
+		Content:         m.Content,
+		ContextBefore:   m.Context.Before,
+		ContextAfter:    m.Context.After,
+		MatchedPatterns: m.MatchedPatterns,
+		Parts:           m.Parts,
+	}
+	if m.Timestamp != nil {
+		match.Timestamp = timestamppb.New(*m.Timestamp)
+	}
+
+	return match
+}
@@ -0,0 +1,530 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/api/internal/fakes"
+	grepv1 "github.com/swarit-pandey/distributed-grep/api/proto/grep/v1"
+	api "github.com/swarit-pandey/distributed-grep/api/server"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestClient serves the GrepService over an in-memory connection
+func newTestClient(t *testing.T, keyring *service.Keyring) (grepv1.GrepServiceClient, *fakes.Storage) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	storage := fakes.NewStorage()
+	storage.AddFile("logs/app.log", "app\n")
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(ServerOptions(keyring)...)
+	grepv1.RegisterGrepServiceServer(srv, New(service.New(store, storage, &fakes.Bus{}, l), l))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return grepv1.NewGrepServiceClient(conn), storage
+}
+
+// errorInfo returns the ErrorInfo detail of a failed call
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status: %v", err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	require.Fail(t, "status has no ErrorInfo", st.String())
+	return nil
+}
+
+func TestGrepJobLifecycle(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "req_grpc")
+
+	var header metadata.MD
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/*.log"},
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, "req_grpc", created.GetRequestId())
+	assert.Equal(t, []string{"req_grpc"}, header.Get(RequestIDMetadata))
+	assert.Equal(t, grepv1.JobState_JOB_STATE_PENDING, created.GetStatus())
+	assert.False(t, created.GetReplayed())
+
+	// The client supplied request ID deduplicates retries, like it does over REST
+	again, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Pattern: "error", Files: []string{"logs/*.log"}})
+	require.NoError(t, err)
+	assert.True(t, again.GetReplayed())
+	assert.Equal(t, created.GetJobId(), again.GetJobId())
+
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	assert.Equal(t, created.GetJobId(), got.GetJob().GetJobId())
+	assert.Equal(t, int32(1), got.GetJob().GetStats().GetTotalFiles())
+	assert.Equal(t, &grepv1.Pagination{CurrentPage: 1, PerPage: 50}, got.GetPagination())
+
+	cancelled, err := client.CancelGrepJob(ctx, &grepv1.CancelGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	assert.Equal(t, grepv1.JobState_JOB_STATE_CANCELLED, cancelled.GetStatus())
+	assert.NotNil(t, cancelled.GetCompletedAt())
+}
+
+func TestGrepJobErrors(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+	_, err = client.CancelGrepJob(ctx, &grepv1.CancelGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		call   func() error
+		code   codes.Code
+		reason string
+	}{
+		{"EmptyPattern", func() error {
+			_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Files: []string{"logs/app.log"}})
+			return err
+		}, codes.InvalidArgument, api.CodeInvalidRequest},
+		{"UnknownFile", func() error {
+			_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Pattern: "x", Files: []string{"nope.log"}})
+			return err
+		}, codes.NotFound, api.CodeFileNotFound},
+		{"UnknownJob", func() error {
+			_, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: "missing"})
+			return err
+		}, codes.NotFound, api.CodeJobNotFound},
+		{"LimitTooLarge", func() error {
+			_, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: "missing", Limit: 101})
+			return err
+		}, codes.InvalidArgument, api.CodeInvalidRequest},
+		{"CancelFinishedJob", func() error {
+			_, err := client.CancelGrepJob(ctx, &grepv1.CancelGrepJobRequest{JobId: created.GetJobId()})
+			return err
+		}, codes.FailedPrecondition, api.CodeJobFinished},
+		{"StreamUnknownJob", func() error {
+			stream, err := client.StreamMatches(ctx, &grepv1.StreamMatchesRequest{JobId: "missing"})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.NotFound, api.CodeJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			assert.Equal(t, tt.code, status.Code(err), err)
+			assert.Equal(t, tt.reason, errorInfo(t, err).GetReason())
+		})
+	}
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Pattern: "level=(error|warn", Files: []string{"logs/app.log"}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		info := errorInfo(t, err)
+		assert.Equal(t, api.CodeInvalidPattern, info.GetReason())
+		assert.Equal(t, "6", info.GetMetadata()["offset"])
+	})
+}
+
+func TestGrepJobQuery(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Query: &grepv1.PatternQuery{Op: grepv1.QueryOp_QUERY_OP_AND, Children: []*grepv1.PatternQuery{
+			{Pattern: "timeout"},
+			{Op: grepv1.QueryOp_QUERY_OP_NOT, Children: []*grepv1.PatternQuery{{Pattern: "healthcheck"}}},
+		}},
+		Files: []string{"logs/app.log"},
+	})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "timeout", MatchedPatterns: []string{"timeout"}},
+	}})
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	require.Len(t, got.GetResults(), 1)
+	assert.Equal(t, []string{"timeout"}, got.GetResults()[0].GetMatchedPatterns())
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+			Query: &grepv1.PatternQuery{Op: grepv1.QueryOp_QUERY_OP_OR, Children: []*grepv1.PatternQuery{
+				{Pattern: "a"},
+				{Pattern: "(b"},
+			}},
+			Files: []string{"logs/app.log"},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, api.CodeInvalidPattern, errorInfo(t, err).GetReason())
+
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, detail := range status.Convert(err).Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				violations = badRequest.GetFieldViolations()
+			}
+		}
+		require.Len(t, violations, 1)
+		assert.Equal(t, "query.children[1]", violations[0].GetField())
+	})
+
+	t.Run("InvalidOperator", func(t *testing.T) {
+		_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+			Query: &grepv1.PatternQuery{Op: grepv1.QueryOp_QUERY_OP_NOT},
+			Files: []string{"logs/app.log"},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, api.CodeInvalidRequest, errorInfo(t, err).GetReason())
+	})
+}
+
+func TestGrepJobTimeRange(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	since := time.Date(2024, 1, 2, 2, 10, 0, 0, time.UTC)
+	until := since.Add(30 * time.Minute)
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:          "error",
+		Files:            []string{"logs/app.log"},
+		Since:            timestamppb.New(since),
+		Until:            timestamppb.New(until),
+		TimestampFormats: []grepv1.TimestampFormat{grepv1.TimestampFormat_TIMESTAMP_FORMAT_SYSLOG},
+	})
+	require.NoError(t, err)
+
+	logged := since.Add(time.Minute)
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "error", Timestamp: &logged},
+	}})
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	require.Len(t, got.GetResults(), 1)
+	assert.True(t, logged.Equal(got.GetResults()[0].GetTimestamp().AsTime()))
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/app.log"},
+		Since:   timestamppb.New(until),
+		Until:   timestamppb.New(since),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:          "error",
+		Files:            []string{"logs/app.log"},
+		TimestampFormats: []grepv1.TimestampFormat{grepv1.TimestampFormat_TIMESTAMP_FORMAT_UNSPECIFIED},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobOutputControl(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:      "err",
+		Files:        []string{"logs/app.log"},
+		WordRegexp:   true,
+		OnlyMatching: true,
+		MaxCount:     2,
+	})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "err: err", Parts: []string{"err", "err"}},
+		{FileName: "logs/app.log", LineNumber: 2, Content: "err", Parts: []string{"err"}},
+		{FileName: "logs/app.log", LineNumber: 3, Content: "err", Parts: []string{"err"}},
+	}})
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	require.Len(t, got.GetResults(), 2)
+	assert.Equal(t, []string{"err", "err"}, got.GetResults()[0].GetParts())
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:      "err",
+		Files:        []string{"logs/app.log"},
+		ContextLines: 2,
+		OnlyMatching: true,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:  "err",
+		Files:    []string{"logs/app.log"},
+		MaxCount: -1,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobFileCounts(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/app.log"},
+		Mode:    grepv1.SearchMode_SEARCH_MODE_COUNT,
+	})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Counts: map[string]int{"logs/app.log": 3}})
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Counts: map[string]int{"logs/app.log": 2}})
+
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	assert.Empty(t, got.GetResults())
+	require.Len(t, got.GetFileCounts(), 1)
+	assert.Equal(t, "logs/app.log", got.GetFileCounts()[0].GetFile())
+	assert.Equal(t, int32(5), got.GetFileCounts()[0].GetCount())
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/app.log"},
+		Mode:    grepv1.SearchMode(42),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobPriority(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:  "error",
+		Files:    []string{"logs/app.log"},
+		Priority: grepv1.JobPriority_JOB_PRIORITY_BATCH,
+	})
+	require.NoError(t, err)
+
+	job := toJob(&grepv1.CreateGrepJobRequest{Priority: grepv1.JobPriority_JOB_PRIORITY_BATCH}, "")
+	assert.Equal(t, models.PriorityBatch, job.Priority)
+
+	// Nothing dispatches the job in this test
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	assert.Nil(t, got.QueuePosition)
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:  "error",
+		Files:    []string{"logs/app.log"},
+		Priority: grepv1.JobPriority(42),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobRetention(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := context.Background()
+
+	job := toJob(&grepv1.CreateGrepJobRequest{Retention: durationpb.New(time.Hour)}, "")
+	assert.Equal(t, time.Hour, job.Retention)
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:   "error",
+		Files:     []string{"logs/app.log"},
+		Retention: durationpb.New(24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Not finished, so not expiring yet
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	assert.Nil(t, got.GetJob().GetExpiresAt())
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern:   "error",
+		Files:     []string{"logs/app.log"},
+		Retention: durationpb.New(time.Second),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobWebhook(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := context.Background()
+
+	hook := &grepv1.Webhook{Url: "https://example.com/hooks/grep", Secret: "s3cret"}
+	job := toJob(&grepv1.CreateGrepJobRequest{Webhook: hook}, "")
+	assert.Equal(t, &models.Webhook{URL: "https://example.com/hooks/grep", Secret: "s3cret"}, job.Webhook)
+	assert.Nil(t, toJob(&grepv1.CreateGrepJobRequest{}, "").Webhook)
+
+	_, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/app.log"},
+		Webhook: hook,
+	})
+	require.NoError(t, err)
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Pattern: "error",
+		Files:   []string{"logs/app.log"},
+		Webhook: &grepv1.Webhook{Url: "ftp://example.com/hooks/grep", Secret: "s3cret"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGrepJobFixedStrings(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Patterns: []string{"198.51.100.7", "evil.example.com"},
+		Files:    []string{"logs/app.log"},
+	})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{JobID: created.GetJobId(), Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "GET evil.example.com from 198.51.100.7", MatchedPatterns: []string{"198.51.100.7", "evil.example.com"}},
+	}})
+	got, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+	require.Len(t, got.GetResults(), 1)
+	assert.Equal(t, []string{"198.51.100.7", "evil.example.com"}, got.GetResults()[0].GetMatchedPatterns())
+
+	_, err = client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{
+		Patterns: []string{"ok", ""},
+		Files:    []string{"logs/app.log"},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, api.CodeInvalidPattern, errorInfo(t, err).GetReason())
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	require.Len(t, violations, 1)
+	assert.Equal(t, "patterns[1]", violations[0].GetField())
+}
+
+func TestStreamMatches(t *testing.T) {
+	client, storage := newTestClient(t, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateGrepJob(ctx, &grepv1.CreateGrepJobRequest{Pattern: "error", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{
+		ID:        "r1",
+		JobID:     created.GetJobId(),
+		CreatedAt: time.Now(),
+		Matches: []models.Match{
+			{FileName: "logs/app.log", LineNumber: 3, Content: "error one", Context: models.Context{Before: []string{"before"}}},
+			{FileName: "logs/app.log", LineNumber: 8, Content: "error two"},
+		},
+	})
+
+	_, err = client.CancelGrepJob(ctx, &grepv1.CancelGrepJobRequest{JobId: created.GetJobId()})
+	require.NoError(t, err)
+
+	receive := func(lastEventID string) []*grepv1.StreamMatchesResponse {
+		stream, err := client.StreamMatches(ctx, &grepv1.StreamMatchesRequest{JobId: created.GetJobId(), LastEventId: lastEventID})
+		require.NoError(t, err)
+
+		var events []*grepv1.StreamMatchesResponse
+		for {
+			ev, err := stream.Recv()
+			if err == io.EOF {
+				return events
+			}
+			require.NoError(t, err)
+			events = append(events, ev)
+		}
+	}
+
+	// A finished job replays its matches and closes the stream
+	events := receive("")
+	require.Len(t, events, 4)
+	assert.Regexp(t, `^stream_[0-9a-f]+:r1:0$`, events[0].GetId())
+	assert.Equal(t, &grepv1.GrepMatch{File: "logs/app.log", LineNumber: 3, Content: "error one", ContextBefore: []string{"before"}}, events[0].GetMatch())
+	assert.Regexp(t, `:r1:1$`, events[1].GetId())
+	assert.NotNil(t, events[2].GetStats())
+	assert.Equal(t, grepv1.JobState_JOB_STATE_CANCELLED, events[3].GetStatus().GetStatus())
+
+	// Resuming skips what the client already received
+	events = receive(events[0].GetId())
+	require.Len(t, events, 3)
+	assert.Regexp(t, `:r1:1$`, events[0].GetId())
+}
+
+func TestAuthentication(t *testing.T) {
+	sum := sha256.Sum256([]byte("key-a"))
+	keyring, err := service.NewKeyring([]service.APIKey{
+		{Tenant: "team-a", KeySHA256: hex.EncodeToString(sum[:])},
+	})
+	require.NoError(t, err)
+
+	client, storage := newTestClient(t, keyring)
+	storage.AddFile("tenants/team-a/logs/a.log", "a\n")
+
+	for _, md := range []metadata.MD{nil, metadata.Pairs(APIKeyMetadata, "wrong"), metadata.Pairs("authorization", "Basic key-a")} {
+		ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+		_, err := client.GetGrepJob(ctx, &grepv1.GetGrepJobRequest{JobId: "missing"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+		stream, err := client.StreamMatches(ctx, &grepv1.StreamMatchesRequest{JobId: "missing"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), err)
+	}
+
+	asA := metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, "key-a")
+	created, err := client.CreateGrepJob(asA, &grepv1.CreateGrepJobRequest{Pattern: "a", Files: []string{"logs/a.log"}})
+	require.NoError(t, err)
+
+	asBearer := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer key-a")
+	_, err = client.GetGrepJob(asBearer, &grepv1.GetGrepJobRequest{JobId: created.GetJobId()})
+	assert.NoError(t, err)
+
+	// Files outside the tenant namespace do not exist for it
+	_, err = client.CreateGrepJob(asA, &grepv1.CreateGrepJobRequest{Pattern: "a", Files: []string{"logs/app.log"}})
+	assert.Equal(t, codes.NotFound, status.Code(err), err)
+}
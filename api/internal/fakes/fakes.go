@@ -0,0 +1,193 @@
+// Package fakes provides in-memory stand-ins for MinIO and NATS in tests
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/minio"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Storage is an in-memory implementation of the storage used by the service.
+// Like minio.Storage, objects of a tenant are stored under its object prefix,
+// AddFile and Content take object names including that prefix.
+type Storage struct {
+	mu      sync.Mutex
+	files   map[string]models.LogFile
+	content map[string][]byte
+	results map[string][]models.Result
+}
+
+// NewStorage returns an empty Storage
+func NewStorage() *Storage {
+	return &Storage{
+		files:   make(map[string]models.LogFile),
+		content: make(map[string][]byte),
+		results: make(map[string][]models.Result),
+	}
+}
+
+// AddFile stores a log file with the given content
+func (s *Storage) AddFile(filePath, content string) {
+	s.UploadLogFile(context.Background(), models.LogFile{Path: filePath}, strings.NewReader(content))
+}
+
+// AddResult stores a mapper result in the namespace of result.Tenant
+func (s *Storage) AddResult(result models.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := tenant.ObjectPrefix(result.Tenant) + result.JobID
+	s.results[key] = append(s.results[key], result)
+}
+
+func objectName(ctx context.Context, key string) string {
+	return tenant.ObjectPrefix(tenant.FromContext(ctx)) + key
+}
+
+// Content returns the stored content of a log file
+func (s *Storage) Content(filePath string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.content[filePath]
+	return string(data), ok
+}
+
+func (s *Storage) UploadLogFile(ctx context.Context, logFile models.LogFile, reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := objectName(ctx, logFile.Path)
+	s.files[name] = models.LogFile{
+		Name:      path.Base(logFile.Path),
+		Path:      name,
+		Size:      int64(len(data)),
+		UpdatedAt: time.Now().UTC(),
+	}
+	s.content[name] = data
+	return nil
+}
+
+func (s *Storage) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
+	return s.ListLogFilesWithPrefix(ctx, "")
+}
+
+func (s *Storage) ListLogFilesWithPrefix(ctx context.Context, prefix string) ([]models.LogFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespace := objectName(ctx, "")
+	var files []models.LogFile
+	for p, file := range s.files {
+		if strings.HasPrefix(p, namespace+prefix) {
+			file.Path = strings.TrimPrefix(p, namespace)
+			files = append(files, file)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (s *Storage) StatLogFile(ctx context.Context, filePath string) (*models.LogFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[objectName(ctx, filePath)]
+	if !ok {
+		return nil, fmt.Errorf("%w: log file %s", minio.ErrObjectNotFound, filePath)
+	}
+	file.Path = filePath
+	return &file, nil
+}
+
+func (s *Storage) DeleteLogFile(ctx context.Context, filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, objectName(ctx, filePath))
+	delete(s.content, objectName(ctx, filePath))
+	return nil
+}
+
+func (s *Storage) GetJobResults(ctx context.Context, jobID string) ([]models.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]models.Result(nil), s.results[objectName(ctx, jobID)]...), nil
+}
+
+func (s *Storage) WalkJobResults(ctx context.Context, jobID string, fn func(result models.Result) error) error {
+	results, _ := s.GetJobResults(ctx, jobID)
+	for _, result := range results {
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) GetResult(ctx context.Context, jobID, resultID string) (*models.Result, error) {
+	results, _ := s.GetJobResults(ctx, jobID)
+	for _, result := range results {
+		if result.ID == resultID {
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: result %s", minio.ErrObjectNotFound, resultID)
+}
+
+// DeleteJob removes the results of a job, chunks are not kept by Storage
+func (s *Storage) DeleteJob(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.results, objectName(ctx, jobID))
+	return nil
+}
+
+// Message is a message sent through Bus
+type Message struct {
+	Subject string
+	Msg     any
+}
+
+// Bus records published messages, Err makes every publish fail
+type Bus struct {
+	mu       sync.Mutex
+	messages []Message
+	Err      error
+}
+
+func (b *Bus) Publish(ctx context.Context, subject string, msg any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Err != nil {
+		return b.Err
+	}
+	b.messages = append(b.messages, Message{subject, msg})
+	return nil
+}
+
+// Messages returns the messages published so far
+func (b *Bus) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Message(nil), b.messages...)
+}
@@ -0,0 +1,1905 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: grep/v1/grep.proto
+
+// gRPC front door of the distributed grep API, it mirrors the REST API in
+// spec/oapi.yaml and is served by the same job service
+
+package grepv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_STATE_PENDING     JobState = 1
+	JobState_JOB_STATE_IN_PROGRESS JobState = 2
+	JobState_JOB_STATE_COMPLETED   JobState = 3
+	JobState_JOB_STATE_FAILED      JobState = 4
+	JobState_JOB_STATE_CANCELLED   JobState = 5
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_PENDING",
+		2: "JOB_STATE_IN_PROGRESS",
+		3: "JOB_STATE_COMPLETED",
+		4: "JOB_STATE_FAILED",
+		5: "JOB_STATE_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_PENDING":     1,
+		"JOB_STATE_IN_PROGRESS": 2,
+		"JOB_STATE_COMPLETED":   3,
+		"JOB_STATE_FAILED":      4,
+		"JOB_STATE_CANCELLED":   5,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_grep_v1_grep_proto_enumTypes[0].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_grep_v1_grep_proto_enumTypes[0]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{0}
+}
+
+// Chunks of interactive jobs are dispatched before those of normal jobs,
+// which go before those of batch jobs, and jobs of the same class go first
+// come first served. Batch jobs are paused altogether while an interactive
+// job is being searched.
+type JobPriority int32
+
+const (
+	JobPriority_JOB_PRIORITY_UNSPECIFIED JobPriority = 0
+	JobPriority_JOB_PRIORITY_INTERACTIVE JobPriority = 1
+	JobPriority_JOB_PRIORITY_NORMAL      JobPriority = 2
+	JobPriority_JOB_PRIORITY_BATCH       JobPriority = 3
+)
+
+// Enum value maps for JobPriority.
+var (
+	JobPriority_name = map[int32]string{
+		0: "JOB_PRIORITY_UNSPECIFIED",
+		1: "JOB_PRIORITY_INTERACTIVE",
+		2: "JOB_PRIORITY_NORMAL",
+		3: "JOB_PRIORITY_BATCH",
+	}
+	JobPriority_value = map[string]int32{
+		"JOB_PRIORITY_UNSPECIFIED": 0,
+		"JOB_PRIORITY_INTERACTIVE": 1,
+		"JOB_PRIORITY_NORMAL":      2,
+		"JOB_PRIORITY_BATCH":       3,
+	}
+)
+
+func (x JobPriority) Enum() *JobPriority {
+	p := new(JobPriority)
+	*p = x
+	return p
+}
+
+func (x JobPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_grep_v1_grep_proto_enumTypes[1].Descriptor()
+}
+
+func (JobPriority) Type() protoreflect.EnumType {
+	return &file_grep_v1_grep_proto_enumTypes[1]
+}
+
+func (x JobPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobPriority.Descriptor instead.
+func (JobPriority) EnumDescriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{1}
+}
+
+// Counting modes report GetGrepJobResponse.file_counts instead of results
+// and cannot be combined with context_lines or only_matching. The remaining
+// chunks of a file are skipped once it is known to match in
+// SEARCH_MODE_FILES_WITH_MATCHES and SEARCH_MODE_FILES_WITHOUT_MATCH.
+type SearchMode int32
+
+const (
+	SearchMode_SEARCH_MODE_UNSPECIFIED SearchMode = 0
+	SearchMode_SEARCH_MODE_LINES       SearchMode = 1
+	// Number of selected lines of each file, like grep -c
+	SearchMode_SEARCH_MODE_COUNT SearchMode = 2
+	// Files with a selected line, like grep -l
+	SearchMode_SEARCH_MODE_FILES_WITH_MATCHES SearchMode = 3
+	// Files without a selected line, like grep -L
+	SearchMode_SEARCH_MODE_FILES_WITHOUT_MATCH SearchMode = 4
+)
+
+// Enum value maps for SearchMode.
+var (
+	SearchMode_name = map[int32]string{
+		0: "SEARCH_MODE_UNSPECIFIED",
+		1: "SEARCH_MODE_LINES",
+		2: "SEARCH_MODE_COUNT",
+		3: "SEARCH_MODE_FILES_WITH_MATCHES",
+		4: "SEARCH_MODE_FILES_WITHOUT_MATCH",
+	}
+	SearchMode_value = map[string]int32{
+		"SEARCH_MODE_UNSPECIFIED":         0,
+		"SEARCH_MODE_LINES":               1,
+		"SEARCH_MODE_COUNT":               2,
+		"SEARCH_MODE_FILES_WITH_MATCHES":  3,
+		"SEARCH_MODE_FILES_WITHOUT_MATCH": 4,
+	}
+)
+
+func (x SearchMode) Enum() *SearchMode {
+	p := new(SearchMode)
+	*p = x
+	return p
+}
+
+func (x SearchMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SearchMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_grep_v1_grep_proto_enumTypes[2].Descriptor()
+}
+
+func (SearchMode) Type() protoreflect.EnumType {
+	return &file_grep_v1_grep_proto_enumTypes[2]
+}
+
+func (x SearchMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SearchMode.Descriptor instead.
+func (SearchMode) EnumDescriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{2}
+}
+
+type TimestampFormat int32
+
+const (
+	TimestampFormat_TIMESTAMP_FORMAT_UNSPECIFIED  TimestampFormat = 0
+	TimestampFormat_TIMESTAMP_FORMAT_RFC3339      TimestampFormat = 1
+	TimestampFormat_TIMESTAMP_FORMAT_SYSLOG       TimestampFormat = 2
+	TimestampFormat_TIMESTAMP_FORMAT_NGINX        TimestampFormat = 3
+	TimestampFormat_TIMESTAMP_FORMAT_EPOCH_MILLIS TimestampFormat = 4
+)
+
+// Enum value maps for TimestampFormat.
+var (
+	TimestampFormat_name = map[int32]string{
+		0: "TIMESTAMP_FORMAT_UNSPECIFIED",
+		1: "TIMESTAMP_FORMAT_RFC3339",
+		2: "TIMESTAMP_FORMAT_SYSLOG",
+		3: "TIMESTAMP_FORMAT_NGINX",
+		4: "TIMESTAMP_FORMAT_EPOCH_MILLIS",
+	}
+	TimestampFormat_value = map[string]int32{
+		"TIMESTAMP_FORMAT_UNSPECIFIED":  0,
+		"TIMESTAMP_FORMAT_RFC3339":      1,
+		"TIMESTAMP_FORMAT_SYSLOG":       2,
+		"TIMESTAMP_FORMAT_NGINX":        3,
+		"TIMESTAMP_FORMAT_EPOCH_MILLIS": 4,
+	}
+)
+
+func (x TimestampFormat) Enum() *TimestampFormat {
+	p := new(TimestampFormat)
+	*p = x
+	return p
+}
+
+func (x TimestampFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimestampFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_grep_v1_grep_proto_enumTypes[3].Descriptor()
+}
+
+func (TimestampFormat) Type() protoreflect.EnumType {
+	return &file_grep_v1_grep_proto_enumTypes[3]
+}
+
+func (x TimestampFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimestampFormat.Descriptor instead.
+func (TimestampFormat) EnumDescriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{3}
+}
+
+type QueryOp int32
+
+const (
+	QueryOp_QUERY_OP_UNSPECIFIED QueryOp = 0
+	QueryOp_QUERY_OP_AND         QueryOp = 1
+	QueryOp_QUERY_OP_OR          QueryOp = 2
+	QueryOp_QUERY_OP_NOT         QueryOp = 3
+)
+
+// Enum value maps for QueryOp.
+var (
+	QueryOp_name = map[int32]string{
+		0: "QUERY_OP_UNSPECIFIED",
+		1: "QUERY_OP_AND",
+		2: "QUERY_OP_OR",
+		3: "QUERY_OP_NOT",
+	}
+	QueryOp_value = map[string]int32{
+		"QUERY_OP_UNSPECIFIED": 0,
+		"QUERY_OP_AND":         1,
+		"QUERY_OP_OR":          2,
+		"QUERY_OP_NOT":         3,
+	}
+)
+
+func (x QueryOp) Enum() *QueryOp {
+	p := new(QueryOp)
+	*p = x
+	return p
+}
+
+func (x QueryOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueryOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_grep_v1_grep_proto_enumTypes[4].Descriptor()
+}
+
+func (QueryOp) Type() protoreflect.EnumType {
+	return &file_grep_v1_grep_proto_enumTypes[4]
+}
+
+func (x QueryOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueryOp.Descriptor instead.
+func (QueryOp) EnumDescriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{4}
+}
+
+type CreateGrepJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Grep pattern to search for, exactly one of pattern, patterns and query is
+	// required
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// Files or patterns to search in, between 1 and 100
+	Files []string `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	// Number of context lines before and after a match, between 0 and 10
+	ContextLines int32 `protobuf:"varint,3,opt,name=context_lines,json=contextLines,proto3" json:"context_lines,omitempty"`
+	// Whether to perform case-sensitive search, defaults to false
+	CaseSensitive *bool `protobuf:"varint,4,opt,name=case_sensitive,json=caseSensitive,proto3,oneof" json:"case_sensitive,omitempty"`
+	// Whether to interpret pattern as regex, defaults to true, or to false when
+	// patterns is set
+	Regex *bool `protobuf:"varint,5,opt,name=regex,proto3,oneof" json:"regex,omitempty"`
+	// Repeating a request with the same key returns the job created the first
+	// time, the x-request-id metadata is used if empty
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Boolean expression of patterns to search for instead of pattern
+	Query *PatternQuery `protobuf:"bytes,7,opt,name=query,proto3" json:"query,omitempty"`
+	// Only search lines logged at or after since and before until
+	Since *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=since,proto3" json:"since,omitempty"`
+	Until *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=until,proto3" json:"until,omitempty"`
+	// Formats the timestamp of each line is parsed with, tried in order, all
+	// of them if empty. Lines without a timestamp inherit the one of the line
+	// before.
+	TimestampFormats []TimestampFormat `protobuf:"varint,10,rep,packed,name=timestamp_formats,json=timestampFormats,proto3,enum=grep.v1.TimestampFormat" json:"timestamp_formats,omitempty"`
+	// Select the lines that do not match, like grep -v
+	InvertMatch bool `protobuf:"varint,11,opt,name=invert_match,json=invertMatch,proto3" json:"invert_match,omitempty"`
+	// Only match whole words, like grep -w
+	WordRegexp bool `protobuf:"varint,12,opt,name=word_regexp,json=wordRegexp,proto3" json:"word_regexp,omitempty"`
+	// Only match whole lines, like grep -x
+	LineRegexp bool `protobuf:"varint,13,opt,name=line_regexp,json=lineRegexp,proto3" json:"line_regexp,omitempty"`
+	// Report the matched parts of each line in GrepMatch.parts, like grep -o.
+	// Cannot be combined with context_lines.
+	OnlyMatching bool `protobuf:"varint,14,opt,name=only_matching,json=onlyMatching,proto3" json:"only_matching,omitempty"`
+	// Stop after this many selected lines per file, like grep -m, 0 for no
+	// limit. StreamMatches may send matches past the limit found by other
+	// chunks of a file before they were trimmed.
+	MaxCount int32 `protobuf:"varint,15,opt,name=max_count,json=maxCount,proto3" json:"max_count,omitempty"`
+	// What the job reports, selected lines if unspecified
+	Mode SearchMode `protobuf:"varint,16,opt,name=mode,proto3,enum=grep.v1.SearchMode" json:"mode,omitempty"`
+	// Fixed strings to search for instead of pattern, like grep -F -f. A line
+	// containing any of them matches and GrepMatch.matched_patterns lists the
+	// ones found on it. At most 10000 strings of 256 KiB together.
+	Patterns []string `protobuf:"bytes,17,rep,name=patterns,proto3" json:"patterns,omitempty"`
+	// Class the chunks of the job are dispatched in, normal if unspecified
+	Priority JobPriority `protobuf:"varint,18,opt,name=priority,proto3,enum=grep.v1.JobPriority" json:"priority,omitempty"`
+	// How long the job, its chunks and results are kept once it finished,
+	// between a minute and a year. The retention configured in the manager
+	// applies if unset.
+	Retention *durationpb.Duration `protobuf:"bytes,19,opt,name=retention,proto3" json:"retention,omitempty"`
+	// Endpoint notified once the job finished, see the webhook of the REST API
+	// for how deliveries are signed and retried
+	Webhook       *Webhook `protobuf:"bytes,20,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGrepJobRequest) Reset() {
+	*x = CreateGrepJobRequest{}
+	mi := &file_grep_v1_grep_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGrepJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGrepJobRequest) ProtoMessage() {}
+
+func (x *CreateGrepJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGrepJobRequest.ProtoReflect.Descriptor instead.
+func (*CreateGrepJobRequest) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateGrepJobRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *CreateGrepJobRequest) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetContextLines() int32 {
+	if x != nil {
+		return x.ContextLines
+	}
+	return 0
+}
+
+func (x *CreateGrepJobRequest) GetCaseSensitive() bool {
+	if x != nil && x.CaseSensitive != nil {
+		return *x.CaseSensitive
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetRegex() bool {
+	if x != nil && x.Regex != nil {
+		return *x.Regex
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateGrepJobRequest) GetQuery() *PatternQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetTimestampFormats() []TimestampFormat {
+	if x != nil {
+		return x.TimestampFormats
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetInvertMatch() bool {
+	if x != nil {
+		return x.InvertMatch
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetWordRegexp() bool {
+	if x != nil {
+		return x.WordRegexp
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetLineRegexp() bool {
+	if x != nil {
+		return x.LineRegexp
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetOnlyMatching() bool {
+	if x != nil {
+		return x.OnlyMatching
+	}
+	return false
+}
+
+func (x *CreateGrepJobRequest) GetMaxCount() int32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *CreateGrepJobRequest) GetMode() SearchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SearchMode_SEARCH_MODE_UNSPECIFIED
+}
+
+func (x *CreateGrepJobRequest) GetPatterns() []string {
+	if x != nil {
+		return x.Patterns
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetPriority() JobPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return JobPriority_JOB_PRIORITY_UNSPECIFIED
+}
+
+func (x *CreateGrepJobRequest) GetRetention() *durationpb.Duration {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *CreateGrepJobRequest) GetWebhook() *Webhook {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+// PatternQuery is a boolean expression of patterns matched against each
+// line. A node is either a leaf with a pattern and its own search options, or
+// an op combining its children. Queries nest at most 8 operators deep and
+// hold at most 32 patterns.
+type PatternQuery struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Operator of the node, unspecified for leaves. NOT takes exactly one child.
+	Op       QueryOp         `protobuf:"varint,1,opt,name=op,proto3,enum=grep.v1.QueryOp" json:"op,omitempty"`
+	Children []*PatternQuery `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"`
+	// Label of a leaf reported in GrepMatch.matched_patterns, defaults to its
+	// pattern. Labels must be unique within a query.
+	Id string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	// Pattern of a leaf
+	Pattern string `protobuf:"bytes,4,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// Whether to match the leaf case-sensitively, defaults to false
+	CaseSensitive *bool `protobuf:"varint,5,opt,name=case_sensitive,json=caseSensitive,proto3,oneof" json:"case_sensitive,omitempty"`
+	// Whether to interpret the leaf pattern as regex, defaults to true
+	Regex         *bool `protobuf:"varint,6,opt,name=regex,proto3,oneof" json:"regex,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatternQuery) Reset() {
+	*x = PatternQuery{}
+	mi := &file_grep_v1_grep_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatternQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatternQuery) ProtoMessage() {}
+
+func (x *PatternQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatternQuery.ProtoReflect.Descriptor instead.
+func (*PatternQuery) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PatternQuery) GetOp() QueryOp {
+	if x != nil {
+		return x.Op
+	}
+	return QueryOp_QUERY_OP_UNSPECIFIED
+}
+
+func (x *PatternQuery) GetChildren() []*PatternQuery {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *PatternQuery) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PatternQuery) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PatternQuery) GetCaseSensitive() bool {
+	if x != nil && x.CaseSensitive != nil {
+		return *x.CaseSensitive
+	}
+	return false
+}
+
+func (x *PatternQuery) GetRegex() bool {
+	if x != nil && x.Regex != nil {
+		return *x.Regex
+	}
+	return false
+}
+
+// Webhook is an endpoint a WebhookEvent of the REST API is POSTed to once the
+// job is completed, failed or cancelled
+type Webhook struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Absolute http or https url, at most 2048 characters
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Shared secret used to sign deliveries, between 1 and 256 characters
+	Secret        string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Webhook) Reset() {
+	*x = Webhook{}
+	mi := &file_grep_v1_grep_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhook) ProtoMessage() {}
+
+func (x *Webhook) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Webhook.ProtoReflect.Descriptor instead.
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Webhook) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Webhook) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type CreateGrepJobResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	JobId     string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	RequestId string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Status    JobState               `protobuf:"varint,3,opt,name=status,proto3,enum=grep.v1.JobState" json:"status,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Whether the job was created by an earlier request with the same key
+	Replayed      bool `protobuf:"varint,5,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGrepJobResponse) Reset() {
+	*x = CreateGrepJobResponse{}
+	mi := &file_grep_v1_grep_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGrepJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGrepJobResponse) ProtoMessage() {}
+
+func (x *CreateGrepJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGrepJobResponse.ProtoReflect.Descriptor instead.
+func (*CreateGrepJobResponse) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateGrepJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *CreateGrepJobResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CreateGrepJobResponse) GetStatus() JobState {
+	if x != nil {
+		return x.Status
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *CreateGrepJobResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *CreateGrepJobResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+type GetGrepJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Page of results to return, defaults to 1
+	Page int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	// Results per page, defaults to 50 and at most 100
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGrepJobRequest) Reset() {
+	*x = GetGrepJobRequest{}
+	mi := &file_grep_v1_grep_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGrepJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGrepJobRequest) ProtoMessage() {}
+
+func (x *GetGrepJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGrepJobRequest.ProtoReflect.Descriptor instead.
+func (*GetGrepJobRequest) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetGrepJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *GetGrepJobRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetGrepJobRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetGrepJobResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Job        *JobStatus             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	Results    []*GrepMatch           `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	Pagination *Pagination            `protobuf:"bytes,3,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	// Files reported by a job in a counting mode, in place of results
+	FileCounts []*FileCount `protobuf:"bytes,4,rep,name=file_counts,json=fileCounts,proto3" json:"file_counts,omitempty"`
+	// Jobs of any tenant ahead of this one in the dispatch queue, absent once
+	// all chunks of the job were handed to the mappers
+	QueuePosition *int32 `protobuf:"varint,5,opt,name=queue_position,json=queuePosition,proto3,oneof" json:"queue_position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGrepJobResponse) Reset() {
+	*x = GetGrepJobResponse{}
+	mi := &file_grep_v1_grep_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGrepJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGrepJobResponse) ProtoMessage() {}
+
+func (x *GetGrepJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGrepJobResponse.ProtoReflect.Descriptor instead.
+func (*GetGrepJobResponse) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetGrepJobResponse) GetJob() *JobStatus {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *GetGrepJobResponse) GetResults() []*GrepMatch {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *GetGrepJobResponse) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+func (x *GetGrepJobResponse) GetFileCounts() []*FileCount {
+	if x != nil {
+		return x.FileCounts
+	}
+	return nil
+}
+
+func (x *GetGrepJobResponse) GetQueuePosition() int32 {
+	if x != nil && x.QueuePosition != nil {
+		return *x.QueuePosition
+	}
+	return 0
+}
+
+type FileCount struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	File  string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	// Selected lines, at most max_count, 1 for a file with a match in the
+	// modes listing files
+	Count         int32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileCount) Reset() {
+	*x = FileCount{}
+	mi := &file_grep_v1_grep_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileCount) ProtoMessage() {}
+
+func (x *FileCount) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileCount.ProtoReflect.Descriptor instead.
+func (*FileCount) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FileCount) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FileCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type CancelGrepJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelGrepJobRequest) Reset() {
+	*x = CancelGrepJobRequest{}
+	mi := &file_grep_v1_grep_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelGrepJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelGrepJobRequest) ProtoMessage() {}
+
+func (x *CancelGrepJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelGrepJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelGrepJobRequest) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelGrepJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type StreamMatchesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// ID of the last match received, resumes the stream right after it
+	LastEventId   string `protobuf:"bytes,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamMatchesRequest) Reset() {
+	*x = StreamMatchesRequest{}
+	mi := &file_grep_v1_grep_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamMatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMatchesRequest) ProtoMessage() {}
+
+func (x *StreamMatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMatchesRequest.ProtoReflect.Descriptor instead.
+func (*StreamMatchesRequest) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamMatchesRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *StreamMatchesRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type StreamMatchesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resume token, only set for matches
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*StreamMatchesResponse_Match
+	//	*StreamMatchesResponse_Stats
+	//	*StreamMatchesResponse_Status
+	Event         isStreamMatchesResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamMatchesResponse) Reset() {
+	*x = StreamMatchesResponse{}
+	mi := &file_grep_v1_grep_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamMatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMatchesResponse) ProtoMessage() {}
+
+func (x *StreamMatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMatchesResponse.ProtoReflect.Descriptor instead.
+func (*StreamMatchesResponse) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamMatchesResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StreamMatchesResponse) GetEvent() isStreamMatchesResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *StreamMatchesResponse) GetMatch() *GrepMatch {
+	if x != nil {
+		if x, ok := x.Event.(*StreamMatchesResponse_Match); ok {
+			return x.Match
+		}
+	}
+	return nil
+}
+
+func (x *StreamMatchesResponse) GetStats() *JobStatus {
+	if x != nil {
+		if x, ok := x.Event.(*StreamMatchesResponse_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+func (x *StreamMatchesResponse) GetStatus() *JobStatus {
+	if x != nil {
+		if x, ok := x.Event.(*StreamMatchesResponse_Status); ok {
+			return x.Status
+		}
+	}
+	return nil
+}
+
+type isStreamMatchesResponse_Event interface {
+	isStreamMatchesResponse_Event()
+}
+
+type StreamMatchesResponse_Match struct {
+	// A single match
+	Match *GrepMatch `protobuf:"bytes,2,opt,name=match,proto3,oneof"`
+}
+
+type StreamMatchesResponse_Stats struct {
+	// Periodic job status and stats
+	Stats *JobStatus `protobuf:"bytes,3,opt,name=stats,proto3,oneof"`
+}
+
+type StreamMatchesResponse_Status struct {
+	// Final job status, last message of the stream
+	Status *JobStatus `protobuf:"bytes,4,opt,name=status,proto3,oneof"`
+}
+
+func (*StreamMatchesResponse_Match) isStreamMatchesResponse_Event() {}
+
+func (*StreamMatchesResponse_Stats) isStreamMatchesResponse_Event() {}
+
+func (*StreamMatchesResponse_Status) isStreamMatchesResponse_Event() {}
+
+type JobStatus struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	JobId     string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	RequestId string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Status    JobState               `protobuf:"varint,3,opt,name=status,proto3,enum=grep.v1.JobState" json:"status,omitempty"`
+	// Progress percentage, between 0 and 100
+	Progress    int32                  `protobuf:"varint,4,opt,name=progress,proto3" json:"progress,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Left out where the REST API leaves it out
+	Stats *JobStats `protobuf:"bytes,7,opt,name=stats,proto3" json:"stats,omitempty"`
+	// Error message if the job failed
+	Error string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// When the job and its data are removed, set once it finished
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobStatus) Reset() {
+	*x = JobStatus{}
+	mi := &file_grep_v1_grep_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStatus) ProtoMessage() {}
+
+func (x *JobStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
+func (*JobStatus) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JobStatus) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobStatus) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *JobStatus) GetStatus() JobState {
+	if x != nil {
+		return x.Status
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *JobStatus) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *JobStatus) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetStats() *JobStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *JobStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *JobStatus) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type JobStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalFiles     int32                  `protobuf:"varint,1,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	ProcessedFiles int32                  `protobuf:"varint,2,opt,name=processed_files,json=processedFiles,proto3" json:"processed_files,omitempty"`
+	TotalMatches   int32                  `protobuf:"varint,3,opt,name=total_matches,json=totalMatches,proto3" json:"total_matches,omitempty"`
+	BytesProcessed int64                  `protobuf:"varint,4,opt,name=bytes_processed,json=bytesProcessed,proto3" json:"bytes_processed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *JobStats) Reset() {
+	*x = JobStats{}
+	mi := &file_grep_v1_grep_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStats) ProtoMessage() {}
+
+func (x *JobStats) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStats.ProtoReflect.Descriptor instead.
+func (*JobStats) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JobStats) GetTotalFiles() int32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *JobStats) GetProcessedFiles() int32 {
+	if x != nil {
+		return x.ProcessedFiles
+	}
+	return 0
+}
+
+func (x *JobStats) GetTotalMatches() int32 {
+	if x != nil {
+		return x.TotalMatches
+	}
+	return 0
+}
+
+func (x *JobStats) GetBytesProcessed() int64 {
+	if x != nil {
+		return x.BytesProcessed
+	}
+	return 0
+}
+
+type GrepMatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Source file where the match was found
+	File          string   `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	LineNumber    int32    `protobuf:"varint,2,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	Content       string   `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	ContextBefore []string `protobuf:"bytes,4,rep,name=context_before,json=contextBefore,proto3" json:"context_before,omitempty"`
+	ContextAfter  []string `protobuf:"bytes,5,rep,name=context_after,json=contextAfter,proto3" json:"context_after,omitempty"`
+	// Labels of the query patterns, or the fixed strings of patterns, found on
+	// the line, empty for a single pattern
+	MatchedPatterns []string `protobuf:"bytes,6,rep,name=matched_patterns,json=matchedPatterns,proto3" json:"matched_patterns,omitempty"`
+	// When the line was logged, only set for jobs with a time range or
+	// timestamp formats
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Matched parts of the line in order, only with only_matching
+	Parts         []string `protobuf:"bytes,8,rep,name=parts,proto3" json:"parts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrepMatch) Reset() {
+	*x = GrepMatch{}
+	mi := &file_grep_v1_grep_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrepMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrepMatch) ProtoMessage() {}
+
+func (x *GrepMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrepMatch.ProtoReflect.Descriptor instead.
+func (*GrepMatch) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GrepMatch) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *GrepMatch) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *GrepMatch) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *GrepMatch) GetContextBefore() []string {
+	if x != nil {
+		return x.ContextBefore
+	}
+	return nil
+}
+
+func (x *GrepMatch) GetContextAfter() []string {
+	if x != nil {
+		return x.ContextAfter
+	}
+	return nil
+}
+
+func (x *GrepMatch) GetMatchedPatterns() []string {
+	if x != nil {
+		return x.MatchedPatterns
+	}
+	return nil
+}
+
+func (x *GrepMatch) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *GrepMatch) GetParts() []string {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type Pagination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrentPage   int32                  `protobuf:"varint,1,opt,name=current_page,json=currentPage,proto3" json:"current_page,omitempty"`
+	TotalPages    int32                  `protobuf:"varint,2,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	TotalResults  int32                  `protobuf:"varint,3,opt,name=total_results,json=totalResults,proto3" json:"total_results,omitempty"`
+	PerPage       int32                  `protobuf:"varint,4,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pagination) Reset() {
+	*x = Pagination{}
+	mi := &file_grep_v1_grep_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pagination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pagination) ProtoMessage() {}
+
+func (x *Pagination) ProtoReflect() protoreflect.Message {
+	mi := &file_grep_v1_grep_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pagination.ProtoReflect.Descriptor instead.
+func (*Pagination) Descriptor() ([]byte, []int) {
+	return file_grep_v1_grep_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Pagination) GetCurrentPage() int32 {
+	if x != nil {
+		return x.CurrentPage
+	}
+	return 0
+}
+
+func (x *Pagination) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+func (x *Pagination) GetTotalResults() int32 {
+	if x != nil {
+		return x.TotalResults
+	}
+	return 0
+}
+
+func (x *Pagination) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+var File_grep_v1_grep_proto protoreflect.FileDescriptor
+
+var file_grep_v1_grep_proto_rawDesc = string([]byte{
+	0x0a, 0x12, 0x67, 0x72, 0x65, 0x70, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd3,
+	0x06, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x78, 0x74, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x0e,
+	0x63, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0d, 0x63, 0x61, 0x73, 0x65, 0x53, 0x65, 0x6e, 0x73,
+	0x69, 0x74, 0x69, 0x76, 0x65, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x72, 0x65, 0x67, 0x65,
+	0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78,
+	0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64,
+	0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x2b, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72,
+	0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e,
+	0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x75,
+	0x6e, 0x74, 0x69, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x45, 0x0a,
+	0x11, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61,
+	0x74, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x52, 0x10, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x5f, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x6e, 0x76, 0x65,
+	0x72, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x64, 0x5f,
+	0x72, 0x65, 0x67, 0x65, 0x78, 0x70, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x77, 0x6f,
+	0x72, 0x64, 0x52, 0x65, 0x67, 0x65, 0x78, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x65,
+	0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x70, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6c,
+	0x69, 0x6e, 0x65, 0x52, 0x65, 0x67, 0x65, 0x78, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x6f, 0x6e, 0x6c,
+	0x79, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x6f, 0x6e, 0x6c, 0x79, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x1b,
+	0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x04, 0x6d,
+	0x6f, 0x64, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x65, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73,
+	0x18, 0x11, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73,
+	0x12, 0x30, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x12, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x14, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62,
+	0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x09, 0x72, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x07, 0x77,
+	0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67,
+	0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x52, 0x07,
+	0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x63, 0x61, 0x73, 0x65,
+	0x5f, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x72,
+	0x65, 0x67, 0x65, 0x78, 0x22, 0xf1, 0x01, 0x0a, 0x0c, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x4f, 0x70, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x31, 0x0a, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64,
+	0x72, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x72, 0x65, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x52, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74,
+	0x74, 0x65, 0x72, 0x6e, 0x12, 0x2a, 0x0a, 0x0e, 0x63, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x65, 0x6e,
+	0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0d,
+	0x63, 0x61, 0x73, 0x65, 0x53, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x19, 0x0a, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x01, 0x52, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x88, 0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f,
+	0x63, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x42, 0x08,
+	0x0a, 0x06, 0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x22, 0x33, 0x0a, 0x07, 0x57, 0x65, 0x62, 0x68,
+	0x6f, 0x6f, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x22, 0xcf, 0x01,
+	0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x29, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e,
+	0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x22,
+	0x54, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x91, 0x02, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x03,
+	0x6a, 0x6f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x03, 0x6a,
+	0x6f, 0x62, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72,
+	0x65, 0x70, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x12, 0x33, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x70, 0x61, 0x67, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x0a,
+	0x66, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x0e, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x00, 0x52, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x35, 0x0a, 0x09, 0x46, 0x69, 0x6c,
+	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x22, 0x2d, 0x0a, 0x14, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f,
+	0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x22,
+	0x51, 0x0a, 0x14, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x12, 0x22,
+	0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x49, 0x64, 0x22, 0xb6, 0x01, 0x0a, 0x15, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2a, 0x0a, 0x05,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72,
+	0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x65, 0x70, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x48,
+	0x00, 0x52, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x2a, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4a,
+	0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0xfc, 0x02, 0x0a, 0x09,
+	0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64,
+	0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12,
+	0x29, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x3d, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x27, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0xa2, 0x01, 0x0a, 0x08, 0x4a,
+	0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0e, 0x62, 0x79, 0x74, 0x65, 0x73, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x22,
+	0xa1, 0x02, 0x0a, 0x09, 0x47, 0x72, 0x65, 0x70, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6c, 0x69, 0x6e, 0x65, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x42, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x61,
+	0x66, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x50, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x14, 0x0a,
+	0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61,
+	0x72, 0x74, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x0a, 0x50, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x61,
+	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x50, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x70,
+	0x65, 0x72, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x70,
+	0x65, 0x72, 0x50, 0x61, 0x67, 0x65, 0x2a, 0x9f, 0x01, 0x0a, 0x08, 0x4a, 0x6f, 0x62, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15,
+	0x0a, 0x11, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x45, 0x4e, 0x44,
+	0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x45, 0x5f, 0x49, 0x4e, 0x5f, 0x50, 0x52, 0x4f, 0x47, 0x52, 0x45, 0x53, 0x53, 0x10, 0x02,
+	0x12, 0x17, 0x0a, 0x13, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x43, 0x4f,
+	0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x4a, 0x4f, 0x42,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x12,
+	0x17, 0x0a, 0x13, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x43, 0x41, 0x4e,
+	0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x05, 0x2a, 0x7a, 0x0a, 0x0b, 0x4a, 0x6f, 0x62, 0x50,
+	0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x18, 0x4a, 0x4f, 0x42, 0x5f, 0x50,
+	0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
+	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18, 0x4a, 0x4f, 0x42, 0x5f, 0x50, 0x52, 0x49,
+	0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x41, 0x43, 0x54, 0x49, 0x56,
+	0x45, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x4a, 0x4f, 0x42, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52,
+	0x49, 0x54, 0x59, 0x5f, 0x4e, 0x4f, 0x52, 0x4d, 0x41, 0x4c, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12,
+	0x4a, 0x4f, 0x42, 0x5f, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x54,
+	0x43, 0x48, 0x10, 0x03, 0x2a, 0xa0, 0x01, 0x0a, 0x0a, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4d, 0x4f,
+	0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x15, 0x0a, 0x11, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f,
+	0x4c, 0x49, 0x4e, 0x45, 0x53, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x45, 0x41, 0x52, 0x43,
+	0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x4f, 0x55, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x22,
+	0x0a, 0x1e, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x46, 0x49,
+	0x4c, 0x45, 0x53, 0x5f, 0x57, 0x49, 0x54, 0x48, 0x5f, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x45, 0x53,
+	0x10, 0x03, 0x12, 0x23, 0x0a, 0x1f, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x44,
+	0x45, 0x5f, 0x46, 0x49, 0x4c, 0x45, 0x53, 0x5f, 0x57, 0x49, 0x54, 0x48, 0x4f, 0x55, 0x54, 0x5f,
+	0x4d, 0x41, 0x54, 0x43, 0x48, 0x10, 0x04, 0x2a, 0xad, 0x01, 0x0a, 0x0f, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x20, 0x0a, 0x1c, 0x54,
+	0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1c, 0x0a,
+	0x18, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41,
+	0x54, 0x5f, 0x52, 0x46, 0x43, 0x33, 0x33, 0x33, 0x39, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x54,
+	0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f,
+	0x53, 0x59, 0x53, 0x4c, 0x4f, 0x47, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x54, 0x49, 0x4d, 0x45,
+	0x53, 0x54, 0x41, 0x4d, 0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x4e, 0x47, 0x49,
+	0x4e, 0x58, 0x10, 0x03, 0x12, 0x21, 0x0a, 0x1d, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d,
+	0x50, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x45, 0x50, 0x4f, 0x43, 0x48, 0x5f, 0x4d,
+	0x49, 0x4c, 0x4c, 0x49, 0x53, 0x10, 0x04, 0x2a, 0x58, 0x0a, 0x07, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x4f, 0x70, 0x12, 0x18, 0x0a, 0x14, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4f, 0x50, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4f, 0x50, 0x5f, 0x41, 0x4e, 0x44, 0x10, 0x01, 0x12, 0x0f,
+	0x0a, 0x0b, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4f, 0x50, 0x5f, 0x4f, 0x52, 0x10, 0x02, 0x12,
+	0x10, 0x0a, 0x0c, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4f, 0x50, 0x5f, 0x4e, 0x4f, 0x54, 0x10,
+	0x03, 0x32, 0xba, 0x02, 0x0a, 0x0b, 0x47, 0x72, 0x65, 0x70, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x4e, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x70, 0x4a,
+	0x6f, 0x62, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x45, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x12,
+	0x1a, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x70, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x72,
+	0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0d, 0x43, 0x61, 0x6e, 0x63,
+	0x65, 0x6c, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f, 0x62, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x65, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x47, 0x72, 0x65, 0x70, 0x4a, 0x6f,
+	0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x50, 0x0a, 0x0d,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x1d, 0x2e,
+	0x67, 0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67,
+	0x72, 0x65, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x44,
+	0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x77, 0x61,
+	0x72, 0x69, 0x74, 0x2d, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x79, 0x2f, 0x64, 0x69, 0x73, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x64, 0x2d, 0x67, 0x72, 0x65, 0x70, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x72, 0x65, 0x70, 0x2f, 0x76, 0x31, 0x3b, 0x67, 0x72,
+	0x65, 0x70, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_grep_v1_grep_proto_rawDescOnce sync.Once
+	file_grep_v1_grep_proto_rawDescData []byte
+)
+
+func file_grep_v1_grep_proto_rawDescGZIP() []byte {
+	file_grep_v1_grep_proto_rawDescOnce.Do(func() {
+		file_grep_v1_grep_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grep_v1_grep_proto_rawDesc), len(file_grep_v1_grep_proto_rawDesc)))
+	})
+	return file_grep_v1_grep_proto_rawDescData
+}
+
+var file_grep_v1_grep_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_grep_v1_grep_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_grep_v1_grep_proto_goTypes = []any{
+	(JobState)(0),                 // 0: grep.v1.JobState
+	(JobPriority)(0),              // 1: grep.v1.JobPriority
+	(SearchMode)(0),               // 2: grep.v1.SearchMode
+	(TimestampFormat)(0),          // 3: grep.v1.TimestampFormat
+	(QueryOp)(0),                  // 4: grep.v1.QueryOp
+	(*CreateGrepJobRequest)(nil),  // 5: grep.v1.CreateGrepJobRequest
+	(*PatternQuery)(nil),          // 6: grep.v1.PatternQuery
+	(*Webhook)(nil),               // 7: grep.v1.Webhook
+	(*CreateGrepJobResponse)(nil), // 8: grep.v1.CreateGrepJobResponse
+	(*GetGrepJobRequest)(nil),     // 9: grep.v1.GetGrepJobRequest
+	(*GetGrepJobResponse)(nil),    // 10: grep.v1.GetGrepJobResponse
+	(*FileCount)(nil),             // 11: grep.v1.FileCount
+	(*CancelGrepJobRequest)(nil),  // 12: grep.v1.CancelGrepJobRequest
+	(*StreamMatchesRequest)(nil),  // 13: grep.v1.StreamMatchesRequest
+	(*StreamMatchesResponse)(nil), // 14: grep.v1.StreamMatchesResponse
+	(*JobStatus)(nil),             // 15: grep.v1.JobStatus
+	(*JobStats)(nil),              // 16: grep.v1.JobStats
+	(*GrepMatch)(nil),             // 17: grep.v1.GrepMatch
+	(*Pagination)(nil),            // 18: grep.v1.Pagination
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 20: google.protobuf.Duration
+}
+var file_grep_v1_grep_proto_depIdxs = []int32{
+	6,  // 0: grep.v1.CreateGrepJobRequest.query:type_name -> grep.v1.PatternQuery
+	19, // 1: grep.v1.CreateGrepJobRequest.since:type_name -> google.protobuf.Timestamp
+	19, // 2: grep.v1.CreateGrepJobRequest.until:type_name -> google.protobuf.Timestamp
+	3,  // 3: grep.v1.CreateGrepJobRequest.timestamp_formats:type_name -> grep.v1.TimestampFormat
+	2,  // 4: grep.v1.CreateGrepJobRequest.mode:type_name -> grep.v1.SearchMode
+	1,  // 5: grep.v1.CreateGrepJobRequest.priority:type_name -> grep.v1.JobPriority
+	20, // 6: grep.v1.CreateGrepJobRequest.retention:type_name -> google.protobuf.Duration
+	7,  // 7: grep.v1.CreateGrepJobRequest.webhook:type_name -> grep.v1.Webhook
+	4,  // 8: grep.v1.PatternQuery.op:type_name -> grep.v1.QueryOp
+	6,  // 9: grep.v1.PatternQuery.children:type_name -> grep.v1.PatternQuery
+	0,  // 10: grep.v1.CreateGrepJobResponse.status:type_name -> grep.v1.JobState
+	19, // 11: grep.v1.CreateGrepJobResponse.created_at:type_name -> google.protobuf.Timestamp
+	15, // 12: grep.v1.GetGrepJobResponse.job:type_name -> grep.v1.JobStatus
+	17, // 13: grep.v1.GetGrepJobResponse.results:type_name -> grep.v1.GrepMatch
+	18, // 14: grep.v1.GetGrepJobResponse.pagination:type_name -> grep.v1.Pagination
+	11, // 15: grep.v1.GetGrepJobResponse.file_counts:type_name -> grep.v1.FileCount
+	17, // 16: grep.v1.StreamMatchesResponse.match:type_name -> grep.v1.GrepMatch
+	15, // 17: grep.v1.StreamMatchesResponse.stats:type_name -> grep.v1.JobStatus
+	15, // 18: grep.v1.StreamMatchesResponse.status:type_name -> grep.v1.JobStatus
+	0,  // 19: grep.v1.JobStatus.status:type_name -> grep.v1.JobState
+	19, // 20: grep.v1.JobStatus.created_at:type_name -> google.protobuf.Timestamp
+	19, // 21: grep.v1.JobStatus.completed_at:type_name -> google.protobuf.Timestamp
+	16, // 22: grep.v1.JobStatus.stats:type_name -> grep.v1.JobStats
+	19, // 23: grep.v1.JobStatus.expires_at:type_name -> google.protobuf.Timestamp
+	19, // 24: grep.v1.GrepMatch.timestamp:type_name -> google.protobuf.Timestamp
+	5,  // 25: grep.v1.GrepService.CreateGrepJob:input_type -> grep.v1.CreateGrepJobRequest
+	9,  // 26: grep.v1.GrepService.GetGrepJob:input_type -> grep.v1.GetGrepJobRequest
+	12, // 27: grep.v1.GrepService.CancelGrepJob:input_type -> grep.v1.CancelGrepJobRequest
+	13, // 28: grep.v1.GrepService.StreamMatches:input_type -> grep.v1.StreamMatchesRequest
+	8,  // 29: grep.v1.GrepService.CreateGrepJob:output_type -> grep.v1.CreateGrepJobResponse
+	10, // 30: grep.v1.GrepService.GetGrepJob:output_type -> grep.v1.GetGrepJobResponse
+	15, // 31: grep.v1.GrepService.CancelGrepJob:output_type -> grep.v1.JobStatus
+	14, // 32: grep.v1.GrepService.StreamMatches:output_type -> grep.v1.StreamMatchesResponse
+	29, // [29:33] is the sub-list for method output_type
+	25, // [25:29] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
+}
+
+func init() { file_grep_v1_grep_proto_init() }
+func file_grep_v1_grep_proto_init() {
+	if File_grep_v1_grep_proto != nil {
+		return
+	}
+	file_grep_v1_grep_proto_msgTypes[0].OneofWrappers = []any{}
+	file_grep_v1_grep_proto_msgTypes[1].OneofWrappers = []any{}
+	file_grep_v1_grep_proto_msgTypes[5].OneofWrappers = []any{}
+	file_grep_v1_grep_proto_msgTypes[9].OneofWrappers = []any{
+		(*StreamMatchesResponse_Match)(nil),
+		(*StreamMatchesResponse_Stats)(nil),
+		(*StreamMatchesResponse_Status)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grep_v1_grep_proto_rawDesc), len(file_grep_v1_grep_proto_rawDesc)),
+			NumEnums:      5,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grep_v1_grep_proto_goTypes,
+		DependencyIndexes: file_grep_v1_grep_proto_depIdxs,
+		EnumInfos:         file_grep_v1_grep_proto_enumTypes,
+		MessageInfos:      file_grep_v1_grep_proto_msgTypes,
+	}.Build()
+	File_grep_v1_grep_proto = out.File
+	file_grep_v1_grep_proto_goTypes = nil
+	file_grep_v1_grep_proto_depIdxs = nil
+}
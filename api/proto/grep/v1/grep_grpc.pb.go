@@ -0,0 +1,270 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: grep/v1/grep.proto
+
+// gRPC front door of the distributed grep API, it mirrors the REST API in
+// spec/oapi.yaml and is served by the same job service
+
+package grepv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GrepService_CreateGrepJob_FullMethodName = "/grep.v1.GrepService/CreateGrepJob"
+	GrepService_GetGrepJob_FullMethodName    = "/grep.v1.GrepService/GetGrepJob"
+	GrepService_CancelGrepJob_FullMethodName = "/grep.v1.GrepService/CancelGrepJob"
+	GrepService_StreamMatches_FullMethodName = "/grep.v1.GrepService/StreamMatches"
+)
+
+// GrepServiceClient is the client API for GrepService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GrepService submits grep jobs and follows their progress. Calls are
+// authenticated with the same API keys as the REST API, sent in the
+// x-api-key metadata or as a bearer token in authorization. An x-request-id
+// metadata value is used as the request ID of the job and echoed back in the
+// response headers.
+//
+// Failed calls carry a google.rpc.ErrorInfo detail whose reason is the error
+// code the REST API would return.
+type GrepServiceClient interface {
+	// Submit a new grep job
+	CreateGrepJob(ctx context.Context, in *CreateGrepJobRequest, opts ...grpc.CallOption) (*CreateGrepJobResponse, error)
+	// Get the status of a job with a page of its results
+	GetGrepJob(ctx context.Context, in *GetGrepJobRequest, opts ...grpc.CallOption) (*GetGrepJobResponse, error)
+	// Cancel a job that has not finished yet
+	CancelGrepJob(ctx context.Context, in *CancelGrepJobRequest, opts ...grpc.CallOption) (*JobStatus, error)
+	// Stream the matches of a job as they are found, along with periodic
+	// stats, ending with the final status of the job
+	StreamMatches(ctx context.Context, in *StreamMatchesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamMatchesResponse], error)
+}
+
+type grepServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGrepServiceClient(cc grpc.ClientConnInterface) GrepServiceClient {
+	return &grepServiceClient{cc}
+}
+
+func (c *grepServiceClient) CreateGrepJob(ctx context.Context, in *CreateGrepJobRequest, opts ...grpc.CallOption) (*CreateGrepJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateGrepJobResponse)
+	err := c.cc.Invoke(ctx, GrepService_CreateGrepJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grepServiceClient) GetGrepJob(ctx context.Context, in *GetGrepJobRequest, opts ...grpc.CallOption) (*GetGrepJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetGrepJobResponse)
+	err := c.cc.Invoke(ctx, GrepService_GetGrepJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grepServiceClient) CancelGrepJob(ctx context.Context, in *CancelGrepJobRequest, opts ...grpc.CallOption) (*JobStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobStatus)
+	err := c.cc.Invoke(ctx, GrepService_CancelGrepJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grepServiceClient) StreamMatches(ctx context.Context, in *StreamMatchesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamMatchesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrepService_ServiceDesc.Streams[0], GrepService_StreamMatches_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamMatchesRequest, StreamMatchesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrepService_StreamMatchesClient = grpc.ServerStreamingClient[StreamMatchesResponse]
+
+// GrepServiceServer is the server API for GrepService service.
+// All implementations must embed UnimplementedGrepServiceServer
+// for forward compatibility.
+//
+// GrepService submits grep jobs and follows their progress. Calls are
+// authenticated with the same API keys as the REST API, sent in the
+// x-api-key metadata or as a bearer token in authorization. An x-request-id
+// metadata value is used as the request ID of the job and echoed back in the
+// response headers.
+//
+// Failed calls carry a google.rpc.ErrorInfo detail whose reason is the error
+// code the REST API would return.
+type GrepServiceServer interface {
+	// Submit a new grep job
+	CreateGrepJob(context.Context, *CreateGrepJobRequest) (*CreateGrepJobResponse, error)
+	// Get the status of a job with a page of its results
+	GetGrepJob(context.Context, *GetGrepJobRequest) (*GetGrepJobResponse, error)
+	// Cancel a job that has not finished yet
+	CancelGrepJob(context.Context, *CancelGrepJobRequest) (*JobStatus, error)
+	// Stream the matches of a job as they are found, along with periodic
+	// stats, ending with the final status of the job
+	StreamMatches(*StreamMatchesRequest, grpc.ServerStreamingServer[StreamMatchesResponse]) error
+	mustEmbedUnimplementedGrepServiceServer()
+}
+
+// UnimplementedGrepServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGrepServiceServer struct{}
+
+func (UnimplementedGrepServiceServer) CreateGrepJob(context.Context, *CreateGrepJobRequest) (*CreateGrepJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGrepJob not implemented")
+}
+func (UnimplementedGrepServiceServer) GetGrepJob(context.Context, *GetGrepJobRequest) (*GetGrepJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGrepJob not implemented")
+}
+func (UnimplementedGrepServiceServer) CancelGrepJob(context.Context, *CancelGrepJobRequest) (*JobStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelGrepJob not implemented")
+}
+func (UnimplementedGrepServiceServer) StreamMatches(*StreamMatchesRequest, grpc.ServerStreamingServer[StreamMatchesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMatches not implemented")
+}
+func (UnimplementedGrepServiceServer) mustEmbedUnimplementedGrepServiceServer() {}
+func (UnimplementedGrepServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeGrepServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GrepServiceServer will
+// result in compilation errors.
+type UnsafeGrepServiceServer interface {
+	mustEmbedUnimplementedGrepServiceServer()
+}
+
+func RegisterGrepServiceServer(s grpc.ServiceRegistrar, srv GrepServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGrepServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GrepService_ServiceDesc, srv)
+}
+
+func _GrepService_CreateGrepJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGrepJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrepServiceServer).CreateGrepJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrepService_CreateGrepJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrepServiceServer).CreateGrepJob(ctx, req.(*CreateGrepJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrepService_GetGrepJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGrepJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrepServiceServer).GetGrepJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrepService_GetGrepJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrepServiceServer).GetGrepJob(ctx, req.(*GetGrepJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrepService_CancelGrepJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelGrepJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrepServiceServer).CancelGrepJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrepService_CancelGrepJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrepServiceServer).CancelGrepJob(ctx, req.(*CancelGrepJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrepService_StreamMatches_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMatchesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrepServiceServer).StreamMatches(m, &grpc.GenericServerStream[StreamMatchesRequest, StreamMatchesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrepService_StreamMatchesServer = grpc.ServerStreamingServer[StreamMatchesResponse]
+
+// GrepService_ServiceDesc is the grpc.ServiceDesc for GrepService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GrepService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grep.v1.GrepService",
+	HandlerType: (*GrepServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateGrepJob",
+			Handler:    _GrepService_CreateGrepJob_Handler,
+		},
+		{
+			MethodName: "GetGrepJob",
+			Handler:    _GrepService_GetGrepJob_Handler,
+		},
+		{
+			MethodName: "CancelGrepJob",
+			Handler:    _GrepService_CancelGrepJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMatches",
+			Handler:       _GrepService_StreamMatches_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grep/v1/grep.proto",
+}
@@ -1,6 +1,6 @@
 // Package api provides primitives to interact with the openapi HTTP API.
 //
-// Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.0 DO NOT EDIT.
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
 package api
 
 import (
@@ -17,6 +17,19 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	ApiKeyAuthScopes = "ApiKeyAuth.Scopes"
+	BearerAuthScopes = "BearerAuth.Scopes"
+)
+
+// Defines values for JobPriority.
+const (
+	Batch       JobPriority = "batch"
+	Interactive JobPriority = "interactive"
+	Normal      JobPriority = "normal"
 )
 
 // Defines values for JobState.
@@ -28,15 +41,74 @@ const (
 	PENDING    JobState = "PENDING"
 )
 
+// Defines values for PatternQueryOp.
+const (
+	AND PatternQueryOp = "AND"
+	NOT PatternQueryOp = "NOT"
+	OR  PatternQueryOp = "OR"
+)
+
+// Defines values for SearchMode.
+const (
+	Count             SearchMode = "count"
+	FilesWithMatches  SearchMode = "files_with_matches"
+	FilesWithoutMatch SearchMode = "files_without_match"
+	Lines             SearchMode = "lines"
+)
+
+// Defines values for TimestampFormat.
+const (
+	EpochMillis TimestampFormat = "epoch_millis"
+	Nginx       TimestampFormat = "nginx"
+	Rfc3339     TimestampFormat = "rfc3339"
+	Syslog      TimestampFormat = "syslog"
+)
+
+// Defines values for ExportGrepJobParamsFormat.
+const (
+	Csv    ExportGrepJobParamsFormat = "csv"
+	Ndjson ExportGrepJobParamsFormat = "ndjson"
+	Text   ExportGrepJobParamsFormat = "text"
+)
+
 // Error defines model for Error.
 type Error struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code string `json:"code"`
+
+	// Details Every field of an INVALID_REQUEST that violates this spec
+	Details *[]FieldError `json:"details,omitempty"`
+	Message string        `json:"message"`
+
+	// Offset Byte offset in the pattern an INVALID_PATTERN error points at
+	Offset *int `json:"offset,omitempty"`
 
 	// RequestId Original request ID for tracking
 	RequestId string `json:"request_id"`
 }
 
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Location of the field, body fields are addressed by their path,
+	// parameters by where they are sent
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FileCount defines model for FileCount.
+type FileCount struct {
+	// Count Selected lines, at most max_count. In files_with_matches and
+	// files_without_match mode it is 1 for a file with a match and 0
+	// otherwise.
+	Count int    `json:"count"`
+	File  string `json:"file"`
+}
+
+// FileList defines model for FileList.
+type FileList struct {
+	Files      []LogFile   `json:"files"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
 // GrepMatch defines model for GrepMatch.
 type GrepMatch struct {
 	Content string `json:"content"`
@@ -48,9 +120,18 @@ type GrepMatch struct {
 	// File Source file where match was found
 	File       string `json:"file"`
 	LineNumber int    `json:"line_number"`
+
+	// MatchedPatterns Labels of the query patterns, or the fixed strings of patterns, found on the line, omitted for a single pattern
+	MatchedPatterns *[]string `json:"matched_patterns,omitempty"`
+
+	// Parts Matched parts of the line in order, only for only_matching jobs
+	Parts *[]string `json:"parts,omitempty"`
+
+	// Timestamp When the line was logged, only set for jobs with since, until or timestamp_formats
+	Timestamp *time.Time `json:"timestamp,omitempty"`
 }
 
-// GrepRequest defines model for GrepRequest.
+// GrepRequest Exactly one of pattern, patterns and query is required
 type GrepRequest struct {
 	// CaseSensitive Whether to perform case-sensitive search
 	CaseSensitive *bool `json:"case_sensitive,omitempty"`
@@ -61,13 +142,153 @@ type GrepRequest struct {
 	// Files Files or patterns to search in
 	Files []string `json:"files"`
 
+	// InvertMatch Select the lines that do not match, like grep -v
+	InvertMatch *bool `json:"invert_match,omitempty"`
+
+	// LineRegexp Only match whole lines, like grep -x, takes precedence over word_regexp
+	LineRegexp *bool `json:"line_regexp,omitempty"`
+
+	// MaxCount Stop after this many selected lines per file, like grep -m, 0 for
+	// no limit. Results and exports hold the first max_count matches of
+	// each file, the stream may deliver matches past it while chunks are
+	// still being searched.
+	MaxCount *int `json:"max_count,omitempty"`
+
+	// Mode What a job reports: every selected line (lines), the number of
+	// selected lines of each file like grep -c (count), the files with a
+	// selected line like grep -l (files_with_matches) or the files without
+	// one like grep -L (files_without_match). The last three report
+	// file_counts instead of results and cannot be combined with
+	// context_lines or only_matching. The remaining chunks of a file are
+	// skipped once it is known to match in files_with_matches and
+	// files_without_match mode.
+	Mode *SearchMode `json:"mode,omitempty"`
+
+	// OnlyMatching Report the matched parts of each line in GrepMatch.parts, like
+	// grep -o. Cannot be combined with context_lines.
+	OnlyMatching *bool `json:"only_matching,omitempty"`
+
 	// Pattern Grep pattern to search for
-	Pattern string `json:"pattern"`
+	Pattern string `json:"pattern,omitempty"`
+
+	// Patterns Fixed strings to search for, like grep -F -f. A line containing any
+	// of them matches and GrepMatch.matched_patterns lists the ones found
+	// on it. They are matched in a single pass over each chunk however
+	// many there are, regex defaults to false and must not be true.
+	Patterns *[]string `json:"patterns,omitempty"`
+
+	// Priority Class the chunks of a job are dispatched in. Chunks of interactive
+	// jobs go out before those of normal jobs, which go out before those of
+	// batch jobs, and jobs of the same class go first come first served.
+	// Batch jobs are paused altogether while an interactive job is being
+	// searched.
+	Priority *JobPriority `json:"priority,omitempty"`
+
+	// Query Boolean expression of patterns matched against each line. A node is
+	// either a leaf with a pattern and its own search options, or an op
+	// combining its children. Queries nest at most 8 operators deep and hold
+	// at most 32 patterns.
+	Query *PatternQuery `json:"query,omitempty"`
 
-	// Regex Whether to interpret pattern as regex
+	// Regex Whether to interpret pattern as regex, defaults to false when patterns is set
 	Regex *bool `json:"regex,omitempty"`
+
+	// RetentionSeconds How long the job, its chunks and results are kept once it
+	// finished, the retention configured in the manager if absent.
+	// Expired jobs answer 410 like deleted ones.
+	RetentionSeconds *int `json:"retention_seconds,omitempty"`
+
+	// Since Only search lines logged at or after this time
+	Since *time.Time `json:"since,omitempty"`
+
+	// TimestampFormats Formats the timestamp of each line is parsed with, tried in order,
+	// all of them by default. Lines without a timestamp inherit the one of
+	// the line before, lines before the first timestamp of a file are
+	// skipped when since or until is set.
+	TimestampFormats *[]TimestampFormat `json:"timestamp_formats,omitempty"`
+
+	// Until Only search lines logged before this time, must be after since
+	Until *time.Time `json:"until,omitempty"`
+
+	// Webhook Endpoint notified once the job finished
+	Webhook *Webhook `json:"webhook,omitempty"`
+
+	// WordRegexp Only match whole words, like grep -w
+	WordRegexp *bool `json:"word_regexp,omitempty"`
+}
+
+// JobDiff defines model for JobDiff.
+type JobDiff struct {
+	BaseJobId string `json:"base_job_id"`
+
+	// BaseMatches Matches of the base job
+	BaseMatches int `json:"base_matches"`
+
+	// Changed Patterns both jobs matched a different number of times
+	Changed []LineDiff `json:"changed"`
+
+	// ChangedTotal Number of changed patterns, including those cut by the limit
+	ChangedTotal int `json:"changed_total"`
+
+	// Disappeared Patterns only the base job matched
+	Disappeared []LineDiff `json:"disappeared"`
+
+	// DisappearedTotal Number of disappeared patterns, including those cut by the limit
+	DisappearedTotal int    `json:"disappeared_total"`
+	HeadJobId        string `json:"head_job_id"`
+
+	// HeadMatches Matches of the head job
+	HeadMatches int `json:"head_matches"`
+
+	// Masks Masks applied to the lines, in the order they were applied
+	Masks []string `json:"masks"`
+
+	// New Patterns only the head job matched
+	New []LineDiff `json:"new"`
+
+	// NewTotal Number of new patterns, including those cut by the limit
+	NewTotal int `json:"new_total"`
+
+	// Unchanged Number of patterns both jobs matched equally often
+	Unchanged int `json:"unchanged"`
+}
+
+// JobEstimate defines model for JobEstimate.
+type JobEstimate struct {
+	// Chunks Number of chunks the splitter would cut the files into
+	Chunks int `json:"chunks"`
+
+	// EstimatedSeconds Expected wall-clock time of the job
+	EstimatedSeconds *float64 `json:"estimated_seconds,omitempty"`
+
+	// Files Number of files the globs resolve to
+	Files int `json:"files"`
+
+	// SampleJobs Number of completed jobs the throughput is based on
+	SampleJobs int `json:"sample_jobs"`
+
+	// ThroughputBytesPerSecond Wall-clock throughput of recently completed jobs
+	ThroughputBytesPerSecond *float64 `json:"throughput_bytes_per_second,omitempty"`
+
+	// TotalBytes Combined size of the files
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+// JobList defines model for JobList.
+type JobList struct {
+	Jobs []JobStatus `json:"jobs"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
 }
 
+// JobPriority Class the chunks of a job are dispatched in. Chunks of interactive
+// jobs go out before those of normal jobs, which go out before those of
+// batch jobs, and jobs of the same class go first come first served.
+// Batch jobs are paused altogether while an interactive job is being
+// searched.
+type JobPriority string
+
 // JobResponse defines model for JobResponse.
 type JobResponse struct {
 	CreatedAt *time.Time `json:"created_at,omitempty"`
@@ -87,18 +308,25 @@ type JobStatus struct {
 	CreatedAt   *time.Time `json:"created_at,omitempty"`
 
 	// Error Error message if job failed
-	Error      *string `json:"error,omitempty"`
-	JobId      string  `json:"job_id"`
-	Pagination *struct {
-		CurrentPage  *int `json:"current_page,omitempty"`
-		PerPage      *int `json:"per_page,omitempty"`
-		TotalPages   *int `json:"total_pages,omitempty"`
-		TotalResults *int `json:"total_results,omitempty"`
-	} `json:"pagination,omitempty"`
-	Progress  *int         `json:"progress,omitempty"`
-	RequestId string       `json:"request_id"`
-	Results   *[]GrepMatch `json:"results,omitempty"`
-	Stats     *struct {
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt When the job and its data are removed, set once it finished
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// FileCounts Files reported by a job in a counting mode, in place of results and
+	// paginated the same way. Files without a match may still find one
+	// until the job completes.
+	FileCounts *[]FileCount `json:"file_counts,omitempty"`
+	JobId      string       `json:"job_id"`
+	Pagination *Pagination  `json:"pagination,omitempty"`
+	Progress   *int         `json:"progress,omitempty"`
+
+	// QueuePosition Jobs of any tenant ahead of this one in the dispatch queue, absent
+	// once all chunks of the job were handed to the mappers
+	QueuePosition *int         `json:"queue_position,omitempty"`
+	RequestId     string       `json:"request_id"`
+	Results       *[]GrepMatch `json:"results,omitempty"`
+	Stats         *struct {
 		// BytesProcessed Total bytes processed
 		BytesProcessed *int `json:"bytes_processed,omitempty"`
 
@@ -114,6 +342,256 @@ type JobStatus struct {
 	Status JobState `json:"status"`
 }
 
+// LineDiff defines model for LineDiff.
+type LineDiff struct {
+	BaseCount int       `json:"base_count"`
+	Example   GrepMatch `json:"example"`
+	HeadCount int       `json:"head_count"`
+
+	// Pattern Matched line with its volatile tokens masked
+	Pattern string `json:"pattern"`
+}
+
+// LogFile defines model for LogFile.
+type LogFile struct {
+	// Name Base name of the file
+	Name string `json:"name"`
+
+	// Path Full path in log storage
+	Path string `json:"path"`
+
+	// Size File size in bytes
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Pagination defines model for Pagination.
+type Pagination struct {
+	CurrentPage  *int `json:"current_page,omitempty"`
+	PerPage      *int `json:"per_page,omitempty"`
+	TotalPages   *int `json:"total_pages,omitempty"`
+	TotalResults *int `json:"total_results,omitempty"`
+}
+
+// PatternQuery Boolean expression of patterns matched against each line. A node is
+// either a leaf with a pattern and its own search options, or an op
+// combining its children. Queries nest at most 8 operators deep and hold
+// at most 32 patterns.
+type PatternQuery struct {
+	// CaseSensitive Whether to match the leaf case-sensitively
+	CaseSensitive *bool `json:"case_sensitive,omitempty"`
+
+	// Children Operands of op
+	Children *[]PatternQuery `json:"children,omitempty"`
+
+	// Id Label of a leaf reported in GrepMatch.matched_patterns, defaults to
+	// its pattern. Labels must be unique within a query.
+	Id *string `json:"id,omitempty"`
+
+	// Op Operator of the node, omitted for leaves. NOT takes exactly one child.
+	Op *PatternQueryOp `json:"op,omitempty"`
+
+	// Pattern Pattern of a leaf
+	Pattern *string `json:"pattern,omitempty"`
+
+	// Regex Whether to interpret the leaf pattern as regex
+	Regex *bool `json:"regex,omitempty"`
+}
+
+// PatternQueryOp Operator of the node, omitted for leaves. NOT takes exactly one child.
+type PatternQueryOp string
+
+// SavedSearch defines model for SavedSearch.
+type SavedSearch struct {
+	CreatedAt time.Time  `json:"created_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	Name      string     `json:"name"`
+
+	// NextRunAt Next scheduled run, absent without a schedule
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+
+	// Request Exactly one of pattern, patterns and query is required
+	Request   GrepRequest `json:"request"`
+	Schedule  *string     `json:"schedule,omitempty"`
+	SearchId  string      `json:"search_id"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// SavedSearchList defines model for SavedSearchList.
+type SavedSearchList struct {
+	Searches []SavedSearch `json:"searches"`
+}
+
+// SavedSearchRequest defines model for SavedSearchRequest.
+type SavedSearchRequest struct {
+	Name string `json:"name"`
+
+	// Request Exactly one of pattern, patterns and query is required
+	Request GrepRequest `json:"request"`
+
+	// Schedule Cron expression with five fields, or a descriptor such as `@daily`
+	// or `@every 6h`, evaluated in UTC unless prefixed with
+	// `CRON_TZ=<zone>`. Runs must be at least a minute apart. Leave it
+	// out to only run the search on demand.
+	Schedule *string `json:"schedule,omitempty"`
+}
+
+// SearchMode What a job reports: every selected line (lines), the number of
+// selected lines of each file like grep -c (count), the files with a
+// selected line like grep -l (files_with_matches) or the files without
+// one like grep -L (files_without_match). The last three report
+// file_counts instead of results and cannot be combined with
+// context_lines or only_matching. The remaining chunks of a file are
+// skipped once it is known to match in files_with_matches and
+// files_without_match mode.
+type SearchMode string
+
+// SearchRun defines model for SearchRun.
+type SearchRun struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	JobId       string     `json:"job_id"`
+
+	// Scheduled Whether the run was started by the schedule
+	Scheduled    bool     `json:"scheduled"`
+	Status       JobState `json:"status"`
+	TotalMatches int      `json:"total_matches"`
+}
+
+// SearchRunList defines model for SearchRunList.
+type SearchRunList struct {
+	Runs     []SearchRun `json:"runs"`
+	SearchId string      `json:"search_id"`
+}
+
+// TimestampFormat How log lines carry their time: rfc3339 (2024-01-02T15:04:05Z, a space
+// instead of T and no zone are accepted, UTC is assumed then), syslog
+// (Jan  2 15:04:05 at the start of the line, in UTC), nginx
+// ([02/Jan/2024:15:04:05 +0000]) or epoch_millis (1704207845000)
+type TimestampFormat string
+
+// Webhook Endpoint notified once the job finished
+type Webhook struct {
+	// Secret Shared secret used to sign deliveries, never returned
+	Secret string `json:"secret"`
+
+	// Url Absolute http or https url the WebhookEvent is POSTed to
+	Url string `json:"url"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempt     int       `json:"attempt"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       *string   `json:"error,omitempty"`
+
+	// StatusCode Response status, missing when no response was received
+	StatusCode *int `json:"status_code,omitempty"`
+}
+
+// WebhookDeliveryLog defines model for WebhookDeliveryLog.
+type WebhookDeliveryLog struct {
+	// Attempts Number of deliveries attempted
+	Attempts      int               `json:"attempts"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty"`
+	Deliveries    []WebhookDelivery `json:"deliveries"`
+	NextAttemptAt *time.Time        `json:"next_attempt_at,omitempty"`
+
+	// State WAITING until the job finished, PENDING while deliveries are
+	// attempted, then DELIVERED or FAILED
+	State string `json:"state"`
+	Url   string `json:"url"`
+}
+
+// JobDeleted defines model for JobDeleted.
+type JobDeleted = Error
+
+// SearchNotFound defines model for SearchNotFound.
+type SearchNotFound = Error
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = Error
+
+// DeleteFileParams defines parameters for DeleteFile.
+type DeleteFileParams struct {
+	// Path Path of the file in log storage
+	Path string `form:"path" json:"path"`
+}
+
+// ListFilesParams defines parameters for ListFiles.
+type ListFilesParams struct {
+	// Prefix Only list files whose path starts with this prefix
+	Prefix *string `form:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// MinSize Only list files of at least this many bytes
+	MinSize *int64 `form:"min_size,omitempty" json:"min_size,omitempty"`
+
+	// MaxSize Only list files of at most this many bytes
+	MaxSize *int64 `form:"max_size,omitempty" json:"max_size,omitempty"`
+
+	// ModifiedAfter Only list files modified at or after this time
+	ModifiedAfter *time.Time `form:"modified_after,omitempty" json:"modified_after,omitempty"`
+
+	// ModifiedBefore Only list files modified before this time
+	ModifiedBefore *time.Time `form:"modified_before,omitempty" json:"modified_before,omitempty"`
+
+	// Page Page number for pagination
+	Page *int `form:"page,omitempty" json:"page,omitempty"`
+
+	// Limit Number of files per page
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// UploadFileMultipartBody defines parameters for UploadFile.
+type UploadFileMultipartBody struct {
+	File openapi_types.File `json:"file"`
+
+	// Path Destination path in log storage
+	Path *string `json:"path,omitempty"`
+}
+
+// PutFileParams defines parameters for PutFile.
+type PutFileParams struct {
+	// Path Destination path in log storage
+	Path string `form:"path" json:"path"`
+}
+
+// ListGrepJobsParams defines parameters for ListGrepJobs.
+type ListGrepJobsParams struct {
+	Status *JobState `form:"status,omitempty" json:"status,omitempty"`
+
+	// CreatedAfter Only jobs created at or after this time
+	CreatedAfter *time.Time `form:"created_after,omitempty" json:"created_after,omitempty"`
+
+	// CreatedBefore Only jobs created before this time
+	CreatedBefore *time.Time `form:"created_before,omitempty" json:"created_before,omitempty"`
+	Pattern       *string    `form:"pattern,omitempty" json:"pattern,omitempty"`
+	RequestId     *string    `form:"request_id,omitempty" json:"request_id,omitempty"`
+	Cursor        *string    `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Limit         *int       `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// CreateGrepJobParams defines parameters for CreateGrepJob.
+type CreateGrepJobParams struct {
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// DiffGrepJobsParams defines parameters for DiffGrepJobs.
+type DiffGrepJobsParams struct {
+	// Base Job the comparison starts from, usually the older one
+	Base string `form:"base" json:"base"`
+
+	// Head Job compared against base
+	Head string `form:"head" json:"head"`
+
+	// Masks Comma separated masks to apply, all of them by default, `none` compares lines as they are
+	Masks *string `form:"masks,omitempty" json:"masks,omitempty"`
+
+	// Limit Number of line patterns returned per category
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // GetGrepJobParams defines parameters for GetGrepJob.
 type GetGrepJobParams struct {
 	// Page Page number for results pagination
@@ -123,20 +601,105 @@ type GetGrepJobParams struct {
 	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// ExportGrepJobParams defines parameters for ExportGrepJob.
+type ExportGrepJobParams struct {
+	Format *ExportGrepJobParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// ExportGrepJobParamsFormat defines parameters for ExportGrepJob.
+type ExportGrepJobParamsFormat string
+
+// StreamGrepJobParams defines parameters for StreamGrepJob.
+type StreamGrepJobParams struct {
+	// LastEventID Id of the last match event received, resumes the stream after it
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
+// ListSearchRunsParams defines parameters for ListSearchRuns.
+type ListSearchRunsParams struct {
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// UploadFileMultipartRequestBody defines body for UploadFile for multipart/form-data ContentType.
+type UploadFileMultipartRequestBody UploadFileMultipartBody
+
 // CreateGrepJobJSONRequestBody defines body for CreateGrepJob for application/json ContentType.
 type CreateGrepJobJSONRequestBody = GrepRequest
 
+// EstimateGrepJobJSONRequestBody defines body for EstimateGrepJob for application/json ContentType.
+type EstimateGrepJobJSONRequestBody = GrepRequest
+
+// CreateSearchJSONRequestBody defines body for CreateSearch for application/json ContentType.
+type CreateSearchJSONRequestBody = SavedSearchRequest
+
+// UpdateSearchJSONRequestBody defines body for UpdateSearch for application/json ContentType.
+type UpdateSearchJSONRequestBody = SavedSearchRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Delete a log file
+	// (DELETE /files)
+	DeleteFile(c *gin.Context, params DeleteFileParams)
+	// List log files available for grepping
+	// (GET /files)
+	ListFiles(c *gin.Context, params ListFilesParams)
+	// Upload a log file as multipart form data
+	// (POST /files)
+	UploadFile(c *gin.Context)
+	// Upload a log file from the raw request body
+	// (PUT /files)
+	PutFile(c *gin.Context, params PutFileParams)
+	// List grep jobs
+	// (GET /grep)
+	ListGrepJobs(c *gin.Context, params ListGrepJobsParams)
 	// Submit a new grep job
 	// (POST /grep)
-	CreateGrepJob(c *gin.Context)
+	CreateGrepJob(c *gin.Context, params CreateGrepJobParams)
+	// Compare the matches of two completed grep jobs
+	// (GET /grep/diff)
+	DiffGrepJobs(c *gin.Context, params DiffGrepJobsParams)
+	// Estimate the size and duration of a grep job without running it
+	// (POST /grep/estimate)
+	EstimateGrepJob(c *gin.Context)
+	// Delete a grep job with its chunks and results
+	// (DELETE /grep/{jobId})
+	DeleteGrepJob(c *gin.Context, jobId string)
 	// Get grep job status and results
 	// (GET /grep/{jobId})
 	GetGrepJob(c *gin.Context, jobId string, params GetGrepJobParams)
 	// Cancel a running grep job
 	// (POST /grep/{jobId}/cancel)
 	CancelGrepJob(c *gin.Context, jobId string)
+	// Export all matches of a completed grep job
+	// (GET /grep/{jobId}/export)
+	ExportGrepJob(c *gin.Context, jobId string, params ExportGrepJobParams)
+	// Stream grep job matches and progress as Server-Sent Events
+	// (GET /grep/{jobId}/stream)
+	StreamGrepJob(c *gin.Context, jobId string, params StreamGrepJobParams)
+	// Get the webhook of a grep job and its delivery log
+	// (GET /grep/{jobId}/webhook)
+	GetGrepJobWebhook(c *gin.Context, jobId string)
+	// List saved searches
+	// (GET /searches)
+	ListSearches(c *gin.Context)
+	// Save a grep request as a named search
+	// (POST /searches)
+	CreateSearch(c *gin.Context)
+	// Delete a saved search and its run history
+	// (DELETE /searches/{searchId})
+	DeleteSearch(c *gin.Context, searchId string)
+	// Get a saved search
+	// (GET /searches/{searchId})
+	GetSearch(c *gin.Context, searchId string)
+	// Replace a saved search
+	// (PUT /searches/{searchId})
+	UpdateSearch(c *gin.Context, searchId string)
+	// Run a saved search now
+	// (POST /searches/{searchId}/run)
+	RunSearch(c *gin.Context, searchId string)
+	// List past runs of a saved search
+	// (GET /searches/{searchId}/runs)
+	ListSearchRuns(c *gin.Context, searchId string, params ListSearchRunsParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -148,8 +711,32 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
-// CreateGrepJob operation middleware
-func (siw *ServerInterfaceWrapper) CreateGrepJob(c *gin.Context) {
+// DeleteFile operation middleware
+func (siw *ServerInterfaceWrapper) DeleteFile(c *gin.Context) {
+
+	var err error
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteFileParams
+
+	// ------------- Required query parameter "path" -------------
+
+	if paramValue := c.Query("path"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument path is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "path", c.Request.URL.Query(), &params.Path)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter path: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -158,25 +745,60 @@ func (siw *ServerInterfaceWrapper) CreateGrepJob(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CreateGrepJob(c)
+	siw.Handler.DeleteFile(c, params)
 }
 
-// GetGrepJob operation middleware
-func (siw *ServerInterfaceWrapper) GetGrepJob(c *gin.Context) {
+// ListFiles operation middleware
+func (siw *ServerInterfaceWrapper) ListFiles(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "jobId" -------------
-	var jobId string
+	c.Set(ApiKeyAuthScopes, []string{})
 
-	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListFilesParams
+
+	// ------------- Optional query parameter "prefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "prefix", c.Request.URL.Query(), &params.Prefix)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter prefix: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetGrepJobParams
+	// ------------- Optional query parameter "min_size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "min_size", c.Request.URL.Query(), &params.MinSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter min_size: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "max_size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "max_size", c.Request.URL.Query(), &params.MaxSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter max_size: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "modified_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "modified_after", c.Request.URL.Query(), &params.ModifiedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter modified_after: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "modified_before" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "modified_before", c.Request.URL.Query(), &params.ModifiedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter modified_before: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	// ------------- Optional query parameter "page" -------------
 
@@ -201,20 +823,50 @@ func (siw *ServerInterfaceWrapper) GetGrepJob(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetGrepJob(c, jobId, params)
+	siw.Handler.ListFiles(c, params)
 }
 
-// CancelGrepJob operation middleware
-func (siw *ServerInterfaceWrapper) CancelGrepJob(c *gin.Context) {
+// UploadFile operation middleware
+func (siw *ServerInterfaceWrapper) UploadFile(c *gin.Context) {
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UploadFile(c)
+}
+
+// PutFile operation middleware
+func (siw *ServerInterfaceWrapper) PutFile(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "jobId" -------------
-	var jobId string
+	c.Set(ApiKeyAuthScopes, []string{})
 
-	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutFileParams
+
+	// ------------- Required query parameter "path" -------------
+
+	if paramValue := c.Query("path"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument path is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "path", c.Request.URL.Query(), &params.Path)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter path: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -225,7 +877,620 @@ func (siw *ServerInterfaceWrapper) CancelGrepJob(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CancelGrepJob(c, jobId)
+	siw.Handler.PutFile(c, params)
+}
+
+// ListGrepJobs operation middleware
+func (siw *ServerInterfaceWrapper) ListGrepJobs(c *gin.Context) {
+
+	var err error
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListGrepJobsParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_after", c.Request.URL.Query(), &params.CreatedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter created_after: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "created_before" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_before", c.Request.URL.Query(), &params.CreatedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter created_before: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pattern" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pattern", c.Request.URL.Query(), &params.Pattern)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pattern: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "request_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "request_id", c.Request.URL.Query(), &params.RequestId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter request_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListGrepJobs(c, params)
+}
+
+// CreateGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) CreateGrepJob(c *gin.Context) {
+
+	var err error
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateGrepJobParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateGrepJob(c, params)
+}
+
+// DiffGrepJobs operation middleware
+func (siw *ServerInterfaceWrapper) DiffGrepJobs(c *gin.Context) {
+
+	var err error
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DiffGrepJobsParams
+
+	// ------------- Required query parameter "base" -------------
+
+	if paramValue := c.Query("base"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument base is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "base", c.Request.URL.Query(), &params.Base)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter base: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "head" -------------
+
+	if paramValue := c.Query("head"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument head is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "head", c.Request.URL.Query(), &params.Head)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter head: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "masks" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "masks", c.Request.URL.Query(), &params.Masks)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter masks: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DiffGrepJobs(c, params)
+}
+
+// EstimateGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) EstimateGrepJob(c *gin.Context) {
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.EstimateGrepJob(c)
+}
+
+// DeleteGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) DeleteGrepJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteGrepJob(c, jobId)
+}
+
+// GetGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) GetGrepJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetGrepJobParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetGrepJob(c, jobId, params)
+}
+
+// CancelGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) CancelGrepJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CancelGrepJob(c, jobId)
+}
+
+// ExportGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) ExportGrepJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportGrepJobParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportGrepJob(c, jobId, params)
+}
+
+// StreamGrepJob operation middleware
+func (siw *ServerInterfaceWrapper) StreamGrepJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamGrepJobParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Last-Event-ID, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Last-Event-ID: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StreamGrepJob(c, jobId, params)
+}
+
+// GetGrepJobWebhook operation middleware
+func (siw *ServerInterfaceWrapper) GetGrepJobWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "jobId", c.Param("jobId"), &jobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetGrepJobWebhook(c, jobId)
+}
+
+// ListSearches operation middleware
+func (siw *ServerInterfaceWrapper) ListSearches(c *gin.Context) {
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSearches(c)
+}
+
+// CreateSearch operation middleware
+func (siw *ServerInterfaceWrapper) CreateSearch(c *gin.Context) {
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateSearch(c)
+}
+
+// DeleteSearch operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSearch(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "searchId" -------------
+	var searchId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "searchId", c.Param("searchId"), &searchId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter searchId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteSearch(c, searchId)
+}
+
+// GetSearch operation middleware
+func (siw *ServerInterfaceWrapper) GetSearch(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "searchId" -------------
+	var searchId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "searchId", c.Param("searchId"), &searchId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter searchId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSearch(c, searchId)
+}
+
+// UpdateSearch operation middleware
+func (siw *ServerInterfaceWrapper) UpdateSearch(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "searchId" -------------
+	var searchId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "searchId", c.Param("searchId"), &searchId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter searchId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateSearch(c, searchId)
+}
+
+// RunSearch operation middleware
+func (siw *ServerInterfaceWrapper) RunSearch(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "searchId" -------------
+	var searchId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "searchId", c.Param("searchId"), &searchId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter searchId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RunSearch(c, searchId)
+}
+
+// ListSearchRuns operation middleware
+func (siw *ServerInterfaceWrapper) ListSearchRuns(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "searchId" -------------
+	var searchId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "searchId", c.Param("searchId"), &searchId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter searchId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ApiKeyAuthScopes, []string{})
+
+	c.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListSearchRunsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSearchRuns(c, searchId, params)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -255,38 +1520,174 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.DELETE(options.BaseURL+"/files", wrapper.DeleteFile)
+	router.GET(options.BaseURL+"/files", wrapper.ListFiles)
+	router.POST(options.BaseURL+"/files", wrapper.UploadFile)
+	router.PUT(options.BaseURL+"/files", wrapper.PutFile)
+	router.GET(options.BaseURL+"/grep", wrapper.ListGrepJobs)
 	router.POST(options.BaseURL+"/grep", wrapper.CreateGrepJob)
+	router.GET(options.BaseURL+"/grep/diff", wrapper.DiffGrepJobs)
+	router.POST(options.BaseURL+"/grep/estimate", wrapper.EstimateGrepJob)
+	router.DELETE(options.BaseURL+"/grep/:jobId", wrapper.DeleteGrepJob)
 	router.GET(options.BaseURL+"/grep/:jobId", wrapper.GetGrepJob)
 	router.POST(options.BaseURL+"/grep/:jobId/cancel", wrapper.CancelGrepJob)
+	router.GET(options.BaseURL+"/grep/:jobId/export", wrapper.ExportGrepJob)
+	router.GET(options.BaseURL+"/grep/:jobId/stream", wrapper.StreamGrepJob)
+	router.GET(options.BaseURL+"/grep/:jobId/webhook", wrapper.GetGrepJobWebhook)
+	router.GET(options.BaseURL+"/searches", wrapper.ListSearches)
+	router.POST(options.BaseURL+"/searches", wrapper.CreateSearch)
+	router.DELETE(options.BaseURL+"/searches/:searchId", wrapper.DeleteSearch)
+	router.GET(options.BaseURL+"/searches/:searchId", wrapper.GetSearch)
+	router.PUT(options.BaseURL+"/searches/:searchId", wrapper.UpdateSearch)
+	router.POST(options.BaseURL+"/searches/:searchId/run", wrapper.RunSearch)
+	router.GET(options.BaseURL+"/searches/:searchId/runs", wrapper.ListSearchRuns)
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8RX227bOBN+FYL/f1WotZ2mm9Z33cQJHKRO4LTYiyAwKGnkMCuRzJBy7Q3y7osh5YMO",
-	"zjZFD1eW6OF8c/jmoEee6MJoBcpZPnzkNrmDQvjHEaJGejCoDaCT4I8TnQL9wlIUJgc+5Kfji9Fscvl5",
-	"dnr5ZXLCI+5Whs6tQ6nm/CniBVgr5o1r1wYSmUlIWSZzYEo7lulSpUwqZp1GutChC+GhBOtmMiV1KdgE",
-	"pXFSKz7klyjnUomcVUJsfMIyjcyhSP6m+9EOPsLDbLn65+j9hzZMhSMRUj68CT5v3agZcbu5rON7SBzZ",
-	"eIZgPgmX3HWFTzlQrh6K0XR6OR2yRCsFCfnCnCxAl64rAF7D0rVVi8wB1hTf8AksHcv1nOVSARvwqHFy",
-	"QPZLB4XX0MKqDgSiWNF7DJlGaGBcISykLm0dp336IqynjrAST9pJv9YlJhBI9PUOEFhBoWdfhQ2EqmU9",
-	"13PbE8a8yfW8K7pk6UyVRdwI5eHBRlgqB3PAFku8eXUN0Sbf+2gyDUzqIIqwMLOgrHRyUbmdiTJ3fJiJ",
-	"3ELUCMNfd+DuAJnTzABmGgtGKl5vVDALApO7rdOx1jkItcOpGdlua1j9Js7Ee8Z0xqpLPrmWBWowoVLm",
-	"iRiyQFUjlrIoCz4c9CNeSBVe+u1whgTbdoZP6ZhpZEY4B6gsORm8YVLtpvcm5PdVld1Gsv0rUFvzB8+y",
-	"sRDLcfhz0A92r1/bVVGZ1TacErw2esfmTGONk8GkV93dbg7LWkIcls/lnqKJBsFtcIVlQUs78Q0Cr/1Y",
-	"J6KLs+c6noI1Wlno4CyCcJDOhOczcZCeeCocvKaO1uXhvY6rXr4NyBzBzEScDA7evnQEfFHyoYTNAJAp",
-	"KEdTBr9vEETcOuFK793/ETI+5P/rbUdmr5qXvXMdXzvhoBXTyr2a0RulewIcNFFEFJXKDb8aTU7GkzMe",
-	"8fFkdjW9PJuOrq95xI8vP11djD6PaOiefhxf+Ifjj5Pj0QU93+56Wb/ZcrOCDZ42RxapeGlev4cLsF45",
-	"6jn1mwirpi+TGbvXMcuEzKHe209fskd8B++MoO0iGNUKUokIys1Mc88ZdDU6A9iWfNfZE512IvfCti69",
-	"XxjBlrmrix+8PeocYC3+GdRzBFu/ffSu1sf/s5HXK/RbS23H7k1jfq7otmtWx6pCJdZB5njlwM4M6gSs",
-	"hY4G8plCyLwY24rt0GzQP3z/7uiPzrSu5Wd7Jtl2eHqBfQD7U7tHbzBaNbTTKhAAauqfYY6f2fsBqr/b",
-	"a9We7ahFrl/bTOm6VJluu/ORpZJ4F5cOUkZlzyzgQibASivVnH0SZgppmfjWIZ0n78nOFT/Zr8MVHvEF",
-	"oA2aB2/6b/rkqjaghJF8yN/6I78m3HnfewToqanD5kcE9X1lnPIhP/adkxDOdbx19k+drhpfEMKYXCb+",
-	"Zu/ehrYUgvgtpbNePZ/qYaYFIxSjn/Le4oP+wQ+D3t0gPHQ9Nec6ZiJJwDhIKY6H/f4Pgw6ftB2gY7UQ",
-	"uUzXa0PAPfz5uPWJ5fluy6IQuKIPmzIupGOCKfgaOHqvYy/jCdR7vNfxOH0i8Dl08OgM3JZERqAowAFa",
-	"Prx55JLQiZA84koURG+vjTeZEO242PpIbpbVFc3nqgvRulU1dLYzOKMA/VACrrbYJgzpLdRm3R3sjJpB",
-	"V5PZ32I36EBfDh6gCzuXhXTd4DSR94y9LltuW1XT/5FVU+1ne2omdEH//bV2XGZMLITMRZzDL6M02bKP",
-	"0WfgNkTuMLjN7V4iVAL5M73S//9zaf5b0xoCkEPKbJnQKM/KPF/9rmwS6odfgypyBJGu2Obro7cJRYNV",
-	"gQNMMCyVouG90ytJEHCx5kOJOR/ynjCytxjwp9unfwMAAP//bij2xwAVAAA=",
+	"H4sIAAAAAAAC/+x9a3MbN5boX0H1vR+SuS2KkiXbUdVWrWLJGWVtySPJO3MnTJFg9yGJqAl0ALQkxuX/",
+	"vnUOgH6QoEj5oSS1+WSZjcbj4Lxf/SHJ1LxUEqQ1ydGHRIMplTRA//lRjU+gAAs5/i9T0oK0+Ccvy0Jk",
+	"3Aold38xSuJvJpvBnONf/1fDJDlK/s9uM/Wue2p2T7VWOvn48WOa5GAyLUqcJDnCtdgdNyx3C7Jvfrz4",
+	"fnhy+ub0+vTk25QpzTTM1S3kTMkMmLCGacD9CCXpRXULeiDptdN/vTu7PD35diCTj2lyBVxns3NlX6tK",
+	"PsFJrjju0tCqTCrLJrTuxzR5L3llZ0qL354Com+FMUJOEXRC3vJC5Oz43Rm7gUWCg/37OL2b4uhDUmpV",
+	"grbCXX+mcsB/4Z7PywKSo+T12ZvT4fnF9fD1xfvzkyRN7KLE343VQk4T2oLloqDXu7s5vQW9YBMBRc7U",
+	"hHHJzs7/+/jN2cnw8vQf70+vrpmdcctuhSq4BcPsTBhmSsiSNBEW5mYTHF7j1B4Y9ca41nyB/5+DMXy6",
+	"dJyrEjIxEZCziSiguSsmJDNWaXwhckY1mRiwq0f8fmGBuYc4g50BK7m1oGX7uO+Or69PL88Z4FZZqYS0",
+	"hnGbpM3GXtSLCmlhCnQiDb9WYOxQ5KsrX2gxFZIXzA9iZydsojSzmmc3uOvW5DjR8H7x24uX360ezq8j",
+	"NCLoTw4DGuB1NvFz/bIa/wKZxT227mAFm+jqV7f+RjmsR6RAiNGwlI1V7rHFMK6B8TzXYAzkbLzAcUIj",
+	"bGfpQJZc8zlY0AYf3c1AAw5Y0GsGpB3IzvFx5h5R3r0dFkKCid1xFF9kNR+DZvPKWDYGxi2bK2PZXn8j",
+	"IN3hm2njwCvglaqkXYVdFn5e4jRQQIbMko6R1hua8/shvdJjZ5Jw2wzvhJ0N59xmMzCMy3wgm99VZd0j",
+	"Nlc5MGGZMGyPMIjT6wxHMc7cIC5z1h9IZWeg74SBXhfCe1H0xWm60CzU1OzysuwVaroFAAtIUg+IddB7",
+	"I4yNIV7h/tiKjbxRU5wqxkNKjkTmYP/wJO+akbGDmOgJftBQvkUAx+6/FhQN/E4vLy8uj1impISMSMiK",
+	"OajKxvDZI/zq1HxiQXcm/ik5h3vLCjUlvGJ7Sbr0yz7uv4bmylrLcBvDRGlYWuOdhluhKtNdZ/XXR631",
+	"MQLWgHhLpKMqnYFHbuIZDrdRk3DyOt0aVdMEdzp03KFzzIP9GCnQSpAPvXCICMs3fAyFCSzx1wpFZxhN",
+	"upDjlPeQM7cJGtuMcFJMORmEm0uZmguLrMIRNSoGRS2e2mf9KWmwyIC+FRk87rZLrm3kSG/doRk9Diej",
+	"GxaSKZ2DTpmSxYI2iH84joQKzC9qbOJbfNTG8CVj+bxc3dw/Z9DAinCgUNMp5H5LBixtCzfiWKERMoOU",
+	"VdKKgu4jzD2cKD3ntrPfZL+/f7DT39vp71/394/2Do8O9v+dpIkbmhwlObewg1Nsywbb+JbW3GEdU7l0",
+	"Qjuik93zzBYLpiS08CcNf5Cc8NgnDKv3kS5zJ25gaEAaYcWtp7UJrwqbHE14YSBdhTaKDmYVK0EjFBhO",
+	"sVNP4ZXnBhpjpQrgssXIvORur9VfXufciWs1Yf4lJyeZ40d0OOJ+jvQTJMx7Ma/mydFeP03mQrr/9NeJ",
+	"swiWo+gwiBA1CK3yp2Fiic6IqfzNs5QlDkP/JQ2RfngQz+f8/sw93Ou7fYf/rtKAkLegvbDffFFOv6gJ",
+	"wzgVPVekKdMcKSvEDbCphpLt3EYvjHBVwxTuy80rXiC5eU48UwUEzaa1yn3KLL8Bw0oNGeQgMyDbj90p",
+	"nYeFYhuptaKHsebKqtLjBdkgcy6RA7Q1LURbEh2djc1T1kc2MZBSsULMhe2xSzBVYR0hwX2pkPfNVJF7",
+	"Bq7byhoL2pmaDCTwbOaXwKHGauBzNucLNJDFbUBa3As3lgnL7mYoy7JZJW9IZR5IY0VRsDEgD3U4CLnT",
+	"1R7G7bm3+x7ScJxR/RZHokXU5tebb/kSEBJ0sPmyWKCDB7lQq0Q9eu7APZAO3qrHXnGJqDgGlqn5WEjI",
+	"HXvuMAl35FV8CMJvhYhx1dpya+h3onSHqzvy/NsKz06T+52p2sEfd8yNKHcUTcyLHTL2QCdHVlfQ7CDK",
+	"R9qyvbOHDs69ZjuTHjt2AMNTcyHxurlcDKQTsnPW0vpbEF3WQlghjDV0KUqC14IGUkmGiHwdLKpwYUK2",
+	"1Qjj/C/u9ggH2UzdAblkiIAs6VhcQ8qIRJnHEDocIQltj0wrf6UIpCXT4qdk77uXvcO93l6/33uBT25F",
+	"0fOPe5madzjlnN+/ATm1s+TooP/dc0L78MNeuoGRbmSlpRZKC7vYRCk/qvG7MPRjmpA43Ww/0J38g8aS",
+	"CjCF+w5dIXAekKuEZ6UG23ggjAN8GoH83QxkI7GEYQZslGRqj9vQQKZkHsHcv6s7Vig5JUT6RY1TctUF",
+	"tiRzpgNL1MBuoLTBn4cGqRRmhloXvluvhXg9EdNKO6xzXEPyKWgmJoyPDUjbG8jT+xK1E6ekcWnuQLOD",
+	"vb6jluBVVDU/qHHq5fODfr8l+5/tHT57Hq7f/fQ8yiZJB4z4YpzGSOTqpIXTJRm3qBi0JIvX+Nbqif2j",
+	"fn9bPTFNVlXQVa7iHhAI6+FLTBcFijaekabMagF5o6IPJC8KFhjLeBGQqcfe0FG9N4Hx1vxCzkALGzgL",
+	"ibda13a6WNrVzBrh2Nml90U42XYjyhJyh7p0Ewhcp487BHbXvJXNfx1WcQDq8oKDVdKnZR5x8/Wx/KWn",
+	"jQuJsMFh0npMOHgcJtzBeKbUzaZT/9MPwzdaqtPjdTR8u6uj3UW4x9Z+EIw6iMlk1VUxRjvjFzX2LtAG",
+	"WrjocI/vj59lMXjQe14IrjNMa5MUByMP6Xi09vtRDpDNuJxCxKn5LvDSsbIzx5GC3OQsF5MJaJCWydpA",
+	"ITzfFl2R1AhCESPXb2loleURFG2ZRG5gy2kgZFZUuSDWrQywrLLe2eq02S5EYvDIheFlCVw/CBMyqdug",
+	"DrD5EudvbWEzDFqDPxUOMTDMgOfr8fQgP4Tnkxie0nvb4ikOXsHTZ4dxhZ6bm+iMBmUyRp4gZ1Y1hl4a",
+	"BC1xfedOvyMFzg1e9cY4x0qaVJWge8S/Z3CfpN5p/jhXjYS7bRAowOBLIpCEu82II+HuExHmWex+KrmW",
+	"kzRrlut5Cvxa8QL9OBMLHd3mIIIOS3y4zVS7qBvwZomBLuGpu6w23Lp8IEaSDedcZlhtWKyRDafGijm3",
+	"EPGSk5L5MNPDEc6iLguBAGV3qipyujOndhRgmJBWdQjreezawO8kX68Nn96Xzm9wx4tiJytUdkO8PlDx",
+	"EgHv93uHbSmvqnHREvGelNa7n5qTunPgEtMCUUWDUcUtsKVzHcbOZegposHDwFQ4zAaVG9eyM62q6ays",
+	"KIKEaIMqd/eEsRWb94bjhQUzLEF7oEactS1QNuupCdOQgUR/Zndn7eUP9w/2X77s97cCMqGk29DqLl4F",
+	"h4MRv0ETvCygs95e/8WzFwd7L/cPWisKaZ8fJBspM0zW3kYakLx7S2tIJR4PC/e6Fav8UY2vLLeVifPK",
+	"ezvMKm2UjgCIfg+QwaGs5FNIvcVWRye4cQ82+r4fOum7ljFea6+JRIgTQ+rurODG4avnB2RboBzhGlAp",
+	"KGsfR4+9qoeQTc0zdFAPJKH8VDE0dmr1XhnCBLcs4V6KbrlstmbkQI5xJT8SjWOa1oPM8DmwjPY6Vd4e",
+	"ytQ8mEYYnCF/3vf1HLT/kldIdrywaur8Ac4zyGX7BHRcYZx3cCC77kGQaPX+lLTGJ2kDTdp08vPKfdFN",
+	"XPoEogh71kDMkhNObmfKrNWi+Djb238We+WhPIn3UvxaQZ0lIXKQVkwE6E/LlkgT42hjOyKCGEo7Sdva",
+	"dD3pGlR3Mx19qG/p3en5ydn5D0manJ0P311e/HB5enWVpMmri7fvKH0qSZPXx2dv6I9Xx+evTt/g3z+3",
+	"T9l9M3avV/VJl0PTntc+6l4/BRcg5JUsiVj8mfnECvQIIWZPuCi6aiqFZrZO8QFyJhm/vzXBQuIYOJU1",
+	"LOeWE/n5LLWUgobetcWCZ2trEx55v4sKrA0yaXKiu4QYx73IJUsvoS6KXnzS48uCZ+DkYx2MwLQZSlGA",
+	"vOE1d3zRY27yxo/j80L4grlwwkRQZBkG0jlbAiACHphH+F2ajJeIcPkE0v+0BA0KZ041GNNZ7MVhJyC4",
+	"MSL4awUVDEtlRNjASoKjkzToDQfJpWWcDBhi98IgUIPZFWQQo0mDyERnfAYMPXCN4ArwJ/NsxmXemHJz",
+	"VLu16bo7n206R5d/bssIPW5trVc0yS6Rq0cGGGE1XjXUKqNMsFUQX6OmxGgYa4Z11LGDl4cvosp8PX64",
+	"pW69ZoHY3E6DWzOv27Rcmt2qsMBmfd1Nv9Zx4Bbwj1eTW2I5KrE8mqcWdbWRHvcD1pHc5iAx2NRPH4GO",
+	"ZN+uzn8Y9XmtjSKGhBeXUoJRSZQTt5jeipLIqhuQaMKbmyVBNaj6/WeZNfQvMErzCqld3mHshshq7sbM",
+	"Dct4USDXd09E6R5s1KmbBKAWUDsQaHYWvSSfLbdyR5LPI7GR77kBho/aBlPn8A8kWWG+Z0QYVkVBqaDI",
+	"OzFrrJHq2+dvoQ0Xl7POvBOSBftrlZdstOvSpCrzR6o7S/dE4PQg8NvtzBq7m3cdabiktVVag7TDcjnH",
+	"NerQRGt8ZeThA6wOB3el6QOMqyU6GjfBsxfbcaVOpHQV31zwAVMvNBjj04xrT1rtk59yIY1twmAYUpeU",
+	"CmsGEgRZUZwVwCchE7ZJ7Hb6n7qTIfTjwv0uT49LpsqBdNkJSJ8uGCqKXIPsMdy2AMMkGFtn775keFPc",
+	"Km1YDlDSGpg0MpBhyLP9+hBL0cwPSZg9OfrpQ8Od6pS5j+mHBEV7ndzXYmFJyRdzkHYnPPuYrp1vBryw",
+	"s2wG2U3y8ec0USXKyIvr5j/H5ydesH6ZXDGniZLPAC+imzJWLOLZYvXmV4J1CGOZkw6lym011uXAfBMm",
+	"fLa/KfUqX5Pn6dwPdKZap+/kvixnanRC+AOJKOUf9ZjPHA3xxcoZvIi0ZB1Q9sESyrQwYbXUoVwDOdty",
+	"7ah8Kb+0AH4LpsfOL659rha0Mg3pTnotN8MxlZJcXGKe8cV11LGwVsr6C2lg2DnZMj5HFddPTq2oUXE5",
+	"xyIe/VzhXVQo5BKpvoy3BH1pQ13JR70UJHUDtYuLt+xGFEW0MIJ8fs0aSzoyevmQVvKqgJzpStbuvsai",
+	"DM+3NoZ1k7+6SYcLqa6+vomW6Zytz16wv7G/sb2dw9hSjoWv2D7+5/WW52cL+GbhNAj7cOyOt2Sj1G8h",
+	"Vdz961Z6REVEa8Zoxv3qMdYE9lsTtVKS43pjHBtbGV3BIn8ooevzEWfJcaxVR5cgXWCC3lRXqeREPgsv",
+	"Kc1MhfUyho3+M+eiWIwGUmk2+k+gYrjns1HK4JYXFfc8//31K1bJAgxlt7oEf1xkIEevLi/Oh9f//g+n",
+	"4P+GHhj8C0Y9dllJ0y5KKoAbct0IWVlgvOQaE3SQKVOeFZKhVS6MqivncQi6i2Q5zLnMl2XEg3QTV1YD",
+	"9KOo0KSPdjz2oR5rmfty631cTkKaI+ZA2MnLZd/Q69+6/LHaoh7Izqgmy5SyiVpZKxn7hmweP8Gk9oQx",
+	"vjRH+62CfbNaYfVtU6TRcqehA6fz7hv2TaQK61tKt3SRETvTAP7UrmTLuwWZkMZ691HLsceyeErsQHZy",
+	"YtlykYVbUsPcJ5C2IyOrWVfBrykMu5Go+tbqmXh8vVk35hAwIFifq7N1fqyniqoNntlU8vf0W3+CI7MW",
+	"oVHvs9NE8LoqV/psLA+eYPy5JWBXNeLHunEi7qVNrqOo28ev2z7bkmDrrrOea1xWMi7ZdCUfIdXCXFEH",
+	"ZFsN2F5w0wZiG19OMFyTNTv1DCrjWocqV8SqI6Yn2bNnz75j37QSA/cOj/oHR/3Df6eMM1PyDAayxRSu",
+	"iR1IxVBWuBraLIPSQp6SnBGGcWOquYsAyG9TZhamUFOsoeeSsX0WFkCZQniFaNYu3Uq9zPo2ZXIq5P1A",
+	"fvNTf3/3Ry53cZ9H9QT/D/OpfyamCKXKZsO5KAph2Dd7L/oH+/0XLw8O+/3+tx1G4I+cpInbV5ImtAgO",
+	"ac0Rpft/NhmQS8EimVMSPpPKuspvYmXBid6K0ywrTZmOlXxfzSh5zD1mFHi1ihkxlaFOQ4BJmURpxTTY",
+	"SkvIu4rM/uHmzPRKR9KRjsdGFSjgZ9aWCFr817BKu6CMh8HpLUhi1O8urq5pex25Tu8c7e62cuh38TWz",
+	"iyxqaaf9g5ebZD/uNA3gipGC39aJg85ilYq5tTAvbYvyWh4j//CRHDivNPnBhnPTeWm9w66ONa6J+A6z",
+	"WnXpFra44Ddzo1I29z0YKE1ZKhbaaxDb1pCBuCV82MBFA0yWANA92RbQfqOmawH+YMijQWZW7yAKOD/w",
+	"sRdUT781/17Go3XpKX67j9qOCSH2JdF7fHZ9dv4D6wY+m6oFH4j3+RZtkKHuVMONtEvJTk7fnP336eXp",
+	"CZKui88v6dz1iGQ9S9iKGuk4aXPRHYCvog0JwKzCfJorhLa7leNS/BcsjivnhBfeB5iDDhbrUfKvneN3",
+	"Zzv/BS1fHKe3cMPfA9egw/t0jaSa0M/NC8iQXDcTIScqwvUwOGq1GFcWcqdGe+8Oq4jU3vLyEvLKeXuE",
+	"dZBsvULVXVe1Q+gWtHEz7/X6vb5zeoHkpUiOkmf0k/O7ExB2W0G8AhySOG+tUPIsx6Xo99curtH0piDv",
+	"6YrrataOg6wGMAjIrmCohrEPATS37NxVTa+YrRss/Jx2u/3s9w/WxEBy3wToY5oc9PfWUWY92W6nzw29",
+	"dPD129108zscFlfzOdeL+lbQRaimBGzc1hTs6v2havnaZ949eH1UBlEIY4ONR0ldeD1OUfKmI4X2nTG/",
+	"7kbDwwYAK1S9aXE1aQz/pmo1xKxiq86FHPo4UrPuimR8KFVg213N1fab4vdffVNzlTvNb109VnRj/qUh",
+	"DY9v70Fn39a7Wq4V2rQhN/4L7Ogdn9ZOkwlVsNfhw3WcaNpdt3bj7LXuaG+bO1rJrQAdckJjK4es+tjS",
+	"nVq+vW4dX2wvq1yw/8V4Vd2TJtadK3S3CBzJuGILZ8YTlyfO2f/6nPPMdwfTjQ/0E9h8h9/iqVsn47dc",
+	"FHxcAGEWCu0yBHZUrDEF+qJG+OqICsOpoo8q4L195SQk44YJy7jWgsJNx2yEcPPvuIwpT04C/+8rH3Mw",
+	"1mN2yuomRvSoKgvF87ofGJ9TQSSadVRgKgxl1vkzadd34E4La0E6L1ZXlryn6bwu4KH7vcoXS1c6rwor",
+	"cM+7SLk7mErYvdXVfkYdMh8LyfVi+5SJkwYAn5k5EetOEtEnPy7rKx9XiG7vi6F53cBpjYoQ7vjPTF4O",
+	"sVrqDJJCjUZIZHPKSCUcqNYQGLVYi1NWyuBhZF9B9XeV3Ubn3Yx6T6DzrqPD9t2rzILdcaDp4sBGsvsL",
+	"3b8+uk+0mjsXOL8LKxFC05vOd3X0IWj4y04adMMZVzEh4Q5ICdPGUvIzIi6hewifpGzk4/ujppPGQFL6",
+	"iZCtnkXV2GFAOyu3zst4x41ho1bBzAiHccrDJuodhZ+tYlOfXDBRRaHuaEoJMQGDYhbt2R9dmdMS6cVI",
+	"qeOGn/Pt4wBrVFgConfjP0qjrl3/X1Kh7uxmS006bOQzFOk1PMtnVj5o1sVe7WbIPvZth0ef8uYDavV+",
+	"f20y/FMr1aGuLcJ6jsloQMJCRHhyjjch7oEk4O/gSynS5OUKR4przFfVmHzNSvrwkesCxEZnOcxLZUFm",
+	"C/TMjShHAajfDssKAdIyU7mq7oEc/WvHJ0LsnJ2MUlcPB3nlQAb5EdNQArdCTpuykcB+vbsDw8T48w0s",
+	"Qt5Zt53LnZC5umPf7B/MWh1EvvXhERN450AGQu62A0nZnOub0OmpOZ/duYSy4AvcJQrdUYxhvqIpPctc",
+	"wzGXvZpLEOwQSCeOc/gZ+sbjULGTsbKNvrH/JcmvLvFb08Y7xBl/L5WDUHy53bKYdBoy5woMeQtxHdQo",
+	"kCruM4DcV4ZSesC9sAs2rvIp2D+X8xOX/e4JAN+QBtG7hiq0Duo0OmlfjLDGU7P/1dkgWFsmJKtLsfAI",
+	"+09whGPXoyEobVhHgSWz3LCZsCkbYaBvxHx5lpqwy+Pr0+Gbs7dn16cn6UBeX1wM3x6f///h5fvz87Pz",
+	"H4Y/Xnx/hce8enV8PvzH+4vr4+Hpv16dnp748I7jLkSWl2D1Yuc4NOBdYuiupQCzit1xUZcOG+Tzlhgw",
+	"Za3H5Hwjhz92hQkJCcs49a8IQqXRmHdzX24TVZvPqfxX/ObbCsxb5S3k6W3aUowREbDqEHe5VPAykIQc",
+	"+BQwpx208fEwn9bUzN0karn8JpwNVxtIT8TYXtG1VGxRtm8PgmAOe0ybpnb0bMwNDKR/SFkS9XPnxKet",
+	"hA45qdtDyzk3U3dsjol9mEznR/UYdVNJ63YqJPaEd+qxuxm3lAPQaqiCwnUqbkEyIY8GkrEdNqpbqYyO",
+	"2NnVBXv5vL8XEjNSZEpz5Qxmp5rS5sc4Eb2YkiHRrR8a+ZmxMcvoiL1/f3bSGYe/d0cKWvzd7UHddL3z",
+	"gii7w2dwPzpi/fsmYxEzGn27QV3JbnDiJZth8z8xFbYz6wzuu9M6T/ToiHlsdvPlkIk5Lzqv1mVQo4jA",
+	"xwqy9RbSquwKvJ9rYZQMkRw0N1NWGddmhbAFURdxZo1VgTj2oMtio0Hzoy+qpQSTUKHip42tiBj/eSu+",
+	"UvM5ZwYQSBZyqk0jBoQ4vUhZvPVbykZSSRiFzRrPErip0XxttMe1l4n5cWpCSH1HoY27b2IIuH7DDkLW",
+	"DQUVMm5hqvRizY62Diwc/t4mkGtftCrOTrzMzaibnr2Dplbd/H76WCVdmijeuE+S/oWIjduQ0Rv4v+Y+",
+	"vZHLIAf+2KoX0unTa17HBMAZb5TYJkS/198Mqtanfbo6witHxcty2N6pZpW2QVorD9DuyxS1US9dDyLT",
+	"Soz2WlfQBCk1eoSZaowmHTk9nSSJQ5OBrKUvtVyoy9+pi5PJuHSZzCExignDws7y2m04kHfrmgi5njQW",
+	"jF1ucIS7EIYVMLGMkrldHhCvb6IeH7M8Q9uqxvb8QxiGX5QphTPGEDY8a3e++ss+/EMkx9RXgwejemdS",
+	"tQIFUQ1AoPi6jktX0he0tnjAh1/U+Cz/2M2PWlIyuMygqL08CHfRMgH9tCkZPYbCxb4UwXcqqtsuMKtY",
+	"rrmQnj1g2xUTdJTQkqXHyLy64zpvOZZEq3tK65NjeDRqGdzKoj7oH8So2fHOh/1IPmrlVQuCzKP0s63y",
+	"s1D6/CnSs1bF5GeJqTqhq4OYa3pNr032+gHs173DjVk2foe/U7ZNvfon59sc/qECA6FVXRz9XOyrjRjU",
+	"QDzkpvyvop8foAkrRACzytN3M2Lcbe1uybVOz5+aJT4h+jgAFJAzU2UZGDOpimLxl3ESX5UXGnje6sW5",
+	"W8PvCxgpNBXjtRYScWYGvHWfXVnr1ryiFA/j60ldVWLL1+eSa6Qv+3SMu8d8I/3gtZM5Am50RD7ium8D",
+	"cVXnsKRRmbkdHdWfjak/4JQyfxlp/dmS0BQ//J9i5H4W/GF05HxQlfVGy8iVk0q28/dRuvS1o8oAG+2M",
+	"GDc4Awv+HaV9fZjcwfFiWqnKsKlWVenyH6hBLoJgtLMT86ydEly/uviMySMfl48KpMRdRquQrP4hM/SF",
+	"Iri3kaKxR/KW+x2Zr1LLyv5ptV1ceJtxZcHFhhmjtBbs9DrUSmaOa8bhv2fwtIHw1rJ/ccblVb+C08bR",
+	"Ihk/LZ8Nj3hsIgyySXJbky6FUfVuYIaAxoxiE65DAEWysjL4EINLynuA6xaEpVZYk4MD5z1GRYk1+6RZ",
+	"R0eMN7wzpSQGqp2SDFwJY84qQznE3LA33NgdmmXn7MRPQ10DaZpalDfWqlNrUmZwqhK0ULnAWN+i9XJl",
+	"PGOd0Ld061n8W9FK0ZTVzJn5Xr+t73UJw7JCGcgj/NMJnic2P87yxsdl/BfHPHxDNWJKwJqDaR+EDsnI",
+	"CIjmSHTuI/k8FY5YIe0pmn+5kSM6wPosIHc4879Kt/cAqNX79ve/QnwdiQiL4EDvXOHtn3owrXCH1sdk",
+	"ouzhok0VwrC663DqixqZ0qzuOsz4cl3yQNaFyTSNXw/LmHvsNbXx7ZRSix70mAR7p/SN+4a2SQfSdzcz",
+	"KTvov0zZwf53dNrD+/u65Nakvj+v+6QRuQvg3oFV8AJ7cWc3ajJJqUMr1QXU76KqZUQObP/+nnoLh0oC",
+	"V3zqqhqUoioB+qF+EthY+FwFMUPiOP/aQdp3ROP5jiNERPCUQW/aY6Nf1LhXM/FR981Q+tp5WfhoN6Vg",
+	"KenVWjz0ovv2lZhKbisNoyM2sr6tTCXFPfMfM6BfIL3d+4/lCK3/YO5K5JZukzFGG/j72+NXO1d/P94/",
+	"fE4a6roFeu4B5u6GaW5gES6IjkLF5BEO2nhvPE79Ca3OSIF2hEH4UWSoe++sfyGUY5uUQtIhlflPxO8Y",
+	"tUka+/ZxTZuwu+bzVJ/v6mhzlq4/u27gHQBaKKeR77YbZa0tFb0Kg74ijiy39IqFA4uCGRwWPvRp0k5q",
+	"+xdLRe0u8nAFVye/S2nnbOcM8bXgFlKfChbqS/wnSnyyA1NyID33qmSP/dPllI1C+5hR50OALlXU+GAc",
+	"hfkwOQfbLuAcnbY4uJ+8gvXpoVfh68NfI0IXaYL2xBUjnX5uEfWJnriL/v0idPVlNd9S/hNH2BDkgekE",
+	"qiBakHxek1OX6+x+cH9tCKf92K54mPimY+7N+gubvTXhqxrPNwvNsJcvH8Dy6PaFYlgPv+TWOlf29UMt",
+	"AtosrhYPupJsJpCNLR6KJj09SPtPxReuZ9ABze94VSjR+cpm1hY60reH8AIp7pvxIqsKohcf8695jcvl",
+	"NL1IIW/On4he/jgSp//EEsc3Nv1L5mx9Ag84pdnkIelDHrwMVkhmjbjZ1b5fYlSxe7WqaTUiJ0VNHou+",
+	"gzVPZiiFj8njgHlUA9lo+1axkUv0cp+/4nlOJf3+7RbTjWlrl5V8en77O1bS/PnQ8I9Rw2Ggm1T4+VUY",
+	"Iez4+BKMy0ouqxhS3T1Ii2a9a76SznQqBIV72hZf4z8J7j/XT5KoFm8ndYmQPtcRO6UwHeYjrXFNvXHd",
+	"ONN8PbL7UxeodruURtD10hdFNHzzyWWeg+Tvp8EhaFjJjW0qRJaFU6sLHeFWu//cTz8jirQ7yv30M94p",
+	"fRPR4yI1yEt2eSl2b/eSjz9//J8BAJe7hJzrlAAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
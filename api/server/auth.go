@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// APIKeyHeader carries the API key, a bearer token in Authorization works too
+const APIKeyHeader = "X-API-Key"
+
+const tenantKey = "tenant"
+
+// APIKeyAuth returns middleware that rejects requests without a key known to
+// keyring and scopes the request context to the tenant of the key. Register it
+// on the router group of the handlers ahead of SpecValidator.
+func APIKeyAuth(keyring *service.Keyring) MiddlewareFunc {
+	return func(c *gin.Context) {
+		ctx, err := keyring.Authenticate(c.Request.Context(), presentedKey(c))
+		if err != nil {
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+			return
+		}
+
+		c.Set(tenantKey, tenant.FromContext(ctx))
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+// presentedKey returns the API key sent with the request, if any
+func presentedKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
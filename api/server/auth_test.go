@@ -0,0 +1,121 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestTenantIsolation(t *testing.T) {
+	keyring, err := service.NewKeyring([]service.APIKey{
+		{Tenant: "team-a", KeySHA256: hashKey("key-a")},
+		{Tenant: "team-b", KeySHA256: strings.ToUpper(hashKey("key-b"))},
+		{Tenant: "team-b", KeySHA256: hashKey("key-b-ci"), Limits: &service.Limits{MaxRunningJobs: 1}},
+	})
+	require.NoError(t, err)
+
+	ts := newTestServer(t, APIKeyAuth(keyring))
+	ts.storage.AddFile("tenants/team-a/logs/a.log", "a\n")
+	ts.storage.AddFile("tenants/team-b/logs/b.log", "b\n")
+
+	asA := map[string]string{APIKeyHeader: "key-a"}
+	asB := map[string]string{"Authorization": "Bearer key-b"}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		for _, headers := range []map[string]string{nil, {APIKeyHeader: "wrong"}, {"Authorization": "Basic key-a"}} {
+			w := doWithHeaders(ts.router, http.MethodGet, "/api/v1/files", nil, headers)
+			require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+			var apiErr Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, CodeUnauthorized, apiErr.Code)
+		}
+	})
+
+	t.Run("UnauthenticatedBeforeValidation", func(t *testing.T) {
+		w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", map[string]any{"files": 42}, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+		w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", map[string]any{"files": 42}, asA)
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		w := doWithHeaders(ts.router, http.MethodGet, "/api/v1/files", nil, asA)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var list FileList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Len(t, list.Files, 1)
+		assert.Equal(t, "logs/a.log", list.Files[0].Path)
+
+		w = doWithHeaders(ts.router, http.MethodDelete, "/api/v1/files?path=logs/a.log", nil, asB)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		_, ok := ts.storage.Content("tenants/team-a/logs/a.log")
+		assert.True(t, ok, "team-b must not delete files of team-a")
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "a", Files: []string{"logs/b.log"}}, asA)
+		require.Equal(t, http.StatusNotFound, w.Code, "team-a must not grep files of team-b")
+
+		w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "a", Files: []string{"logs/*.log"}}, asA)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var created JobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+		ts.storage.AddResult(models.Result{ID: "r1", JobID: created.JobId, Tenant: "team-a", Matches: []models.Match{{FileName: "logs/a.log", LineNumber: 1, Content: "a"}}})
+
+		w = doWithHeaders(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil, asA)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var status JobStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		assert.Len(t, *status.Results, 1)
+
+		for _, path := range []string{"/api/v1/grep/" + created.JobId, "/api/v1/grep/" + created.JobId + "/stream"} {
+			w = doWithHeaders(ts.router, http.MethodGet, path, nil, asB)
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+		w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep/"+created.JobId+"/cancel", nil, asB)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doWithHeaders(ts.router, http.MethodGet, "/api/v1/grep", nil, asB)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list JobList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Empty(t, list.Jobs)
+	})
+	t.Run("Limits", func(t *testing.T) {
+		asCI := map[string]string{APIKeyHeader: "key-b-ci"}
+		req := GrepRequest{Pattern: "b", Files: []string{"logs/b.log"}}
+
+		w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", req, asCI)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", req, asCI)
+		require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+		var apiErr Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, service.LimitRunningJobs, apiErr.Code)
+
+		// The other key of the tenant has no limits
+		w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", req, asB)
+		assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	})
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+)
+
+// defaultDiffLimit is the number of line patterns per category, as documented
+// in the spec
+const defaultDiffLimit = 100
+
+// DiffGrepJobs compares the matches of two completed jobs by line pattern
+func (h *Handler) DiffGrepJobs(c *gin.Context, params DiffGrepJobsParams) {
+	limit := defaultDiffLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	diff, err := h.svc.DiffJobs(c.Request.Context(), params.Base, params.Head, parseDiffMasks(params.Masks), limit)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	masks := make([]string, 0, len(diff.Masks))
+	for _, mask := range diff.Masks {
+		masks = append(masks, string(mask))
+	}
+
+	c.JSON(http.StatusOK, JobDiff{
+		BaseJobId:        diff.Base.ID,
+		HeadJobId:        diff.Head.ID,
+		Masks:            masks,
+		BaseMatches:      diff.BaseMatches,
+		HeadMatches:      diff.HeadMatches,
+		New:              toLineDiffs(diff.New),
+		NewTotal:         diff.NewTotal,
+		Disappeared:      toLineDiffs(diff.Disappeared),
+		DisappearedTotal: diff.DisappearedTotal,
+		Changed:          toLineDiffs(diff.Changed),
+		ChangedTotal:     diff.ChangedTotal,
+		Unchanged:        diff.Unchanged,
+	})
+}
+
+// parseDiffMasks splits the comma separated masks parameter, nil selects the
+// default masks and "none" none at all
+func parseDiffMasks(param *string) []service.DiffMask {
+	if param == nil {
+		return nil
+	}
+
+	masks := []service.DiffMask{}
+	for _, name := range strings.Split(*param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "none" {
+			continue
+		}
+		masks = append(masks, service.DiffMask(name))
+	}
+	return masks
+}
+
+func toLineDiffs(lines []service.LineDiff) []LineDiff {
+	out := make([]LineDiff, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, LineDiff{
+			Pattern:   line.Pattern,
+			BaseCount: line.BaseCount,
+			HeadCount: line.HeadCount,
+			Example:   toGrepMatch(line.Example),
+		})
+	}
+	return out
+}
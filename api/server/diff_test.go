@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestDiffGrepJobs(t *testing.T) {
+	ts := newTestServer(t)
+	router, storage := ts.router, ts.storage
+
+	create := func() string {
+		w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}}, nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var created JobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created.JobId
+	}
+	base, head := create(), create()
+
+	storage.AddResult(models.Result{ID: "r1", JobID: base, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "2024-01-02T02:15:42Z error timeout after 30ms"},
+		{FileName: "logs/app.log", LineNumber: 2, Content: "2024-01-02T02:15:43Z error disk full"},
+	}})
+	storage.AddResult(models.Result{ID: "r2", JobID: head, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 7, Content: "2024-01-03T02:15:42Z error timeout after 95ms"},
+		{FileName: "logs/app.log", LineNumber: 8, Content: "2024-01-03T02:15:44Z error timeout after 12ms"},
+		{FileName: "logs/app.log", LineNumber: 9, Content: "2024-01-03T02:15:45Z error connection refused"},
+	}})
+
+	path := "/api/v1/grep/diff?base=" + base + "&head=" + head
+
+	w := do(router, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	for _, id := range []string{base, head} {
+		_, err := ts.store.UpdateJob(context.Background(), id, func(job *models.Job) error {
+			job.Status = models.JobStatusCompleted
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("DefaultMasks", func(t *testing.T) {
+		w := do(router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var diff JobDiff
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+		assert.Equal(t, base, diff.BaseJobId)
+		assert.Equal(t, head, diff.HeadJobId)
+		assert.Equal(t, []string{"timestamp", "uuid", "ip", "hex", "number"}, diff.Masks)
+		assert.Equal(t, 2, diff.BaseMatches)
+		assert.Equal(t, 3, diff.HeadMatches)
+		assert.Zero(t, diff.Unchanged)
+
+		require.Len(t, diff.New, 1)
+		assert.Equal(t, LineDiff{
+			Pattern:   "<ts> error connection refused",
+			HeadCount: 1,
+			Example:   GrepMatch{File: "logs/app.log", LineNumber: 9, Content: "2024-01-03T02:15:45Z error connection refused"},
+		}, diff.New[0])
+
+		require.Len(t, diff.Disappeared, 1)
+		assert.Equal(t, "<ts> error disk full", diff.Disappeared[0].Pattern)
+
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, "<ts> error timeout after <num>ms", diff.Changed[0].Pattern)
+		assert.Equal(t, 1, diff.Changed[0].BaseCount)
+		assert.Equal(t, 2, diff.Changed[0].HeadCount)
+	})
+
+	t.Run("SelectedMasks", func(t *testing.T) {
+		w := do(router, http.MethodGet, path+"&masks=timestamp&limit=1", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var diff JobDiff
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+		assert.Equal(t, []string{"timestamp"}, diff.Masks)
+		assert.Len(t, diff.New, 1)
+		assert.Equal(t, 3, diff.NewTotal)
+		assert.Equal(t, 2, diff.DisappearedTotal)
+	})
+
+	t.Run("NoMasks", func(t *testing.T) {
+		w := do(router, http.MethodGet, path+"&masks=none", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var diff JobDiff
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+		assert.Empty(t, diff.Masks)
+		assert.Empty(t, diff.Changed)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			path  string
+			code  int
+			error string
+		}{
+			{"MissingHead", "/api/v1/grep/diff?base=" + base, http.StatusBadRequest, CodeInvalidRequest},
+			{"UnknownMask", path + "&masks=email", http.StatusBadRequest, CodeInvalidRequest},
+			{"SameJob", "/api/v1/grep/diff?base=" + base + "&head=" + base, http.StatusBadRequest, CodeInvalidRequest},
+			{"UnknownJob", "/api/v1/grep/diff?base=" + base + "&head=grep_missing", http.StatusNotFound, CodeJobNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := do(router, http.MethodGet, tt.path, nil)
+				require.Equal(t, tt.code, w.Code, w.Body.String())
+
+				var apiErr Error
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.error, apiErr.Code)
+			})
+		}
+	})
+
+	t.Run("JobRouteUnaffected", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/"+base, nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// matchWriter renders exported matches in one output format
+type matchWriter interface {
+	WriteMatch(m models.Match) error
+	Close() error
+}
+
+// ExportGrepJob streams all matches of a completed job as NDJSON, CSV or
+// grep formatted text
+func (h *Handler) ExportGrepJob(c *gin.Context, jobId string, params ExportGrepJobParams) {
+	format := Ndjson
+	if params.Format != nil {
+		format = *params.Format
+	}
+
+	var contentType string
+	switch format {
+	case Ndjson:
+		contentType = "application/x-ndjson"
+	case Csv:
+		contentType = "text/csv; charset=utf-8"
+	case Text:
+		contentType = "text/plain; charset=utf-8"
+	default:
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("unknown export format %q", format))
+		return
+	}
+
+	job, _, err := h.svc.GetJob(c.Request.Context(), jobId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	// Like the SSE stream, headers are committed with the first match so a job
+	// that is not complete yet still gets a proper status code
+	var w matchWriter
+	start := func() {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", jobId+"."+exportExtension(format)))
+		c.Status(http.StatusOK)
+
+		switch format {
+		case Csv:
+			w = newCSVMatchWriter(c.Writer)
+		case Text:
+			w = newGrepTextWriter(c.Writer, job.ContextLines, job.OnlyMatching)
+		default:
+			w = newNDJSONMatchWriter(c.Writer)
+		}
+	}
+
+	err = h.svc.ExportMatches(c.Request.Context(), jobId, func(m models.Match) error {
+		if w == nil {
+			start()
+		}
+		return w.WriteMatch(m)
+	})
+	switch {
+	case err != nil && w == nil:
+		abortWithServiceError(c, err)
+		return
+	case errors.Is(err, context.Canceled):
+		log.Debug("export client went away", "job_id", jobId, "request_id", requestID(c))
+		return
+	case err != nil:
+		log.Warn("job export aborted", "job_id", jobId, "request_id", requestID(c), "err", err)
+		return
+	}
+
+	if w == nil {
+		start()
+	}
+	if err := w.Close(); err != nil {
+		log.Warn("job export aborted", "job_id", jobId, "request_id", requestID(c), "err", err)
+	}
+}
+
+func exportExtension(format ExportGrepJobParamsFormat) string {
+	if format == Text {
+		return "txt"
+	}
+	return string(format)
+}
+
+// ndjsonMatchWriter writes one GrepMatch object per line
+type ndjsonMatchWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONMatchWriter(w io.Writer) *ndjsonMatchWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &ndjsonMatchWriter{enc: enc}
+}
+
+func (w *ndjsonMatchWriter) WriteMatch(m models.Match) error {
+	return w.enc.Encode(toGrepMatch(m))
+}
+
+func (w *ndjsonMatchWriter) Close() error {
+	return nil
+}
+
+// csvMatchWriter writes one row per match, context lines are joined by newlines
+type csvMatchWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVMatchWriter(w io.Writer) *csvMatchWriter {
+	return &csvMatchWriter{w: csv.NewWriter(w)}
+}
+
+func (w *csvMatchWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.w.Write([]string{"file", "line_number", "content", "context_before", "context_after"})
+}
+
+func (w *csvMatchWriter) WriteMatch(m models.Match) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	return w.w.Write([]string{
+		m.FileName,
+		strconv.Itoa(m.LineNumber),
+		m.Content,
+		strings.Join(m.Context.Before, "\n"),
+		strings.Join(m.Context.After, "\n"),
+	})
+}
+
+func (w *csvMatchWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// grepLine is a line waiting to be printed by grepTextWriter
+type grepLine struct {
+	text    string
+	parts   []string // Printed instead of text by `grep -o`
+	isMatch bool
+}
+
+// grepTextWriter reproduces the output of `grep -n -H`. Matches are printed as
+// file:line:content and context lines as file-line-content. Context of nearby
+// matches is merged, and non-contiguous groups are separated by "--" when
+// context was requested. With onlyMatching each matched part is printed as
+// file:line:part instead, like `grep -o` does.
+type grepTextWriter struct {
+	w            io.Writer
+	context      int
+	onlyMatching bool
+
+	file    string
+	pending map[int]grepLine
+	last    int  // last line printed in the current file, -1 if none
+	printed bool // whether anything was printed at all
+}
+
+func newGrepTextWriter(w io.Writer, contextLines int, onlyMatching bool) *grepTextWriter {
+	return &grepTextWriter{w: w, context: contextLines, onlyMatching: onlyMatching, pending: make(map[int]grepLine), last: -1}
+}
+
+func (w *grepTextWriter) WriteMatch(m models.Match) error {
+	if m.FileName != w.file {
+		if err := w.flush(math.MaxInt); err != nil {
+			return err
+		}
+		w.file = m.FileName
+		w.last = -1
+	} else {
+		// Matches arrive ordered by line, so nothing below the context window
+		// of this match can change anymore
+		if err := w.flush(m.LineNumber - w.context); err != nil {
+			return err
+		}
+	}
+
+	for i, text := range m.Context.Before {
+		w.add(m.LineNumber-len(m.Context.Before)+i, grepLine{text: text})
+	}
+	w.add(m.LineNumber, grepLine{text: m.Content, parts: m.Parts, isMatch: true})
+	for i, text := range m.Context.After {
+		w.add(m.LineNumber+1+i, grepLine{text: text})
+	}
+
+	return nil
+}
+
+func (w *grepTextWriter) Close() error {
+	return w.flush(math.MaxInt)
+}
+
+func (w *grepTextWriter) add(line int, l grepLine) {
+	if line < 1 || line <= w.last {
+		return
+	}
+	if existing, ok := w.pending[line]; ok && existing.isMatch {
+		return
+	}
+	w.pending[line] = l
+}
+
+// flush prints the pending lines numbered below before
+func (w *grepTextWriter) flush(before int) error {
+	lines := make([]int, 0, len(w.pending))
+	for line := range w.pending {
+		if line < before {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+
+	for _, line := range lines {
+		if w.context > 0 && w.printed && line != w.last+1 {
+			if _, err := io.WriteString(w.w, "--\n"); err != nil {
+				return err
+			}
+		}
+
+		l := w.pending[line]
+		if err := w.print(line, l); err != nil {
+			return err
+		}
+
+		delete(w.pending, line)
+		w.last = line
+		w.printed = true
+	}
+
+	return nil
+}
+
+func (w *grepTextWriter) print(line int, l grepLine) error {
+	if w.onlyMatching && l.isMatch {
+		for _, part := range l.parts {
+			if _, err := fmt.Fprintf(w.w, "%s:%d:%s\n", w.file, line, part); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sep := "-"
+	if l.isMatch {
+		sep = ":"
+	}
+	_, err := fmt.Fprintf(w.w, "%s%s%d%s%s\n", w.file, sep, line, sep, l.text)
+	return err
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestExportGrepJob(t *testing.T) {
+	ts := newTestServer(t)
+	router, storage := ts.router, ts.storage
+
+	w := do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	storage.AddResult(models.Result{ID: "r2", JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/b.log", LineNumber: 2, Content: "error b"},
+	}})
+	storage.AddResult(models.Result{ID: "r1", JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/a.log", LineNumber: 9, Content: "error \"two\"", Context: models.Context{Before: []string{"x", "y"}}},
+		{FileName: "logs/a.log", LineNumber: 3, Content: "error one"},
+	}})
+
+	w = do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export", nil)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeJobNotComplete, apiErr.Code)
+
+	_, err := ts.store.UpdateJob(context.Background(), created.JobId, func(job *models.Job) error {
+		job.Status = models.JobStatusCompleted
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("NDJSON", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		require.Len(t, lines, 3)
+
+		var match GrepMatch
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &match))
+		assert.Equal(t, 9, match.LineNumber)
+		assert.Equal(t, []string{"x", "y"}, *match.Context.Before)
+		assert.Contains(t, lines[2], `"file":"logs/b.log"`)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export?format=csv", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), created.JobId+".csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"file", "line_number", "content", "context_before", "context_after"},
+			{"logs/a.log", "3", "error one", "", ""},
+			{"logs/a.log", "9", "error \"two\"", "x\ny", ""},
+			{"logs/b.log", "2", "error b", "", ""},
+		}, records)
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export?format=text", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "logs/a.log:3:error one\nlogs/a.log-7-x\nlogs/a.log-8-y\nlogs/a.log:9:error \"two\"\nlogs/b.log:2:error b\n", w.Body.String())
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export?format=xml", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownJob", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/grep/missing/export", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestExportGrepJobOutputControl(t *testing.T) {
+	ts := newTestServer(t)
+
+	yes, one := true, 1
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{
+		Pattern:      "foo",
+		Files:        []string{"logs/app.log"},
+		WordRegexp:   &yes,
+		OnlyMatching: &yes,
+		MaxCount:     &one,
+	})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.UpdateJob(context.Background(), created.JobId, func(job *models.Job) error {
+		job.Status = models.JobStatusCompleted
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, job.WordRegexp)
+	assert.True(t, job.OnlyMatching)
+	assert.Equal(t, 1, job.MaxCount)
+
+	// Two chunks of the same file each found their first match
+	ts.storage.AddResult(models.Result{ID: "r2", JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 40, Content: "foo again", Parts: []string{"foo"}},
+	}})
+	ts.storage.AddResult(models.Result{ID: "r1", JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 2, Content: "foo, foo", Parts: []string{"foo", "foo"}},
+	}})
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export?format=text", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "logs/app.log:2:foo\nlogs/app.log:2:foo\n", w.Body.String())
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, *status.Results, 1)
+	assert.Equal(t, []string{"foo", "foo"}, *(*status.Results)[0].Parts)
+}
+
+func TestGrepTextWriter(t *testing.T) {
+	ctx := func(before, after []string) models.Context {
+		return models.Context{Before: before, After: after}
+	}
+
+	tests := []struct {
+		name         string
+		context      int
+		onlyMatching bool
+		matches      []models.Match
+		want         string
+	}{
+		{
+			name:    "NoContext",
+			matches: []models.Match{{FileName: "a", LineNumber: 1, Content: "m1"}, {FileName: "a", LineNumber: 5, Content: "m5"}},
+			want:    "a:1:m1\na:5:m5\n",
+		},
+		{
+			name:    "SeparatedGroups",
+			context: 1,
+			matches: []models.Match{
+				{FileName: "a", LineNumber: 2, Content: "m2", Context: ctx([]string{"l1"}, []string{"l3"})},
+				{FileName: "a", LineNumber: 6, Content: "m6", Context: ctx([]string{"l5"}, []string{"l7"})},
+			},
+			want: "a-1-l1\na:2:m2\na-3-l3\n--\na-5-l5\na:6:m6\na-7-l7\n",
+		},
+		{
+			name:    "OverlappingContext",
+			context: 2,
+			matches: []models.Match{
+				{FileName: "a", LineNumber: 3, Content: "m3", Context: ctx([]string{"l1", "l2"}, []string{"m4", "l5"})},
+				{FileName: "a", LineNumber: 4, Content: "m4", Context: ctx([]string{"l2", "m3"}, []string{"l5", "l6"})},
+			},
+			want: "a-1-l1\na-2-l2\na:3:m3\na:4:m4\na-5-l5\na-6-l6\n",
+		},
+		{
+			name:    "AcrossFiles",
+			context: 1,
+			matches: []models.Match{
+				{FileName: "a", LineNumber: 1, Content: "m1", Context: ctx(nil, []string{"l2"})},
+				{FileName: "b", LineNumber: 1, Content: "m1", Context: ctx(nil, []string{"l2"})},
+			},
+			want: "a:1:m1\na-2-l2\n--\nb:1:m1\nb-2-l2\n",
+		},
+		{
+			name:         "OnlyMatching",
+			onlyMatching: true,
+			matches: []models.Match{
+				{FileName: "a", LineNumber: 1, Content: "foo bar foo", Parts: []string{"foo", "foo"}},
+				{FileName: "a", LineNumber: 4, Content: "baz foo", Parts: []string{"foo"}},
+			},
+			want: "a:1:foo\na:1:foo\na:4:foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newGrepTextWriter(&buf, tt.context, tt.onlyMatching)
+			for _, m := range tt.matches {
+				require.NoError(t, w.WriteMatch(m))
+			}
+			require.NoError(t, w.Close())
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Defaults for the file listing, as documented in the spec
+const (
+	defaultFileLimit = 100
+	maxFileLimit     = 1000
+)
+
+// maxPathLength bounds the path form field of multipart uploads
+const maxPathLength = 1024
+
+// ListFiles lists log files matching the given filters
+func (h *Handler) ListFiles(c *gin.Context, params ListFilesParams) {
+	page, limit := defaultPage, defaultFileLimit
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if page < 1 || limit < 1 || limit > maxFileLimit {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "page must be >= 1 and limit between 1 and 1000")
+		return
+	}
+
+	var filter service.FileFilter
+	if params.Prefix != nil {
+		filter.Prefix = *params.Prefix
+	}
+	if params.MinSize != nil {
+		filter.MinSize = *params.MinSize
+	}
+	if params.MaxSize != nil {
+		filter.MaxSize = *params.MaxSize
+	}
+	if params.ModifiedAfter != nil {
+		filter.ModifiedAfter = *params.ModifiedAfter
+	}
+	if params.ModifiedBefore != nil {
+		filter.ModifiedBefore = *params.ModifiedBefore
+	}
+
+	files, total, err := h.svc.ListFiles(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	list := FileList{
+		Files:      make([]LogFile, 0, len(files)),
+		Pagination: newPagination(page, limit, total),
+	}
+	for _, file := range files {
+		list.Files = append(list.Files, toLogFile(file))
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// UploadFile streams the file part of a multipart form into log storage
+func (h *Handler) UploadFile(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "expected a multipart/form-data body: "+err.Error())
+		return
+	}
+
+	var filePath string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "malformed multipart body: "+err.Error())
+			return
+		}
+
+		switch part.FormName() {
+		case "path":
+			data, err := io.ReadAll(io.LimitReader(part, maxPathLength+1))
+			if err != nil || len(data) > maxPathLength {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "invalid path field")
+				return
+			}
+			filePath = string(data)
+
+		case "file":
+			if filePath == "" {
+				filePath = part.FileName()
+			}
+
+			file, err := h.svc.UploadFile(c.Request.Context(), filePath, -1, part)
+			if err != nil {
+				abortWithServiceError(c, err)
+				return
+			}
+
+			c.JSON(http.StatusCreated, toLogFile(*file))
+			return
+		}
+
+		part.Close()
+	}
+
+	abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "missing file part")
+}
+
+// PutFile streams the raw request body into log storage
+func (h *Handler) PutFile(c *gin.Context, params PutFileParams) {
+	file, err := h.svc.UploadFile(c.Request.Context(), params.Path, c.Request.ContentLength, c.Request.Body)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toLogFile(*file))
+}
+
+// DeleteFile removes a log file
+func (h *Handler) DeleteFile(c *gin.Context, params DeleteFileParams) {
+	if err := h.svc.DeleteFile(c.Request.Context(), params.Path); err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toLogFile(file models.LogFile) LogFile {
+	return LogFile{
+		Name:      file.Name,
+		Path:      file.Path,
+		Size:      file.Size,
+		UpdatedAt: file.UpdatedAt,
+	}
+}
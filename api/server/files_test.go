@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadFile(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	upload := func(fields [][2]string, fileName, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for _, field := range fields {
+			mw.WriteField(field[0], field[1])
+		}
+		if fileName != "" {
+			fw, err := mw.CreateFormFile("file", fileName)
+			require.NoError(t, err)
+			fw.Write([]byte(content))
+		}
+		mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/files", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("WithPath", func(t *testing.T) {
+		w := upload([][2]string{{"path", "logs/svc/web.log"}}, "local.log", "line one\nline two\n")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var file LogFile
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+		assert.Equal(t, "logs/svc/web.log", file.Path)
+		assert.Equal(t, "web.log", file.Name)
+		assert.Equal(t, int64(18), file.Size)
+
+		content, ok := storage.Content("logs/svc/web.log")
+		require.True(t, ok)
+		assert.Equal(t, "line one\nline two\n", content)
+	})
+
+	t.Run("FileNameFallback", func(t *testing.T) {
+		w := upload(nil, "local.log", "x\n")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		_, ok := storage.Content("local.log")
+		assert.True(t, ok)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		w := upload([][2]string{{"path", "logs/a.log"}}, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		w := upload([][2]string{{"path", "../etc/passwd"}}, "x.log", "x")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotMultipart", func(t *testing.T) {
+		w := do(router, http.MethodPost, "/api/v1/files", map[string]string{"path": "x"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPutFile(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/files?path=logs/raw.log", strings.NewReader("raw body\n"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	content, ok := storage.Content("logs/raw.log")
+	require.True(t, ok)
+	assert.Equal(t, "raw body\n", content)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/files", strings.NewReader("x"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "path is required")
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/files?path=/abs.log", strings.NewReader("x"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListFiles(t *testing.T) {
+	router, storage := newTestRouter(t)
+	storage.AddFile("logs/big.log", strings.Repeat("x", 1000))
+	storage.AddFile("logs/small.log", "x")
+	storage.AddFile("other/small.log", "x")
+
+	list := func(query string) FileList {
+		w := do(router, http.MethodGet, "/api/v1/files"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var list FileList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		return list
+	}
+
+	paths := func(list FileList) []string {
+		var out []string
+		for _, f := range list.Files {
+			out = append(out, f.Path)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"logs/app.log", "logs/big.log", "logs/small.log", "other/small.log"}, paths(list("")))
+	assert.Equal(t, []string{"logs/app.log", "logs/big.log", "logs/small.log"}, paths(list("?prefix=logs/")))
+	assert.Equal(t, []string{"logs/big.log"}, paths(list("?min_size=100")))
+	assert.Equal(t, []string{"logs/small.log", "other/small.log"}, paths(list("?max_size=1")))
+	assert.Empty(t, paths(list("?modified_before=2000-01-01T00:00:00Z")))
+	assert.Len(t, paths(list("?modified_after=2000-01-01T00:00:00Z")), 4)
+
+	page := list("?limit=3&page=2")
+	assert.Equal(t, []string{"other/small.log"}, paths(page))
+	assert.Equal(t, 2, *page.Pagination.TotalPages)
+	assert.Equal(t, 4, *page.Pagination.TotalResults)
+
+	w := do(router, http.MethodGet, "/api/v1/files?min_size=10&max_size=5", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(router, http.MethodGet, "/api/v1/files?limit=1001", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteFile(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	w := do(router, http.MethodDelete, "/api/v1/files?path=logs/app.log", nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	_, ok := storage.Content("logs/app.log")
+	assert.False(t, ok)
+
+	w = do(router, http.MethodDelete, "/api/v1/files?path=logs/app.log", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeFileNotFound, apiErr.Code)
+}
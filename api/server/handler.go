@@ -0,0 +1,454 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Defaults applied to optional request parameters, as documented in the spec
+const (
+	defaultPage      = 1
+	defaultListLimit = 20
+)
+
+// Handler implements ServerInterface on top of the job service
+type Handler struct {
+	svc *service.Service
+}
+
+var _ ServerInterface = (*Handler)(nil)
+
+// NewHandler returns a new Handler
+func NewHandler(svc *service.Service, log *logger.Logger) *Handler {
+	InitLogger(log)
+
+	return &Handler{svc: svc}
+}
+
+// CreateGrepJob validates the request and submits a new job. Repeated
+// submissions with the same Idempotency-Key, or client supplied request ID,
+// return the job created the first time.
+func (h *Handler) CreateGrepJob(c *gin.Context, params CreateGrepJobParams) {
+	var req GrepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	key := c.GetHeader(RequestIDHeader)
+	if params.IdempotencyKey != nil {
+		key = *params.IdempotencyKey
+	}
+	job, replayed, err := h.svc.CreateJobIdempotent(c.Request.Context(), key, toJob(req, requestID(c)))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	if replayed {
+		c.Header(IdempotentReplayedHeader, "true")
+	}
+
+	c.JSON(http.StatusAccepted, JobResponse{
+		JobId:     job.ID,
+		RequestId: job.RequestID,
+		Status:    toJobState(job.Status),
+		CreatedAt: &job.CreatedAt,
+	})
+}
+
+// EstimateGrepJob reports the size and expected duration of a job without
+// creating it
+func (h *Handler) EstimateGrepJob(c *gin.Context) {
+	var req GrepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	estimate, err := h.svc.EstimateJob(c.Request.Context(), toJob(req, requestID(c)))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	resp := JobEstimate{
+		Files:      estimate.Files,
+		TotalBytes: estimate.Bytes,
+		Chunks:     estimate.Chunks,
+		SampleJobs: estimate.Samples,
+	}
+	if estimate.Samples > 0 {
+		seconds := estimate.Duration.Seconds()
+		resp.ThroughputBytesPerSecond = &estimate.Throughput
+		resp.EstimatedSeconds = &seconds
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListGrepJobs returns a page of jobs matching the filters, newest first
+func (h *Handler) ListGrepJobs(c *gin.Context, params ListGrepJobsParams) {
+	query := redis.JobQuery{Limit: defaultListLimit}
+	if params.Status != nil {
+		status, ok := fromJobState(*params.Status)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("unknown status %q", *params.Status))
+			return
+		}
+		query.Status = status
+	}
+	if params.CreatedAfter != nil {
+		query.CreatedAfter = *params.CreatedAfter
+	}
+	if params.CreatedBefore != nil {
+		query.CreatedBefore = *params.CreatedBefore
+	}
+	if params.Pattern != nil {
+		query.PatternContains = *params.Pattern
+	}
+	if params.RequestId != nil {
+		query.RequestID = *params.RequestId
+	}
+	if params.Cursor != nil {
+		query.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		query.Limit = *params.Limit
+	}
+
+	page, err := h.svc.ListJobs(c.Request.Context(), query)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	list := JobList{Jobs: make([]JobStatus, 0, len(page.Jobs))}
+	for _, job := range page.Jobs {
+		list.Jobs = append(list.Jobs, toJobStatus(job, nil))
+	}
+	if page.NextCursor != "" {
+		list.NextCursor = &page.NextCursor
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetGrepJob returns the job status with a page of its results
+func (h *Handler) GetGrepJob(c *gin.Context, jobId string, params GetGrepJobParams) {
+	page, limit := defaultPage, service.DefaultResultsLimit
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	res, err := h.svc.GetJobResults(c.Request.Context(), jobId, page, limit)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	status := toJobStatus(res.Job, res.Stats)
+	status.QueuePosition = res.QueuePosition
+	if res.Job.Mode.Counts() {
+		status.FileCounts = toFileCounts(res.Counts)
+	} else {
+		status.Results = toGrepMatches(res.Matches)
+	}
+	status.Pagination = newPagination(page, limit, res.Total)
+
+	c.JSON(http.StatusOK, status)
+}
+
+// CancelGrepJob cancels a job that has not finished yet
+func (h *Handler) CancelGrepJob(c *gin.Context, jobId string) {
+	ctx := c.Request.Context()
+
+	job, err := h.svc.CancelJob(ctx, jobId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	_, stats, err := h.svc.GetJob(ctx, jobId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toJobStatus(job, stats))
+}
+
+// DeleteGrepJob cancels a job if needed and removes all of its data
+func (h *Handler) DeleteGrepJob(c *gin.Context, jobId string) {
+	if err := h.svc.DeleteJob(c.Request.Context(), jobId); err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func newPagination(page, limit, total int) *Pagination {
+	totalPages := (total + limit - 1) / limit
+	return &Pagination{
+		CurrentPage:  &page,
+		PerPage:      &limit,
+		TotalPages:   &totalPages,
+		TotalResults: &total,
+	}
+}
+
+// toJob maps a request onto a job, applying the defaults from the spec
+func toJob(req GrepRequest, requestID string) models.Job {
+	job := models.Job{
+		RequestID: requestID,
+		Pattern:   req.Pattern,
+		Files:     req.Files,
+		Regex:     true,
+	}
+
+	if req.CaseSensitive != nil {
+		job.CaseSensitive = *req.CaseSensitive
+	}
+	if req.Patterns != nil {
+		job.Patterns = *req.Patterns
+		job.Regex = false
+	}
+	if req.Regex != nil {
+		job.Regex = *req.Regex
+	}
+	if req.ContextLines != nil {
+		job.ContextLines = *req.ContextLines
+	}
+	if req.InvertMatch != nil {
+		job.InvertMatch = *req.InvertMatch
+	}
+	if req.WordRegexp != nil {
+		job.WordRegexp = *req.WordRegexp
+	}
+	if req.LineRegexp != nil {
+		job.LineRegexp = *req.LineRegexp
+	}
+	if req.OnlyMatching != nil {
+		job.OnlyMatching = *req.OnlyMatching
+	}
+	if req.MaxCount != nil {
+		job.MaxCount = *req.MaxCount
+	}
+	if req.Mode != nil {
+		job.Mode = models.SearchMode(*req.Mode)
+	}
+	if req.Priority != nil {
+		job.Priority = models.JobPriority(*req.Priority)
+	}
+	if req.RetentionSeconds != nil {
+		job.Retention = time.Duration(*req.RetentionSeconds) * time.Second
+	}
+	if req.Query != nil {
+		job.Query = toQuery(*req.Query)
+	}
+	if req.Since != nil || req.Until != nil || req.TimestampFormats != nil {
+		job.TimeRange = &models.TimeRange{Since: req.Since, Until: req.Until}
+		if req.TimestampFormats != nil {
+			for _, format := range *req.TimestampFormats {
+				job.TimeRange.Formats = append(job.TimeRange.Formats, models.TimestampFormat(format))
+			}
+		}
+	}
+	if req.Webhook != nil {
+		job.Webhook = &models.Webhook{URL: req.Webhook.Url, Secret: req.Webhook.Secret}
+	}
+
+	return job
+}
+
+// toQuery maps a query onto the model, applying the defaults from the spec
+func toQuery(q PatternQuery) *models.Query {
+	query := &models.Query{Regex: true}
+	if q.Op != nil {
+		query.Op = models.QueryOp(*q.Op)
+	}
+	if q.Id != nil {
+		query.ID = *q.Id
+	}
+	if q.Pattern != nil {
+		query.Pattern = *q.Pattern
+	}
+	if q.CaseSensitive != nil {
+		query.CaseSensitive = *q.CaseSensitive
+	}
+	if q.Regex != nil {
+		query.Regex = *q.Regex
+	}
+	if q.Children != nil {
+		query.Children = make([]models.Query, 0, len(*q.Children))
+		for _, child := range *q.Children {
+			query.Children = append(query.Children, *toQuery(child))
+		}
+	}
+
+	return query
+}
+
+// fromQuery maps a query onto the API schema
+func fromQuery(q *models.Query) *PatternQuery {
+	query := &PatternQuery{}
+	if q.IsLeaf() {
+		caseSensitive, regex := q.CaseSensitive, q.Regex
+		query.Pattern = &q.Pattern
+		query.CaseSensitive = &caseSensitive
+		query.Regex = &regex
+		if q.ID != "" {
+			query.Id = &q.ID
+		}
+		return query
+	}
+
+	op := PatternQueryOp(q.Op)
+	children := make([]PatternQuery, 0, len(q.Children))
+	for i := range q.Children {
+		children = append(children, *fromQuery(&q.Children[i]))
+	}
+	query.Op = &op
+	query.Children = &children
+
+	return query
+}
+
+func toJobState(status models.JobStatus) JobState {
+	switch status {
+	case models.JobStatusProcessing:
+		return INPROGRESS
+	case models.JobStatusCompleted:
+		return COMPLETED
+	case models.JobStatusFailed:
+		return FAILED
+	case models.JobStatusCancelled:
+		return CANCELLED
+	default:
+		return PENDING
+	}
+}
+
+func fromJobState(state JobState) (models.JobStatus, bool) {
+	switch state {
+	case PENDING:
+		return models.JobStatusPending, true
+	case INPROGRESS:
+		return models.JobStatusProcessing, true
+	case COMPLETED:
+		return models.JobStatusCompleted, true
+	case FAILED:
+		return models.JobStatusFailed, true
+	case CANCELLED:
+		return models.JobStatusCancelled, true
+	default:
+		return "", false
+	}
+}
+
+// toJobStatus maps a job onto the API schema, stats are left out if nil
+func toJobStatus(job *models.Job, stats *models.JobStats) JobStatus {
+	progress := int(job.Progress)
+
+	status := JobStatus{
+		JobId:       job.ID,
+		RequestId:   job.RequestID,
+		Status:      toJobState(job.Status),
+		Progress:    &progress,
+		CreatedAt:   &job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+
+	if stats != nil {
+		bytesProcessed := int(stats.BytesProcessed)
+		status.Stats = &struct {
+			BytesProcessed *int `json:"bytes_processed,omitempty"`
+			ProcessedFiles *int `json:"processed_files,omitempty"`
+			TotalFiles     *int `json:"total_files,omitempty"`
+			TotalMatches   *int `json:"total_matches,omitempty"`
+		}{
+			BytesProcessed: &bytesProcessed,
+			ProcessedFiles: &stats.ProcessedFiles,
+			TotalFiles:     &stats.TotalFiles,
+			TotalMatches:   &stats.TotalMatches,
+		}
+	}
+
+	if job.Error != "" {
+		status.Error = &job.Error
+	}
+
+	return status
+}
+
+func toFileCounts(counts []service.FileCount) *[]FileCount {
+	out := make([]FileCount, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, FileCount{File: c.File, Count: c.Count})
+	}
+	return &out
+}
+
+func toGrepMatches(matches []models.Match) *[]GrepMatch {
+	out := make([]GrepMatch, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, toGrepMatch(m))
+	}
+	return &out
+}
+
+func toGrepMatch(m models.Match) GrepMatch {
+	match := GrepMatch{
+		File:       m.FileName,
+		LineNumber: m.LineNumber,
+		Content:    m.Content,
+	}
+	if len(m.Parts) > 0 {
+		parts := m.Parts
+		match.Parts = &parts
+	}
+	if len(m.MatchedPatterns) > 0 {
+		patterns := m.MatchedPatterns
+		match.MatchedPatterns = &patterns
+	}
+	if m.Timestamp != nil {
+		timestamp := m.Timestamp.UTC()
+		match.Timestamp = &timestamp
+	}
+
+	if len(m.Context.Before) > 0 || len(m.Context.After) > 0 {
+		before, after := m.Context.Before, m.Context.After
+		match.Context = &struct {
+			After  *[]string `json:"after,omitempty"`
+			Before *[]string `json:"before,omitempty"`
+		}{
+			After:  &after,
+			Before: &before,
+		}
+	}
+
+	return match
+}
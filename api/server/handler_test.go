@@ -0,0 +1,621 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/api/internal/fakes"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+// testServer bundles the router under test with the fakes behind it
+type testServer struct {
+	router  *gin.Engine
+	storage *fakes.Storage
+	store   *redis.Store
+}
+
+func newTestRouter(t *testing.T) (*gin.Engine, *fakes.Storage) {
+	ts := newTestServer(t)
+	return ts.router, ts.storage
+}
+
+func newTestServer(t *testing.T, middlewares ...MiddlewareFunc) *testServer {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	storage := fakes.NewStorage()
+	storage.AddFile("logs/app.log", "app\n")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	validator, err := SpecValidator("/api/v1", ValidatorOptions{Responses: true})
+	require.NoError(t, err)
+	router.Use(RequestID())
+
+	// Wired like the gateway, credentials are checked before validation
+	var handlers []gin.HandlerFunc
+	for _, middleware := range middlewares {
+		handlers = append(handlers, gin.HandlerFunc(middleware))
+	}
+	handlers = append(handlers, validator)
+
+	RegisterHandlersWithOptions(router.Group("", handlers...), NewHandler(service.New(store, storage, &fakes.Bus{}, l), l), GinServerOptions{
+		BaseURL:      "/api/v1",
+		ErrorHandler: ErrorHandler,
+	})
+
+	return &testServer{router: router, storage: storage, store: store}
+}
+
+func do(router *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	return doWithHeaders(router, method, path, body, map[string]string{RequestIDHeader: "req_test"})
+}
+
+func doWithHeaders(router *gin.Engine, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGrepJobLifecycle(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	w := do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/*.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "req_test", created.RequestId)
+	assert.Equal(t, PENDING, created.Status)
+
+	storage.AddResult(models.Result{JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "error one", Context: models.Context{After: []string{"next"}}},
+		{FileName: "logs/app.log", LineNumber: 5, Content: "error two"},
+		{FileName: "logs/app.log", LineNumber: 9, Content: "error three"},
+	}})
+
+	w = do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"?page=2&limit=2", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, created.JobId, status.JobId)
+	assert.Equal(t, 1, *status.Stats.TotalFiles)
+	require.Len(t, *status.Results, 1)
+	assert.Equal(t, "error three", (*status.Results)[0].Content)
+	assert.Equal(t, 2, *status.Pagination.TotalPages)
+	assert.Equal(t, 3, *status.Pagination.TotalResults)
+
+	w = do(router, http.MethodPost, "/api/v1/grep/"+created.JobId+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, CANCELLED, status.Status)
+
+	w = do(router, http.MethodPost, "/api/v1/grep/"+created.JobId+"/cancel", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestGrepJobErrors(t *testing.T) {
+	router, _ := newTestRouter(t)
+	negative, yes, two := -1, true, 2
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+		code   string
+	}{
+		{"EmptyPattern", http.MethodPost, "/api/v1/grep", GrepRequest{Files: []string{"logs/app.log"}}, http.StatusBadRequest, CodeInvalidRequest},
+		{"InvalidPattern", http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "(x", Files: []string{"logs/app.log"}}, http.StatusBadRequest, CodeInvalidPattern},
+		{"UnknownFile", http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "x", Files: []string{"nope.log"}}, http.StatusNotFound, CodeFileNotFound},
+		{"NegativeMaxCount", http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "x", Files: []string{"logs/app.log"}, MaxCount: &negative}, http.StatusBadRequest, CodeInvalidRequest},
+		{"OnlyMatchingContext", http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "x", Files: []string{"logs/app.log"}, OnlyMatching: &yes, ContextLines: &two}, http.StatusBadRequest, CodeInvalidRequest},
+		{"UnknownJob", http.MethodGet, "/api/v1/grep/missing", nil, http.StatusNotFound, CodeJobNotFound},
+		{"LimitTooLarge", http.MethodGet, "/api/v1/grep/missing?limit=101", nil, http.StatusBadRequest, CodeInvalidRequest},
+		{"BadPage", http.MethodGet, "/api/v1/grep/missing?page=abc", nil, http.StatusBadRequest, CodeInvalidRequest},
+		{"CancelUnknownJob", http.MethodPost, "/api/v1/grep/missing/cancel", nil, http.StatusNotFound, CodeJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(router, tt.method, tt.path, tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var apiErr Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.code, apiErr.Code)
+			assert.Equal(t, "req_test", apiErr.RequestId)
+		})
+	}
+}
+
+func TestCreateGrepJobInvalidPattern(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "level=(error|warn", Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeInvalidPattern, apiErr.Code)
+	require.NotNil(t, apiErr.Offset)
+	assert.Equal(t, 6, *apiErr.Offset)
+	assert.Contains(t, apiErr.Message, "missing )")
+
+	// The same text is a valid fixed string
+	regex := false
+	w = do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "level=(error|warn", Files: []string{"logs/app.log"}, Regex: &regex})
+	assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+}
+
+func TestCreateGrepJobQuery(t *testing.T) {
+	ts := newTestServer(t)
+
+	and, not := AND, NOT
+	timeout, service, healthcheck, label := "timeout", "payment-service", "healthcheck", "service"
+	query := &PatternQuery{Op: &and, Children: &[]PatternQuery{
+		{Pattern: &timeout},
+		{Id: &label, Pattern: &service},
+		{Op: &not, Children: &[]PatternQuery{{Pattern: &healthcheck}}},
+	}}
+
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Query: query, Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(context.Background(), created.JobId)
+	require.NoError(t, err)
+	require.NotNil(t, job.Query)
+	assert.Equal(t, models.QueryOpAnd, job.Query.Op)
+	assert.Equal(t, models.Query{ID: "service", Pattern: "payment-service", Regex: true}, job.Query.Children[1])
+
+	ts.storage.AddResult(models.Result{JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3, Content: "payment-service timeout", MatchedPatterns: []string{"timeout", "service"}},
+	}})
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.NotNil(t, status.Results)
+	require.Len(t, *status.Results, 1)
+	assert.Equal(t, []string{"timeout", "service"}, *(*status.Results)[0].MatchedPatterns)
+}
+
+func TestCreateGrepJobFixedStrings(t *testing.T) {
+	ts := newTestServer(t)
+
+	literals := []string{"198.51.100.7", "evil.example.com"}
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Patterns: &literals, Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(context.Background(), created.JobId)
+	require.NoError(t, err)
+	assert.Equal(t, literals, job.Patterns)
+	assert.False(t, job.Regex)
+
+	t.Run("Invalid", func(t *testing.T) {
+		yes := true
+		large := make([]string, 100)
+		for i := range large {
+			large[i] = strings.Repeat("a", 4096)
+		}
+		tests := []struct {
+			name  string
+			req   GrepRequest
+			field string
+		}{
+			{"Regex", GrepRequest{Patterns: &literals, Regex: &yes, Files: []string{"logs/app.log"}}, ""},
+			{"WithPattern", GrepRequest{Pattern: "x", Patterns: &literals, Files: []string{"logs/app.log"}}, ""},
+			{"TooLarge", GrepRequest{Patterns: &large, Files: []string{"logs/app.log"}}, "body.patterns[64]"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", tt.req, nil)
+				require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+				var apiErr Error
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				if tt.field != "" {
+					require.NotNil(t, apiErr.Details, w.Body.String())
+					assert.Equal(t, tt.field, (*apiErr.Details)[0].Field)
+				}
+			})
+		}
+	})
+}
+
+func TestCreateGrepJobInvalidQuery(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	not, bad := NOT, "level=(error"
+	query := &PatternQuery{Op: &not, Children: &[]PatternQuery{{Pattern: &bad}}}
+
+	w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{Query: query, Files: []string{"logs/app.log"}}, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeInvalidPattern, apiErr.Code)
+	require.NotNil(t, apiErr.Details)
+	assert.Equal(t, "body.query.children[0]", (*apiErr.Details)[0].Field)
+
+	w = doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "x", Query: query, Files: []string{"logs/app.log"}}, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeInvalidRequest, apiErr.Code)
+	assert.Contains(t, apiErr.Message, "mutually exclusive")
+}
+
+func TestCreateGrepJobTimeRange(t *testing.T) {
+	ts := newTestServer(t)
+
+	since := time.Date(2024, 1, 2, 2, 10, 0, 0, time.UTC)
+	until := since.Add(30 * time.Minute)
+	formats := []TimestampFormat{Nginx, Rfc3339}
+	w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{
+		Pattern:          "error",
+		Files:            []string{"logs/app.log"},
+		Since:            &since,
+		Until:            &until,
+		TimestampFormats: &formats,
+	}, nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(context.Background(), created.JobId)
+	require.NoError(t, err)
+	require.NotNil(t, job.TimeRange)
+	assert.True(t, since.Equal(*job.TimeRange.Since))
+	assert.True(t, until.Equal(*job.TimeRange.Until))
+	assert.Equal(t, []models.TimestampFormat{models.TimestampNginx, models.TimestampRFC3339}, job.TimeRange.Formats)
+
+	logged := since.Add(5 * time.Minute)
+	ts.storage.AddResult(models.Result{JobID: created.JobId, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "error", Timestamp: &logged},
+	}})
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, *status.Results, 1)
+	assert.True(t, logged.Equal(*(*status.Results)[0].Timestamp))
+
+	t.Run("UntilBeforeSince", func(t *testing.T) {
+		w := doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}, Since: &until, Until: &since}, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "since must be before until")
+	})
+}
+
+func TestGetGrepJobFileCounts(t *testing.T) {
+	ts := newTestServer(t)
+	ts.storage.AddFile("logs/error.log", "error\n")
+
+	mode := FilesWithMatches
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/*.log"}, Mode: &mode})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(context.Background(), created.JobId)
+	require.NoError(t, err)
+	assert.Equal(t, models.SearchModeFilesWithMatches, job.Mode)
+
+	ts.storage.AddResult(models.Result{ID: "r1", JobID: created.JobId, Counts: map[string]int{"logs/error.log": 1}})
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Nil(t, status.Results)
+	require.NotNil(t, status.FileCounts)
+	assert.Equal(t, []FileCount{{File: "logs/error.log", Count: 1}}, *status.FileCounts)
+	assert.Equal(t, 1, *status.Pagination.TotalResults)
+
+	_, err = ts.store.UpdateJob(context.Background(), created.JobId, func(job *models.Job) error {
+		job.Status = models.JobStatusCompleted
+		return nil
+	})
+	require.NoError(t, err)
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/export", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Context lines need lines to surround
+	mode = Count
+	contextLines := 2
+	w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/*.log"}, Mode: &mode, ContextLines: &contextLines}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestGetGrepJobQueuePosition(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	priority := Batch
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/app.log"}, Priority: &priority})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(ctx, created.JobId)
+	require.NoError(t, err)
+	assert.Equal(t, models.PriorityBatch, job.Priority)
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Nil(t, status.QueuePosition)
+
+	ahead := &models.Job{ID: "grep_ahead", Priority: models.PriorityInteractive, CreatedAt: job.CreatedAt}
+	require.NoError(t, ts.store.EnqueueJob(ctx, ahead))
+	require.NoError(t, ts.store.EnqueueJob(ctx, job))
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.NotNil(t, status.QueuePosition)
+	assert.Equal(t, 1, *status.QueuePosition)
+
+	priority = JobPriority("urgent")
+	w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/app.log"}, Priority: &priority}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestGrepJobRetention(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	retention := 3600
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/app.log"}, RetentionSeconds: &retention})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	job, err := ts.store.GetJob(ctx, created.JobId)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, job.Retention)
+
+	// The manager sets the expiry once the job finished
+	expires := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	_, err = ts.store.UpdateJob(ctx, created.JobId, func(job *models.Job) error {
+		job.Status = models.JobStatusCompleted
+		job.ExpiresAt = &expires
+		return nil
+	})
+	require.NoError(t, err)
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.NotNil(t, status.ExpiresAt)
+	assert.True(t, expires.Equal(*status.ExpiresAt))
+
+	retention = 10
+	w = doWithHeaders(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "timeout", Files: []string{"logs/app.log"}, RetentionSeconds: &retention}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestEstimateGrepJob(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := do(router, http.MethodPost, "/api/v1/grep/estimate", GrepRequest{Pattern: "error", Files: []string{"logs/*.log"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var estimate JobEstimate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &estimate))
+	assert.Equal(t, 1, estimate.Files)
+	assert.EqualValues(t, len("app\n"), estimate.TotalBytes)
+	assert.Equal(t, 1, estimate.Chunks)
+	assert.Zero(t, estimate.SampleJobs)
+	assert.Nil(t, estimate.EstimatedSeconds)
+
+	w = do(router, http.MethodGet, "/api/v1/grep", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list JobList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Empty(t, list.Jobs)
+
+	w = do(router, http.MethodPost, "/api/v1/grep/estimate", GrepRequest{Pattern: "error", Files: []string{"nope.log"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteGrepJob(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	w := do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	storage.AddResult(models.Result{JobID: created.JobId, Matches: []models.Match{{FileName: "logs/app.log", LineNumber: 1}}})
+
+	w = do(router, http.MethodDelete, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Empty(t, w.Body.String())
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = do(router, method, "/api/v1/grep/"+created.JobId, nil)
+		require.Equal(t, http.StatusGone, w.Code, w.Body.String())
+
+		var apiErr Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, CodeJobDeleted, apiErr.Code)
+	}
+
+	w = do(router, http.MethodDelete, "/api/v1/grep/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestExpiredGrepJob(t *testing.T) {
+	ts := newTestServer(t)
+
+	w := do(ts.router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	ctx := context.Background()
+	job, err := ts.store.GetJob(ctx, created.JobId)
+	require.NoError(t, err)
+	require.NoError(t, ts.store.PurgeJob(ctx, job, redis.TombstoneExpired, time.Hour))
+
+	w = do(ts.router, http.MethodGet, "/api/v1/grep/"+created.JobId, nil)
+	require.Equal(t, http.StatusGone, w.Code, w.Body.String())
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeJobExpired, apiErr.Code)
+}
+
+func TestListGrepJobs(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	var ids []string
+	for _, pattern := range []string{"timeout", "error.*", "Connection refused"} {
+		w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: pattern, Files: []string{"logs/app.log"}}, nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var created JobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		ids = append(ids, created.JobId)
+	}
+
+	w := do(router, http.MethodPost, "/api/v1/grep/"+ids[1]+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	list := func(query string) JobList {
+		w := do(router, http.MethodGet, "/api/v1/grep"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var list JobList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		return list
+	}
+
+	jobIDs := func(list JobList) []string {
+		var out []string
+		for _, job := range list.Jobs {
+			out = append(out, job.JobId)
+		}
+		return out
+	}
+
+	all := list("")
+	assert.ElementsMatch(t, ids, jobIDs(all))
+	assert.Nil(t, all.NextCursor)
+	assert.Nil(t, all.Jobs[0].Stats)
+
+	first := list("?limit=2")
+	require.Len(t, first.Jobs, 2)
+	require.NotNil(t, first.NextCursor)
+	second := list("?limit=2&cursor=" + *first.NextCursor)
+	assert.Len(t, second.Jobs, 1)
+	assert.ElementsMatch(t, ids, append(jobIDs(first), jobIDs(second)...))
+
+	assert.Equal(t, []string{ids[1]}, jobIDs(list("?status=CANCELLED")))
+	assert.Equal(t, []string{ids[2]}, jobIDs(list("?pattern=connection")))
+	assert.Empty(t, list("?created_before=2000-01-01T00:00:00Z").Jobs)
+
+	for _, query := range []string{"?cursor=bogus", "?limit=101", "?status=DONE", "?created_after=2030-01-01T00:00:00Z&created_before=2020-01-01T00:00:00Z"} {
+		w := do(router, http.MethodGet, "/api/v1/grep"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestCreateGrepJobIdempotency(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	submit := func(headers map[string]string, pattern string) (*httptest.ResponseRecorder, JobResponse) {
+		w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: pattern, Files: []string{"logs/app.log"}}, headers)
+
+		var resp JobResponse
+		if w.Code == http.StatusAccepted {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+
+	w, first := submit(map[string]string{"Idempotency-Key": "ci-run-1"}, "error")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+
+	w, again := submit(map[string]string{"Idempotency-Key": "ci-run-1", RequestIDHeader: "req_retry"}, "error")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, first, again)
+
+	w, _ = submit(map[string]string{"Idempotency-Key": "ci-run-1"}, "warning")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeIdempotency, apiErr.Code)
+
+	// Without an Idempotency-Key the client supplied request ID is used
+	w, byRequest := submit(map[string]string{RequestIDHeader: "req_ci"}, "error")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.NotEqual(t, first.JobId, byRequest.JobId)
+	_, replay := submit(map[string]string{RequestIDHeader: "req_ci"}, "error")
+	assert.Equal(t, byRequest.JobId, replay.JobId)
+
+	// Requests without any key are never deduplicated
+	_, a := submit(nil, "error")
+	_, b := submit(nil, "error")
+	assert.NotEqual(t, a.JobId, b.JobId)
+
+	// A failed submission releases its key
+	w, _ = submit(map[string]string{"Idempotency-Key": "ci-run-2"}, "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = submit(map[string]string{"Idempotency-Key": "ci-run-2"}, "error")
+	assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+)
+
+// RequestIDHeader carries the request ID in and out of the API
+const RequestIDHeader = "X-Request-ID"
+
+// IdempotentReplayedHeader marks a response returning a previously created job
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const requestIDKey = "request_id"
+
+// Error codes returned in the Error schema
+const (
+	CodeInvalidRequest  = "INVALID_REQUEST"
+	CodeInvalidPattern  = "INVALID_PATTERN"
+	CodeUnauthorized    = "UNAUTHORIZED"
+	CodeFileNotFound    = "FILE_NOT_FOUND"
+	CodeJobNotFound     = "JOB_NOT_FOUND"
+	CodeJobDeleted      = "JOB_DELETED"
+	CodeJobExpired      = "JOB_EXPIRED"
+	CodeJobFinished     = "JOB_FINISHED"
+	CodeJobNotComplete  = "JOB_NOT_COMPLETED"
+	CodeSearchNotFound  = "SEARCH_NOT_FOUND"
+	CodeWebhookNotFound = "WEBHOOK_NOT_FOUND"
+	CodeIdempotency     = "IDEMPOTENCY_CONFLICT"
+	CodeInProgress      = "REQUEST_IN_PROGRESS"
+	CodeInternal        = "INTERNAL_ERROR"
+)
+
+// RequestID reuses the caller's X-Request-ID or assigns a new one, and echoes
+// it back on the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = ids.New("req")
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// requestID returns the ID assigned by the RequestID middleware
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// ErrorHandler renders parameter binding errors of the generated wrapper,
+// pass it as GinServerOptions.ErrorHandler
+func ErrorHandler(c *gin.Context, err error, statusCode int) {
+	abortWithError(c, statusCode, CodeInvalidRequest, err.Error())
+}
+
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, Error{
+		Code:      code,
+		Message:   message,
+		RequestId: requestID(c),
+	})
+}
+
+// abortWithServiceError maps errors returned by the service onto HTTP responses
+func abortWithServiceError(c *gin.Context, err error) {
+	var limitErr *service.LimitError
+	var patternErr *pattern.Error
+	switch {
+	case errors.As(err, &patternErr):
+		offset := patternErr.Offset
+		resp := Error{
+			Code:      CodeInvalidPattern,
+			Message:   patternErr.Reason,
+			Offset:    &offset,
+			RequestId: requestID(c),
+		}
+		if patternErr.Field != "" {
+			resp.Details = &[]FieldError{{Field: "body." + patternErr.Field, Message: patternErr.Reason}}
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, resp)
+	case errors.As(err, &limitErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+		abortWithError(c, http.StatusTooManyRequests, limitErr.Code, err.Error())
+	case errors.Is(err, service.ErrInvalidRequest):
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+	case errors.Is(err, service.ErrFileNotFound):
+		abortWithError(c, http.StatusNotFound, CodeFileNotFound, err.Error())
+	case errors.Is(err, service.ErrJobDeleted):
+		abortWithError(c, http.StatusGone, CodeJobDeleted, err.Error())
+	case errors.Is(err, service.ErrJobExpired):
+		abortWithError(c, http.StatusGone, CodeJobExpired, err.Error())
+	case errors.Is(err, service.ErrJobNotFound):
+		abortWithError(c, http.StatusNotFound, CodeJobNotFound, err.Error())
+	case errors.Is(err, service.ErrSearchNotFound):
+		abortWithError(c, http.StatusNotFound, CodeSearchNotFound, err.Error())
+	case errors.Is(err, service.ErrWebhookNotFound):
+		abortWithError(c, http.StatusNotFound, CodeWebhookNotFound, err.Error())
+	case errors.Is(err, service.ErrJobFinished):
+		abortWithError(c, http.StatusConflict, CodeJobFinished, err.Error())
+	case errors.Is(err, service.ErrJobIncomplete):
+		abortWithError(c, http.StatusConflict, CodeJobNotComplete, err.Error())
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		abortWithError(c, http.StatusConflict, CodeIdempotency, err.Error())
+	case errors.Is(err, service.ErrRequestInProgress):
+		abortWithError(c, http.StatusConflict, CodeInProgress, err.Error())
+	default:
+		log.Error("request failed", "request_id", requestID(c), "tenant", c.GetString(tenantKey), "path", c.FullPath(), "err", err)
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, "internal server error")
+	}
+}
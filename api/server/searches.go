@@ -0,0 +1,213 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// CreateSearch saves a grep request as a named, optionally scheduled search
+func (h *Handler) CreateSearch(c *gin.Context) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	search, err := h.svc.CreateSearch(c.Request.Context(), toSavedSearch(req))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toSavedSearchResponse(search))
+}
+
+// ListSearches returns all saved searches, newest first
+func (h *Handler) ListSearches(c *gin.Context) {
+	searches, err := h.svc.ListSearches(c.Request.Context())
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	list := SavedSearchList{Searches: make([]SavedSearch, 0, len(searches))}
+	for _, search := range searches {
+		list.Searches = append(list.Searches, toSavedSearchResponse(search))
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetSearch returns a saved search
+func (h *Handler) GetSearch(c *gin.Context, searchId string) {
+	search, err := h.svc.GetSearch(c.Request.Context(), searchId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSavedSearchResponse(search))
+}
+
+// UpdateSearch replaces a saved search
+func (h *Handler) UpdateSearch(c *gin.Context, searchId string) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	search, err := h.svc.UpdateSearch(c.Request.Context(), searchId, toSavedSearch(req))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSavedSearchResponse(search))
+}
+
+// DeleteSearch removes a saved search and its run history
+func (h *Handler) DeleteSearch(c *gin.Context, searchId string) {
+	if err := h.svc.DeleteSearch(c.Request.Context(), searchId); err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunSearch creates a job from a saved search right away
+func (h *Handler) RunSearch(c *gin.Context, searchId string) {
+	job, err := h.svc.RunSearch(c.Request.Context(), searchId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, JobResponse{
+		JobId:     job.ID,
+		RequestId: job.RequestID,
+		Status:    toJobState(job.Status),
+		CreatedAt: &job.CreatedAt,
+	})
+}
+
+// ListSearchRuns returns past runs of a saved search, newest first
+func (h *Handler) ListSearchRuns(c *gin.Context, searchId string, params ListSearchRunsParams) {
+	limit := service.DefaultSearchRunsLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	runs, err := h.svc.SearchRuns(c.Request.Context(), searchId, limit)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	list := SearchRunList{SearchId: searchId, Runs: make([]SearchRun, 0, len(runs))}
+	for _, run := range runs {
+		list.Runs = append(list.Runs, SearchRun{
+			JobId:        run.JobID,
+			Status:       toJobState(run.Status),
+			Scheduled:    run.Scheduled,
+			CreatedAt:    run.CreatedAt,
+			CompletedAt:  run.CompletedAt,
+			TotalMatches: run.TotalMatches,
+		})
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// toSavedSearch maps a request onto a saved search, applying the defaults of
+// GrepRequest from the spec
+func toSavedSearch(req SavedSearchRequest) models.SavedSearch {
+	job := toJob(req.Request, "")
+
+	search := models.SavedSearch{
+		Name:          req.Name,
+		Pattern:       job.Pattern,
+		Patterns:      job.Patterns,
+		Query:         job.Query,
+		TimeRange:     job.TimeRange,
+		Mode:          job.Mode,
+		Priority:      job.Priority,
+		Retention:     job.Retention,
+		Files:         job.Files,
+		CaseSensitive: job.CaseSensitive,
+		Regex:         job.Regex,
+		ContextLines:  job.ContextLines,
+		InvertMatch:   job.InvertMatch,
+		WordRegexp:    job.WordRegexp,
+		LineRegexp:    job.LineRegexp,
+		OnlyMatching:  job.OnlyMatching,
+		MaxCount:      job.MaxCount,
+	}
+	if req.Schedule != nil {
+		search.Schedule = *req.Schedule
+	}
+
+	return search
+}
+
+func toSavedSearchResponse(search *models.SavedSearch) SavedSearch {
+	resp := SavedSearch{
+		SearchId: search.ID,
+		Name:     search.Name,
+		Request: GrepRequest{
+			Pattern:       search.Pattern,
+			Files:         search.Files,
+			CaseSensitive: &search.CaseSensitive,
+			Regex:         &search.Regex,
+			ContextLines:  &search.ContextLines,
+			InvertMatch:   &search.InvertMatch,
+			WordRegexp:    &search.WordRegexp,
+			LineRegexp:    &search.LineRegexp,
+			OnlyMatching:  &search.OnlyMatching,
+			MaxCount:      &search.MaxCount,
+		},
+		NextRunAt: search.NextRunAt,
+		LastRunAt: search.LastRunAt,
+		CreatedAt: search.CreatedAt,
+		UpdatedAt: search.UpdatedAt,
+	}
+	if search.Patterns != nil {
+		resp.Request.Patterns = &search.Patterns
+	}
+	if search.Query != nil {
+		resp.Request.Query = fromQuery(search.Query)
+	}
+	if search.Mode != "" {
+		mode := SearchMode(search.Mode)
+		resp.Request.Mode = &mode
+	}
+	if search.Priority != "" {
+		priority := JobPriority(search.Priority)
+		resp.Request.Priority = &priority
+	}
+	if search.Retention != 0 {
+		seconds := int(search.Retention / time.Second)
+		resp.Request.RetentionSeconds = &seconds
+	}
+	if r := search.TimeRange; r != nil {
+		resp.Request.Since = r.Since
+		resp.Request.Until = r.Until
+		if len(r.Formats) > 0 {
+			formats := make([]TimestampFormat, 0, len(r.Formats))
+			for _, format := range r.Formats {
+				formats = append(formats, TimestampFormat(format))
+			}
+			resp.Request.TimestampFormats = &formats
+		}
+	}
+	if search.Schedule != "" {
+		resp.Schedule = &search.Schedule
+	}
+
+	return resp
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearches(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	schedule := "@daily"
+	w := do(router, http.MethodPost, "/api/v1/searches", SavedSearchRequest{
+		Name:     "OOM kills",
+		Request:  GrepRequest{Pattern: "OOMKilled", Files: []string{"logs/*.log"}},
+		Schedule: &schedule,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var search SavedSearch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &search))
+	assert.Equal(t, "OOM kills", search.Name)
+	assert.Equal(t, "@daily", *search.Schedule)
+	assert.True(t, *search.Request.Regex)
+	require.NotNil(t, search.NextRunAt)
+
+	w = do(router, http.MethodPut, "/api/v1/searches/"+search.SearchId, SavedSearchRequest{
+		Name:    "OOM kills",
+		Request: GrepRequest{Pattern: "OOMKilled", Files: []string{"logs/app.log"}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated SavedSearch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Nil(t, updated.Schedule)
+	assert.Nil(t, updated.NextRunAt)
+
+	w = do(router, http.MethodGet, "/api/v1/searches", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list SavedSearchList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Searches, 1)
+	assert.Equal(t, []string{"logs/app.log"}, list.Searches[0].Request.Files)
+
+	w = do(router, http.MethodPost, "/api/v1/searches/"+search.SearchId+"/run", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var job JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+
+	w = do(router, http.MethodGet, "/api/v1/searches/"+search.SearchId+"/runs", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var runs SearchRunList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &runs))
+	require.Len(t, runs.Runs, 1)
+	assert.Equal(t, job.JobId, runs.Runs[0].JobId)
+	assert.Equal(t, PENDING, runs.Runs[0].Status)
+
+	w = do(router, http.MethodDelete, "/api/v1/searches/"+search.SearchId, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	// The job outlives its search
+	w = do(router, http.MethodGet, "/api/v1/grep/"+job.JobId, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSavedSearchQuery(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	or, oom, killed := OR, "OOMKilled", "signal: killed"
+	w := do(router, http.MethodPost, "/api/v1/searches", SavedSearchRequest{
+		Name: "OOM kills",
+		Request: GrepRequest{
+			Query: &PatternQuery{Op: &or, Children: &[]PatternQuery{{Pattern: &oom}, {Pattern: &killed}}},
+			Files: []string{"logs/*.log"},
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var search SavedSearch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &search))
+	query := search.Request.Query
+	require.NotNil(t, query)
+	assert.Equal(t, OR, *query.Op)
+	require.Len(t, *query.Children, 2)
+	assert.Equal(t, "signal: killed", *(*query.Children)[1].Pattern)
+	assert.True(t, *(*query.Children)[1].Regex)
+	assert.Empty(t, search.Request.Pattern)
+}
+
+func TestSavedSearchErrors(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	badSchedule := "sometimes"
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+		code   string
+	}{
+		{"MissingName", http.MethodPost, "/api/v1/searches", SavedSearchRequest{Request: GrepRequest{Pattern: "x", Files: []string{"logs/app.log"}}}, http.StatusBadRequest, CodeInvalidRequest},
+		{"InvalidSchedule", http.MethodPost, "/api/v1/searches", SavedSearchRequest{Name: "n", Request: GrepRequest{Pattern: "x", Files: []string{"logs/app.log"}}, Schedule: &badSchedule}, http.StatusBadRequest, CodeInvalidRequest},
+		{"InvalidPattern", http.MethodPost, "/api/v1/searches", SavedSearchRequest{Name: "n", Request: GrepRequest{Pattern: "(x", Files: []string{"logs/app.log"}}}, http.StatusBadRequest, CodeInvalidPattern},
+		{"UnknownFile", http.MethodPost, "/api/v1/searches", SavedSearchRequest{Name: "n", Request: GrepRequest{Pattern: "x", Files: []string{"nope.log"}}}, http.StatusNotFound, CodeFileNotFound},
+		{"UnknownSearch", http.MethodGet, "/api/v1/searches/missing", nil, http.StatusNotFound, CodeSearchNotFound},
+		{"RunUnknownSearch", http.MethodPost, "/api/v1/searches/missing/run", nil, http.StatusNotFound, CodeSearchNotFound},
+		{"RunsOfUnknownSearch", http.MethodGet, "/api/v1/searches/missing/runs", nil, http.StatusNotFound, CodeSearchNotFound},
+		{"RunsLimitTooLarge", http.MethodGet, "/api/v1/searches/missing/runs?limit=101", nil, http.StatusBadRequest, CodeInvalidRequest},
+		{"DeleteUnknownSearch", http.MethodDelete, "/api/v1/searches/missing", nil, http.StatusNotFound, CodeSearchNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(router, tt.method, tt.path, tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var apiErr Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.code, apiErr.Code)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+)
+
+// StreamGrepJob streams matches and progress of a job as Server-Sent Events
+func (h *Handler) StreamGrepJob(c *gin.Context, jobId string, params StreamGrepJobParams) {
+	lastEventID := ""
+	if params.LastEventID != nil {
+		lastEventID = *params.LastEventID
+	}
+
+	// Headers are only committed with the first event, so errors found before
+	// that (unknown job, bad Last-Event-ID) still get a proper status code
+	started := false
+	emit := func(ev service.StreamEvent) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+		}
+
+		var payload any
+		switch ev.Type {
+		case service.EventMatch:
+			payload = toGrepMatch(*ev.Match)
+		default:
+			payload = toJobStatus(ev.Job, ev.Stats)
+		}
+
+		if err := writeEvent(c.Writer, ev.ID, ev.Type, payload); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	err := h.svc.StreamJob(c.Request.Context(), jobId, lastEventID, emit)
+	switch {
+	case err == nil:
+	case !started:
+		abortWithServiceError(c, err)
+	case errors.Is(err, context.Canceled):
+		log.Debug("stream client went away", "job_id", jobId, "request_id", requestID(c))
+	default:
+		log.Warn("job stream aborted", "job_id", jobId, "request_id", requestID(c), "err", err)
+	}
+}
+
+// writeEvent writes a single event in the text/event-stream format
+func writeEvent(w http.ResponseWriter, id, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event, err)
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestStreamGrepJob(t *testing.T) {
+	router, storage := newTestRouter(t)
+
+	w := do(router, http.MethodPost, "/api/v1/grep", GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var created JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	storage.AddResult(models.Result{
+		ID:        "r1",
+		JobID:     created.JobId,
+		CreatedAt: time.Now(),
+		Matches: []models.Match{
+			{FileName: "logs/app.log", LineNumber: 3, Content: "error one"},
+			{FileName: "logs/app.log", LineNumber: 8, Content: "error two"},
+		},
+	})
+
+	// A finished job replays its matches and closes the stream
+	w = do(router, http.MethodPost, "/api/v1/grep/"+created.JobId+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = do(router, http.MethodGet, "/api/v1/grep/"+created.JobId+"/stream", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	first := regexp.MustCompile(`id: (stream_[0-9a-f]+:r1:0)\n`).FindStringSubmatch(body)
+	require.Len(t, first, 2, body)
+	assert.Contains(t, body, "id: "+first[1]+"\nevent: match\ndata: {\"content\":\"error one\",\"file\":\"logs/app.log\",\"line_number\":3}\n\n")
+	assert.Regexp(t, `id: stream_[0-9a-f]+:r1:1\nevent: match\n`, body)
+	assert.Contains(t, body, "event: stats\n")
+	assert.True(t, strings.HasSuffix(body, "\n\n"))
+	assert.Contains(t, body[strings.LastIndex(body, "event: "):], "event: status\ndata: {")
+	assert.Contains(t, body, `"status":"CANCELLED"`)
+
+	// Resuming skips what the client already received
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/grep/"+created.JobId+"/stream", nil)
+	req.Header.Set("Last-Event-ID", first[1])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), ":r1:0\n")
+	assert.Contains(t, w.Body.String(), ":r1:1\n")
+}
+
+func TestStreamGrepJobErrors(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := do(router, http.MethodGet, "/api/v1/grep/missing/stream", nil)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	var apiErr Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, CodeJobNotFound, apiErr.Code)
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// streamedBodies are the operations whose request bodies are file uploads,
+// they are not validated so that they can be streamed
+var streamedBodies = map[string]bool{"uploadFile": true, "putFile": true}
+
+// CodeInvalidResponse is returned in place of a response that violates the
+// spec, only when responses are validated
+const CodeInvalidResponse = "INVALID_RESPONSE"
+
+// ValidatorOptions configure SpecValidator
+type ValidatorOptions struct {
+	// Responses also validates every JSON response and replaces the ones
+	// violating the spec with a 500, meant for debugging and tests since
+	// responses are buffered
+	Responses bool
+}
+
+// SpecValidator returns middleware that rejects requests violating the
+// embedded OpenAPI spec with INVALID_REQUEST and the offending fields. Register
+// it on the router group of the handlers, after APIKeyAuth so that callers
+// without credentials get 401 rather than 400. Requests to paths outside of
+// the spec are passed on untouched. The bodies of file uploads are not
+// validated so that they can be streamed.
+func SpecValidator(baseURL string, opts ValidatorOptions) (gin.HandlerFunc, error) {
+	spec, err := GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spec: %w", err)
+	}
+	// Requests are matched on their path below baseURL instead
+	spec.Servers = nil
+
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build spec router: %w", err)
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, ok := findRoute(router, c.Request, baseURL)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				ExcludeRequestBody: streamedBodies[route.Operation.OperationID],
+				MultiError:         true,
+				// Credentials are checked by APIKeyAuth, if at all
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+				SkipSettingDefaults: true,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, Error{
+				Code:      CodeInvalidRequest,
+				Message:   "request does not match the API spec",
+				Details:   fieldErrors(err),
+				RequestId: requestID(c),
+			})
+			return
+		}
+
+		if !opts.Responses || !jsonResponses(route.Operation) {
+			c.Next()
+			return
+		}
+
+		validateResponse(c, input)
+	}, nil
+}
+
+// findRoute looks up the operation of a request, paths are matched without
+// the baseURL prefix
+func findRoute(router routers.Router, req *http.Request, baseURL string) (*routers.Route, map[string]string, bool) {
+	path, ok := strings.CutPrefix(req.URL.Path, baseURL)
+	if !ok {
+		return nil, nil, false
+	}
+
+	lookup := req.Clone(req.Context())
+	lookup.URL.Path = path
+	lookup.URL.RawPath = ""
+
+	route, pathParams, err := router.FindRoute(lookup)
+	if err != nil {
+		return nil, nil, false
+	}
+	return route, pathParams, true
+}
+
+// validateResponse buffers the response of the handlers down the chain and
+// sends it on only if it matches the spec
+func validateResponse(c *gin.Context, input *openapi3filter.RequestValidationInput) {
+	writer := c.Writer
+	recorder := &responseRecorder{ResponseWriter: writer, status: http.StatusOK}
+	c.Writer = recorder
+	c.Next()
+	c.Writer = writer
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 recorder.status,
+		Header:                 writer.Header(),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	responseInput.SetBodyBytes(recorder.body.Bytes())
+
+	// A fresh context, the request may be gone by now
+	if err := openapi3filter.ValidateResponse(context.Background(), responseInput); err != nil {
+		log.Error("response does not match the API spec", "request_id", requestID(c), "path", c.FullPath(), "status", recorder.status, "err", err)
+		c.JSON(http.StatusInternalServerError, Error{
+			Code:      CodeInvalidResponse,
+			Message:   "response does not match the API spec",
+			Details:   fieldErrors(err),
+			RequestId: requestID(c),
+		})
+		return
+	}
+
+	writer.WriteHeader(recorder.status)
+	writer.Write(recorder.body.Bytes())
+}
+
+// jsonResponses reports whether every response of an operation is JSON or
+// empty, streamed responses are never buffered
+func jsonResponses(op *openapi3.Operation) bool {
+	for _, response := range op.Responses.Map() {
+		for contentType := range response.Value.Content {
+			if !isJSON(contentType) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// fieldErrors flattens a validation error into one FieldError per field
+func fieldErrors(err error) *[]FieldError {
+	details := appendFieldErrors(nil, "", err)
+	return &details
+}
+
+func appendFieldErrors(details []FieldError, field string, err error) []FieldError {
+	// Matched on the error itself, errors.As would look through the wrapping
+	// errors that name the field
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range err {
+			details = appendFieldErrors(details, field, err)
+		}
+		return details
+	case *openapi3filter.RequestError:
+		field = "body"
+		if err.Parameter != nil {
+			field = err.Parameter.In + "." + err.Parameter.Name
+		}
+		if err.Err == nil {
+			return append(details, FieldError{Field: field, Message: err.Reason})
+		}
+		return appendFieldErrors(details, field, err.Err)
+	case *openapi3filter.ResponseError:
+		if err.Err == nil {
+			return append(details, FieldError{Field: "response", Message: err.Reason})
+		}
+		return appendFieldErrors(details, "response", err.Err)
+	case *openapi3.SchemaError:
+		return append(details, FieldError{Field: field + jsonPath(err.JSONPointer()), Message: err.Reason})
+	default:
+		return append(details, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// jsonPath renders a JSON pointer as .name and [index] segments
+func jsonPath(pointer []string) string {
+	var b strings.Builder
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			b.WriteString("[" + segment + "]")
+		} else {
+			b.WriteString("." + segment)
+		}
+	}
+	return b.String()
+}
+
+// responseRecorder holds back a response until it was validated
+type responseRecorder struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *responseRecorder) WriteHeaderNow() {}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *responseRecorder) Status() int {
+	return w.status
+}
+
+func (w *responseRecorder) Size() int {
+	return w.body.Len()
+}
+
+func (w *responseRecorder) Written() bool {
+	return w.body.Len() > 0
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecValidatorRequests(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		fields []string
+	}{
+		{
+			name:   "ContextLinesAboveMaximum",
+			method: http.MethodPost,
+			path:   "/api/v1/grep",
+			body:   map[string]any{"pattern": "error", "files": []string{"logs/app.log"}, "context_lines": 11},
+			fields: []string{"body.context_lines"},
+		},
+		{
+			name:   "TooManyFiles",
+			method: http.MethodPost,
+			path:   "/api/v1/grep",
+			body:   map[string]any{"pattern": "error", "files": make([]string, 101)},
+			fields: []string{"body.files"},
+		},
+		{
+			name:   "EveryViolatingField",
+			method: http.MethodPost,
+			path:   "/api/v1/grep/estimate",
+			body:   map[string]any{"pattern": 7, "files": []string{}, "regex": "yes"},
+			fields: []string{"body.files", "body.pattern", "body.regex"},
+		},
+		{
+			name:   "NestedField",
+			method: http.MethodPost,
+			path:   "/api/v1/grep",
+			body:   map[string]any{"pattern": "error", "files": []string{"logs/app.log"}, "webhook": map[string]any{"url": "https://example.com", "secret": ""}},
+			fields: []string{"body.webhook.secret"},
+		},
+		{
+			name:   "LimitAboveMaximum",
+			method: http.MethodGet,
+			path:   "/api/v1/grep?limit=101",
+			fields: []string{"query.limit"},
+		},
+		{
+			name:   "MalformedBody",
+			method: http.MethodPost,
+			path:   "/api/v1/searches",
+			body:   "not an object",
+			fields: []string{"body"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(router, tt.method, tt.path, tt.body)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var apiErr Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, CodeInvalidRequest, apiErr.Code)
+			assert.Equal(t, "req_test", apiErr.RequestId)
+			require.NotNil(t, apiErr.Details)
+
+			var fields []string
+			for _, detail := range *apiErr.Details {
+				assert.NotEmpty(t, detail.Message)
+				fields = append(fields, detail.Field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+
+	t.Run("BodyOfUndeclaredType", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/grep", strings.NewReader(`{"pattern": "error", "files": ["logs/app.log"]}`))
+		req.Header.Set("Content-Type", "text/plain")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, "only uploads should skip body validation")
+
+		var apiErr Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		require.NotNil(t, apiErr.Details)
+		assert.Equal(t, "body", (*apiErr.Details)[0].Field)
+	})
+
+	t.Run("UnknownPathsPassThrough", func(t *testing.T) {
+		w := do(router, http.MethodGet, "/api/v1/unknown", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSpecValidatorResponses(t *testing.T) {
+	validator, err := SpecValidator("/api/v1", ValidatorOptions{Responses: true})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RequestID(), validator)
+	router.GET("/api/v1/searches", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"searches": "none"})
+	})
+	router.DELETE("/api/v1/searches/:searchId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/api/v1/files", func(c *gin.Context) {
+		c.JSON(http.StatusTeapot, gin.H{})
+	})
+
+	w := do(router, http.MethodDelete, "/api/v1/searches/s_1", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	for path, field := range map[string]string{
+		"/api/v1/searches": "response.searches",
+		"/api/v1/files":    "response",
+	} {
+		w := do(router, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+		var apiErr Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, CodeInvalidResponse, apiErr.Code)
+		require.NotNil(t, apiErr.Details)
+		assert.Equal(t, field, (*apiErr.Details)[0].Field)
+	}
+}
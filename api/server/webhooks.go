@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarit-pandey/distributed-grep/api/service"
+)
+
+// GetGrepJobWebhook returns the webhook of a job and its delivery attempts
+func (h *Handler) GetGrepJobWebhook(c *gin.Context, jobId string) {
+	webhookLog, err := h.svc.GetWebhook(c.Request.Context(), jobId)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookDeliveryLog(webhookLog))
+}
+
+func toWebhookDeliveryLog(webhookLog *service.WebhookLog) WebhookDeliveryLog {
+	hook := webhookLog.Webhook
+	resp := WebhookDeliveryLog{
+		Url:           hook.URL,
+		State:         string(hook.State),
+		Attempts:      hook.Attempts,
+		NextAttemptAt: hook.NextAttemptAt,
+		DeliveredAt:   hook.DeliveredAt,
+		Deliveries:    make([]WebhookDelivery, 0, len(webhookLog.Attempts)),
+	}
+
+	for _, attempt := range webhookLog.Attempts {
+		delivery := WebhookDelivery{
+			Attempt:     attempt.Attempt,
+			AttemptedAt: attempt.AttemptedAt,
+			DurationMs:  attempt.Duration.Milliseconds(),
+		}
+		if attempt.StatusCode != 0 {
+			statusCode := attempt.StatusCode
+			delivery.StatusCode = &statusCode
+		}
+		if attempt.Error != "" {
+			errMsg := attempt.Error
+			delivery.Error = &errMsg
+		}
+		resp.Deliveries = append(resp.Deliveries, delivery)
+	}
+
+	return resp
+}
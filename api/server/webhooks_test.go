@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGrepJobWebhook(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	createJob := func(hook *Webhook) string {
+		req := GrepRequest{Pattern: "error", Files: []string{"logs/app.log"}, Webhook: hook}
+		w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", req, nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var created JobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created.JobId
+	}
+
+	jobID := createJob(&Webhook{Url: "https://example.com/hook", Secret: "s3cret"})
+
+	w := do(router, http.MethodGet, "/api/v1/grep/"+jobID+"/webhook", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "s3cret")
+
+	var deliveryLog WebhookDeliveryLog
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveryLog))
+	assert.Equal(t, "https://example.com/hook", deliveryLog.Url)
+	assert.Equal(t, "WAITING", deliveryLog.State)
+	assert.Zero(t, deliveryLog.Attempts)
+	assert.Empty(t, deliveryLog.Deliveries)
+
+	w = do(router, http.MethodPost, "/api/v1/grep/"+jobID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = do(router, http.MethodGet, "/api/v1/grep/"+jobID+"/webhook", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var queued WebhookDeliveryLog
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queued))
+	assert.Equal(t, "PENDING", queued.State)
+	assert.NotNil(t, queued.NextAttemptAt)
+
+	t.Run("Errors", func(t *testing.T) {
+		w := doWithHeaders(router, http.MethodPost, "/api/v1/grep", GrepRequest{
+			Pattern: "error",
+			Files:   []string{"logs/app.log"},
+			Webhook: &Webhook{Url: "ftp://example.com/hook", Secret: "s3cret"},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		tests := []struct {
+			jobID  string
+			status int
+			code   string
+		}{
+			{createJob(nil), http.StatusNotFound, CodeWebhookNotFound},
+			{"grep_missing", http.StatusNotFound, CodeJobNotFound},
+		}
+
+		for _, tt := range tests {
+			w := do(router, http.MethodGet, "/api/v1/grep/"+tt.jobID+"/webhook", nil)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var apiErr Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.code, apiErr.Code)
+		}
+	})
+}
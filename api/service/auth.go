@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// ErrUnauthenticated is returned for requests without a known API key
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// APIKey grants a tenant access, only the SHA-256 of the key is configured
+// so the config file does not hold usable secrets. Requests made with the key
+// are held to Limits, or to the default limits of the service if unset.
+type APIKey struct {
+	Tenant    string  `mapstructure:"tenant"`
+	KeySHA256 string  `mapstructure:"keySha256"`
+	Limits    *Limits `mapstructure:"limits"`
+}
+
+// keyIDLength is the number of hex digits of the SHA-256 of a key that
+// identify it, e.g. in the names of its usage counters
+const keyIDLength = 16
+
+// Keyring authenticates the API keys presented to any of the transports
+type Keyring struct {
+	principals map[string]APIKey
+}
+
+// NewKeyring validates the configured keys and returns a Keyring of them
+func NewKeyring(keys []APIKey) (*Keyring, error) {
+	principals := make(map[string]APIKey, len(keys))
+	for _, key := range keys {
+		if !tenant.Valid(key.Tenant) {
+			return nil, fmt.Errorf("invalid tenant ID %q", key.Tenant)
+		}
+
+		hash := strings.ToLower(key.KeySHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("key of tenant %s is not a hex encoded SHA-256", key.Tenant)
+		}
+		if _, ok := principals[hash]; ok {
+			return nil, fmt.Errorf("key of tenant %s is configured more than once", key.Tenant)
+		}
+		principals[hash] = key
+	}
+
+	return &Keyring{principals: principals}, nil
+}
+
+// Authenticate returns a copy of ctx scoped to the tenant of key, carrying its
+// limits and counting usage against it, or ErrUnauthenticated if the key is
+// missing or unknown
+func (k *Keyring) Authenticate(ctx context.Context, key string) (context.Context, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing API key", ErrUnauthenticated)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	principal, ok := k.principals[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+
+	ctx = tenant.NewContext(ctx, principal.Tenant)
+	ctx = submit.WithKey(ctx, hash[:keyIDLength])
+	if principal.Limits != nil {
+		ctx = WithLimits(ctx, *principal.Limits)
+	}
+	return ctx, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []APIKey
+	}{
+		{"InvalidTenant", []APIKey{{Tenant: "Team A", KeySHA256: hashKey("a")}}},
+		{"NotAHash", []APIKey{{Tenant: "team-a", KeySHA256: "secret"}}},
+		{"Placeholder", []APIKey{{Tenant: "team-a", KeySHA256: "REPLACE-WITH-THE-SHA256-OF-A-KEY"}}},
+		{"ShortHash", []APIKey{{Tenant: "team-a", KeySHA256: hashKey("a")[:63]}}},
+		{"LongHash", []APIKey{{Tenant: "team-a", KeySHA256: hashKey("a") + "00"}}},
+		{"DuplicateKey", []APIKey{{Tenant: "team-a", KeySHA256: hashKey("a")}, {Tenant: "team-b", KeySHA256: hashKey("a")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeyringAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	limits := Limits{MaxRunningJobs: 1}
+
+	keyring, err := NewKeyring([]APIKey{
+		{Tenant: "team-a", KeySHA256: strings.ToUpper(hashKey("key-a"))},
+		{Tenant: "team-b", KeySHA256: hashKey("key-b"), Limits: &limits},
+	})
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "wrong"} {
+		_, err := keyring.Authenticate(ctx, key)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	}
+
+	defaults := submit.New(nil, nil, nil, nil)
+	defaults.SetDefaultLimits(Limits{JobsPerMinute: 5})
+
+	authed, err := keyring.Authenticate(ctx, "key-a")
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", tenant.FromContext(authed))
+	assert.Equal(t, Limits{JobsPerMinute: 5}, defaults.LimitsFor(authed))
+	assert.Equal(t, hashKey("key-a")[:keyIDLength], submit.KeyFromContext(authed))
+
+	authed, err = keyring.Authenticate(ctx, "key-b")
+	require.NoError(t, err)
+	assert.Equal(t, "team-b", tenant.FromContext(authed))
+	assert.Equal(t, limits, defaults.LimitsFor(authed))
+	assert.Equal(t, hashKey("key-b")[:keyIDLength], submit.KeyFromContext(authed))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// FileCount is what a job in a counting mode reports about one of its files
+type FileCount struct {
+	File  string
+	Count int // Selected lines, at most the job's MaxCount, 1 for a match in modes settled by the first one
+}
+
+// FileCounts returns one page of the per-file counts of a job in a counting
+// mode, ordered by file, along with the total number of files reported. In
+// count mode every file of the job is listed, in files_with_matches mode
+// only the files with a selected line and in files_without_match mode only
+// the ones without. Until the job completes, files without a match may still
+// find one.
+func (s *Service) FileCounts(ctx context.Context, jobID string, page, limit int) ([]FileCount, int, error) {
+	if err := validatePage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer metrics.ObserveReduce(time.Now())
+	results, err := s.storage.GetJobResults(ctx, jobID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get results for job %s: %w", jobID, err)
+	}
+
+	counts := make(map[string]int, len(job.Files))
+	for _, file := range job.Files {
+		counts[file] = 0
+	}
+	for _, result := range results {
+		for file, n := range result.Counts {
+			counts[file] += n
+		}
+	}
+
+	max := job.MaxCount
+	if job.Mode.SettledByFirstMatch() {
+		max = 1
+	}
+
+	files := make([]FileCount, 0, len(counts))
+	for file, n := range counts {
+		if max > 0 && n > max {
+			n = max
+		}
+
+		switch {
+		case job.Mode == models.SearchModeFilesWithMatches && n == 0:
+			continue
+		case job.Mode == models.SearchModeFilesWithoutMatch && n > 0:
+			continue
+		}
+		files = append(files, FileCount{File: file, Count: n})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].File < files[j].File
+	})
+
+	return paginate(files, page, limit), len(files), nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+// Defaults of job deletion
+const (
+	DefaultTombstoneTTL = 30 * 24 * time.Hour
+	DefaultDrainTimeout = 30 * time.Second
+)
+
+// drainInterval is how often DeleteJob checks for chunks still in flight
+const drainInterval = 250 * time.Millisecond
+
+// SetTombstoneTTL changes how long deleted jobs are reported as deleted
+// rather than unknown
+func (s *Service) SetTombstoneTTL(ttl time.Duration) {
+	s.tombstoneTTL = ttl
+}
+
+// SetDrainTimeout changes how long DeleteJob waits for chunks being
+// processed before it removes the job anyway
+func (s *Service) SetDrainTimeout(timeout time.Duration) {
+	s.drainTimeout = timeout
+}
+
+// DeleteJob cancels a job that is still running, waits for the chunks being
+// processed to drain and removes its chunks, results and state. The job is
+// reported as ErrJobDeleted afterwards, until its tombstone expires.
+func (s *Service) DeleteJob(ctx context.Context, jobID string) error {
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	if !job.Status.IsTerminal() {
+		cancelled, err := s.CancelJob(ctx, jobID)
+		switch {
+		case err == nil:
+			job = cancelled
+		case errors.Is(err, ErrJobFinished):
+			// Finished on its own in the meantime
+			if job, err = s.jobs.GetJob(ctx, jobID); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	if err := s.drainChunks(ctx, jobID); err != nil {
+		return err
+	}
+
+	// Data goes first, a failure leaves the job in place so the delete can
+	// be retried
+	if err := s.storage.DeleteJob(ctx, jobID); err != nil {
+		return fmt.Errorf("failed to delete data of job %s: %w", jobID, err)
+	}
+
+	if err := s.jobs.PurgeJob(ctx, job, redis.TombstoneDeleted, s.tombstoneTTL); err != nil {
+		return err
+	}
+
+	log.Info("job deleted", "job_id", jobID)
+	return nil
+}
+
+// drainChunks waits until no chunk of the job is being processed. Workers
+// check for cancellation between chunks, so only chunks that were already
+// started are waited for, and only up to the drain timeout.
+func (s *Service) drainChunks(ctx context.Context, jobID string) error {
+	deadline := time.NewTimer(s.drainTimeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.jobs.CountInFlightChunks(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			log.Warn("deleting job with chunks still in flight", "job_id", jobID, "chunks", n)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
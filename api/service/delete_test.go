@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+func TestDeleteJob(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CancelsRunningJob", func(t *testing.T) {
+		svc, storage, bus := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, models.Job{Pattern: "error", Files: []string{"logs/app.log"}, Regex: true})
+		require.NoError(t, err)
+		storage.AddResult(models.Result{JobID: job.ID, Matches: []models.Match{{FileName: "logs/app.log", LineNumber: 1}}})
+
+		require.NoError(t, svc.DeleteJob(ctx, job.ID))
+		messages := bus.Messages()
+		assert.Equal(t, nats.SubjectJobCancel, messages[len(messages)-2].Subject)
+		assert.Equal(t, nats.SubjectJobFinished, messages[len(messages)-1].Subject)
+
+		results, err := storage.GetJobResults(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		_, _, err = svc.GetJob(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrJobDeleted)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+
+		page, err := svc.ListJobs(ctx, redis.JobQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, page.Jobs)
+
+		assert.ErrorIs(t, svc.DeleteJob(ctx, job.ID), ErrJobDeleted)
+	})
+
+	t.Run("FinishedJob", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, models.Job{Pattern: "error", Files: []string{"logs/app.log"}, Regex: true})
+		require.NoError(t, err)
+		completeJob(t, svc, job.ID)
+		published := len(bus.Messages())
+
+		require.NoError(t, svc.DeleteJob(ctx, job.ID))
+		assert.Len(t, bus.Messages(), published)
+	})
+
+	t.Run("UnknownJob", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		err := svc.DeleteJob(ctx, "missing")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		assert.NotErrorIs(t, err, ErrJobDeleted)
+	})
+}
+
+func TestDeleteJobDrainsChunks(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t)
+	store := svc.jobs.(*redis.Store)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "error", Files: []string{"logs/app.log"}, Regex: true})
+	require.NoError(t, err)
+	require.NoError(t, store.StartChunk(ctx, job.ID, "chunk_1", time.Minute))
+
+	t.Run("WaitsForChunks", func(t *testing.T) {
+		go func() {
+			time.Sleep(2 * drainInterval)
+			store.FinishChunk(ctx, job.ID, "chunk_1")
+		}()
+
+		start := time.Now()
+		require.NoError(t, svc.drainChunks(ctx, job.ID))
+		assert.GreaterOrEqual(t, time.Since(start), 2*drainInterval)
+	})
+
+	t.Run("GivesUpAfterTimeout", func(t *testing.T) {
+		require.NoError(t, store.StartChunk(ctx, job.ID, "chunk_2", time.Minute))
+		svc.SetDrainTimeout(drainInterval)
+
+		require.NoError(t, svc.DeleteJob(ctx, job.ID))
+
+		n, err := store.CountInFlightChunks(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// MaxDiffLimit caps the line patterns returned per category of a diff
+const MaxDiffLimit = 500
+
+// DiffMask names a class of volatile tokens that is replaced by a placeholder
+// before the lines of two jobs are compared
+type DiffMask string
+
+const (
+	MaskTimestamp DiffMask = "timestamp"
+	MaskUUID      DiffMask = "uuid"
+	MaskIP        DiffMask = "ip"
+	MaskHex       DiffMask = "hex"
+	MaskNumber    DiffMask = "number"
+)
+
+// DefaultDiffMasks are applied when a diff does not ask for masks itself
+var DefaultDiffMasks = []DiffMask{MaskTimestamp, MaskUUID, MaskIP, MaskHex, MaskNumber}
+
+type diffMask struct {
+	name        DiffMask
+	re          *regexp.Regexp
+	placeholder string
+}
+
+// diffMasks lists every mask in the order it is applied, broader tokens go
+// first so a timestamp is not masked as a handful of numbers
+var diffMasks = []diffMask{
+	{
+		name: MaskTimestamp,
+		re: regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?` +
+			`|\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}` +
+			`|\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) +\d{1,2} \d{2}:\d{2}:\d{2}\b` +
+			`|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`),
+		placeholder: "<ts>",
+	},
+	{
+		name:        MaskUUID,
+		re:          regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		placeholder: "<uuid>",
+	},
+	{
+		name:        MaskIP,
+		re:          regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		placeholder: "<ip>",
+	},
+	{
+		name:        MaskHex,
+		re:          regexp.MustCompile(`\b0[xX][0-9a-fA-F]+\b|\b[0-9a-fA-F]{8,}\b`),
+		placeholder: "<hex>",
+	},
+	{
+		name:        MaskNumber,
+		re:          regexp.MustCompile(`\d+(?:\.\d+)?`),
+		placeholder: "<num>",
+	},
+}
+
+// LineNormalizer replaces volatile tokens of log lines by placeholders, so
+// lines that only differ in them compare equal
+type LineNormalizer struct {
+	masks []diffMask
+}
+
+// NewLineNormalizer returns a LineNormalizer applying masks, nil selects
+// DefaultDiffMasks and an empty slice leaves lines untouched
+func NewLineNormalizer(masks []DiffMask) (*LineNormalizer, error) {
+	if masks == nil {
+		masks = DefaultDiffMasks
+	}
+
+	selected := make(map[DiffMask]bool, len(masks))
+	for _, name := range masks {
+		selected[name] = true
+	}
+
+	n := &LineNormalizer{}
+	for _, mask := range diffMasks {
+		if selected[mask.name] {
+			n.masks = append(n.masks, mask)
+			delete(selected, mask.name)
+		}
+	}
+	for name := range selected {
+		return nil, fmt.Errorf("%w: unknown mask %q", ErrInvalidRequest, name)
+	}
+
+	return n, nil
+}
+
+// Masks returns the names of the masks n applies, in the order it applies them
+func (n *LineNormalizer) Masks() []DiffMask {
+	names := make([]DiffMask, 0, len(n.masks))
+	for _, mask := range n.masks {
+		names = append(names, mask.name)
+	}
+	return names
+}
+
+// Normalize returns line with its volatile tokens masked
+func (n *LineNormalizer) Normalize(line string) string {
+	for _, mask := range n.masks {
+		line = mask.re.ReplaceAllLiteralString(line, mask.placeholder)
+	}
+	return line
+}
+
+// LineDiff is a normalized line pattern with how often it matched in either job
+type LineDiff struct {
+	Pattern   string
+	BaseCount int
+	HeadCount int
+
+	// Example is a match of the pattern, taken from head unless the pattern
+	// disappeared
+	Example models.Match
+}
+
+// JobDiff compares the matches of two completed jobs by line pattern
+type JobDiff struct {
+	Base  *models.Job
+	Head  *models.Job
+	Masks []DiffMask
+
+	BaseMatches int
+	HeadMatches int
+
+	// New lists patterns only head matched, Disappeared the ones only base
+	// matched and Changed the ones both matched a different number of times.
+	// Each is ordered by how much the count changed and cut at the limit, the
+	// totals count the patterns before the cut.
+	New              []LineDiff
+	NewTotal         int
+	Disappeared      []LineDiff
+	DisappearedTotal int
+	Changed          []LineDiff
+	ChangedTotal     int
+
+	// Unchanged counts the patterns both jobs matched equally often
+	Unchanged int
+}
+
+// DiffJobs compares the matches of the completed jobs base and head after
+// masking their volatile tokens. Matches are counted per normalized line, so
+// memory grows with the number of distinct patterns rather than of matches.
+func (s *Service) DiffJobs(ctx context.Context, baseID, headID string, masks []DiffMask, limit int) (*JobDiff, error) {
+	if limit < 1 || limit > MaxDiffLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxDiffLimit)
+	}
+	if baseID == headID {
+		return nil, fmt.Errorf("%w: base and head must be different jobs", ErrInvalidRequest)
+	}
+
+	normalizer, err := NewLineNormalizer(masks)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.jobs.GetJob(ctx, baseID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.jobs.GetJob(ctx, headID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &JobDiff{Base: base, Head: head, Masks: normalizer.Masks()}
+	lines := make(map[string]*LineDiff)
+
+	err = s.ExportMatches(ctx, baseID, func(m models.Match) error {
+		diff.BaseMatches++
+		pattern := normalizer.Normalize(m.Content)
+		line, ok := lines[pattern]
+		if !ok {
+			line = &LineDiff{Pattern: pattern, Example: m}
+			lines[pattern] = line
+		}
+		line.BaseCount++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.ExportMatches(ctx, headID, func(m models.Match) error {
+		diff.HeadMatches++
+		pattern := normalizer.Normalize(m.Content)
+		line, ok := lines[pattern]
+		if !ok {
+			line = &LineDiff{Pattern: pattern}
+			lines[pattern] = line
+		}
+		if line.HeadCount == 0 {
+			line.Example = m
+		}
+		line.HeadCount++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		switch {
+		case line.BaseCount == 0:
+			diff.New = append(diff.New, *line)
+		case line.HeadCount == 0:
+			diff.Disappeared = append(diff.Disappeared, *line)
+		case line.BaseCount != line.HeadCount:
+			diff.Changed = append(diff.Changed, *line)
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	diff.NewTotal = len(diff.New)
+	diff.DisappearedTotal = len(diff.Disappeared)
+	diff.ChangedTotal = len(diff.Changed)
+	diff.New = topLineDiffs(diff.New, limit)
+	diff.Disappeared = topLineDiffs(diff.Disappeared, limit)
+	diff.Changed = topLineDiffs(diff.Changed, limit)
+
+	return diff, nil
+}
+
+// topLineDiffs orders lines by how much their count changed, then by pattern,
+// and keeps the first limit
+func topLineDiffs(lines []LineDiff, limit int) []LineDiff {
+	sort.Slice(lines, func(i, j int) bool {
+		di, dj := lines[i].delta(), lines[j].delta()
+		if di != dj {
+			return di > dj
+		}
+		return lines[i].Pattern < lines[j].Pattern
+	})
+
+	if len(lines) > limit {
+		lines = lines[:limit]
+	}
+	return lines
+}
+
+// delta is the absolute change of the count of l
+func (l LineDiff) delta() int {
+	if l.HeadCount > l.BaseCount {
+		return l.HeadCount - l.BaseCount
+	}
+	return l.BaseCount - l.HeadCount
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestLineNormalizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		masks []DiffMask
+		line  string
+		want  string
+	}{
+		{
+			name: "Defaults",
+			line: "2024-01-02T02:15:42.123Z ERROR request 0b9f6c1e-3d2a-4c8e-9f1a-2b3c4d5e6f70 from 10.0.0.12 failed after 350ms",
+			want: "<ts> ERROR request <uuid> from <ip> failed after <num>ms",
+		},
+		{
+			name: "SyslogTimestamp",
+			line: "Jan  2 02:15:42 host sshd[4242]: session opened",
+			want: "<ts> host sshd[<num>]: session opened",
+		},
+		{
+			name: "CommonLogTimestamp",
+			line: `127.0.0.1 - - [02/Jan/2024:02:15:42 +0000] "GET /api HTTP/1.1" 500 1043`,
+			want: `<ip> - - [<ts>] "GET /api HTTP/<num>" <num> <num>`,
+		},
+		{
+			name: "Hex",
+			line: "panic at 0x7ffd1234 in commit deadbeef42",
+			want: "panic at <hex> in commit <hex>",
+		},
+		{
+			name:  "OnlySelectedMasks",
+			masks: []DiffMask{MaskNumber, MaskUUID},
+			line:  "job 0b9f6c1e-3d2a-4c8e-9f1a-2b3c4d5e6f70 retried 3 times",
+			want:  "job <uuid> retried <num> times",
+		},
+		{
+			name:  "NoMasks",
+			masks: []DiffMask{},
+			line:  "retried 3 times",
+			want:  "retried 3 times",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewLineNormalizer(tt.masks)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, n.Normalize(tt.line))
+		})
+	}
+
+	t.Run("AppliedInOrder", func(t *testing.T) {
+		n, err := NewLineNormalizer([]DiffMask{MaskNumber, MaskTimestamp})
+		require.NoError(t, err)
+		assert.Equal(t, []DiffMask{MaskTimestamp, MaskNumber}, n.Masks())
+	})
+
+	t.Run("UnknownMask", func(t *testing.T) {
+		_, err := NewLineNormalizer([]DiffMask{"email"})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
+
+func TestDiffJobs(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+
+	base, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+	head, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{ID: "r1", JobID: base.ID, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 1, Content: "10:00:01 timeout after 30s"},
+		{FileName: "logs/app.log", LineNumber: 2, Content: "10:00:02 timeout after 31s"},
+		{FileName: "logs/app.log", LineNumber: 3, Content: "10:00:03 disk full"},
+		{FileName: "logs/app.log", LineNumber: 4, Content: "10:00:04 user 7 logged out"},
+	}})
+	storage.AddResult(models.Result{ID: "r2", JobID: head.ID, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 5, Content: "11:00:01 timeout after 12s"},
+		{FileName: "logs/app.log", LineNumber: 6, Content: "11:00:02 user 9 logged out"},
+		{FileName: "logs/app.log", LineNumber: 7, Content: "11:00:03 connection refused"},
+		{FileName: "logs/app.log", LineNumber: 8, Content: "11:00:04 connection refused"},
+		{FileName: "logs/app.log", LineNumber: 9, Content: "11:00:05 out of memory"},
+	}})
+
+	_, err = svc.DiffJobs(ctx, base.ID, head.ID, nil, 10)
+	assert.ErrorIs(t, err, ErrJobIncomplete)
+
+	completeJob(t, svc, base.ID)
+	completeJob(t, svc, head.ID)
+
+	t.Run("DefaultMasks", func(t *testing.T) {
+		diff, err := svc.DiffJobs(ctx, base.ID, head.ID, nil, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, base.ID, diff.Base.ID)
+		assert.Equal(t, head.ID, diff.Head.ID)
+		assert.Equal(t, DefaultDiffMasks, diff.Masks)
+		assert.Equal(t, 4, diff.BaseMatches)
+		assert.Equal(t, 5, diff.HeadMatches)
+		assert.Equal(t, 1, diff.Unchanged)
+
+		require.Len(t, diff.New, 2)
+		assert.Equal(t, 2, diff.NewTotal)
+		assert.Equal(t, LineDiff{Pattern: "<ts> connection refused", HeadCount: 2, Example: models.Match{FileName: "logs/app.log", LineNumber: 7, Content: "11:00:03 connection refused"}}, diff.New[0])
+		assert.Equal(t, "<ts> out of memory", diff.New[1].Pattern)
+
+		require.Len(t, diff.Disappeared, 1)
+		assert.Equal(t, "<ts> disk full", diff.Disappeared[0].Pattern)
+		assert.Equal(t, 1, diff.Disappeared[0].BaseCount)
+		assert.Equal(t, 3, diff.Disappeared[0].Example.LineNumber)
+
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, "<ts> timeout after <num>s", diff.Changed[0].Pattern)
+		assert.Equal(t, 2, diff.Changed[0].BaseCount)
+		assert.Equal(t, 1, diff.Changed[0].HeadCount)
+		assert.Equal(t, 5, diff.Changed[0].Example.LineNumber)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		diff, err := svc.DiffJobs(ctx, base.ID, head.ID, nil, 1)
+		require.NoError(t, err)
+		require.Len(t, diff.New, 1)
+		assert.Equal(t, 2, diff.NewTotal)
+		assert.Equal(t, "<ts> connection refused", diff.New[0].Pattern)
+	})
+
+	t.Run("NoMasks", func(t *testing.T) {
+		diff, err := svc.DiffJobs(ctx, base.ID, head.ID, []DiffMask{}, 10)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Masks)
+		assert.Equal(t, 5, diff.NewTotal)
+		assert.Equal(t, 4, diff.DisappearedTotal)
+		assert.Zero(t, diff.ChangedTotal)
+		assert.Zero(t, diff.Unchanged)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := svc.DiffJobs(ctx, base.ID, base.ID, nil, 10)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		_, err = svc.DiffJobs(ctx, base.ID, head.ID, nil, MaxDiffLimit+1)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		_, err = svc.DiffJobs(ctx, base.ID, head.ID, []DiffMask{"email"}, 10)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		_, err = svc.DiffJobs(ctx, base.ID, "grep_missing", nil, 10)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
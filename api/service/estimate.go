@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+)
+
+// throughputSamples is the number of recently completed jobs the throughput
+// of an estimate is based on
+const throughputSamples = 20
+
+// Estimate is the expected size and duration of a job that was not created
+type Estimate struct {
+	Files  int
+	Bytes  int64
+	Chunks int
+
+	// Throughput in bytes per second of recently completed jobs and the
+	// duration it implies for this job, both zero without any completed jobs
+	Throughput float64
+	Duration   time.Duration
+	Samples    int // Completed jobs the throughput is based on
+}
+
+// EstimateJob resolves the files of a job like CreateJob would, without
+// creating it, and estimates its size and duration. The duration assumes the
+// wall-clock throughput of the latest completed jobs of the tenant.
+func (s *Service) EstimateJob(ctx context.Context, job models.Job) (*Estimate, error) {
+	if err := submit.Validate(&job); err != nil {
+		return nil, err
+	}
+
+	files, err := s.ResolveFiles(ctx, job.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize, err := s.jobs.ChunkSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &Estimate{Files: len(files)}
+	for _, file := range files {
+		estimate.Bytes += file.Size
+		estimate.Chunks += models.ChunkCount(file.Size, chunkSize)
+	}
+
+	throughput, samples, err := s.recentThroughput(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if samples > 0 {
+		estimate.Throughput = throughput
+		estimate.Samples = samples
+		estimate.Duration = time.Duration(float64(estimate.Bytes) / throughput * float64(time.Second))
+	}
+
+	return estimate, nil
+}
+
+// recentThroughput returns the bytes processed per second of wall-clock time
+// across the latest completed jobs, along with the number of jobs it is based
+// on. Jobs without timestamps or processed bytes are skipped.
+func (s *Service) recentThroughput(ctx context.Context) (float64, int, error) {
+	page, err := s.jobs.ListJobs(ctx, redis.JobQuery{Status: models.JobStatusCompleted, Limit: throughputSamples})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var bytes int64
+	var elapsed time.Duration
+	samples := 0
+
+	for _, job := range page.Jobs {
+		if job.CompletedAt == nil {
+			continue
+		}
+		started := job.CreatedAt
+		if job.StartedAt != nil {
+			started = *job.StartedAt
+		}
+		took := job.CompletedAt.Sub(started)
+		if took <= 0 {
+			continue
+		}
+
+		stats, err := s.jobs.GetStats(ctx, job.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if stats.BytesProcessed <= 0 {
+			continue
+		}
+
+		bytes += stats.BytesProcessed
+		elapsed += took
+		samples++
+	}
+
+	if samples == 0 {
+		return 0, 0, nil
+	}
+	return float64(bytes) / elapsed.Seconds(), samples, nil
+}
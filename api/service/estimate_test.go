@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+func TestEstimateJob(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, bus := newTestService(t)
+	storage.AddFile("logs/big.log", strings.Repeat("x", 1000))
+
+	job := models.Job{Pattern: "error", Files: []string{"logs/*.log"}, Regex: true}
+
+	estimate, err := svc.EstimateJob(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, 3, estimate.Files)
+	assert.EqualValues(t, len("app\n")+len("error\n")+1000, estimate.Bytes)
+	assert.Equal(t, 3, estimate.Chunks)
+	assert.Zero(t, estimate.Samples)
+	assert.Zero(t, estimate.Duration)
+
+	// Chunks are counted with the size the managers split files with
+	require.NoError(t, svc.jobs.(*redis.Store).SetChunkSize(ctx, 300))
+	estimate, err = svc.EstimateJob(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, 6, estimate.Chunks)
+
+	// Two completed jobs processed 3000 bytes in 3 seconds
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i, took := range []time.Duration{time.Second, 2 * time.Second} {
+		completed := start.Add(took)
+		done := &models.Job{ID: fmt.Sprintf("grep_done%d", i), Status: models.JobStatusCompleted, CreatedAt: start, StartedAt: &start, CompletedAt: &completed}
+		require.NoError(t, svc.jobs.SaveJob(ctx, done))
+		require.NoError(t, svc.jobs.SetStats(ctx, done.ID, models.JobStats{BytesProcessed: int64(took.Seconds() * 1000)}))
+	}
+
+	estimate, err = svc.EstimateJob(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, 2, estimate.Samples)
+	assert.InDelta(t, 1000, estimate.Throughput, 0.001)
+	assert.Equal(t, time.Duration(estimate.Bytes)*time.Millisecond, estimate.Duration)
+
+	// Nothing is created
+	assert.Empty(t, bus.Messages())
+
+	_, err = svc.EstimateJob(ctx, models.Job{Pattern: "error", Files: []string{"nope.log"}})
+	assert.ErrorIs(t, err, ErrFileNotFound)
+	_, err = svc.EstimateJob(ctx, models.Job{Pattern: "(", Regex: true, Files: []string{"logs/app.log"}})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// resultRef locates a stored result by the first match it holds
+type resultRef struct {
+	id        string
+	fileName  string
+	firstLine int
+}
+
+// ExportMatches calls fn for every match of a completed job, ordered by file
+// and line number. Results are indexed in a first pass and then loaded one at
+// a time, so memory grows with the number of results, not of matches.
+func (s *Service) ExportMatches(ctx context.Context, jobID string, fn func(match models.Match) error) error {
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.JobStatusCompleted {
+		return fmt.Errorf("%w: job %s is %s", ErrJobIncomplete, jobID, job.Status)
+	}
+	if job.Mode.Counts() {
+		return fmt.Errorf("%w: job %s reports %s rather than matches", ErrInvalidRequest, jobID, job.Mode)
+	}
+
+	// Only indexing the results counts, the matches are written as they are
+	// read
+	start := time.Now()
+	var refs []resultRef
+	err = s.storage.WalkJobResults(ctx, jobID, func(result models.Result) error {
+		if len(result.Matches) == 0 {
+			return nil
+		}
+
+		first := result.Matches[0]
+		for _, m := range result.Matches[1:] {
+			if matchLess(m, first) {
+				first = m
+			}
+		}
+
+		refs = append(refs, resultRef{id: result.ID, fileName: first.FileName, firstLine: first.LineNumber})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index results of job %s: %w", jobID, err)
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].fileName != refs[j].fileName {
+			return refs[i].fileName < refs[j].fileName
+		}
+		return refs[i].firstLine < refs[j].firstLine
+	})
+	metrics.ObserveReduce(start)
+
+	var (
+		file  string
+		count int // Matches of file passed to fn so far
+	)
+	for _, ref := range refs {
+		result, err := s.storage.GetResult(ctx, jobID, ref.id)
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(result.Matches, func(i, j int) bool {
+			return matchLess(result.Matches[i], result.Matches[j])
+		})
+
+		for _, m := range result.Matches {
+			if m.FileName != file {
+				file, count = m.FileName, 0
+			}
+			// Chunks of a file are mapped independently, only here is
+			// known which matches come first
+			if count++; job.MaxCount > 0 && count > job.MaxCount {
+				continue
+			}
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// capPerFile keeps the first max matches of each file, matches must be
+// ordered by matchLess. A max of zero keeps everything.
+func capPerFile(matches []models.Match, max int) []models.Match {
+	if max <= 0 {
+		return matches
+	}
+
+	kept := matches[:0]
+	file, count := "", 0
+	for _, m := range matches {
+		if m.FileName != file {
+			file, count = m.FileName, 0
+		}
+		if count++; count <= max {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// matchLess orders matches by file name, then line number
+func matchLess(a, b models.Match) bool {
+	if a.FileName != b.FileName {
+		return a.FileName < b.FileName
+	}
+	return a.LineNumber < b.LineNumber
+}
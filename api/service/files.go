@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// ResolveFiles expands the given file names and glob patterns against the
+// files in log storage. Every pattern has to match at least one file.
+func (s *Service) ResolveFiles(ctx context.Context, patterns []string) ([]models.LogFile, error) {
+	return s.submit.ResolveFiles(ctx, patterns)
+}
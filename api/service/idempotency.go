@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+// DefaultIdempotencyTTL is how long an idempotency key maps to its job
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyClaimLease is how long a key is held by a request still creating
+// its job, a request that dies meanwhile blocks retries at most that long
+const IdempotencyClaimLease = time.Minute
+
+// MaxIdempotencyKeyLength bounds the keys clients may send
+const MaxIdempotencyKeyLength = 255
+
+// SetIdempotencyTTL changes the retention window of idempotency keys
+func (s *Service) SetIdempotencyTTL(ttl time.Duration) {
+	s.idempotencyTTL = ttl
+}
+
+// CreateJobIdempotent behaves like CreateJob, except that repeating a request
+// with the same key within the retention window returns the job created the
+// first time instead of a new one. replayed reports whether that happened.
+// Reusing a key for a different request fails with ErrIdempotencyConflict.
+func (s *Service) CreateJobIdempotent(ctx context.Context, key string, job models.Job) (created *models.Job, replayed bool, err error) {
+	if key == "" {
+		created, err = s.CreateJob(ctx, job)
+		return created, false, err
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return nil, false, fmt.Errorf("%w: idempotency key must be at most %d characters", ErrInvalidRequest, MaxIdempotencyKeyLength)
+	}
+
+	fingerprint, err := requestFingerprint(job)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, claimed, err := s.jobs.ClaimIdempotencyKey(ctx, key, redis.IdempotencyRecord{Fingerprint: fingerprint}, IdempotencyClaimLease)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !claimed {
+		if existing.Fingerprint != fingerprint {
+			return nil, false, fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+		}
+		if existing.JobID == "" {
+			return nil, false, fmt.Errorf("%w: %s", ErrRequestInProgress, key)
+		}
+
+		original, err := s.jobs.GetJob(ctx, existing.JobID)
+		if err != nil {
+			return nil, false, err
+		}
+		log.Info("replayed idempotent job submission", "job_id", original.ID, "idempotency_key", key)
+		return original, true, nil
+	}
+
+	created, err = s.CreateJob(ctx, job)
+	if err != nil {
+		// Nothing was created, let the client retry with the same key
+		if releaseErr := s.jobs.ReleaseIdempotencyKey(context.WithoutCancel(ctx), key); releaseErr != nil {
+			log.Warn("failed to release idempotency key", "idempotency_key", key, "err", releaseErr)
+		}
+		return nil, false, err
+	}
+
+	record := redis.IdempotencyRecord{Fingerprint: fingerprint, JobID: created.ID}
+	if err := s.jobs.CompleteIdempotencyKey(context.WithoutCancel(ctx), key, record, s.idempotencyTTL); err != nil {
+		log.Warn("failed to record job of idempotency key", "job_id", created.ID, "idempotency_key", key, "err", err)
+	}
+
+	return created, false, nil
+}
+
+// requestFingerprint hashes the parts of a job a client submits, the request
+// ID is left out since retries often carry a new one
+func requestFingerprint(job models.Job) (string, error) {
+	job.RequestID = ""
+
+	// The webhook is not part of the job's JSON, hash it along with it
+	data, err := json.Marshal(struct {
+		models.Job
+		Webhook *models.Webhook `json:"webhook,omitempty"`
+	}{job, job.Webhook})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint request: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
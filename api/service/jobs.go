@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Limits on a grep request, kept in sync with the OpenAPI spec
+const (
+	MaxFiles            = submit.MaxFiles
+	MaxContextLines     = submit.MaxContextLines
+	MaxListLimit        = 100
+	DefaultResultsLimit = 50
+	MaxResultsLimit     = 100
+	MinRetention        = submit.MinRetention
+	MaxRetention        = submit.MaxRetention
+)
+
+// JobResults is a job together with its stats and one page of its matches,
+// or of its file counts for jobs in a counting mode
+type JobResults struct {
+	Job           *models.Job
+	Stats         *models.JobStats
+	Matches       []models.Match
+	Counts        []FileCount
+	Total         int  // Matches or files found so far across all pages
+	QueuePosition *int // Jobs ahead in the dispatch queue, nil once every chunk was dispatched
+}
+
+// CreateJob validates the requested job, resolves its files, admits it
+// against the limits of the caller, persists it and hands it over to the
+// manager, see submit.Submitter.Create
+func (s *Service) CreateJob(ctx context.Context, job models.Job) (*models.Job, error) {
+	return s.submit.Create(ctx, job)
+}
+
+// GetJob returns the job together with its current stats
+func (s *Service) GetJob(ctx context.Context, jobID string) (*models.Job, *models.JobStats, error) {
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats, err := s.jobs.GetStats(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return job, stats, nil
+}
+
+// GetJobResults returns the job, its stats and one page of its matches, or of
+// its file counts in a counting mode. The page is checked before the job is
+// looked up.
+func (s *Service) GetJobResults(ctx context.Context, jobID string, page, limit int) (*JobResults, error) {
+	if err := validatePage(page, limit); err != nil {
+		return nil, err
+	}
+
+	job, stats, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &JobResults{Job: job, Stats: stats}
+	if res.QueuePosition, err = s.queuePosition(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if job.Mode.Counts() {
+		res.Counts, res.Total, err = s.FileCounts(ctx, jobID, page, limit)
+	} else {
+		res.Matches, res.Total, err = s.Results(ctx, jobID, page, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// queuePosition returns the number of jobs ahead of job in the dispatch
+// queue, nil if none of its chunks are waiting to be dispatched
+func (s *Service) queuePosition(ctx context.Context, job *models.Job) (*int, error) {
+	if job.Status.IsTerminal() {
+		return nil, nil
+	}
+
+	position, ok, err := s.jobs.QueuePosition(ctx, job.ID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// Results returns one page of the matches found so far for a job, ordered by
+// file and line number, along with the total number of matches
+func (s *Service) Results(ctx context.Context, jobID string, page, limit int) ([]models.Match, int, error) {
+	if err := validatePage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer metrics.ObserveReduce(time.Now())
+	results, err := s.storage.GetJobResults(ctx, jobID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get results for job %s: %w", jobID, err)
+	}
+
+	var matches []models.Match
+	for _, result := range results {
+		matches = append(matches, result.Matches...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matchLess(matches[i], matches[j])
+	})
+	matches = capPerFile(matches, job.MaxCount)
+
+	return paginate(matches, page, limit), len(matches), nil
+}
+
+// ListJobs returns a page of jobs matching the query, newest first
+func (s *Service) ListJobs(ctx context.Context, query redis.JobQuery) (*redis.JobPage, error) {
+	if query.Limit < 1 || query.Limit > MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxListLimit)
+	}
+	if !query.CreatedAfter.IsZero() && !query.CreatedBefore.IsZero() && !query.CreatedAfter.Before(query.CreatedBefore) {
+		return nil, fmt.Errorf("%w: created_after must be before created_before", ErrInvalidRequest)
+	}
+
+	page, err := s.jobs.ListJobs(ctx, query)
+	if errors.Is(err, redis.ErrInvalidCursor) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return page, err
+}
+
+// CancelJob marks a job as cancelled and tells the pipeline to stop working on
+// it, ErrJobFinished is returned if the job already reached a final state
+func (s *Service) CancelJob(ctx context.Context, jobID string) (*models.Job, error) {
+	job, err := s.jobs.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if job.Status.IsTerminal() {
+			return fmt.Errorf("%w: job %s is %s", ErrJobFinished, job.ID, job.Status)
+		}
+
+		now := time.Now().UTC()
+		job.Status = models.JobStatusCancelled
+		job.IsCancelled = true
+		job.CompletedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The job state is authoritative, workers that miss this message still
+	// stop once they see the cancelled job
+	if err := s.bus.Publish(ctx, nats.SubjectJobCancel, models.CancelMessage{JobID: jobID, Tenant: tenant.FromContext(ctx)}); err != nil {
+		log.Warn("failed to broadcast job cancellation", "job_id", jobID, "err", err)
+	}
+	s.jobFinished(ctx, job)
+
+	log.Info("job cancelled", "job_id", jobID)
+	return job, nil
+}
+
+func validatePage(page, limit int) error {
+	if page < 1 || limit < 1 || limit > MaxResultsLimit {
+		return fmt.Errorf("%w: page must be >= 1 and limit between 1 and %d", ErrInvalidRequest, MaxResultsLimit)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+)
+
+// ErrQuotaExceeded is wrapped by every LimitError
+var ErrQuotaExceeded = submit.ErrQuotaExceeded
+
+// Codes of the limit a job submission ran into
+const (
+	LimitJobRate     = submit.LimitJobRate
+	LimitRunningJobs = submit.LimitRunningJobs
+	LimitScanBytes   = submit.LimitScanBytes
+)
+
+// Limits bound what a caller may submit, zero disables a limit. Usage is
+// counted per API key, a tenant with several keys is held to the limits of
+// each of them separately.
+type Limits = models.Limits
+
+// LimitError reports which limit refused a job and when to try again
+type LimitError = submit.LimitError
+
+// WithLimits returns a copy of ctx carrying the limits of the caller, they
+// take precedence over the default limits of the service
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return submit.WithLimits(ctx, limits)
+}
+
+// SetDefaultLimits sets the limits of callers without limits of their own
+func (s *Service) SetDefaultLimits(limits Limits) {
+	s.submit.SetDefaultLimits(limits)
+}
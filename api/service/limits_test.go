@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestCreateJobLimits(t *testing.T) {
+	req := models.Job{Pattern: "error", Files: []string{"logs/app.log"}}
+
+	limitCode := func(t *testing.T, err error) string {
+		t.Helper()
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr), "expected a LimitError, got %v", err)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Greater(t, limitErr.RetryAfter, time.Duration(0))
+		return limitErr.Code
+	}
+
+	t.Run("JobsPerMinute", func(t *testing.T) {
+		svc, storage, _ := newTestService(t)
+		storage.AddFile("tenants/team-b/logs/app.log", "app\n")
+		svc.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC) }
+		ctx := WithLimits(context.Background(), Limits{JobsPerMinute: 2})
+
+		for i := 0; i < 2; i++ {
+			_, err := svc.CreateJob(ctx, req)
+			require.NoError(t, err)
+		}
+		_, err := svc.CreateJob(ctx, req)
+		assert.Equal(t, LimitJobRate, limitCode(t, err))
+
+		// Other tenants and other keys of the same tenant have their own
+		// counters
+		_, err = svc.CreateJob(tenant.NewContext(ctx, "team-b"), req)
+		assert.NoError(t, err)
+		_, err = svc.CreateJob(submit.WithKey(ctx, "key-b"), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("MaxRunningJobs", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+		svc.SetDefaultLimits(Limits{MaxRunningJobs: 1})
+		ctx := context.Background()
+
+		job, err := svc.CreateJob(ctx, req)
+		require.NoError(t, err)
+		_, err = svc.CreateJob(ctx, req)
+		assert.Equal(t, LimitRunningJobs, limitCode(t, err))
+
+		_, err = svc.CancelJob(ctx, job.ID)
+		require.NoError(t, err)
+		_, err = svc.CreateJob(ctx, req)
+		assert.NoError(t, err, "finished jobs should not count as running")
+
+		_, err = svc.CreateJob(submit.WithKey(ctx, "key-b"), req)
+		assert.NoError(t, err, "other keys should have their own slots")
+	})
+
+	t.Run("MaxRunningJobsConcurrently", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+		ctx := WithLimits(context.Background(), Limits{MaxRunningJobs: 3})
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		created := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := svc.CreateJob(ctx, req); err == nil {
+					mu.Lock()
+					created++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 3, created)
+	})
+
+	t.Run("BytesPerDay", func(t *testing.T) {
+		svc, storage, _ := newTestService(t)
+		storage.AddFile("logs/big.log", "0123456789")
+		svc.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+		ctx := WithLimits(context.Background(), Limits{BytesPerDay: 15})
+
+		_, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/big.log"}})
+		require.NoError(t, err)
+		_, err = svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/big.log"}})
+		assert.Equal(t, LimitScanBytes, limitCode(t, err))
+
+		// logs/app.log holds 4 bytes, which still fit
+		_, err = svc.CreateJob(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RefundOnFailure", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+		ctx := WithLimits(context.Background(), Limits{JobsPerMinute: 1})
+
+		bus.Err = errors.New("nats down")
+		_, err := svc.CreateJob(ctx, req)
+		require.Error(t, err)
+
+		bus.Err = nil
+		_, err = svc.CreateJob(ctx, req)
+		assert.NoError(t, err, "a job that failed to schedule should not use up the rate limit")
+	})
+}
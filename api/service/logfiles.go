@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/minio"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// FileFilter narrows down a log file listing, zero values disable a filter
+type FileFilter struct {
+	Prefix         string
+	MinSize        int64
+	MaxSize        int64
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+func (f FileFilter) matches(file models.LogFile) bool {
+	if f.MinSize > 0 && file.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && file.Size > f.MaxSize {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && file.UpdatedAt.Before(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !file.UpdatedAt.Before(f.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// UploadFile streams r into log storage at filePath, size is -1 when unknown.
+// An existing file at the same path is overwritten.
+func (s *Service) UploadFile(ctx context.Context, filePath string, size int64, r io.Reader) (*models.LogFile, error) {
+	if err := ValidateFilePath(filePath); err != nil {
+		return nil, err
+	}
+
+	logFile := models.LogFile{
+		Name: path.Base(filePath),
+		Path: filePath,
+		Size: size,
+	}
+	if err := s.storage.UploadLogFile(ctx, logFile, r); err != nil {
+		return nil, err
+	}
+
+	return s.storage.StatLogFile(ctx, filePath)
+}
+
+// ListFiles returns one page of the log files matching filter, ordered by
+// path, along with the total number of matching files
+func (s *Service) ListFiles(ctx context.Context, filter FileFilter, page, limit int) ([]models.LogFile, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("%w: page and limit must be positive", ErrInvalidRequest)
+	}
+	if filter.MaxSize > 0 && filter.MinSize > filter.MaxSize {
+		return nil, 0, fmt.Errorf("%w: min_size must not exceed max_size", ErrInvalidRequest)
+	}
+
+	files, err := s.storage.ListLogFilesWithPrefix(ctx, filter.Prefix)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list log files: %w", err)
+	}
+
+	// Object listings are already ordered by key
+	matched := files[:0]
+	for _, file := range files {
+		if filter.matches(file) {
+			matched = append(matched, file)
+		}
+	}
+
+	return paginate(matched, page, limit), len(matched), nil
+}
+
+// DeleteFile removes a log file, ErrFileNotFound if it does not exist
+func (s *Service) DeleteFile(ctx context.Context, filePath string) error {
+	if err := ValidateFilePath(filePath); err != nil {
+		return err
+	}
+
+	if _, err := s.storage.StatLogFile(ctx, filePath); err != nil {
+		if errors.Is(err, minio.ErrObjectNotFound) {
+			return fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+		}
+		return err
+	}
+
+	return s.storage.DeleteLogFile(ctx, filePath)
+}
+
+// ValidateFilePath checks that p is a clean, relative object path
+func ValidateFilePath(p string) error {
+	switch {
+	case p == "":
+		return fmt.Errorf("%w: path must not be empty", ErrInvalidRequest)
+	case strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/"):
+		return fmt.Errorf("%w: path %q must not start or end with /", ErrInvalidRequest, p)
+	case path.Clean(p) != p:
+		return fmt.Errorf("%w: path %q is not clean", ErrInvalidRequest, p)
+	case p == ".." || strings.HasPrefix(p, "../"):
+		return fmt.Errorf("%w: path %q escapes the log storage", ErrInvalidRequest, p)
+	}
+	return nil
+}
+
+// paginate returns the page-th slice of limit items, pages start at 1
+func paginate[T any](items []T, page, limit int) []T {
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return []T{}
+	}
+
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end]
+}
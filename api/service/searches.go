@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/schedule"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Limits on saved searches, kept in sync with the OpenAPI spec
+const (
+	MaxSearchNameLength    = 100
+	DefaultSearchRunsLimit = 20
+	MaxSearchRunsLimit     = 100
+)
+
+// CreateSearch validates and stores a saved search. Name, Schedule and the
+// job template fields are taken from search, everything else is filled in by
+// the service. A scheduled search is run by the manager from its next tick on.
+func (s *Service) CreateSearch(ctx context.Context, search models.SavedSearch) (*models.SavedSearch, error) {
+	if err := s.validateSearch(ctx, &search); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	search.ID = ids.New("search")
+	search.Tenant = tenant.FromContext(ctx)
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	search.LastRunAt = nil
+	s.holdToCaller(ctx, &search)
+	if err := setNextRun(&search, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.jobs.SaveSearch(ctx, &search); err != nil {
+		return nil, err
+	}
+
+	log.Info("saved search created", "search_id", search.ID, "schedule", search.Schedule)
+	return &search, nil
+}
+
+// GetSearch returns a saved search
+func (s *Service) GetSearch(ctx context.Context, searchID string) (*models.SavedSearch, error) {
+	return s.jobs.GetSearch(ctx, searchID)
+}
+
+// ListSearches returns all saved searches, newest first
+func (s *Service) ListSearches(ctx context.Context) ([]*models.SavedSearch, error) {
+	return s.jobs.ListSearches(ctx)
+}
+
+// UpdateSearch replaces the name, schedule and job template of a saved search,
+// its next run is recalculated when the schedule changes
+func (s *Service) UpdateSearch(ctx context.Context, searchID string, search models.SavedSearch) (*models.SavedSearch, error) {
+	if err := s.validateSearch(ctx, &search); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	return s.jobs.UpdateSearch(ctx, searchID, func(stored *models.SavedSearch) error {
+		rescheduled := stored.Schedule != search.Schedule
+
+		stored.Name = search.Name
+		stored.Pattern = search.Pattern
+		stored.Patterns = search.Patterns
+		stored.Query = search.Query
+		stored.TimeRange = search.TimeRange
+		stored.Mode = search.Mode
+		stored.Priority = search.Priority
+		stored.Retention = search.Retention
+		stored.Files = search.Files
+		stored.CaseSensitive = search.CaseSensitive
+		stored.Regex = search.Regex
+		stored.ContextLines = search.ContextLines
+		stored.InvertMatch = search.InvertMatch
+		stored.WordRegexp = search.WordRegexp
+		stored.LineRegexp = search.LineRegexp
+		stored.OnlyMatching = search.OnlyMatching
+		stored.MaxCount = search.MaxCount
+		stored.Schedule = search.Schedule
+		s.holdToCaller(ctx, stored)
+		stored.UpdatedAt = now
+
+		if rescheduled {
+			return setNextRun(stored, now)
+		}
+		return nil
+	})
+}
+
+// DeleteSearch removes a saved search with its schedule and run history,
+// jobs it created are left alone
+func (s *Service) DeleteSearch(ctx context.Context, searchID string) error {
+	if err := s.jobs.DeleteSearch(ctx, searchID); err != nil {
+		return err
+	}
+
+	log.Info("saved search deleted", "search_id", searchID)
+	return nil
+}
+
+// RunSearch creates a job from a saved search right away, subject to the same
+// limits as any other job, and records it in the history of the search
+func (s *Service) RunSearch(ctx context.Context, searchID string) (*models.Job, error) {
+	search, err := s.jobs.GetSearch(ctx, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.CreateJob(ctx, search.Job())
+	if err != nil {
+		return nil, err
+	}
+
+	run := models.SearchRun{JobID: job.ID, Status: job.Status, CreatedAt: job.CreatedAt}
+	if err := s.jobs.SaveSearchRun(ctx, searchID, run); err != nil {
+		return nil, err
+	}
+
+	_, err = s.jobs.UpdateSearch(ctx, searchID, func(search *models.SavedSearch) error {
+		search.LastRunAt = &job.CreatedAt
+		return nil
+	})
+	if err != nil {
+		log.Warn("failed to record last run of search", "search_id", searchID, "err", err)
+	}
+
+	return job, nil
+}
+
+// SearchRuns returns up to limit runs of a saved search, newest first. Runs
+// of jobs that have not finished when last seen are brought up to date with
+// their job, finished runs stay in the history after their job is deleted.
+func (s *Service) SearchRuns(ctx context.Context, searchID string, limit int) ([]models.SearchRun, error) {
+	if limit < 1 || limit > MaxSearchRunsLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxSearchRunsLimit)
+	}
+
+	if _, err := s.jobs.GetSearch(ctx, searchID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.jobs.ListSearchRuns(ctx, searchID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].Status.IsTerminal() {
+			continue
+		}
+
+		run, err := s.refreshRun(ctx, runs[i])
+		if err != nil {
+			log.Warn("failed to refresh search run", "search_id", searchID, "job_id", runs[i].JobID, "err", err)
+			continue
+		}
+
+		if run.Status.IsTerminal() {
+			if err := s.jobs.SaveSearchRun(ctx, searchID, *run); err != nil {
+				log.Warn("failed to save search run", "search_id", searchID, "job_id", run.JobID, "err", err)
+			}
+		}
+		runs[i] = *run
+	}
+
+	return runs, nil
+}
+
+// finishRun records the final state of a job in the run of the saved search
+// it was created for, so the run keeps it once the job is deleted. Runs are
+// also brought up to date when they are listed, in case this message was
+// lost. Errors are only logged.
+func (s *Service) finishRun(ctx context.Context, jobID string) {
+	job, err := s.jobs.GetJob(ctx, jobID)
+	if err != nil {
+		log.Warn("failed to look up finished job", "job_id", jobID, "err", err)
+		return
+	}
+	if job.SearchID == "" || !job.Status.IsTerminal() {
+		return
+	}
+
+	run, err := s.jobs.GetSearchRun(ctx, job.SearchID, jobID)
+	if errors.Is(err, redis.ErrSearchRunNotFound) {
+		return
+	}
+	if err != nil {
+		log.Warn("failed to get search run", "search_id", job.SearchID, "job_id", jobID, "err", err)
+		return
+	}
+	if run.Status.IsTerminal() {
+		return
+	}
+
+	if run, err = s.refreshRun(ctx, *run); err != nil {
+		log.Warn("failed to refresh search run", "search_id", job.SearchID, "job_id", jobID, "err", err)
+		return
+	}
+	if err := s.jobs.SaveSearchRun(ctx, job.SearchID, *run); err != nil {
+		log.Warn("failed to save search run", "search_id", job.SearchID, "job_id", jobID, "err", err)
+	}
+}
+
+// refreshRun returns run updated with the current state of its job
+func (s *Service) refreshRun(ctx context.Context, run models.SearchRun) (*models.SearchRun, error) {
+	job, stats, err := s.GetJob(ctx, run.JobID)
+	if errors.Is(err, ErrJobNotFound) {
+		// Gone before it was seen finishing, there is nothing left to update
+		return &run, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = job.Status
+	run.CompletedAt = job.CompletedAt
+	run.TotalMatches = stats.TotalMatches
+	return &run, nil
+}
+
+func (s *Service) validateSearch(ctx context.Context, search *models.SavedSearch) error {
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" || len(search.Name) > MaxSearchNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidRequest, MaxSearchNameLength)
+	}
+
+	job := search.Job()
+	if err := submit.Validate(&job); err != nil {
+		return err
+	}
+
+	search.Schedule = strings.TrimSpace(search.Schedule)
+	if search.Schedule != "" {
+		if _, err := schedule.Parse(search.Schedule); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+	}
+
+	// Files are resolved again on every run, this only catches typos early
+	_, err := s.ResolveFiles(ctx, search.Files)
+	return err
+}
+
+// holdToCaller holds the scheduled runs of search to the limits of the
+// caller saving it, counted against its API key
+func (s *Service) holdToCaller(ctx context.Context, search *models.SavedSearch) {
+	limits := s.submit.LimitsFor(ctx)
+	search.Limits = &limits
+	search.KeyID = submit.KeyFromContext(ctx)
+}
+
+// setNextRun sets the next scheduled run of a search after now
+func setNextRun(search *models.SavedSearch, now time.Time) error {
+	search.NextRunAt = nil
+	if search.Schedule == "" {
+		return nil
+	}
+
+	next, err := schedule.Next(search.Schedule, now)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	search.NextRunAt = &next
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+)
+
+func TestCreateSearch(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t)
+	now := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.SetDefaultLimits(Limits{JobsPerMinute: 3})
+
+	search, err := svc.CreateSearch(ctx, models.SavedSearch{
+		Name:     " OOM kills ",
+		Pattern:  "OOMKilled",
+		Files:    []string{"logs/*.log"},
+		Schedule: "0 8 * * *",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, search.ID)
+	assert.Equal(t, "OOM kills", search.Name)
+	assert.Equal(t, []string{"logs/*.log"}, search.Files)
+	require.NotNil(t, search.NextRunAt)
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), *search.NextRunAt)
+	assert.Equal(t, &Limits{JobsPerMinute: 3}, search.Limits)
+
+	// Dropping the schedule turns it into an on demand search, runs are held
+	// to the limits of whoever saved it last
+	updated, err := svc.UpdateSearch(submit.WithKey(WithLimits(ctx, Limits{JobsPerMinute: 7}), "key-a"), search.ID, models.SavedSearch{Name: "OOM", Pattern: "OOMKilled", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+	assert.Equal(t, "OOM", updated.Name)
+	assert.Nil(t, updated.NextRunAt)
+	assert.Equal(t, &Limits{JobsPerMinute: 7}, updated.Limits)
+	assert.Equal(t, "key-a", updated.KeyID)
+
+	tests := []struct {
+		name   string
+		search models.SavedSearch
+		err    error
+	}{
+		{"MissingName", models.SavedSearch{Pattern: "x", Files: []string{"logs/app.log"}}, ErrInvalidRequest},
+		{"InvalidPattern", models.SavedSearch{Name: "n", Pattern: "(x", Regex: true, Files: []string{"logs/app.log"}}, ErrInvalidRequest},
+		{"InvalidSchedule", models.SavedSearch{Name: "n", Pattern: "x", Files: []string{"logs/app.log"}, Schedule: "every day"}, ErrInvalidRequest},
+		{"TooFrequent", models.SavedSearch{Name: "n", Pattern: "x", Files: []string{"logs/app.log"}, Schedule: "@every 10s"}, ErrInvalidRequest},
+		{"UnknownFile", models.SavedSearch{Name: "n", Pattern: "x", Files: []string{"nope.log"}}, ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateSearch(ctx, tt.search)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	ctx := context.Background()
+	svc, _, bus := newTestService(t)
+
+	search, err := svc.CreateSearch(ctx, models.SavedSearch{Name: "panics", Pattern: "panic:", Files: []string{"logs/*.log"}})
+	require.NoError(t, err)
+
+	job, err := svc.RunSearch(ctx, search.ID)
+	require.NoError(t, err)
+	assert.Equal(t, search.ID, job.SearchID)
+	assert.Equal(t, "panic:", job.Pattern)
+	assert.Equal(t, []string{"logs/app.log", "logs/error.log"}, job.Files)
+	assert.Len(t, bus.Messages(), 1)
+
+	got, err := svc.GetSearch(ctx, search.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastRunAt)
+	assert.Equal(t, job.CreatedAt, *got.LastRunAt)
+
+	runs, err := svc.SearchRuns(ctx, search.ID, DefaultSearchRunsLimit)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobStatusPending, runs[0].Status)
+	assert.False(t, runs[0].Scheduled)
+
+	// Finished runs pick up the matches of their job and keep them after
+	// the job is gone
+	require.NoError(t, svc.jobs.SetStats(ctx, job.ID, models.JobStats{TotalFiles: 2, TotalMatches: 3}))
+	completeJob(t, svc, job.ID)
+
+	runs, err = svc.SearchRuns(ctx, search.ID, DefaultSearchRunsLimit)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobStatusCompleted, runs[0].Status)
+	assert.Equal(t, 3, runs[0].TotalMatches)
+
+	require.NoError(t, svc.DeleteJob(ctx, job.ID))
+
+	runs, err = svc.SearchRuns(ctx, search.ID, DefaultSearchRunsLimit)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 3, runs[0].TotalMatches)
+
+	// A run finished by the manager is recorded before its job is deleted,
+	// even if it was never listed meanwhile
+	job, err = svc.RunSearch(ctx, search.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.jobs.SetStats(ctx, job.ID, models.JobStats{TotalFiles: 2, TotalMatches: 5}))
+	completeJob(t, svc, job.ID)
+
+	data, err := json.Marshal(models.JobFinishedMessage{JobID: job.ID, Status: models.JobStatusCompleted})
+	require.NoError(t, err)
+	svc.HandleJobFinishedMessage(data)
+	require.NoError(t, svc.DeleteJob(ctx, job.ID))
+
+	runs, err = svc.SearchRuns(ctx, search.ID, DefaultSearchRunsLimit)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, models.JobStatusCompleted, runs[0].Status)
+	assert.Equal(t, 5, runs[0].TotalMatches)
+
+	require.NoError(t, svc.DeleteSearch(ctx, search.ID))
+	_, err = svc.RunSearch(ctx, search.ID)
+	assert.ErrorIs(t, err, ErrSearchNotFound)
+	_, err = svc.SearchRuns(ctx, search.ID, DefaultSearchRunsLimit)
+	assert.ErrorIs(t, err, ErrSearchNotFound)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Errors returned by the service, transports map them onto their own status codes
+var (
+	ErrInvalidRequest  = submit.ErrInvalidRequest
+	ErrFileNotFound    = submit.ErrFileNotFound
+	ErrJobNotFound     = redis.ErrJobNotFound
+	ErrJobDeleted      = redis.ErrJobDeleted
+	ErrJobExpired      = redis.ErrJobExpired
+	ErrJobFinished     = errors.New("job already finished")
+	ErrJobIncomplete   = errors.New("job not completed")
+	ErrSearchNotFound  = redis.ErrSearchNotFound
+	ErrWebhookNotFound = redis.ErrWebhookNotFound
+
+	ErrIdempotencyConflict = errors.New("idempotency key reused with a different request")
+	ErrRequestInProgress   = errors.New("request with this idempotency key is still in progress")
+)
+
+// JobStore keeps job state, implemented by redis.Store
+type JobStore interface {
+	SaveJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	UpdateJob(ctx context.Context, jobID string, fn func(job *models.Job) error) (*models.Job, error)
+	SetStats(ctx context.Context, jobID string, stats models.JobStats) error
+	GetStats(ctx context.Context, jobID string) (*models.JobStats, error)
+	ListJobs(ctx context.Context, query redis.JobQuery) (*redis.JobPage, error)
+	ClaimIdempotencyKey(ctx context.Context, key string, record redis.IdempotencyRecord, ttl time.Duration) (*redis.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, record redis.IdempotencyRecord, ttl time.Duration) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	ConsumeQuota(ctx context.Context, name string, amount, limit int64, ttl time.Duration) (bool, error)
+	RefundQuota(ctx context.Context, name string, amount int64) error
+	AcquireJobSlot(ctx context.Context, name, jobID string, limit int64) (bool, error)
+	ReleaseJobSlot(ctx context.Context, name, jobID string) error
+	CountInFlightChunks(ctx context.Context, jobID string) (int, error)
+	AppendStreamResult(ctx context.Context, jobID, streamID, resultID string, ttl time.Duration) error
+	StreamResults(ctx context.Context, jobID, streamID string) ([]string, error)
+	TrimStreamResults(ctx context.Context, jobID, streamID string, n int) error
+	QueuePosition(ctx context.Context, jobID string) (int, bool, error)
+	ChunkSize(ctx context.Context) (int64, error)
+	PurgeJob(ctx context.Context, job *models.Job, tombstone redis.Tombstone, ttl time.Duration) error
+	SaveSearch(ctx context.Context, search *models.SavedSearch) error
+	GetSearch(ctx context.Context, searchID string) (*models.SavedSearch, error)
+	ListSearches(ctx context.Context) ([]*models.SavedSearch, error)
+	UpdateSearch(ctx context.Context, searchID string, fn func(search *models.SavedSearch) error) (*models.SavedSearch, error)
+	DeleteSearch(ctx context.Context, searchID string) error
+	SaveSearchRun(ctx context.Context, searchID string, run models.SearchRun) error
+	GetSearchRun(ctx context.Context, searchID, jobID string) (*models.SearchRun, error)
+	ListSearchRuns(ctx context.Context, searchID string, limit int) ([]models.SearchRun, error)
+	SaveWebhook(ctx context.Context, jobID string, hook *models.Webhook) error
+	GetWebhook(ctx context.Context, jobID string) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, jobID string, fn func(hook *models.Webhook) error) (*models.Webhook, error)
+	ListWebhookAttempts(ctx context.Context, jobID string) ([]models.WebhookAttempt, error)
+}
+
+// Storage gives access to log files and job results, implemented by minio.Storage
+type Storage interface {
+	UploadLogFile(ctx context.Context, logFile models.LogFile, reader io.Reader) error
+	ListLogFiles(ctx context.Context) ([]models.LogFile, error)
+	ListLogFilesWithPrefix(ctx context.Context, prefix string) ([]models.LogFile, error)
+	StatLogFile(ctx context.Context, path string) (*models.LogFile, error)
+	DeleteLogFile(ctx context.Context, path string) error
+	GetJobResults(ctx context.Context, jobID string) ([]models.Result, error)
+	WalkJobResults(ctx context.Context, jobID string, fn func(result models.Result) error) error
+	GetResult(ctx context.Context, jobID, resultID string) (*models.Result, error)
+	DeleteJob(ctx context.Context, jobID string) error
+}
+
+// Publisher sends messages to the other services, implemented by nats.Bus
+type Publisher interface {
+	Publish(ctx context.Context, subject string, msg any) error
+}
+
+// Service implements the grep job operations independent of any transport
+type Service struct {
+	jobs    JobStore
+	storage Storage
+	bus     Publisher
+	results *resultHub
+	submit  *submit.Submitter
+
+	streamInterval time.Duration
+	idempotencyTTL time.Duration
+	tombstoneTTL   time.Duration
+	drainTimeout   time.Duration
+	now            func() time.Time
+}
+
+// New returns a new Service
+func New(jobs JobStore, storage Storage, bus Publisher, log *logger.Logger) *Service {
+	InitLogger(log)
+
+	s := &Service{
+		jobs:    jobs,
+		storage: storage,
+		bus:     bus,
+		results: newResultHub(),
+		submit:  submit.New(jobs, storage, bus, log),
+
+		streamInterval: DefaultStreamInterval,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		tombstoneTTL:   DefaultTombstoneTTL,
+		drainTimeout:   DefaultDrainTimeout,
+		now:            time.Now,
+	}
+	s.submit.SetClock(func() time.Time { return s.now() })
+	s.submit.SetJobFinished(s.jobFinished)
+	return s
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/api/internal/fakes"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+)
+
+func newTestService(t *testing.T) (*Service, *fakes.Storage, *fakes.Bus) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	storage := fakes.NewStorage()
+	storage.AddFile("logs/app.log", "app\n")
+	storage.AddFile("logs/error.log", "error\n")
+	storage.AddFile("logs/2024/01/api.log", "api\n")
+	storage.AddFile("notes.txt", "notes\n")
+	bus := &fakes.Bus{}
+
+	return New(store, storage, bus, l), storage, bus
+}
+
+func TestCreateJob(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ResolvesFilesAndPublishes", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, models.Job{
+			RequestID: "req_1",
+			Pattern:   "error.*",
+			Files:     []string{"logs/*.log", "logs/app.log"},
+			Regex:     true,
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, job.ID)
+		assert.Equal(t, "req_1", job.RequestID)
+		assert.Equal(t, models.JobStatusPending, job.Status)
+		assert.Equal(t, []string{"logs/app.log", "logs/error.log"}, job.Files)
+
+		stored, stats, err := svc.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, job.Files, stored.Files)
+		assert.Equal(t, 2, stats.TotalFiles)
+
+		messages := bus.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, nats.SubjectJobs, messages[0].Subject)
+		assert.Equal(t, job.ID, messages[0].Msg.(models.JobMessage).ID)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		query := &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+			{Pattern: "timeout"},
+			{Pattern: "payment-service"},
+			{Op: models.QueryOpNot, Children: []models.Query{{Pattern: "healthcheck"}}},
+		}}
+		job, err := svc.CreateJob(ctx, models.Job{Query: query, Files: []string{"logs/app.log"}})
+		require.NoError(t, err)
+
+		stored, _, err := svc.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, query, stored.Query)
+		assert.Empty(t, stored.Pattern)
+		assert.Equal(t, query, bus.Messages()[0].Msg.(models.JobMessage).Query)
+	})
+
+	t.Run("TimeRange", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		since := time.Date(2024, 1, 2, 2, 10, 0, 0, time.UTC)
+		timeRange := &models.TimeRange{Since: &since, Formats: []models.TimestampFormat{models.TimestampNginx}}
+		job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", TimeRange: timeRange, Files: []string{"logs/app.log"}})
+		require.NoError(t, err)
+
+		stored, _, err := svc.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, timeRange, stored.TimeRange)
+		assert.Equal(t, timeRange, bus.Messages()[0].Msg.(models.JobMessage).TimeRange)
+	})
+
+	t.Run("FixedStrings", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		literals := []string{"198.51.100.7", "evil.example.com"}
+		job, err := svc.CreateJob(ctx, models.Job{Patterns: literals, Files: []string{"logs/app.log"}})
+		require.NoError(t, err)
+
+		stored, _, err := svc.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, literals, stored.Patterns)
+		assert.Empty(t, stored.Pattern)
+		assert.Equal(t, literals, bus.Messages()[0].Msg.(models.JobMessage).Patterns)
+	})
+
+	t.Run("WhitespacePattern", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, models.Job{Pattern: " ", Files: []string{"logs/app.log"}})
+		require.NoError(t, err)
+		assert.Equal(t, " ", job.Pattern)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		query := &models.Query{Pattern: "x"}
+		since, until := time.Date(2024, 1, 2, 2, 40, 0, 0, time.UTC), time.Date(2024, 1, 2, 2, 10, 0, 0, time.UTC)
+		invalid := []models.Job{
+			{Pattern: "x", Files: []string{"logs/app.log"}, TimeRange: &models.TimeRange{Since: &since, Until: &until}},
+			{Pattern: "x", Files: []string{"logs/app.log"}, TimeRange: &models.TimeRange{Formats: []models.TimestampFormat{"iso"}}},
+			{Pattern: "x", Files: []string{"logs/app.log"}, MaxCount: -1},
+			{Pattern: "x", Files: []string{"logs/app.log"}, OnlyMatching: true, ContextLines: 2},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Mode: "lines_only"},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Priority: "urgent"},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Retention: 30 * time.Second},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Retention: MaxRetention + time.Hour},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Retention: -time.Hour},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Mode: models.SearchModeCount, ContextLines: 2},
+			{Pattern: "x", Files: []string{"logs/app.log"}, Mode: models.SearchModeFilesWithMatches, OnlyMatching: true},
+			{Pattern: "", Files: []string{"logs/app.log"}},
+			{Pattern: "x", Query: query, Files: []string{"logs/app.log"}},
+			{Pattern: "x", Patterns: []string{"y"}, Files: []string{"logs/app.log"}},
+			{Patterns: []string{"y"}, Query: query, Files: []string{"logs/app.log"}},
+			{Patterns: []string{}, Files: []string{"logs/app.log"}},
+			{Patterns: []string{"y", ""}, Files: []string{"logs/app.log"}},
+			{Patterns: []string{"y"}, Regex: true, Files: []string{"logs/app.log"}},
+			{Query: &models.Query{Op: models.QueryOpNot}, Files: []string{"logs/app.log"}},
+			{Query: &models.Query{Pattern: "a[b", Regex: true}, Files: []string{"logs/app.log"}},
+			{Pattern: "x"},
+			{Pattern: "x", Files: make([]string, MaxFiles+1)},
+			{Pattern: "x", Files: []string{"logs/app.log"}, ContextLines: MaxContextLines + 1},
+			{Pattern: "a[b", Files: []string{"logs/app.log"}, Regex: true},
+		}
+
+		for _, job := range invalid {
+			_, err := svc.CreateJob(ctx, job)
+			assert.ErrorIs(t, err, ErrInvalidRequest)
+		}
+	})
+
+	t.Run("UnknownFile", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		_, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/missing.log"}})
+		assert.ErrorIs(t, err, ErrFileNotFound)
+		assert.Empty(t, bus.Messages())
+	})
+
+	t.Run("PublishFailureFailsJob", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+		bus.Err = errors.New("nats down")
+
+		_, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestResults(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/*.log"}})
+	require.NoError(t, err)
+
+	storage.AddResult(models.Result{JobID: job.ID, Matches: []models.Match{
+		{FileName: "logs/error.log", LineNumber: 2},
+		{FileName: "logs/app.log", LineNumber: 9},
+	}})
+	storage.AddResult(models.Result{JobID: job.ID, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3},
+	}})
+
+	matches, total, err := svc.Results(ctx, job.ID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3},
+		{FileName: "logs/app.log", LineNumber: 9},
+	}, matches)
+
+	matches, _, err = svc.Results(ctx, job.ID, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Match{{FileName: "logs/error.log", LineNumber: 2}}, matches)
+
+	matches, _, err = svc.Results(ctx, job.ID, 3, 2)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, _, err = svc.Results(ctx, "missing", 1, 2)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestResultsMaxCount(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/*.log"}, MaxCount: 2})
+	require.NoError(t, err)
+
+	// Each chunk stops at the limit on its own, later chunks must be trimmed
+	storage.AddResult(models.Result{JobID: job.ID, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 900},
+		{FileName: "logs/app.log", LineNumber: 901},
+	}})
+	storage.AddResult(models.Result{JobID: job.ID, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3},
+		{FileName: "logs/app.log", LineNumber: 7},
+		{FileName: "logs/error.log", LineNumber: 1},
+	}})
+
+	matches, total, err := svc.Results(ctx, job.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3},
+		{FileName: "logs/app.log", LineNumber: 7},
+		{FileName: "logs/error.log", LineNumber: 1},
+	}, matches)
+}
+
+func TestFileCounts(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		mode     models.SearchMode
+		maxCount int
+		want     []FileCount
+	}{
+		{"Count", models.SearchModeCount, 0, []FileCount{{"logs/2024/01/api.log", 0}, {"logs/app.log", 7}, {"logs/error.log", 1}}},
+		{"CountMaxCount", models.SearchModeCount, 5, []FileCount{{"logs/2024/01/api.log", 0}, {"logs/app.log", 5}, {"logs/error.log", 1}}},
+		{"FilesWithMatches", models.SearchModeFilesWithMatches, 0, []FileCount{{"logs/app.log", 1}, {"logs/error.log", 1}}},
+		{"FilesWithoutMatch", models.SearchModeFilesWithoutMatch, 0, []FileCount{{"logs/2024/01/api.log", 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, storage, _ := newTestService(t)
+
+			job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/**"}, Mode: tt.mode, MaxCount: tt.maxCount})
+			require.NoError(t, err)
+
+			// Two chunks of app.log, api.log has no selected line
+			storage.AddResult(models.Result{JobID: job.ID, Counts: map[string]int{"logs/app.log": 4}})
+			storage.AddResult(models.Result{JobID: job.ID, Counts: map[string]int{"logs/app.log": 3, "logs/error.log": 1}})
+
+			res, err := svc.GetJobResults(ctx, job.ID, 1, 10)
+			require.NoError(t, err)
+			assert.Empty(t, res.Matches)
+			assert.Equal(t, tt.want, res.Counts)
+			assert.Equal(t, len(tt.want), res.Total)
+		})
+	}
+
+	t.Run("Paginates", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/**"}, Mode: models.SearchModeCount})
+		require.NoError(t, err)
+
+		counts, total, err := svc.FileCounts(ctx, job.ID, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []FileCount{{"logs/error.log", 0}}, counts)
+	})
+}
+
+func TestQueuePosition(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t)
+	store := svc.jobs.(*redis.Store)
+
+	batch, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}, Priority: models.PriorityBatch})
+	require.NoError(t, err)
+	urgent, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}, Priority: models.PriorityInteractive})
+	require.NoError(t, err)
+
+	res, err := svc.GetJobResults(ctx, batch.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Nil(t, res.QueuePosition)
+
+	// The manager queues jobs once it split them into chunks
+	require.NoError(t, store.EnqueueJob(ctx, batch))
+	require.NoError(t, store.EnqueueJob(ctx, urgent))
+
+	res, err = svc.GetJobResults(ctx, batch.ID, 1, 10)
+	require.NoError(t, err)
+	require.NotNil(t, res.QueuePosition)
+	assert.Equal(t, 1, *res.QueuePosition)
+
+	res, err = svc.GetJobResults(ctx, urgent.ID, 1, 10)
+	require.NoError(t, err)
+	require.NotNil(t, res.QueuePosition)
+	assert.Equal(t, 0, *res.QueuePosition)
+
+	// Finished jobs are not waiting for anything, even before the manager
+	// dequeued them
+	_, err = svc.CancelJob(ctx, batch.ID)
+	require.NoError(t, err)
+	res, err = svc.GetJobResults(ctx, batch.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Nil(t, res.QueuePosition)
+}
+
+func TestCancelJob(t *testing.T) {
+	ctx := context.Background()
+	svc, _, bus := newTestService(t)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	cancelled, err := svc.CancelJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCancelled, cancelled.Status)
+	assert.True(t, cancelled.IsCancelled)
+	assert.NotNil(t, cancelled.CompletedAt)
+
+	messages := bus.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, nats.SubjectJobCancel, messages[1].Subject)
+	assert.Equal(t, models.CancelMessage{JobID: job.ID}, messages[1].Msg)
+	assert.Equal(t, nats.SubjectJobFinished, messages[2].Subject)
+
+	_, err = svc.CancelJob(ctx, job.ID)
+	assert.ErrorIs(t, err, ErrJobFinished)
+
+	_, err = svc.CancelJob(ctx, "missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestValidateFilePath(t *testing.T) {
+	valid := []string{"app.log", "logs/app.log", "logs/2024/01/api.log", "logs/..hidden"}
+	for _, p := range valid {
+		assert.NoError(t, ValidateFilePath(p), p)
+	}
+
+	invalid := []string{"", "/logs/app.log", "logs/", "logs//app.log", "logs/./app.log", "../app.log", "logs/../../app.log", ".."}
+	for _, p := range invalid {
+		assert.ErrorIs(t, ValidateFilePath(p), ErrInvalidRequest, p)
+	}
+}
+
+func TestCreateJobIdempotent(t *testing.T) {
+	ctx := context.Background()
+	svc, _, bus := newTestService(t)
+
+	req := models.Job{Pattern: "error", Files: []string{"logs/app.log"}, RequestID: "req_1"}
+
+	first, replayed, err := svc.CreateJobIdempotent(ctx, "key", req)
+	require.NoError(t, err)
+	assert.False(t, replayed)
+
+	req.RequestID = "req_2"
+	again, replayed, err := svc.CreateJobIdempotent(ctx, "key", req)
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Len(t, bus.Messages(), 1, "a replayed submission should not schedule the job again")
+
+	req.ContextLines = 2
+	_, _, err = svc.CreateJobIdempotent(ctx, "key", req)
+	assert.ErrorIs(t, err, ErrIdempotencyConflict)
+
+	// A key claimed by a submission that has not created its job yet
+	fingerprint, err := requestFingerprint(req)
+	require.NoError(t, err)
+	_, _, err = svc.jobs.ClaimIdempotencyKey(ctx, "pending", redis.IdempotencyRecord{Fingerprint: fingerprint}, time.Minute)
+	require.NoError(t, err)
+	_, _, err = svc.CreateJobIdempotent(ctx, "pending", req)
+	assert.ErrorIs(t, err, ErrRequestInProgress)
+
+	// A submission that failed releases its key for the retry
+	_, _, err = svc.CreateJobIdempotent(ctx, "failed", models.Job{Pattern: "error", Files: []string{"logs/gone.log"}})
+	assert.ErrorIs(t, err, ErrFileNotFound)
+	_, replayed, err = svc.CreateJobIdempotent(ctx, "failed", req)
+	require.NoError(t, err)
+	assert.False(t, replayed)
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Event types pushed to job streams
+const (
+	EventMatch  = "match"  // a single match, carries a resumable ID
+	EventStats  = "stats"  // periodic job status and stats
+	EventStatus = "status" // final job status, last event of a stream
+)
+
+// DefaultStreamInterval is how often stats events are sent on a job stream
+const DefaultStreamInterval = 2 * time.Second
+
+// streamTTL is how long a stream can be resumed after it sent its last result
+const streamTTL = 24 * time.Hour
+
+// watchBuffer is the number of result messages a stream may fall behind
+// before it falls back to reading results from storage
+const watchBuffer = 256
+
+// StreamEvent is a single event of a job stream
+type StreamEvent struct {
+	ID    string           // Resume token, only set for match events
+	Type  string           // One of the Event* constants
+	Match *models.Match    // Set for match events
+	Job   *models.Job      // Set for stats and status events
+	Stats *models.JobStats // Set for stats and status events
+}
+
+// resultWatch receives the result messages of one job
+type resultWatch struct {
+	jobID  string
+	ch     chan models.ResultMessage
+	lagged atomic.Bool
+}
+
+// resultHub fans result messages out to the streams watching their job
+type resultHub struct {
+	mu      sync.RWMutex
+	watches map[string]map[*resultWatch]struct{}
+}
+
+func newResultHub() *resultHub {
+	return &resultHub{watches: make(map[string]map[*resultWatch]struct{})}
+}
+
+func (h *resultHub) watch(jobID string) *resultWatch {
+	w := &resultWatch{
+		jobID: jobID,
+		ch:    make(chan models.ResultMessage, watchBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.watches[jobID] == nil {
+		h.watches[jobID] = make(map[*resultWatch]struct{})
+	}
+	h.watches[jobID][w] = struct{}{}
+
+	return w
+}
+
+func (h *resultHub) unwatch(w *resultWatch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.watches[w.jobID], w)
+	if len(h.watches[w.jobID]) == 0 {
+		delete(h.watches, w.jobID)
+	}
+}
+
+// dispatch never blocks, a watch that cannot keep up is flagged as lagged
+// and catches up from storage instead
+func (h *resultHub) dispatch(msg models.ResultMessage) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for w := range h.watches[msg.JobID] {
+		select {
+		case w.ch <- msg:
+		default:
+			w.lagged.Store(true)
+		}
+	}
+}
+
+// HandleResultMessage feeds a models.ResultMessage published by a mapper to
+// the streams watching its job, which read the result from storage. Subscribe
+// it to nats.SubjectResults.
+func (s *Service) HandleResultMessage(data []byte) {
+	var msg models.ResultMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed result message", "err", err)
+		return
+	}
+
+	s.results.dispatch(msg)
+}
+
+// streamCursor identifies the last match a client received
+type streamCursor struct {
+	streamID string // Empty for tokens without a stream
+	resultID string
+	index    int
+}
+
+// matchEventID builds the resume token of the index-th match of a result
+// sent on a stream
+func matchEventID(streamID, resultID string, index int) string {
+	return streamID + ":" + resultID + ":" + strconv.Itoa(index)
+}
+
+func parseEventID(id string) (*streamCursor, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	sep := strings.LastIndex(id, ":")
+	if sep <= 0 {
+		return nil, fmt.Errorf("%w: malformed Last-Event-ID %q", ErrInvalidRequest, id)
+	}
+
+	index, err := strconv.Atoi(id[sep+1:])
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("%w: malformed Last-Event-ID %q", ErrInvalidRequest, id)
+	}
+
+	cursor := &streamCursor{resultID: id[:sep], index: index}
+	if stream, result, ok := strings.Cut(cursor.resultID, ":"); ok {
+		cursor.streamID, cursor.resultID = stream, result
+	}
+	return cursor, nil
+}
+
+// streamState is what a stream already sent
+type streamState struct {
+	id       string          // Stream ID, the order results were sent in is kept under it
+	sent     map[string]bool // Result IDs
+	perFile  map[string]int  // Matches per file
+	maxCount int             // Matches per file a stream sends, 0 for no limit
+}
+
+func newStreamState(maxCount int) *streamState {
+	return &streamState{
+		id:       ids.New("stream"),
+		sent:     make(map[string]bool),
+		perFile:  make(map[string]int),
+		maxCount: maxCount,
+	}
+}
+
+// StreamJob pushes the matches of a job to emit as they are found, along with
+// periodic stats, until the job finishes or ctx is done. Matches already in
+// storage are replayed first, lastEventID resumes right after that match.
+// Like GetJobResults a stream sends at most MaxCount matches per file, though
+// they are the first ones found rather than the first ones of the file.
+//
+// Mappers store a result before publishing it, so every result is either
+// replayed from storage or delivered live. Live results arrive in no
+// particular order, so the order a stream sent results in is recorded and a
+// resume point skips the results sent up to it, on the stream it came from.
+func (s *Service) StreamJob(ctx context.Context, jobID, lastEventID string, emit func(StreamEvent) error) error {
+	cursor, err := parseEventID(lastEventID)
+	if err != nil {
+		return err
+	}
+
+	job, stats, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	// Watch before replaying so nothing published in between is missed
+	w := s.results.watch(jobID)
+	defer s.results.unwatch(w)
+
+	state := newStreamState(job.MaxCount)
+	if err := s.replayResults(ctx, jobID, cursor, state, emit); err != nil {
+		return err
+	}
+
+	if err := emit(StreamEvent{Type: EventStats, Job: job, Stats: stats}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.streamInterval)
+	defer ticker.Stop()
+
+	for {
+		if job.Status.IsTerminal() {
+			// Pick up results stored after the last replay but never delivered live
+			if err := s.replayResults(ctx, jobID, nil, state, emit); err != nil {
+				return err
+			}
+			return emit(StreamEvent{Type: EventStatus, Job: job, Stats: stats})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case msg := <-w.ch:
+			if state.sent[msg.ID] {
+				continue
+			}
+			// Messages do not carry matches, the result is read from storage
+			result, err := s.storage.GetResult(ctx, jobID, msg.ID)
+			if err != nil {
+				log.Warn("failed to read live result, replaying from storage", "job_id", jobID, "result_id", msg.ID, "err", err)
+				w.lagged.Store(true)
+				continue
+			}
+			if err := s.emitResult(ctx, jobID, state, *result, emit); err != nil {
+				return err
+			}
+
+		case <-ticker.C:
+			if w.lagged.Swap(false) {
+				if err := s.replayResults(ctx, jobID, nil, state, emit); err != nil {
+					return err
+				}
+			}
+
+			if job, stats, err = s.GetJob(ctx, jobID); err != nil {
+				return err
+			}
+
+			if !job.Status.IsTerminal() {
+				if err := emit(StreamEvent{Type: EventStats, Job: job, Stats: stats}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// replayResults emits the stored results of a job that were not sent yet. A
+// cursor resumes its stream, the results that stream sent up to and
+// including the cursor's match are skipped.
+func (s *Service) replayResults(ctx context.Context, jobID string, cursor *streamCursor, state *streamState, emit func(StreamEvent) error) error {
+	results, err := s.storage.GetJobResults(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get results for job %s: %w", jobID, err)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.Before(results[j].CreatedAt)
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if cursor != nil {
+		if err := s.resumeStream(ctx, jobID, cursor, results, state, emit); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range results {
+		if err := s.emitResult(ctx, jobID, state, result, emit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resumeStream picks up the stream of a cursor: results it sent before the
+// cursor's are skipped, the cursor's result goes on after its match and
+// results it sent after that are sent again. An unknown or expired stream
+// leaves state untouched so that everything is replayed, duplicates beat lost
+// matches.
+func (s *Service) resumeStream(ctx context.Context, jobID string, cursor *streamCursor, results []models.Result, state *streamState, emit func(StreamEvent) error) error {
+	if cursor.streamID == "" {
+		return nil
+	}
+
+	sent, err := s.jobs.StreamResults(ctx, jobID, cursor.streamID)
+	if err != nil {
+		return err
+	}
+	pos := slices.Index(sent, cursor.resultID)
+	if pos < 0 {
+		return nil
+	}
+
+	byID := make(map[string]models.Result, len(results))
+	for _, result := range results {
+		byID[result.ID] = result
+	}
+	last, ok := byID[cursor.resultID]
+	if !ok {
+		return nil
+	}
+
+	// Results sent after the cursor's never reached the client
+	if err := s.jobs.TrimStreamResults(ctx, jobID, cursor.streamID, pos+1); err != nil {
+		return err
+	}
+	state.id = cursor.streamID
+
+	for _, id := range sent[:pos] {
+		if result, ok := byID[id]; ok {
+			state.skip(result, len(result.Matches))
+		}
+	}
+	return state.emitMatches(last, cursor.index+1, emit)
+}
+
+// emitResult records a result as sent on the stream and emits its matches,
+// unless it was already sent
+func (s *Service) emitResult(ctx context.Context, jobID string, state *streamState, result models.Result, emit func(StreamEvent) error) error {
+	if state.sent[result.ID] {
+		return nil
+	}
+	if err := s.jobs.AppendStreamResult(ctx, jobID, state.id, result.ID, streamTTL); err != nil {
+		return err
+	}
+	return state.emitMatches(result, 0, emit)
+}
+
+// skip marks a result as sent, along with its first n matches
+func (st *streamState) skip(result models.Result, n int) {
+	st.sent[result.ID] = true
+	for _, m := range result.Matches[:n] {
+		st.perFile[m.FileName]++
+	}
+}
+
+// emitMatches emits the matches of a result starting at from, matches of
+// files that reached maxCount are dropped
+func (st *streamState) emitMatches(result models.Result, from int, emit func(StreamEvent) error) error {
+	st.skip(result, min(from, len(result.Matches)))
+
+	for i := from; i < len(result.Matches); i++ {
+		file := result.Matches[i].FileName
+		if st.perFile[file]++; st.maxCount > 0 && st.perFile[file] > st.maxCount {
+			continue
+		}
+
+		err := emit(StreamEvent{
+			ID:    matchEventID(st.id, result.ID, i),
+			Type:  EventMatch,
+			Match: &result.Matches[i],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
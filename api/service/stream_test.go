@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func completeJob(t *testing.T, svc *Service, jobID string) {
+	_, err := svc.jobs.UpdateJob(context.Background(), jobID, func(job *models.Job) error {
+		job.Status = models.JobStatusCompleted
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func publishResult(t *testing.T, svc *Service, result models.Result) {
+	data, err := json.Marshal(result.Message(models.JobStats{}))
+	require.NoError(t, err)
+	svc.HandleResultMessage(data)
+}
+
+// collect runs StreamJob to completion and returns the emitted events
+func collect(t *testing.T, svc *Service, jobID, lastEventID string) []StreamEvent {
+	var events []StreamEvent
+	err := svc.StreamJob(context.Background(), jobID, lastEventID, func(ev StreamEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	require.NoError(t, err)
+	return events
+}
+
+// matchID strips the stream from the ID of a match event
+func matchID(id string) string {
+	_, match, _ := strings.Cut(id, ":")
+	return match
+}
+
+func matchIDs(events []StreamEvent) []string {
+	var ids []string
+	for _, ev := range events {
+		if ev.Type == EventMatch {
+			ids = append(ids, matchID(ev.ID))
+		}
+	}
+	return ids
+}
+
+// eventID returns the ID of the match event of a stream with the given
+// stripped ID
+func eventID(t *testing.T, events []StreamEvent, id string) string {
+	for _, ev := range events {
+		if ev.Type == EventMatch && matchID(ev.ID) == id {
+			return ev.ID
+		}
+	}
+	t.Fatalf("no match event %s", id)
+	return ""
+}
+
+func TestStreamJob(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	svc.streamInterval = 10 * time.Millisecond
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	now := time.Now()
+	storage.AddResult(models.Result{ID: "r1", JobID: job.ID, CreatedAt: now, Matches: []models.Match{{LineNumber: 1}, {LineNumber: 2}}})
+
+	events := make(chan StreamEvent, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.StreamJob(ctx, job.ID, "", func(ev StreamEvent) error {
+			events <- ev
+			return nil
+		})
+	}()
+
+	next := func() StreamEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for stream event")
+			return StreamEvent{}
+		}
+	}
+
+	// Replayed matches come first, followed by the initial stats
+	first := next()
+	assert.Equal(t, "r1:0", matchID(first.ID))
+	assert.Equal(t, "r1:1", matchID(next().ID))
+	assert.Equal(t, EventStats, next().Type)
+
+	live := models.Result{ID: "r2", JobID: job.ID, CreatedAt: now.Add(time.Second), Matches: []models.Match{{LineNumber: 7}}}
+	storage.AddResult(live)
+	publishResult(t, svc, live)
+
+	var ev StreamEvent
+	for ev = next(); ev.Type == EventStats; ev = next() {
+	}
+	assert.Equal(t, "r2:0", matchID(ev.ID))
+	assert.True(t, strings.HasPrefix(ev.ID, strings.Split(first.ID, ":")[0]+":"), "events of a stream should share its ID")
+	assert.Equal(t, 7, ev.Match.LineNumber)
+
+	// A result only found in storage is swept up before the final status
+	storage.AddResult(models.Result{ID: "r3", JobID: job.ID, CreatedAt: now.Add(2 * time.Second), Matches: []models.Match{{LineNumber: 9}}})
+	completeJob(t, svc, job.ID)
+
+	for ev = next(); ev.Type == EventStats; ev = next() {
+	}
+	assert.Equal(t, "r3:0", matchID(ev.ID))
+	ev = next()
+	assert.Equal(t, EventStatus, ev.Type)
+	assert.Equal(t, models.JobStatusCompleted, ev.Job.Status)
+
+	require.NoError(t, <-done)
+}
+
+func TestStreamJobResume(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	now := time.Now()
+	storage.AddResult(models.Result{ID: "r2", JobID: job.ID, CreatedAt: now.Add(time.Second), Matches: []models.Match{{}, {}}})
+	storage.AddResult(models.Result{ID: "r1", JobID: job.ID, CreatedAt: now, Matches: []models.Match{{}, {}}})
+	storage.AddResult(models.Result{ID: "r3", JobID: job.ID, CreatedAt: now.Add(2 * time.Second), Matches: []models.Match{{}}})
+	completeJob(t, svc, job.ID)
+
+	events := collect(t, svc, job.ID, "")
+	assert.Equal(t, []string{"r1:0", "r1:1", "r2:0", "r2:1", "r3:0"}, matchIDs(events))
+	assert.Equal(t, EventStatus, events[len(events)-1].Type)
+
+	assert.Equal(t, []string{"r2:1", "r3:0"}, matchIDs(collect(t, svc, job.ID, eventID(t, events, "r2:0"))))
+	assert.Equal(t, []string{"r3:0"}, matchIDs(collect(t, svc, job.ID, eventID(t, events, "r2:1"))))
+	assert.Empty(t, matchIDs(collect(t, svc, job.ID, eventID(t, events, "r3:0"))))
+
+	// A resumed stream can be resumed again
+	resumed := collect(t, svc, job.ID, eventID(t, events, "r1:0"))
+	assert.Equal(t, []string{"r1:1", "r2:0", "r2:1", "r3:0"}, matchIDs(resumed))
+	assert.Equal(t, []string{"r3:0"}, matchIDs(collect(t, svc, job.ID, eventID(t, resumed, "r2:1"))))
+
+	// An unknown resume point replays everything rather than losing matches
+	assert.Len(t, matchIDs(collect(t, svc, job.ID, "gone:4")), 5)
+	assert.Len(t, matchIDs(collect(t, svc, job.ID, "stream_gone:r2:0")), 5)
+
+	err = svc.StreamJob(ctx, job.ID, "garbage", func(StreamEvent) error { return nil })
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	err = svc.StreamJob(ctx, "missing", "", func(StreamEvent) error { return nil })
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestStreamJobLargeResult(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	svc.streamInterval = 10 * time.Millisecond
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	// More matches than fit in a NATS message, 1MB by default
+	matches := make([]models.Match, 20000)
+	for i := range matches {
+		matches[i] = models.Match{FileName: "logs/app.log", LineNumber: i + 1, Content: strings.Repeat("x", 100)}
+	}
+	big := models.Result{ID: "r1", JobID: job.ID, CreatedAt: time.Now(), Matches: matches, MatchCount: len(matches)}
+
+	data, err := json.Marshal(big.Message(models.JobStats{}))
+	require.NoError(t, err)
+	assert.Less(t, len(data), 1<<10)
+
+	var count int
+	stats := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.StreamJob(ctx, job.ID, "", func(ev StreamEvent) error {
+			switch ev.Type {
+			case EventMatch:
+				count++
+			case EventStats:
+				select {
+				case stats <- struct{}{}:
+				default:
+				}
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case <-stats:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for stream")
+	}
+
+	storage.AddResult(big)
+	publishResult(t, svc, big)
+	completeJob(t, svc, job.ID)
+
+	require.NoError(t, <-done)
+	assert.Equal(t, len(matches), count, "matches should be read from storage")
+}
+
+func TestStreamJobMaxCount(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/*.log"}, MaxCount: 1})
+	require.NoError(t, err)
+
+	now := time.Now()
+	storage.AddResult(models.Result{ID: "r1", JobID: job.ID, CreatedAt: now, Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 3},
+		{FileName: "logs/app.log", LineNumber: 7},
+		{FileName: "logs/error.log", LineNumber: 1},
+	}})
+	storage.AddResult(models.Result{ID: "r2", JobID: job.ID, CreatedAt: now.Add(time.Second), Matches: []models.Match{
+		{FileName: "logs/app.log", LineNumber: 900},
+		{FileName: "logs/error.log", LineNumber: 40},
+	}})
+	completeJob(t, svc, job.ID)
+
+	events := collect(t, svc, job.ID, "")
+	assert.Equal(t, []string{"r1:0", "r1:2"}, matchIDs(events))
+
+	// Matches the client received before resuming count towards the limit
+	assert.Equal(t, []string{"r1:2"}, matchIDs(collect(t, svc, job.ID, eventID(t, events, "r1:0"))))
+	assert.Empty(t, matchIDs(collect(t, svc, job.ID, eventID(t, events, "r1:2"))))
+}
+
+func TestStreamJobResumeOutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	svc.streamInterval = 10 * time.Millisecond
+
+	job, err := svc.CreateJob(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+	require.NoError(t, err)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan StreamEvent, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.StreamJob(streamCtx, job.ID, "", func(ev StreamEvent) error {
+			events <- ev
+			return nil
+		})
+	}()
+
+	// A result created later is delivered live before an earlier one is
+	// stored, the client disconnects in between
+	now := time.Now()
+	live := models.Result{ID: "r2", JobID: job.ID, CreatedAt: now.Add(time.Second), Matches: []models.Match{{LineNumber: 7}}}
+	storage.AddResult(live)
+	publishResult(t, svc, live)
+
+	var received string
+	for received == "" {
+		select {
+		case ev := <-events:
+			if ev.Type == EventMatch {
+				received = ev.ID
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for live match")
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	storage.AddResult(models.Result{ID: "r1", JobID: job.ID, CreatedAt: now, Matches: []models.Match{{LineNumber: 1}}})
+	completeJob(t, svc, job.ID)
+
+	assert.Equal(t, "r2:0", matchID(received))
+	assert.Equal(t, []string{"r1:0"}, matchIDs(collect(t, svc, job.ID, received)), "a result created earlier but never sent should not be skipped")
+}
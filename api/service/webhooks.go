@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Limits on a webhook, kept in sync with the OpenAPI spec
+const (
+	MaxWebhookURLLength    = submit.MaxWebhookURLLength
+	MaxWebhookSecretLength = submit.MaxWebhookSecretLength
+)
+
+// errWebhookQueued aborts queueing a webhook that already left WAITING
+var errWebhookQueued = errors.New("webhook already queued")
+
+// WebhookLog is the webhook of a job along with its delivery attempts
+type WebhookLog struct {
+	Webhook  *models.Webhook
+	Attempts []models.WebhookAttempt
+}
+
+// GetWebhook returns the webhook of a job and its delivery attempts
+func (s *Service) GetWebhook(ctx context.Context, jobID string) (*WebhookLog, error) {
+	if _, err := s.jobs.GetJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	hook, err := s.jobs.GetWebhook(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.jobs.ListWebhookAttempts(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookLog{Webhook: hook, Attempts: attempts}, nil
+}
+
+// HandleJobFinishedMessage queues the webhook of a job that finished for
+// delivery and records the run of its saved search as finished, subscribe it
+// to nats.SubjectJobFinished with a queue group
+func (s *Service) HandleJobFinishedMessage(data []byte) {
+	var msg models.JobFinishedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed job finished message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	s.queueWebhook(ctx, msg.JobID)
+	s.finishRun(ctx, msg.JobID)
+}
+
+// jobFinished is called whenever the service moves a job to a final status,
+// it queues the webhook of the job, finishes its search run and tells
+// everyone else about it
+func (s *Service) jobFinished(ctx context.Context, job *models.Job) {
+	metrics.ObserveJob(job.Status)
+	s.queueWebhook(ctx, job.ID)
+	s.finishRun(ctx, job.ID)
+
+	msg := models.JobFinishedMessage{JobID: job.ID, Tenant: tenant.FromContext(ctx), Status: job.Status}
+	if err := s.bus.Publish(ctx, nats.SubjectJobFinished, msg); err != nil {
+		log.Warn("failed to announce finished job", "job_id", job.ID, "err", err)
+	}
+}
+
+// queueWebhook makes the webhook of a job due for delivery, it is a no-op
+// for jobs without a webhook or with one that was queued before
+func (s *Service) queueWebhook(ctx context.Context, jobID string) {
+	now := s.now().UTC()
+	_, err := s.jobs.UpdateWebhook(ctx, jobID, func(hook *models.Webhook) error {
+		if hook.State != models.WebhookStateWaiting {
+			return errWebhookQueued
+		}
+		hook.State = models.WebhookStatePending
+		hook.NextAttemptAt = &now
+		return nil
+	})
+	if err != nil && !errors.Is(err, errWebhookQueued) && !errors.Is(err, ErrWebhookNotFound) {
+		log.Error("failed to queue webhook", "job_id", jobID, "err", err)
+	}
+}
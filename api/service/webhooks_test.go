@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+)
+
+func TestWebhook(t *testing.T) {
+	ctx := context.Background()
+	newJob := func(hook *models.Webhook) models.Job {
+		return models.Job{Pattern: "error", Files: []string{"logs/app.log"}, Webhook: hook}
+	}
+
+	t.Run("QueuedOnCancel", func(t *testing.T) {
+		svc, _, bus := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, newJob(&models.Webhook{URL: "https://example.com/hook", Secret: "s3cret"}))
+		require.NoError(t, err)
+
+		webhookLog, err := svc.GetWebhook(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStateWaiting, webhookLog.Webhook.State)
+		assert.Equal(t, "https://example.com/hook", webhookLog.Webhook.URL)
+		assert.Nil(t, webhookLog.Webhook.NextAttemptAt)
+		assert.Empty(t, webhookLog.Attempts)
+
+		_, err = svc.CancelJob(ctx, job.ID)
+		require.NoError(t, err)
+
+		webhookLog, err = svc.GetWebhook(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStatePending, webhookLog.Webhook.State)
+		assert.NotNil(t, webhookLog.Webhook.NextAttemptAt)
+
+		messages := bus.Messages()
+		last := messages[len(messages)-1]
+		assert.Equal(t, nats.SubjectJobFinished, last.Subject)
+		assert.Equal(t, models.JobFinishedMessage{JobID: job.ID, Status: models.JobStatusCancelled}, last.Msg)
+	})
+
+	t.Run("QueuedOnFinishedMessage", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, newJob(&models.Webhook{URL: "http://localhost:9999/hook", Secret: "s3cret"}))
+		require.NoError(t, err)
+
+		data, err := json.Marshal(models.JobFinishedMessage{JobID: job.ID, Status: models.JobStatusCompleted})
+		require.NoError(t, err)
+		svc.HandleJobFinishedMessage(data)
+
+		webhookLog, err := svc.GetWebhook(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStatePending, webhookLog.Webhook.State)
+		queuedAt := *webhookLog.Webhook.NextAttemptAt
+
+		// Redelivered messages leave the queued webhook alone
+		svc.HandleJobFinishedMessage(data)
+		webhookLog, err = svc.GetWebhook(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queuedAt, *webhookLog.Webhook.NextAttemptAt)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		job, err := svc.CreateJob(ctx, newJob(nil))
+		require.NoError(t, err)
+
+		_, err = svc.CancelJob(ctx, job.ID)
+		require.NoError(t, err)
+
+		_, err = svc.GetWebhook(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+		_, err = svc.GetWebhook(ctx, "grep_missing")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("PartOfIdempotencyFingerprint", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		_, _, err := svc.CreateJobIdempotent(ctx, "key", newJob(&models.Webhook{URL: "https://example.com/a", Secret: "s3cret"}))
+		require.NoError(t, err)
+
+		_, _, err = svc.CreateJobIdempotent(ctx, "key", newJob(&models.Webhook{URL: "https://example.com/b", Secret: "s3cret"}))
+		assert.ErrorIs(t, err, ErrIdempotencyConflict)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		svc, _, _ := newTestService(t)
+
+		invalid := []*models.Webhook{
+			{URL: "example.com/hook", Secret: "s3cret"},
+			{URL: "ftp://example.com/hook", Secret: "s3cret"},
+			{URL: "https:///hook", Secret: "s3cret"},
+			{URL: "https://example.com/hook"},
+			{URL: "https://example.com/" + string(make([]byte, MaxWebhookURLLength)), Secret: "s3cret"},
+		}
+
+		for _, hook := range invalid {
+			_, err := svc.CreateJob(ctx, newJob(hook))
+			assert.ErrorIs(t, err, ErrInvalidRequest, "webhook %+v", hook)
+		}
+	})
+}
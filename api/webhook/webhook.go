@@ -0,0 +1,430 @@
+// Package webhook delivers the events of finished jobs to their webhooks
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Headers sent with every delivery
+const (
+	EventHeader     = "X-Grep-Event"
+	DeliveryHeader  = "X-Grep-Delivery"
+	SignatureHeader = "X-Grep-Signature"
+)
+
+// Defaults of Options
+const (
+	DefaultMaxAttempts = 8
+	DefaultTimeout     = 10 * time.Second
+	DefaultBackoff     = 10 * time.Second
+	DefaultInterval    = time.Second
+)
+
+// MaxBackoff caps the delay between two attempts
+const MaxBackoff = time.Hour
+
+// batchSize is the number of due webhooks fetched and delivered per tick
+const batchSize = 100
+
+// workers is the number of deliveries made concurrently
+const workers = 8
+
+// ErrInvalidSignature is returned by Verify for deliveries that were not
+// signed with the secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrAddressNotPublic fails deliveries to loopback, link-local, private and
+// other addresses that are not reachable from the internet
+var ErrAddressNotPublic = errors.New("webhook address is not public")
+
+// errNotDue aborts claiming a webhook another dispatcher already claimed
+var errNotDue = errors.New("webhook not due")
+
+// Options tune the delivery of webhooks, zero values take the defaults
+type Options struct {
+	MaxAttempts int           `mapstructure:"maxAttempts"` // Attempts before a delivery is given up on
+	Timeout     time.Duration `mapstructure:"timeout"`     // Timeout of a single attempt
+	Backoff     time.Duration `mapstructure:"backoff"`     // Delay before the first retry, doubled on every further retry
+
+	// AllowPrivateNetworks lets webhooks reach addresses that are not
+	// public, for receivers next to the gateway. Tenants could otherwise use
+	// webhooks to reach internal services.
+	AllowPrivateNetworks bool `mapstructure:"allowPrivateNetworks"`
+}
+
+// Store keeps webhooks and jobs, implemented by redis.Store
+type Store interface {
+	DueWebhooks(ctx context.Context, now time.Time, limit int) ([]redis.PendingWebhook, error)
+	UpdateWebhook(ctx context.Context, jobID string, fn func(hook *models.Webhook) error) (*models.Webhook, error)
+	AddWebhookAttempt(ctx context.Context, jobID string, attempt models.WebhookAttempt) error
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	GetStats(ctx context.Context, jobID string) (*models.JobStats, error)
+}
+
+// Dispatcher POSTs the events of finished jobs to their webhooks and retries
+// failed deliveries with exponential backoff. Several dispatchers may run
+// against the same store, each attempt is made by exactly one of them.
+type Dispatcher struct {
+	store    Store
+	client   *http.Client
+	opts     Options
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New returns a new Dispatcher
+func New(store Store, opts Options, log *logger.Logger) *Dispatcher {
+	InitLogger(log)
+
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+
+	return &Dispatcher{
+		store:    store,
+		client:   newClient(opts),
+		opts:     opts,
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+}
+
+// newClient returns the client deliveries are made with. Redirects are not
+// followed, and addresses are checked as they are dialed, after resolution,
+// so that a host cannot resolve to a public address when the webhook is
+// created and to an internal one when it is delivered.
+func newClient(opts Options) *http.Client {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrAddressNotPublic, address)
+			}
+			if !publicAddr(addr.Addr()) {
+				return fmt.Errorf("%w: %s", ErrAddressNotPublic, addr.Addr())
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			// A proxy would dial the receiver on our behalf, unchecked
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: opts.Timeout,
+			MaxIdleConns:        workers,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// sharedAddressSpace is 100.64.0.0/10, used for carrier-grade NAT
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether addr is a unicast address reachable from the
+// internet
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	switch {
+	case !addr.IsGlobalUnicast(), addr.IsPrivate():
+		return false
+	case sharedAddressSpace.Contains(addr):
+		return false
+	default:
+		return true
+	}
+}
+
+// SetInterval changes how often the queue is checked for due webhooks
+func (d *Dispatcher) SetInterval(interval time.Duration) {
+	d.interval = interval
+}
+
+// Run delivers due webhooks every interval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	log.Info("webhook dispatcher started", "interval", d.interval, "max_attempts", d.opts.MaxAttempts)
+	for {
+		if _, err := d.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Error("failed to deliver webhooks", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick makes one attempt for every webhook that is due and returns how many
+// of them were delivered
+func (d *Dispatcher) Tick(ctx context.Context) (int, error) {
+	due, err := d.store.DueWebhooks(ctx, d.now().UTC(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		delivered int
+		sem       = make(chan struct{}, workers)
+	)
+	for _, pending := range due {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(pending redis.PendingWebhook) {
+			defer func() { <-sem; wg.Done() }()
+
+			ok := d.dispatch(tenant.NewContext(ctx, pending.Tenant), pending.JobID)
+			if ok {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(pending)
+	}
+	wg.Wait()
+
+	return delivered, nil
+}
+
+// dispatch claims the webhook of a job, attempts a delivery and records its
+// outcome, it reports whether the receiver acknowledged the event
+func (d *Dispatcher) dispatch(ctx context.Context, jobID string) bool {
+	now := d.now().UTC()
+	lease := now.Add(2 * d.opts.Timeout)
+	hook, err := d.store.UpdateWebhook(ctx, jobID, func(hook *models.Webhook) error {
+		if hook.State != models.WebhookStatePending || hook.NextAttemptAt == nil || hook.NextAttemptAt.After(now) {
+			return errNotDue
+		}
+		// A dispatcher that dies mid attempt leaves the webhook to be
+		// retried once the lease expired
+		hook.Attempts++
+		hook.NextAttemptAt = &lease
+		return nil
+	})
+	if errors.Is(err, errNotDue) || errors.Is(err, redis.ErrWebhookNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Error("failed to claim webhook", "job_id", jobID, "err", err)
+		return false
+	}
+
+	event, err := d.event(ctx, jobID)
+	if err != nil {
+		log.Error("failed to build webhook event", "job_id", jobID, "err", err)
+		return false
+	}
+
+	attempt := d.deliver(ctx, hook, event)
+	attempt.Attempt = hook.Attempts
+	if err := d.store.AddWebhookAttempt(ctx, jobID, attempt); err != nil {
+		log.Warn("failed to record webhook attempt", "job_id", jobID, "err", err)
+	}
+
+	_, err = d.store.UpdateWebhook(ctx, jobID, func(hook *models.Webhook) error {
+		finishedAt := attempt.AttemptedAt.Add(attempt.Duration)
+		switch {
+		case attempt.Succeeded():
+			hook.State = models.WebhookStateDelivered
+			hook.DeliveredAt = &finishedAt
+			hook.NextAttemptAt = nil
+		case !retryable(attempt.StatusCode) || hook.Attempts >= d.opts.MaxAttempts:
+			hook.State = models.WebhookStateFailed
+			hook.NextAttemptAt = nil
+		default:
+			next := finishedAt.Add(d.backoff(hook.Attempts))
+			hook.NextAttemptAt = &next
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to update webhook", "job_id", jobID, "err", err)
+	}
+
+	log.Info("webhook attempted", "job_id", jobID, "attempt", attempt.Attempt, "status_code", attempt.StatusCode, "err", attempt.Error)
+	return attempt.Succeeded()
+}
+
+// event builds the payload sent for a finished job
+func (d *Dispatcher) event(ctx context.Context, jobID string) (*models.WebhookEvent, error) {
+	job, err := d.store.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := d.store.GetStats(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookEvent{
+		ID:          "evt_" + job.ID,
+		Type:        "job." + strings.ToLower(string(job.Status)),
+		JobID:       job.ID,
+		RequestID:   job.RequestID,
+		Status:      job.Status,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		Stats:       *stats,
+	}, nil
+}
+
+// deliver POSTs a signed event to the webhook and reports how it went
+func (d *Dispatcher) deliver(ctx context.Context, hook *models.Webhook, event *models.WebhookEvent) models.WebhookAttempt {
+	attempt := models.WebhookAttempt{AttemptedAt: d.now().UTC()}
+	start := time.Now()
+
+	statusCode, err := d.post(ctx, hook, event, attempt.AttemptedAt)
+	attempt.Duration = time.Since(start)
+	attempt.StatusCode = statusCode
+	if err != nil {
+		attempt.Error = err.Error()
+	} else if !attempt.Succeeded() {
+		attempt.Error = "unexpected response " + strconv.Itoa(statusCode) + " " + http.StatusText(statusCode)
+	}
+
+	return attempt
+}
+
+// post sends an event signed at t and returns the status of the response
+func (d *Dispatcher) post(ctx context.Context, hook *models.Webhook, event *models.WebhookEvent, t time.Time) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, t, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.Backoff
+	for i := 1; i < attempts && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxBackoff)
+}
+
+// retryable reports whether an attempt that ended with the given status,
+// zero if no response was received, is worth retrying
+func retryable(statusCode int) bool {
+	switch {
+	case statusCode == 0, statusCode >= 500:
+		return true
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sign returns the X-Grep-Signature of a delivery made at t, t=<unix>,v1=<hex>
+// where hex is the HMAC-SHA256 of "<unix>.<body>" keyed with the secret
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, body)
+}
+
+// Verify checks the X-Grep-Signature of a delivery, it is meant for receivers
+// implemented in Go. Signatures older than tolerance are rejected unless
+// tolerance is zero.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: timestamp too old", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
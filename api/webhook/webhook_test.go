@@ -0,0 +1,328 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+const secret = "s3cret"
+
+// receiver is a webhook endpoint answering with the given statuses in turn,
+// the last one is repeated
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	events   []models.WebhookEvent
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	require.NoError(r.t, err)
+	assert.NoError(r.t, Verify(secret, req.Header.Get(SignatureHeader), body, time.Minute))
+
+	var event models.WebhookEvent
+	require.NoError(r.t, json.Unmarshal(body, &event))
+	assert.Equal(r.t, event.Type, req.Header.Get(EventHeader))
+	assert.Equal(r.t, event.ID, req.Header.Get(DeliveryHeader))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	status := r.statuses[min(len(r.events), len(r.statuses))-1]
+	w.WriteHeader(status)
+}
+
+func (r *receiver) received() []models.WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.WebhookEvent(nil), r.events...)
+}
+
+// setup saves a finished job with a webhook pointing at a receiver, which
+// listens on loopback and so is only reachable with AllowPrivateNetworks
+func setup(t *testing.T, opts Options, statuses ...int) (context.Context, *Dispatcher, *redis.Store, *receiver, *time.Time) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	recv := &receiver{t: t, statuses: statuses}
+	srv := httptest.NewServer(recv)
+	t.Cleanup(srv.Close)
+
+	ctx := tenant.NewContext(context.Background(), "team-a")
+	now := time.Now().UTC().Truncate(time.Second)
+	completedAt := now.Add(-time.Second)
+	require.NoError(t, store.SaveJob(ctx, &models.Job{
+		ID:          "grep_1",
+		RequestID:   "req_1",
+		Pattern:     "error",
+		Status:      models.JobStatusCompleted,
+		CreatedAt:   now.Add(-time.Minute),
+		CompletedAt: &completedAt,
+	}))
+	require.NoError(t, store.SetStats(ctx, "grep_1", models.JobStats{TotalFiles: 2, ProcessedFiles: 2, TotalMatches: 7}))
+	require.NoError(t, store.SaveWebhook(ctx, "grep_1", &models.Webhook{
+		URL:           srv.URL,
+		Secret:        secret,
+		State:         models.WebhookStatePending,
+		NextAttemptAt: &now,
+	}))
+
+	d := New(store, opts, l)
+	d.now = func() time.Time { return now }
+	return ctx, d, store, recv, &now
+}
+
+func TestTick(t *testing.T) {
+	t.Run("Delivered", func(t *testing.T) {
+		ctx, d, store, recv, _ := setup(t, Options{AllowPrivateNetworks: true}, http.StatusOK)
+
+		delivered, err := d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+
+		events := recv.received()
+		require.Len(t, events, 1)
+		assert.Equal(t, "evt_grep_1", events[0].ID)
+		assert.Equal(t, "job.completed", events[0].Type)
+		assert.Equal(t, "req_1", events[0].RequestID)
+		assert.Equal(t, models.JobStatusCompleted, events[0].Status)
+		assert.Equal(t, 7, events[0].Stats.TotalMatches)
+
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStateDelivered, hook.State)
+		assert.Equal(t, 1, hook.Attempts)
+		assert.NotNil(t, hook.DeliveredAt)
+		assert.Nil(t, hook.NextAttemptAt)
+
+		attempts, err := store.ListWebhookAttempts(ctx, "grep_1")
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Equal(t, 1, attempts[0].Attempt)
+		assert.Equal(t, http.StatusOK, attempts[0].StatusCode)
+		assert.Empty(t, attempts[0].Error)
+
+		// Nothing is left to deliver
+		delivered, err = d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Len(t, recv.received(), 1)
+	})
+
+	t.Run("RetriedWithBackoff", func(t *testing.T) {
+		ctx, d, store, recv, now := setup(t, Options{AllowPrivateNetworks: true, Backoff: time.Minute}, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+		delivered, err := d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStatePending, hook.State)
+		require.NotNil(t, hook.NextAttemptAt)
+		assert.WithinDuration(t, now.Add(time.Minute), *hook.NextAttemptAt, time.Second)
+
+		// Not due before the backoff passed
+		delivered, err = d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Len(t, recv.received(), 1)
+
+		*now = now.Add(time.Minute + time.Second)
+		_, err = d.Tick(ctx)
+		require.NoError(t, err)
+
+		hook, err = store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.WithinDuration(t, now.Add(2*time.Minute), *hook.NextAttemptAt, time.Second)
+
+		*now = now.Add(2*time.Minute + time.Second)
+		delivered, err = d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+
+		events := recv.received()
+		require.Len(t, events, 3)
+		assert.Equal(t, events[0].ID, events[2].ID)
+
+		attempts, err := store.ListWebhookAttempts(ctx, "grep_1")
+		require.NoError(t, err)
+		require.Len(t, attempts, 3)
+		assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			[]int{attempts[0].StatusCode, attempts[1].StatusCode, attempts[2].StatusCode})
+		assert.NotEmpty(t, attempts[0].Error)
+	})
+
+	t.Run("GivesUpOnClientError", func(t *testing.T) {
+		ctx, d, store, recv, _ := setup(t, Options{AllowPrivateNetworks: true}, http.StatusBadRequest)
+
+		_, err := d.Tick(ctx)
+		require.NoError(t, err)
+
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStateFailed, hook.State)
+		assert.Nil(t, hook.NextAttemptAt)
+		assert.Len(t, recv.received(), 1)
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		ctx, d, store, recv, now := setup(t, Options{AllowPrivateNetworks: true, MaxAttempts: 2, Backoff: time.Second}, http.StatusInternalServerError)
+
+		for range 3 {
+			_, err := d.Tick(ctx)
+			require.NoError(t, err)
+			*now = now.Add(time.Minute)
+		}
+
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStateFailed, hook.State)
+		assert.Equal(t, 2, hook.Attempts)
+		assert.Len(t, recv.received(), 2)
+	})
+
+	t.Run("UnreachableReceiver", func(t *testing.T) {
+		ctx, d, store, _, _ := setup(t, Options{AllowPrivateNetworks: true}, http.StatusOK)
+		_, err := store.UpdateWebhook(ctx, "grep_1", func(hook *models.Webhook) error {
+			hook.URL = "http://127.0.0.1:1/hook"
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = d.Tick(ctx)
+		require.NoError(t, err)
+
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStatePending, hook.State)
+
+		attempts, err := store.ListWebhookAttempts(ctx, "grep_1")
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Zero(t, attempts[0].StatusCode)
+		assert.NotEmpty(t, attempts[0].Error)
+	})
+}
+
+func TestTickRefusesInternalReceivers(t *testing.T) {
+	t.Run("PrivateAddress", func(t *testing.T) {
+		ctx, d, store, recv, _ := setup(t, Options{}, http.StatusOK)
+
+		delivered, err := d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Empty(t, recv.received())
+
+		attempts, err := store.ListWebhookAttempts(ctx, "grep_1")
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Contains(t, attempts[0].Error, ErrAddressNotPublic.Error())
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		ctx, d, store, recv, _ := setup(t, Options{AllowPrivateNetworks: true}, http.StatusOK)
+		hook, err := store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+
+		redirect := httptest.NewServer(http.RedirectHandler(hook.URL, http.StatusFound))
+		t.Cleanup(redirect.Close)
+		_, err = store.UpdateWebhook(ctx, "grep_1", func(hook *models.Webhook) error {
+			hook.URL = redirect.URL
+			return nil
+		})
+		require.NoError(t, err)
+
+		delivered, err := d.Tick(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Empty(t, recv.received())
+
+		hook, err = store.GetWebhook(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookStateFailed, hook.State)
+	})
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, publicAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := New(nil, Options{Backoff: 10 * time.Second}, nil)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{12, MaxBackoff},
+		{100, MaxBackoff},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, d.backoff(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_grep_1"}`)
+	header := Sign(secret, time.Now(), body)
+
+	assert.NoError(t, Verify(secret, header, body, time.Minute))
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, header, []byte(`{"id":"evt_grep_2"}`), time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, "v1=abc", body, 0), ErrInvalidSignature)
+
+	old := Sign(secret, time.Now().Add(-time.Hour), body)
+	assert.ErrorIs(t, Verify(secret, old, body, time.Minute), ErrInvalidSignature)
+	assert.NoError(t, Verify(secret, old, body, 0))
+}
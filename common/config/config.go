@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Load reads the YAML file at path (if set) into out. Keys present in the file
+// can be overridden with environment variables named envPrefix_SECTION_KEY.
+// Fields of out missing from the file keep the values they already hold, so
+// callers pass out pre-populated with their defaults.
+func Load(path, envPrefix string, out any) error {
+	v := viper.New()
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+	}
+
+	if err := v.Unmarshal(out); err != nil {
+		return fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package glob
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Expand returns the files matched by any of the patterns ordered by path.
+// Every pattern has to match at least one file, otherwise the first pattern
+// without a match is returned as well.
+func Expand(patterns []string, files []models.LogFile) ([]models.LogFile, string) {
+	seen := make(map[string]bool)
+	var expanded []models.LogFile
+
+	for _, pattern := range patterns {
+		matched := false
+		for _, file := range files {
+			if !Match(pattern, file.Path) {
+				continue
+			}
+
+			matched = true
+			if !seen[file.Path] {
+				seen[file.Path] = true
+				expanded = append(expanded, file)
+			}
+		}
+
+		if !matched {
+			return nil, pattern
+		}
+	}
+
+	sort.Slice(expanded, func(i, j int) bool {
+		return expanded[i].Path < expanded[j].Path
+	})
+
+	return expanded, ""
+}
+
+// Match reports whether name matches pattern. Segments are matched with
+// path.Match, additionally a "**" segment matches any number of directories.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,48 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"logs/app.log", "logs/app.log", true},
+		{"logs/*.log", "logs/app.log", true},
+		{"logs/*.log", "logs/2024/01/api.log", false},
+		{"logs/**", "logs/2024/01/api.log", true},
+		{"logs/**/*.log", "logs/app.log", true},
+		{"logs/**/*.log", "logs/2024/01/api.log", true},
+		{"**/api.log", "logs/2024/01/api.log", true},
+		{"logs/**/*.txt", "logs/2024/01/api.log", false},
+		{"logs/[", "logs/[", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"~"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	files := []models.LogFile{
+		{Path: "logs/error.log"},
+		{Path: "logs/app.log"},
+		{Path: "logs/2024/01/api.log"},
+	}
+
+	expanded, unmatched := Expand([]string{"logs/*.log", "logs/app.log"}, files)
+	assert.Empty(t, unmatched)
+	assert.Equal(t, []models.LogFile{{Path: "logs/app.log"}, {Path: "logs/error.log"}}, expanded)
+
+	expanded, unmatched = Expand([]string{"logs/**", "nope.log"}, files)
+	assert.Equal(t, "nope.log", unmatched)
+	assert.Nil(t, expanded)
+}
@@ -0,0 +1,150 @@
+// Package health serves the liveness and readiness probes of a service
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds how long a readiness check waits for each dependency
+const DefaultTimeout = 2 * time.Second
+
+// Statuses reported in a Report and its checks
+const (
+	StatusOK           = "ok"
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+	StatusFailing      = "failing"
+)
+
+// Pinger is a dependency that can be checked, implemented by minio.Storage,
+// redis.Store and nats.Bus
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// CheckResult is the outcome of checking one dependency
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the body of a readiness response
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name   string
+	pinger Pinger
+}
+
+// Checker runs the readiness checks of a service
+type Checker struct {
+	checks       []check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// New returns a Checker without any dependencies
+func New() *Checker {
+	return &Checker{timeout: DefaultTimeout}
+}
+
+// Add registers a dependency checked under name
+func (c *Checker) Add(name string, pinger Pinger) {
+	c.checks = append(c.checks, check{name: name, pinger: pinger})
+	sort.Slice(c.checks, func(i, j int) bool { return c.checks[i].name < c.checks[j].name })
+}
+
+// SetTimeout changes how long each dependency is waited for
+func (c *Checker) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// Shutdown marks the service as not ready, call it once graceful shutdown
+// starts so that no new traffic is routed to it
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check pings every dependency concurrently and reports whether all of them
+// are healthy, dependencies are not checked once shutdown started
+func (c *Checker) Check(ctx context.Context) (Report, bool) {
+	if c.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		ready  = true
+		report = Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(c.checks))}
+	)
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := chk.pinger.Ping(ctx)
+			result := CheckResult{Status: StatusOK, Duration: time.Since(start).Round(time.Microsecond).String()}
+			if err != nil {
+				result.Status = StatusFailing
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[chk.name] = result
+			if err != nil {
+				ready = false
+				report.Status = StatusNotReady
+			}
+		}(chk)
+	}
+	wg.Wait()
+
+	return report, ready
+}
+
+// Healthz answers 200 as long as the process is able to serve requests
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+// Readyz answers 200 when every dependency is healthy and 503 with the
+// failing ones otherwise
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	report, ready := c.Check(r.Context())
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Register serves /healthz and /readyz on mux
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", c.Healthz)
+	mux.HandleFunc("GET /readyz", c.Readyz)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
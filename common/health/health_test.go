@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+func healthy(ctx context.Context) error { return nil }
+
+func TestReadyz(t *testing.T) {
+	slow := pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name       string
+		pingers    map[string]Pinger
+		shutdown   bool
+		wantCode   int
+		wantStatus string
+		wantFailed []string
+	}{
+		{
+			name:       "AllHealthy",
+			pingers:    map[string]Pinger{"minio": pingerFunc(healthy), "redis": pingerFunc(healthy), "nats": pingerFunc(healthy)},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusReady,
+		},
+		{
+			name: "DependencyFailing",
+			pingers: map[string]Pinger{
+				"minio": pingerFunc(healthy),
+				"redis": pingerFunc(func(ctx context.Context) error { return errors.New("connection refused") }),
+				"nats":  slow,
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusNotReady,
+			wantFailed: []string{"nats", "redis"},
+		},
+		{
+			name:       "ShuttingDown",
+			pingers:    map[string]Pinger{"redis": pingerFunc(healthy)},
+			shutdown:   true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusShuttingDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := New()
+			checker.SetTimeout(50 * time.Millisecond)
+			for name, pinger := range tt.pingers {
+				checker.Add(name, pinger)
+			}
+			if tt.shutdown {
+				checker.Shutdown()
+			}
+
+			mux := http.NewServeMux()
+			checker.Register(mux)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			require.Equal(t, tt.wantCode, w.Code)
+
+			var report Report
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantStatus, report.Status)
+
+			var failed []string
+			for name, result := range report.Checks {
+				if result.Status == StatusFailing {
+					assert.NotEmpty(t, result.Error)
+					failed = append(failed, name)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantFailed, failed)
+
+			// Liveness does not depend on anything
+			w = httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// New returns a random identifier with the given prefix, e.g. grep_3f9a...
+func New(prefix string) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return prefix + "_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,13 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	id := New("grep")
+	assert.Regexp(t, `^grep_[0-9a-f]{16}$`, id)
+	assert.NotEqual(t, id, New("grep"))
+}
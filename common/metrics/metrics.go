@@ -0,0 +1,145 @@
+// Package metrics defines the Prometheus metrics shared by all services. Every
+// service registers the same set so that one dashboard covers the pipeline,
+// series are told apart by the job and instance labels added when scraping.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+const namespace = "grep"
+
+// Label names used across metrics
+const (
+	LabelStatus    = "status"    // models.JobStatus
+	LabelOperation = "operation" // Storage method, e.g. store_chunk
+	LabelSubject   = "subject"   // NATS subject
+)
+
+var (
+	// JobsTotal counts jobs entering each status
+	JobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Jobs that entered a status.",
+	}, []string{LabelStatus})
+
+	// ChunksProcessed counts chunks grepped by mappers
+	ChunksProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chunks_processed_total",
+		Help:      "Chunks grepped by mappers.",
+	})
+
+	// BytesScanned counts bytes of log data grepped by mappers
+	BytesScanned = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bytes_scanned_total",
+		Help:      "Bytes of log data grepped by mappers.",
+	})
+
+	// MatchesFound counts matching lines found by mappers
+	MatchesFound = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_found_total",
+		Help:      "Matching lines found by mappers.",
+	})
+
+	// MapDuration observes how long mapping a single chunk took
+	MapDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "map_duration_seconds",
+		Help:      "Time taken to grep a single chunk.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+	})
+
+	// ReduceDuration observes how long merging the results of a job took
+	ReduceDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reduce_duration_seconds",
+		Help:      "Time taken to merge the results of a job.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+	})
+
+	// StorageDuration observes the latency of object storage operations
+	StorageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Latency of object storage operations.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{LabelOperation})
+
+	// StorageErrors counts failed object storage operations
+	StorageErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_errors_total",
+		Help:      "Failed object storage operations.",
+	}, []string{LabelOperation})
+
+	// MessagesPublished counts messages published on NATS
+	MessagesPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_messages_published_total",
+		Help:      "Messages published on NATS.",
+	}, []string{LabelSubject})
+
+	// PublishErrors counts messages that failed to be published on NATS
+	PublishErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_publish_errors_total",
+		Help:      "Messages that failed to be published on NATS.",
+	}, []string{LabelSubject})
+
+	// MessagesConsumed counts messages received from NATS
+	MessagesConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nats_messages_consumed_total",
+		Help:      "Messages received from NATS.",
+	}, []string{LabelSubject})
+)
+
+// Handler serves the metrics of the process in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveJob records a job entering status
+func ObserveJob(status models.JobStatus) {
+	JobsTotal.WithLabelValues(string(status)).Inc()
+}
+
+// ObserveStorage records an object storage operation that started at start,
+// errors that are expected outcomes, such as a missing object, are passed in
+// ignore and not counted as failures
+func ObserveStorage(operation string, start time.Time, err error, ignore ...error) {
+	StorageDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+	for _, target := range ignore {
+		if errors.Is(err, target) {
+			return
+		}
+	}
+	StorageErrors.WithLabelValues(operation).Inc()
+}
+
+// ObserveReduce records merging the results of a job that started at start
+func ObserveReduce(start time.Time) {
+	ReduceDuration.Observe(time.Since(start).Seconds())
+}
+
+// ObserveChunk records a chunk grepped by a mapper
+func ObserveChunk(bytes int64, matches int, duration time.Duration) {
+	ChunksProcessed.Inc()
+	BytesScanned.Add(float64(bytes))
+	MatchesFound.Add(float64(matches))
+	MapDuration.Observe(duration.Seconds())
+}
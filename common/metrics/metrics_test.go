@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestObserveStorage(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	tests := []struct {
+		operation string
+		err       error
+		wantErrs  float64
+	}{
+		{"test_succeeded", nil, 0},
+		{"test_failed", errors.New("connection reset"), 1},
+		{"test_expected", fmt.Errorf("stat: %w", errNotFound), 0},
+	}
+
+	for _, tt := range tests {
+		ObserveStorage(tt.operation, time.Now(), tt.err, errNotFound)
+
+		assert.Equal(t, tt.wantErrs, testutil.ToFloat64(StorageErrors.WithLabelValues(tt.operation)), tt.operation)
+	}
+	assert.Equal(t, 3, testutil.CollectAndCount(StorageDuration))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveJob(models.JobStatusPending)
+	ObserveChunk(1024, 3, 20*time.Millisecond)
+	ObserveReduce(time.Now())
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `grep_jobs_total{status="PENDING"} 1`)
+	assert.Contains(t, body, "grep_chunks_processed_total 1")
+	assert.Contains(t, body, "grep_bytes_scanned_total 1024")
+	assert.Contains(t, body, "grep_matches_found_total 3")
+	assert.Contains(t, body, "grep_map_duration_seconds_count 1")
+	assert.Contains(t, body, "grep_reduce_duration_seconds_count 1")
+}
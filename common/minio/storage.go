@@ -4,17 +4,28 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 	"time"
 
 	gominio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
 	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
 )
 
+// ErrObjectNotFound is returned when a requested object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// uploadPartSize is the part size used when streaming uploads of unknown size,
+// without it the client would buffer parts sized for a 5TiB object
+const uploadPartSize = 16 << 20
+
 // Storage represents the MinIO client wrapper
 type Storage struct {
 	client         *MinOptions
@@ -24,9 +35,7 @@ type Storage struct {
 
 // New returns a new Storage with access to storage APIs for MinIO
 func New(endpoint, accessKeyID, secretAccessKey string, ssl bool, log *logger.Logger, opts *StorageOptions) (*Storage, error) {
-	if log == nil {
-		InitLogger(nil)
-	}
+	InitLogger(log)
 
 	minioOptions := MinOptions{
 		Endpoint:        endpoint,
@@ -78,17 +87,46 @@ func (s *Storage) Instantiate(ctx context.Context) error {
 	return nil
 }
 
-// UploadLogFile uploads a single log file
-func (s *Storage) UploadLogFile(ctx context.Context, logFile models.LogFile, reader io.Reader) error {
+// Ping checks that every configured bucket exists
+func (s *Storage) Ping(ctx context.Context) error {
+	for _, bucket := range s.storageOptions.Buckets {
+		exists, err := s.minioClient.BucketExists(ctx, bucket.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check bucket %s: %w", bucket.Name, err)
+		}
+		if !exists {
+			return fmt.Errorf("bucket %s does not exist", bucket.Name)
+		}
+	}
+	return nil
+}
+
+// objectName places key in the namespace of the tenant ctx is scoped to, all
+// object names handed to and returned by Storage are relative to it
+func objectName(ctx context.Context, key string) string {
+	return tenant.ObjectPrefix(tenant.FromContext(ctx)) + key
+}
+
+// UploadLogFile uploads a single log file, logFile.Size is used as the object
+// size when set, otherwise the reader is streamed in parts of unknown total size
+func (s *Storage) UploadLogFile(ctx context.Context, logFile models.LogFile, reader io.Reader) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("upload_log_file", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(LogStorage)
 
-	_, err := s.minioClient.PutObject(ctx, bucket.Name, logFile.Path, reader, -1,
-		gominio.PutObjectOptions{
-			ContentType: string(bucket.Type),
-			UserMetadata: map[string]string{
-				"uploaded_at": time.Now().Format(time.RFC3339),
-			},
-		})
+	size := logFile.Size
+	opts := gominio.PutObjectOptions{
+		ContentType: string(bucket.Type),
+		UserMetadata: map[string]string{
+			"uploaded_at": time.Now().Format(time.RFC3339),
+		},
+	}
+	if size <= 0 {
+		size = -1
+		opts.PartSize = uploadPartSize
+	}
+
+	_, err = s.minioClient.PutObject(ctx, bucket.Name, objectName(ctx, logFile.Path), reader, size, opts)
 	if err != nil {
 		log.Error("failed to upload log file", "file", logFile.Name, "err", err)
 		return fmt.Errorf("failed to upload log file %s: %w", logFile.Name, err)
@@ -100,20 +138,34 @@ func (s *Storage) UploadLogFile(ctx context.Context, logFile models.LogFile, rea
 
 // ListLogFiles returns all available log files
 func (s *Storage) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
+	return s.ListLogFilesWithPrefix(ctx, "")
+}
+
+// ListLogFilesWithPrefix returns the log files whose path starts with prefix.
+// Listing fails as a whole rather than leaving files out, so callers never
+// take some of the files for all of them.
+func (s *Storage) ListLogFilesWithPrefix(ctx context.Context, prefix string) (_ []models.LogFile, err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("list_log_files", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(LogStorage)
+	namespace := objectName(ctx, "")
 	var logFiles []models.LogFile
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	for object := range s.minioClient.ListObjects(ctx, bucket.Name, gominio.ListObjectsOptions{
+		Prefix:    namespace + prefix,
 		Recursive: true,
 	}) {
 		if object.Err != nil {
-			log.Error("error listing objects", "err", object.Err)
-			continue
+			return nil, fmt.Errorf("failed to list log files: %w", object.Err)
 		}
 
+		path := strings.TrimPrefix(object.Key, namespace)
 		logFiles = append(logFiles, models.LogFile{
-			Name:      filepath.Base(object.Key),
-			Path:      object.Key,
+			Name:      filepath.Base(path),
+			Path:      path,
 			Size:      object.Size,
 			UpdatedAt: object.LastModified,
 		})
@@ -122,12 +174,77 @@ func (s *Storage) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
 	return logFiles, nil
 }
 
+// StatLogFile returns a single log file, ErrObjectNotFound if it does not exist
+func (s *Storage) StatLogFile(ctx context.Context, path string) (_ *models.LogFile, err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("stat_log_file", start, err, ErrObjectNotFound) }(time.Now())
+
+	bucket := s.storageOptions.GetBucketByCategory(LogStorage)
+
+	info, err := s.minioClient.StatObject(ctx, bucket.Name, objectName(ctx, path), gominio.StatObjectOptions{})
+	if err != nil {
+		if gominio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: log file %s", ErrObjectNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to stat log file %s: %w", path, err)
+	}
+
+	return &models.LogFile{
+		Name:      filepath.Base(path),
+		Path:      path,
+		Size:      info.Size,
+		UpdatedAt: info.LastModified,
+	}, nil
+}
+
+// OpenLogFile returns a reader of length bytes of a log file from offset on,
+// or of the rest of the file if length is not positive. Close the reader
+// once done with it.
+func (s *Storage) OpenLogFile(ctx context.Context, path string, offset, length int64) (_ io.ReadCloser, err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("open_log_file", start, err) }(time.Now())
+
+	bucket := s.storageOptions.GetBucketByCategory(LogStorage)
+
+	opts := gominio.GetObjectOptions{}
+	switch {
+	case length > 0:
+		err = opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		err = opts.SetRange(offset, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid range of log file %s: %w", path, err)
+	}
+
+	object, err := s.minioClient.GetObject(ctx, bucket.Name, objectName(ctx, path), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+	return object, nil
+}
+
+// DeleteLogFile removes a single log file
+func (s *Storage) DeleteLogFile(ctx context.Context, path string) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("delete_log_file", start, err) }(time.Now())
+
+	bucket := s.storageOptions.GetBucketByCategory(LogStorage)
+
+	if err := s.minioClient.RemoveObject(ctx, bucket.Name, objectName(ctx, path), gominio.RemoveObjectOptions{}); err != nil {
+		log.Error("failed to delete log file", "file", path, "err", err)
+		return fmt.Errorf("failed to delete log file %s: %w", path, err)
+	}
+
+	log.Info("deleted log file", "file", path)
+	return nil
+}
+
 // StoreChunk stores a file chunk with metadata
-func (s *Storage) StoreChunk(ctx context.Context, chunk models.Chunk, reader io.Reader) error {
+func (s *Storage) StoreChunk(ctx context.Context, chunk models.Chunk, reader io.Reader) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("store_chunk", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(ChunkStorage)
-	objectName := fmt.Sprintf("%s/chunk_%s", chunk.JobID, chunk.ID)
+	name := objectName(ctx, fmt.Sprintf("%s/chunk_%s", chunk.JobID, chunk.ID))
 
-	_, err := s.minioClient.PutObject(ctx, bucket.Name, objectName, reader, chunk.Size,
+	_, err = s.minioClient.PutObject(ctx, bucket.Name, name, reader, chunk.Size,
 		gominio.PutObjectOptions{
 			ContentType: string(bucket.Type),
 			UserMetadata: map[string]string{
@@ -148,11 +265,13 @@ func (s *Storage) StoreChunk(ctx context.Context, chunk models.Chunk, reader io.
 }
 
 // GetChunk retrieves a specific chunk
-func (s *Storage) GetChunk(ctx context.Context, jobID, chunkID string) (*models.Chunk, io.ReadCloser, error) {
+func (s *Storage) GetChunk(ctx context.Context, jobID, chunkID string) (_ *models.Chunk, _ io.ReadCloser, err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("get_chunk", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(ChunkStorage)
-	objectName := fmt.Sprintf("%s/chunk_%s", jobID, chunkID)
+	name := objectName(ctx, fmt.Sprintf("%s/chunk_%s", jobID, chunkID))
 
-	object, err := s.minioClient.GetObject(ctx, bucket.Name, objectName, gominio.GetObjectOptions{})
+	object, err := s.minioClient.GetObject(ctx, bucket.Name, name, gominio.GetObjectOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get chunk %s: %w", chunkID, err)
 	}
@@ -174,16 +293,18 @@ func (s *Storage) GetChunk(ctx context.Context, jobID, chunkID string) (*models.
 }
 
 // StoreResult stores the grep results for a job
-func (s *Storage) StoreResult(ctx context.Context, result models.Result) error {
+func (s *Storage) StoreResult(ctx context.Context, result models.Result) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("store_result", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(ResultStorage)
-	objectName := fmt.Sprintf("%s/result_%s.json", result.JobID, result.ID)
+	name := objectName(ctx, fmt.Sprintf("%s/result_%s.json", result.JobID, result.ID))
 
 	data, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal result: %w", err)
 	}
 
-	_, err = s.minioClient.PutObject(ctx, bucket.Name, objectName, bytes.NewReader(data), int64(len(data)),
+	_, err = s.minioClient.PutObject(ctx, bucket.Name, name, bytes.NewReader(data), int64(len(data)),
 		gominio.PutObjectOptions{
 			ContentType: "application/json",
 			UserMetadata: map[string]string{
@@ -202,38 +323,85 @@ func (s *Storage) StoreResult(ctx context.Context, result models.Result) error {
 
 // GetJobResults retrieves all results for a specific job
 func (s *Storage) GetJobResults(ctx context.Context, jobID string) ([]models.Result, error) {
+	var results []models.Result
+	err := s.WalkJobResults(ctx, jobID, func(result models.Result) error {
+		results = append(results, result)
+		return nil
+	})
+
+	return results, err
+}
+
+// WalkJobResults calls fn for each result of a job, one result object is held
+// in memory at a time. Walking stops at the first error returned by fn, and
+// at the first result that cannot be listed or read so that callers never
+// take a partial set of results for all of them.
+func (s *Storage) WalkJobResults(ctx context.Context, jobID string, fn func(result models.Result) error) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("walk_job_results", start, err) }(time.Now())
+
 	bucket := s.storageOptions.GetBucketByCategory(ResultStorage)
-	prefix := fmt.Sprintf("%s/", jobID)
+	prefix := objectName(ctx, jobID+"/")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	var results []models.Result
 	for object := range s.minioClient.ListObjects(ctx, bucket.Name, gominio.ListObjectsOptions{
 		Prefix: prefix,
 	}) {
 		if object.Err != nil {
-			log.Error("error listing results", "err", object.Err)
-			continue
+			return fmt.Errorf("failed to list results of job %s: %w", jobID, object.Err)
 		}
 
-		obj, err := s.minioClient.GetObject(ctx, bucket.Name, object.Key, gominio.GetObjectOptions{})
+		result, err := s.getResultObject(ctx, bucket.Name, object.Key)
 		if err != nil {
-			log.Error("failed to get result object", "key", object.Key, "err", err)
-			continue
+			return fmt.Errorf("failed to read result %s: %w", object.Key, err)
 		}
 
-		var result models.Result
-		if err := json.NewDecoder(obj).Decode(&result); err != nil {
-			log.Error("failed to decode result", "key", object.Key, "err", err)
-			continue
+		if err := fn(*result); err != nil {
+			return err
 		}
+	}
 
-		results = append(results, result)
+	return nil
+}
+
+// GetResult retrieves a single result of a job
+func (s *Storage) GetResult(ctx context.Context, jobID, resultID string) (_ *models.Result, err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("get_result", start, err, ErrObjectNotFound) }(time.Now())
+
+	bucket := s.storageOptions.GetBucketByCategory(ResultStorage)
+	name := objectName(ctx, fmt.Sprintf("%s/result_%s.json", jobID, resultID))
+
+	result, err := s.getResultObject(ctx, bucket.Name, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result %s: %w", resultID, err)
+	}
+
+	return result, nil
+}
+
+func (s *Storage) getResultObject(ctx context.Context, bucketName, key string) (*models.Result, error) {
+	obj, err := s.minioClient.GetObject(ctx, bucketName, key, gominio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
 	}
+	defer obj.Close()
 
-	return results, nil
+	var result models.Result
+	if err := json.NewDecoder(obj).Decode(&result); err != nil {
+		if gominio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	return &result, nil
 }
 
 // DeleteJob removes all data associated with a job
-func (s *Storage) DeleteJob(ctx context.Context, jobID string) error {
+func (s *Storage) DeleteJob(ctx context.Context, jobID string) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("delete_job", start, err) }(time.Now())
+
 	if err := s.deleteJobObjects(ctx, ChunkStorage, jobID); err != nil {
 		return fmt.Errorf("failed to delete job chunks: %w", err)
 	}
@@ -245,10 +413,21 @@ func (s *Storage) DeleteJob(ctx context.Context, jobID string) error {
 	return nil
 }
 
+// DeleteJobChunks removes the chunks of a job and keeps its results
+func (s *Storage) DeleteJobChunks(ctx context.Context, jobID string) (err error) {
+	defer func(start time.Time) { metrics.ObserveStorage("delete_job_chunks", start, err) }(time.Now())
+
+	if err := s.deleteJobObjects(ctx, ChunkStorage, jobID); err != nil {
+		return fmt.Errorf("failed to delete job chunks: %w", err)
+	}
+
+	return nil
+}
+
 // deleteJobObjects removes all objects with the given job ID prefix from the specified bucket
 func (s *Storage) deleteJobObjects(ctx context.Context, category StorageCategory, jobID string) error {
 	bucket := s.storageOptions.GetBucketByCategory(category)
-	prefix := fmt.Sprintf("%s/", jobID)
+	prefix := objectName(ctx, jobID+"/")
 
 	objectsCh := make(chan gominio.ObjectInfo)
 
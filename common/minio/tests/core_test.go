@@ -114,6 +114,28 @@ func TestLogFileOperations(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("OpenLogFileRange", func(t *testing.T) {
+		content := "line 1\nline 2\nline 3\n"
+		err := storage.UploadLogFile(ctx, models.LogFile{Name: "range.log", Path: "range.log", Size: int64(len(content))}, strings.NewReader(content))
+		require.NoError(t, err)
+
+		for _, tt := range []struct {
+			offset, length int64
+			want           string
+		}{
+			{0, 0, content},
+			{7, 7, "line 2\n"},
+			{14, 0, "line 3\n"},
+		} {
+			r, err := storage.OpenLogFile(ctx, "range.log", tt.offset, tt.length)
+			require.NoError(t, err)
+			data, err := io.ReadAll(r)
+			r.Close()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		}
+	})
 }
 
 func createSampleLogs(service string, lines int) string {
@@ -0,0 +1,28 @@
+package models
+
+// SearchMode is what a job reports about the lines it selects
+type SearchMode string
+
+const (
+	SearchModeLines             SearchMode = "lines"               // Every selected line, the default
+	SearchModeCount             SearchMode = "count"               // Number of selected lines per file, grep -c
+	SearchModeFilesWithMatches  SearchMode = "files_with_matches"  // Files with a selected line, grep -l
+	SearchModeFilesWithoutMatch SearchMode = "files_without_match" // Files without a selected line, grep -L
+)
+
+// Counts reports whether jobs in this mode report counts per file rather than
+// the selected lines
+func (m SearchMode) Counts() bool {
+	switch m {
+	case SearchModeCount, SearchModeFilesWithMatches, SearchModeFilesWithoutMatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// SettledByFirstMatch reports whether the first selected line of a file
+// settles its outcome, so that the rest of the file need not be searched
+func (m SearchMode) SettledByFirstMatch() bool {
+	return m == SearchModeFilesWithMatches || m == SearchModeFilesWithoutMatch
+}
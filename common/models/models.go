@@ -14,39 +14,233 @@ const (
 	JobStatusCancelled  JobStatus = "CANCELLED"
 )
 
+// IsTerminal reports whether a job in this status will not change anymore
+func (s JobStatus) IsTerminal() bool {
+	switch s {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 // Job represents a grep search job
 type Job struct {
-	ID          string     `json:"id"`              // Unique identifier for the job
-	RequestID   string     `json:"request_id"`      // Original request ID from API
-	Pattern     string     `json:"pattern"`         // Grep pattern to search
-	Files       []string   `json:"files"`           // List of files/patterns to search
-	Status      JobStatus  `json:"status"`          // Current job status
+	ID          string      `json:"id"`                   // Unique identifier for the job
+	Tenant      string      `json:"tenant,omitempty"`     // Owning tenant, empty for the global namespace
+	RequestID   string      `json:"request_id"`           // Original request ID from API
+	SearchID    string      `json:"search_id,omitempty"`  // Saved search the job was run for
+	Pattern     string      `json:"pattern"`              // Grep pattern to search, empty when Patterns or Query is set
+	Patterns    []string    `json:"patterns,omitempty"`   // Fixed strings to search, a line containing any of them matches
+	Query       *Query      `json:"query,omitempty"`      // Boolean expression of patterns to search
+	TimeRange   *TimeRange  `json:"time_range,omitempty"` // Only search lines logged within this range
+	Mode        SearchMode  `json:"mode,omitempty"`       // What is reported, selected lines if empty
+	Priority    JobPriority `json:"priority,omitempty"`   // Dispatch class, normal if empty
+	Files       []string    `json:"files"`                // List of files/patterns to search
+	Status      JobStatus   `json:"status"`               // Current job status
+	CreatedAt   time.Time   `json:"created_at"`           // Job creation timestamp
+	StartedAt   *time.Time  `json:"started_at"`           // When job processing began
+	CompletedAt *time.Time  `json:"completed_at"`         // When job finished (success/failure)
+	Error       string      `json:"error,omitempty"`      // Error message if job failed
+	Progress    float64     `json:"progress"`             // Progress percentage (0-100)
+	IsCancelled bool        `json:"is_cancelled"`         // Whether job was cancelled
+
+	// Retention
+	Retention time.Duration `json:"retention,omitempty"`  // How long the job is kept once finished, the manager's default if zero
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"` // When the job and its data are removed, set once it finished
+
+	// Search options
+	CaseSensitive bool `json:"case_sensitive"`          // Whether search is case-sensitive
+	Regex         bool `json:"regex"`                   // Whether pattern is regex
+	ContextLines  int  `json:"context_lines"`           // Number of context lines
+	InvertMatch   bool `json:"invert_match,omitempty"`  // Select non-matching lines, grep -v
+	WordRegexp    bool `json:"word_regexp,omitempty"`   // Only match whole words, grep -w
+	LineRegexp    bool `json:"line_regexp,omitempty"`   // Only match whole lines, grep -x
+	OnlyMatching  bool `json:"only_matching,omitempty"` // Report the matched parts of lines, grep -o
+	MaxCount      int  `json:"max_count,omitempty"`     // Selected lines per file, 0 for no limit, grep -m
+
+	// Webhook to notify once the job finishes, it is stored apart from the
+	// job so that its secret is not passed around with it
+	Webhook *Webhook `json:"-"`
+}
+
+// WebhookState tracks the notification of a job that finished
+type WebhookState string
+
+const (
+	WebhookStateWaiting   WebhookState = "WAITING"   // Job has not finished yet
+	WebhookStatePending   WebhookState = "PENDING"   // Delivery is due or being retried
+	WebhookStateDelivered WebhookState = "DELIVERED" // Receiver acknowledged the event
+	WebhookStateFailed    WebhookState = "FAILED"    // Delivery was given up on
+)
+
+// Webhook is a callback notified once its job is completed, failed or cancelled
+type Webhook struct {
+	URL           string       `json:"url"`             // Endpoint the event is POSTed to
+	Secret        string       `json:"secret"`          // Key of the HMAC signature of each delivery
+	State         WebhookState `json:"state"`           // Progress of the notification
+	Attempts      int          `json:"attempts"`        // Delivery attempts made so far
+	NextAttemptAt *time.Time   `json:"next_attempt_at"` // When the next attempt is due
+	DeliveredAt   *time.Time   `json:"delivered_at"`    // When the receiver acknowledged the event
+}
+
+// WebhookAttempt records one delivery attempt of a webhook
+type WebhookAttempt struct {
+	Attempt     int           `json:"attempt"`               // Attempt number, starting at 1
+	AttemptedAt time.Time     `json:"attempted_at"`          // When the request was sent
+	StatusCode  int           `json:"status_code,omitempty"` // Response status, zero if none was received
+	Error       string        `json:"error,omitempty"`       // Why the attempt failed
+	Duration    time.Duration `json:"duration"`              // Time until the response or error
+}
+
+// Succeeded reports whether the receiver acknowledged the event
+func (a WebhookAttempt) Succeeded() bool {
+	return a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// WebhookEvent is the payload POSTed to a webhook, ID stays the same across
+// retries of the same event
+type WebhookEvent struct {
+	ID          string     `json:"id"`              // Unique identifier of the event
+	Type        string     `json:"type"`            // job.completed, job.failed or job.cancelled
+	JobID       string     `json:"job_id"`          // Job that finished
+	RequestID   string     `json:"request_id"`      // Original request ID of the job
+	Status      JobStatus  `json:"status"`          // Final status of the job
+	Error       string     `json:"error,omitempty"` // Error message if the job failed
 	CreatedAt   time.Time  `json:"created_at"`      // Job creation timestamp
-	StartedAt   *time.Time `json:"started_at"`      // When job processing began
-	CompletedAt *time.Time `json:"completed_at"`    // When job finished (success/failure)
-	Error       string     `json:"error,omitempty"` // Error message if job failed
-	Progress    float64    `json:"progress"`        // Progress percentage (0-100)
-	IsCancelled bool       `json:"is_cancelled"`    // Whether job was cancelled
+	CompletedAt *time.Time `json:"completed_at"`    // When the job finished
+	Stats       JobStats   `json:"stats"`           // Final stats of the job
+}
+
+// SavedSearch is a named grep request template, it is run on demand or by the
+// manager whenever its schedule is due
+type SavedSearch struct {
+	ID        string        `json:"id"`                   // Unique identifier for the search
+	Tenant    string        `json:"tenant,omitempty"`     // Owning tenant, empty for the global namespace
+	Name      string        `json:"name"`                 // Name given by the user
+	Pattern   string        `json:"pattern"`              // Grep pattern to search, empty when Patterns or Query is set
+	Patterns  []string      `json:"patterns,omitempty"`   // Fixed strings to search, a line containing any of them matches
+	Query     *Query        `json:"query,omitempty"`      // Boolean expression of patterns to search
+	TimeRange *TimeRange    `json:"time_range,omitempty"` // Only search lines logged within this range
+	Mode      SearchMode    `json:"mode,omitempty"`       // What is reported, selected lines if empty
+	Priority  JobPriority   `json:"priority,omitempty"`   // Dispatch class of its jobs, normal if empty
+	Retention time.Duration `json:"retention,omitempty"`  // How long its jobs are kept once finished, the manager's default if zero
+	Files     []string      `json:"files"`                // Files/patterns to search, resolved on each run
+	Schedule  string        `json:"schedule"`             // Cron expression, empty when only run on demand
+	NextRunAt *time.Time    `json:"next_run_at"`          // Next scheduled run
+	LastRunAt *time.Time    `json:"last_run_at"`          // Last run, scheduled or not
+	CreatedAt time.Time     `json:"created_at"`           // Creation timestamp
+	UpdatedAt time.Time     `json:"updated_at"`           // Last modification timestamp
 
 	// Search options
-	CaseSensitive bool `json:"case_sensitive"` // Whether search is case-sensitive
-	Regex         bool `json:"regex"`          // Whether pattern is regex
-	ContextLines  int  `json:"context_lines"`  // Number of context lines
+	CaseSensitive bool `json:"case_sensitive"`          // Whether search is case-sensitive
+	Regex         bool `json:"regex"`                   // Whether pattern is regex
+	ContextLines  int  `json:"context_lines"`           // Number of context lines
+	InvertMatch   bool `json:"invert_match,omitempty"`  // Select non-matching lines, grep -v
+	WordRegexp    bool `json:"word_regexp,omitempty"`   // Only match whole words, grep -w
+	LineRegexp    bool `json:"line_regexp,omitempty"`   // Only match whole lines, grep -x
+	OnlyMatching  bool `json:"only_matching,omitempty"` // Report the matched parts of lines, grep -o
+	MaxCount      int  `json:"max_count,omitempty"`     // Selected lines per file, 0 for no limit, grep -m
+
+	// Limits its scheduled runs are held to and the API key whose usage they
+	// count against, those of the caller that last saved it. Nil holds them
+	// to the defaults of the manager.
+	Limits *Limits `json:"limits,omitempty"`
+	KeyID  string  `json:"key_id,omitempty"`
+}
+
+// Limits bound what a caller may submit, zero disables a limit
+type Limits struct {
+	JobsPerMinute  int   `mapstructure:"jobsPerMinute" json:"jobs_per_minute,omitempty"`   // Submitted jobs per calendar minute
+	MaxRunningJobs int   `mapstructure:"maxRunningJobs" json:"max_running_jobs,omitempty"` // Pending and processing jobs at once
+	BytesPerDay    int64 `mapstructure:"bytesPerDay" json:"bytes_per_day,omitempty"`       // Bytes of resolved files per UTC day
+}
+
+// Job returns a new job for a run of the search
+func (s *SavedSearch) Job() Job {
+	return Job{
+		Tenant:        s.Tenant,
+		SearchID:      s.ID,
+		Pattern:       s.Pattern,
+		Patterns:      append([]string(nil), s.Patterns...),
+		Query:         s.Query.Clone(),
+		TimeRange:     s.TimeRange.Clone(),
+		Mode:          s.Mode,
+		Priority:      s.Priority,
+		Retention:     s.Retention,
+		Files:         append([]string(nil), s.Files...),
+		CaseSensitive: s.CaseSensitive,
+		Regex:         s.Regex,
+		ContextLines:  s.ContextLines,
+		InvertMatch:   s.InvertMatch,
+		WordRegexp:    s.WordRegexp,
+		LineRegexp:    s.LineRegexp,
+		OnlyMatching:  s.OnlyMatching,
+		MaxCount:      s.MaxCount,
+	}
+}
+
+// ChunkMessage returns the message handing chunk of the job to a mapper
+func (j *Job) ChunkMessage(chunk Chunk) ChunkMessage {
+	return ChunkMessage{
+		Chunk:         chunk,
+		Pattern:       j.Pattern,
+		Patterns:      j.Patterns,
+		Query:         j.Query,
+		TimeRange:     j.TimeRange,
+		Mode:          j.Mode,
+		CaseSensitive: j.CaseSensitive,
+		Regex:         j.Regex,
+		ContextLines:  j.ContextLines,
+		InvertMatch:   j.InvertMatch,
+		WordRegexp:    j.WordRegexp,
+		LineRegexp:    j.LineRegexp,
+		OnlyMatching:  j.OnlyMatching,
+		MaxCount:      j.MaxCount,
+	}
+}
+
+// SearchRun records a job created for a saved search
+type SearchRun struct {
+	JobID        string     `json:"job_id"`        // Job created for the run
+	Scheduled    bool       `json:"scheduled"`     // Whether the run was started by the schedule
+	Status       JobStatus  `json:"status"`        // Last known status of the job
+	CreatedAt    time.Time  `json:"created_at"`    // When the run was started
+	CompletedAt  *time.Time `json:"completed_at"`  // When the job finished
+	TotalMatches int        `json:"total_matches"` // Matches found by the job
+}
+
+// DefaultChunkSize is the most bytes of a file the splitter puts in one chunk
+const DefaultChunkSize int64 = 64 << 20
+
+// ChunkCount returns about the number of chunks a file of size bytes is split
+// into, chunks end at a line boundary so there may be a few more
+func ChunkCount(size, chunkSize int64) int {
+	if size <= 0 {
+		return 0
+	}
+	return int((size + chunkSize - 1) / chunkSize)
 }
 
 // Chunk represents a portion of a file to be processed
 type Chunk struct {
-	ID        string    `json:"id"`         // Unique identifier for the chunk
-	JobID     string    `json:"job_id"`     // Parent job ID
-	FileName  string    `json:"file_name"`  // Original file name
-	StartByte int64     `json:"start_byte"` // Starting byte position
-	EndByte   int64     `json:"end_byte"`   // Ending byte position
-	Size      int64     `json:"size"`       // Chunk size in bytes
-	CreatedAt time.Time `json:"created_at"` // When chunk was created
+	ID        string    `json:"id"`               // Unique identifier for the chunk
+	JobID     string    `json:"job_id"`           // Parent job ID
+	Tenant    string    `json:"tenant,omitempty"` // Owning tenant of the job
+	FileName  string    `json:"file_name"`        // Original file name
+	StartByte int64     `json:"start_byte"`       // Starting byte position
+	EndByte   int64     `json:"end_byte"`         // Ending byte position
+	Size      int64     `json:"size"`             // Chunk size in bytes
+	CreatedAt time.Time `json:"created_at"`       // When chunk was created
+
+	// Chunks hold whole lines, numbered from 1 within the file
+	StartLine int `json:"start_line"` // Number of the first line of the chunk
+	EndLine   int `json:"end_line"`   // Number of the last line of the chunk
 
-	// Optional: for context-aware splitting
-	StartLine int `json:"start_line"` // Starting line number
-	EndLine   int `json:"end_line"`   // Ending line number
+	// What a chunk needs of the lines before it to be searched like the rest
+	// of its file, set when the job asks for context or a time range
+	ContextBytes int64      `json:"context_bytes,omitempty"` // Bytes of the lines before the chunk that are context of its first lines
+	Inherited    *time.Time `json:"inherited,omitempty"`     // Time of the last timestamped line before the chunk
 }
 
 // Match represents a single grep match
@@ -55,6 +249,17 @@ type Match struct {
 	Content    string  `json:"content"`     // The matching line content
 	FileName   string  `json:"file_name"`   // Source file name
 	Context    Context `json:"context"`     // Surrounding context lines
+
+	// When the line was logged, parsed for jobs with a TimeRange. Lines
+	// without a timestamp of their own inherit the one of the line before.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// Labels of the query patterns, or the fixed strings, found on the line,
+	// empty for jobs searching a single pattern
+	MatchedPatterns []string `json:"matched_patterns,omitempty"`
+
+	// Parts of the line that matched, in order, for jobs with OnlyMatching
+	Parts []string `json:"parts,omitempty"`
 }
 
 // Context holds lines before and after a match
@@ -65,11 +270,13 @@ type Context struct {
 
 // Result represents processed results from a mapper
 type Result struct {
-	ID        string    `json:"id"`         // Unique identifier for this result
-	JobID     string    `json:"job_id"`     // Parent job ID
-	ChunkID   string    `json:"chunk_id"`   // Source chunk ID
-	Matches   []Match   `json:"matches"`    // Grep matches found
-	CreatedAt time.Time `json:"created_at"` // When result was created
+	ID        string         `json:"id"`               // Unique identifier for this result
+	JobID     string         `json:"job_id"`           // Parent job ID
+	Tenant    string         `json:"tenant,omitempty"` // Owning tenant of the job
+	ChunkID   string         `json:"chunk_id"`         // Source chunk ID
+	Matches   []Match        `json:"matches"`          // Grep matches found
+	Counts    map[string]int `json:"counts,omitempty"` // Selected lines per file, reported instead of Matches by jobs in a counting mode
+	CreatedAt time.Time      `json:"created_at"`       // When result was created
 
 	// Statistics
 	ProcessedBytes int64 `json:"processed_bytes"` // Number of bytes processed
@@ -91,35 +298,261 @@ type JobStats struct {
 	ProcessedFiles  int   `json:"processed_files"`  // Number of files processed
 	TotalChunks     int   `json:"total_chunks"`     // Total number of chunks created
 	ProcessedChunks int   `json:"processed_chunks"` // Number of chunks processed
-	TotalMatches    int   `json:"total_matches"`    // Total matches found
+	TotalMatches    int   `json:"total_matches"`    // Lines selected, capped per file like the results
 	BytesProcessed  int64 `json:"bytes_processed"`  // Total bytes processed
 }
 
 // Message types for NATS
 type ChunkMessage struct {
 	Chunk
-	Pattern       string `json:"pattern"` // Search pattern
-	CaseSensitive bool   `json:"case_sensitive"`
-	Regex         bool   `json:"regex"`
-	ContextLines  int    `json:"context_lines"`
+	Pattern       string     `json:"pattern"`              // Search pattern
+	Patterns      []string   `json:"patterns,omitempty"`   // Fixed strings matched in one pass, replaces Pattern when set
+	Query         *Query     `json:"query,omitempty"`      // Boolean expression of patterns, replaces Pattern when set
+	TimeRange     *TimeRange `json:"time_range,omitempty"` // Lines logged outside the range are skipped
+	Mode          SearchMode `json:"mode,omitempty"`       // Counting modes report Result.Counts and no matches
+	CaseSensitive bool       `json:"case_sensitive"`
+	Regex         bool       `json:"regex"`
+	ContextLines  int        `json:"context_lines"`
+	InvertMatch   bool       `json:"invert_match,omitempty"`
+	WordRegexp    bool       `json:"word_regexp,omitempty"`
+	LineRegexp    bool       `json:"line_regexp,omitempty"`
+	OnlyMatching  bool       `json:"only_matching,omitempty"`
+	MaxCount      int        `json:"max_count,omitempty"` // Per chunk, the API trims matches of a file past it
 }
 
+// ResultMessage announces a result stored by a mapper. Its matches can make
+// a result larger than a NATS message may be, they are read from storage.
 type ResultMessage struct {
-	Result
-	Stats JobStats `json:"stats"` // Partial stats from this chunk
+	ID         string   `json:"id"`               // Result ID
+	JobID      string   `json:"job_id"`           // Parent job ID
+	Tenant     string   `json:"tenant,omitempty"` // Owning tenant of the job
+	ChunkID    string   `json:"chunk_id"`         // Source chunk ID
+	MatchCount int      `json:"match_count"`      // Number of matches found
+	Stats      JobStats `json:"stats"`            // Partial stats from this chunk
+}
+
+// Message returns the message announcing the result, with stats of its chunk
+func (r *Result) Message(stats JobStats) ResultMessage {
+	return ResultMessage{
+		ID:         r.ID,
+		JobID:      r.JobID,
+		Tenant:     r.Tenant,
+		ChunkID:    r.ChunkID,
+		MatchCount: r.MatchCount,
+		Stats:      stats,
+	}
+}
+
+// JobMessage announces a newly submitted job to the manager
+type JobMessage struct {
+	Job
+}
+
+// FileMatchedMessage announces that a file of a job in a mode settled by the
+// first match has one, the remaining chunks of the file need not be searched
+type FileMatchedMessage struct {
+	JobID    string `json:"job_id"`           // Job the file is searched for
+	Tenant   string `json:"tenant,omitempty"` // Owning tenant of the job
+	FileName string `json:"file_name"`        // File known to match
 }
 
-// Redis key types (for consistent key formatting)
-type RedisKeys struct{}
+// JobFinishedMessage announces that a job reached a final status
+type JobFinishedMessage struct {
+	JobID  string    `json:"job_id"`           // Job that finished
+	Tenant string    `json:"tenant,omitempty"` // Owning tenant of the job
+	Status JobStatus `json:"status"`           // Final status of the job
+}
+
+// CancelMessage asks the pipeline to stop working on a job
+type CancelMessage struct {
+	JobID  string `json:"job_id"`           // Job to be cancelled
+	Tenant string `json:"tenant,omitempty"` // Owning tenant of the job
+}
+
+// Redis key types (for consistent key formatting), keys of a tenant are
+// prefixed with its namespace, the zero value uses the global namespace
+type RedisKeys struct {
+	Tenant string
+}
+
+func (k RedisKeys) prefix() string {
+	if k.Tenant == "" {
+		return ""
+	}
+	return "tenant:" + k.Tenant + ":"
+}
 
 func (k RedisKeys) JobKey(jobID string) string {
-	return "job:" + jobID
+	return k.prefix() + "job:" + jobID
 }
 
 func (k RedisKeys) JobStatsKey(jobID string) string {
-	return "job:" + jobID + ":stats"
+	return k.prefix() + "job:" + jobID + ":stats"
 }
 
+// JobChunksKey is a hash of the chunks of a job by chunk ID, kept while the
+// job is being dispatched
+func (k RedisKeys) JobChunksKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks"
+}
+
+// PendingChunksKey is a list of the IDs of the chunks of a job that were not
+// dispatched yet, in file order
+func (k RedisKeys) PendingChunksKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks:pending"
+}
+
+// DoneChunksKey is a set of the IDs of the chunks of a job that were reported
+// back or need not be searched
+func (k RedisKeys) DoneChunksKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks:done"
+}
+
+// ChunkAttemptsKey is a hash of the number of times chunks of a job timed out
+// by chunk ID
+func (k RedisKeys) ChunkAttemptsKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks:timeouts"
+}
+
+// ChunkKey marks a chunk as being worked on, it exists while a worker holds it
 func (k RedisKeys) ChunkKey(jobID, chunkID string) string {
-	return "job:" + jobID + ":chunk:" + chunkID
+	return k.prefix() + "job:" + jobID + ":chunk:" + chunkID
+}
+
+// WorkingChunksKey is a set of the IDs of the chunks of a job workers took,
+// their ChunkKey may have expired since
+func (k RedisKeys) WorkingChunksKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks:working"
+}
+
+// MatchedFilesKey is a set of the files of a job known to match, kept for
+// jobs in a mode settled by the first match
+func (k RedisKeys) MatchedFilesKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":files:matched"
+}
+
+// ChunkMatchesKey is a hash of the number of lines chunks of a job selected by
+// chunk ID, kept while the job is being dispatched
+func (k RedisKeys) ChunkMatchesKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":chunks:matches"
+}
+
+// FileMatchesKey is a hash of the number of lines the reported chunks of a
+// file selected by file, kept while the job is being dispatched
+func (k RedisKeys) FileMatchesKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":files:matches"
+}
+
+// JobStreamKey is a list of the IDs of the results a stream of a job sent,
+// in the order it sent them
+func (k RedisKeys) JobStreamKey(jobID, streamID string) string {
+	return k.prefix() + "job:" + jobID + ":stream:" + streamID
+}
+
+// JobStreamsKey is a set of the IDs of the streams of a job that sent
+// results, their JobStreamKey may have expired since
+func (k RedisKeys) JobStreamsKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":streams"
+}
+
+// JobTombstoneKey remembers that a job was deleted
+func (k RedisKeys) JobTombstoneKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":deleted"
+}
+
+// WebhookKey holds the webhook of a job and the state of its delivery
+func (k RedisKeys) WebhookKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":webhook"
+}
+
+// WebhookAttemptsKey is a list of the delivery attempts of a job's webhook
+func (k RedisKeys) WebhookAttemptsKey(jobID string) string {
+	return k.prefix() + "job:" + jobID + ":webhook:attempts"
+}
+
+// JobsIndexKey is a sorted set of all job IDs scored by creation time
+func (k RedisKeys) JobsIndexKey() string {
+	return k.prefix() + "jobs:by_created"
+}
+
+// JobsByStatusKey is a sorted set of job IDs in a status scored by creation time
+func (k RedisKeys) JobsByStatusKey(status JobStatus) string {
+	return k.prefix() + "jobs:by_status:" + string(status)
+}
+
+// JobsByRequestKey is a sorted set of job IDs of a request scored by creation time
+func (k RedisKeys) JobsByRequestKey(requestID string) string {
+	return k.prefix() + "jobs:by_request:" + requestID
+}
+
+// IdempotencyKey holds the job created for an idempotency key
+func (k RedisKeys) IdempotencyKey(key string) string {
+	return k.prefix() + "idempotency:" + key
+}
+
+// SavedSearchKey holds a saved search
+func (k RedisKeys) SavedSearchKey(searchID string) string {
+	return k.prefix() + "search:" + searchID
+}
+
+// SavedSearchesKey is a sorted set of all saved search IDs scored by creation time
+func (k RedisKeys) SavedSearchesKey() string {
+	return k.prefix() + "searches"
+}
+
+// SearchRunsKey is a hash of the runs of a saved search keyed by job ID
+func (k RedisKeys) SearchRunsKey(searchID string) string {
+	return k.prefix() + "search:" + searchID + ":runs"
+}
+
+// SearchRunsIndexKey is a sorted set of the job IDs of a saved search scored
+// by run time
+func (k RedisKeys) SearchRunsIndexKey(searchID string) string {
+	return k.prefix() + "search:" + searchID + ":runs:by_created"
+}
+
+// ScheduledSearchesKey is a sorted set of scheduled searches of all tenants
+// scored by their next run time, members are <tenant>/<search ID>
+const ScheduledSearchesKey = "searches:scheduled"
+
+// JobQueueKey is a sorted set of the jobs of all tenants whose chunks are
+// still being dispatched, scored by QueueScore, members are <tenant>/<job ID>
+const JobQueueKey = "jobs:queue"
+
+// ExpiringJobsKey is a sorted set of finished jobs of all tenants, scored by
+// their expiry time, members are <tenant>/<job ID>
+const ExpiringJobsKey = "jobs:expiring"
+
+// UnretiredJobsKey is a sorted set of finished jobs of all tenants whose
+// expiry is not scheduled yet, scored by when they finished, members are
+// <tenant>/<job ID>
+const UnretiredJobsKey = "jobs:unretired"
+
+// InFlightChunksKey is a sorted set of the chunks of all tenants handed to
+// mappers and not reported back, scored by the time they are given up on,
+// members are <tenant>/<job ID>/<chunk ID>
+const InFlightChunksKey = "chunks:in_flight"
+
+// InteractiveJobsKey is a set of the interactive jobs of all tenants that did
+// not finish yet, batch jobs are paused while it is not empty. Members are
+// <tenant>/<job ID>.
+const InteractiveJobsKey = "jobs:interactive"
+
+// ChunkSizeKey holds the chunk size the managers split files with, so that
+// the gateway estimates jobs the way they are dispatched
+const ChunkSizeKey = "dispatch:chunk_size"
+
+// PendingWebhooksKey is a sorted set of webhooks of all tenants that are due
+// for delivery scored by their next attempt, members are <tenant>/<job ID>
+const PendingWebhooksKey = "webhooks:pending"
+
+// QuotaKey counts usage of a quota within one window, e.g. jobs:29061440
+func (k RedisKeys) QuotaKey(name string) string {
+	return k.prefix() + "quota:" + name
+}
+
+// RunningJobsKey is a sorted set of the IDs of the pending and processing
+// jobs counted against one running jobs limit, scored by admission time
+func (k RedisKeys) RunningJobsKey(name string) string {
+	return k.prefix() + "running:" + name
 }
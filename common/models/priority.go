@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// JobPriority is the class a job is dispatched in, the chunks of higher
+// classes are handed to mappers first
+type JobPriority string
+
+const (
+	PriorityInteractive JobPriority = "interactive" // Someone is waiting for the result, e.g. during an incident
+	PriorityNormal      JobPriority = "normal"      // The default
+	PriorityBatch       JobPriority = "batch"       // Bulk jobs, paused while interactive jobs are pending
+)
+
+// Rank orders priorities for dispatch, lower ranks go first. An empty
+// priority is normal, unknown ones rank last.
+func (p JobPriority) Rank() int {
+	switch p {
+	case PriorityInteractive:
+		return 0
+	case PriorityNormal, "":
+		return 1
+	case PriorityBatch:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// queueRankScale separates the scores of priorities in JobQueueKey.
+// Milliseconds stay below 1e13 until the year 2286, so every rank has its own
+// range of scores, all exactly representable as float64.
+const queueRankScale = 1e13
+
+// QueueScore orders jobs in JobQueueKey, by priority and then by creation
+func QueueScore(priority JobPriority, createdAt time.Time) float64 {
+	return float64(priority.Rank())*queueRankScale + float64(createdAt.UnixMilli())
+}
+
+// QueuePriority returns the priority of a job from its score in JobQueueKey,
+// unknown priorities are returned as batch
+func QueuePriority(score float64) JobPriority {
+	switch int(score / queueRankScale) {
+	case 0:
+		return PriorityInteractive
+	case 1:
+		return PriorityNormal
+	default:
+		return PriorityBatch
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+// QueryOp is the boolean operator of a Query node
+type QueryOp string
+
+const (
+	QueryOpAnd QueryOp = "AND" // Every child matches
+	QueryOpOr  QueryOp = "OR"  // At least one child matches
+	QueryOpNot QueryOp = "NOT" // The only child does not match
+)
+
+// Query is a boolean expression of patterns evaluated against each line. A
+// node is either a leaf holding a Pattern with its own search options, or an
+// operator combining its Children.
+type Query struct {
+	ID       string  `json:"id,omitempty"`       // Label of a leaf reported in Match.MatchedPatterns
+	Op       QueryOp `json:"op,omitempty"`       // Operator, empty for leaves
+	Children []Query `json:"children,omitempty"` // Operands of Op, NOT takes exactly one
+
+	// Leaf pattern and its search options
+	Pattern       string `json:"pattern,omitempty"`
+	CaseSensitive bool   `json:"case_sensitive,omitempty"`
+	Regex         bool   `json:"regex,omitempty"`
+}
+
+// IsLeaf reports whether the node is a pattern rather than an operator
+func (q *Query) IsLeaf() bool {
+	return q.Op == ""
+}
+
+// Label identifies a leaf in Match.MatchedPatterns, its ID or else its pattern
+func (q *Query) Label() string {
+	if q.ID != "" {
+		return q.ID
+	}
+	return q.Pattern
+}
+
+// Patterns returns the patterns of all leaves, depth first
+func (q *Query) Patterns() []string {
+	if q == nil {
+		return nil
+	}
+	if q.IsLeaf() {
+		return []string{q.Pattern}
+	}
+
+	var patterns []string
+	for i := range q.Children {
+		patterns = append(patterns, q.Children[i].Patterns()...)
+	}
+	return patterns
+}
+
+// Clone returns a deep copy of the query, nil stays nil
+func (q *Query) Clone() *Query {
+	if q == nil {
+		return nil
+	}
+
+	clone := *q
+	if q.Children != nil {
+		clone.Children = make([]Query, len(q.Children))
+		for i := range q.Children {
+			clone.Children[i] = *q.Children[i].Clone()
+		}
+	}
+	return &clone
+}
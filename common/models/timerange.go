@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// TimestampFormat is a way log lines carry the time they were logged at
+type TimestampFormat string
+
+const (
+	TimestampRFC3339     TimestampFormat = "rfc3339"      // 2024-01-02T15:04:05.000Z, also with a space or without a zone
+	TimestampSyslog      TimestampFormat = "syslog"       // Jan  2 15:04:05 at the start of the line
+	TimestampNginx       TimestampFormat = "nginx"        // [02/Jan/2024:15:04:05 +0000]
+	TimestampEpochMillis TimestampFormat = "epoch_millis" // 1704207845000
+)
+
+// TimeRange restricts a job to the lines logged within [Since, Until). Either
+// bound may be left open.
+type TimeRange struct {
+	Since   *time.Time        `json:"since,omitempty"`   // Earliest time included
+	Until   *time.Time        `json:"until,omitempty"`   // Latest time excluded
+	Formats []TimestampFormat `json:"formats,omitempty"` // Formats tried on each line in order, all of them if empty
+}
+
+// Clone returns a deep copy of the range, nil stays nil
+func (r *TimeRange) Clone() *TimeRange {
+	if r == nil {
+		return nil
+	}
+
+	clone := &TimeRange{Formats: append([]TimestampFormat(nil), r.Formats...)}
+	if r.Since != nil {
+		since := *r.Since
+		clone.Since = &since
+	}
+	if r.Until != nil {
+		until := *r.Until
+		clone.Until = &until
+	}
+	return clone
+}
@@ -1 +1,146 @@
 package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	gonats "github.com/nats-io/nats.go"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Subjects used between the services
+const (
+	SubjectJobs        = "grep.jobs"          // models.JobMessage, API -> manager
+	SubjectJobCancel   = "grep.jobs.cancel"   // models.CancelMessage, API -> everyone
+	SubjectJobFinished = "grep.jobs.finished" // models.JobFinishedMessage, everyone -> API
+	SubjectChunks      = "grep.chunks"        // models.ChunkMessage, manager -> mappers
+	SubjectFileMatched = "grep.files.matched" // models.FileMatchedMessage, mappers -> manager and mappers
+	SubjectResults     = "grep.results"       // models.ResultMessage, mappers -> manager and API
+)
+
+// NatsOptions configures the NATS connection
+type NatsOptions struct {
+	URL  string `mapstructure:"url"`
+	Name string `mapstructure:"name"`
+}
+
+// Bus represents the NATS connection wrapper
+type Bus struct {
+	options *NatsOptions
+	conn    *gonats.Conn
+}
+
+// New returns a new Bus, call Instantiate before using it
+func New(url, name string, log *logger.Logger) *Bus {
+	InitLogger(log)
+
+	return &Bus{
+		options: &NatsOptions{
+			URL:  url,
+			Name: name,
+		},
+	}
+}
+
+// Instantiate connects to the NATS server
+func (b *Bus) Instantiate(ctx context.Context) error {
+	conn, err := gonats.Connect(b.options.URL,
+		gonats.Name(b.options.Name),
+		gonats.MaxReconnects(-1),
+		gonats.DisconnectErrHandler(func(_ *gonats.Conn, err error) {
+			if err != nil {
+				log.Warn("disconnected from nats", "err", err)
+			}
+		}),
+		gonats.ReconnectHandler(func(c *gonats.Conn) {
+			log.Info("reconnected to nats", "url", c.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		log.Error("failed to connect to nats", "url", b.options.URL, "err", err)
+		return fmt.Errorf("failed to connect to nats at %s: %w", b.options.URL, err)
+	}
+
+	b.conn = conn
+
+	log.Info("nats initialized successfully", "url", b.options.URL)
+	return nil
+}
+
+// Publish JSON encodes msg and publishes it on subject
+func (b *Bus) Publish(ctx context.Context, subject string, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message for %s: %w", subject, err)
+	}
+
+	if err := b.conn.Publish(subject, data); err != nil {
+		metrics.PublishErrors.WithLabelValues(subject).Inc()
+		log.Error("failed to publish message", "subject", subject, "err", err)
+		return fmt.Errorf("failed to publish on %s: %w", subject, err)
+	}
+
+	metrics.MessagesPublished.WithLabelValues(subject).Inc()
+	return nil
+}
+
+// Subscribe delivers every message on subject to handler
+func (b *Bus) Subscribe(subject string, handler func(data []byte)) (*gonats.Subscription, error) {
+	sub, err := b.conn.Subscribe(subject, consume(subject, handler))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	return sub, nil
+}
+
+// QueueSubscribe delivers each message on subject to only one member of queue
+func (b *Bus) QueueSubscribe(subject, queue string, handler func(data []byte)) (*gonats.Subscription, error) {
+	sub, err := b.conn.QueueSubscribe(subject, queue, consume(subject, handler))
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue subscribe to %s: %w", subject, err)
+	}
+
+	return sub, nil
+}
+
+// consume adapts handler to the NATS client and counts the messages it gets
+func consume(subject string, handler func(data []byte)) gonats.MsgHandler {
+	consumed := metrics.MessagesConsumed.WithLabelValues(subject)
+	return func(m *gonats.Msg) {
+		consumed.Inc()
+		handler(m.Data)
+	}
+}
+
+// Ping checks that the connection to NATS is up
+func (b *Bus) Ping(ctx context.Context) error {
+	if b.conn == nil {
+		return fmt.Errorf("not connected to nats")
+	}
+	if status := b.conn.Status(); status != gonats.CONNECTED {
+		return fmt.Errorf("nats connection is %s", status)
+	}
+	return nil
+}
+
+// Close drains pending messages and closes the connection
+func (b *Bus) Close() error {
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Drain()
+}
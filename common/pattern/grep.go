@@ -0,0 +1,256 @@
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Options are the search options of a job, named after the GNU grep flags
+// they mirror
+type Options struct {
+	Regex         bool // Interpret the pattern as RE2 rather than a fixed string, leaves of a query carry their own
+	CaseSensitive bool // Match case, leaves of a query carry their own
+	InvertMatch   bool // -v, select the lines that do not match
+	WordRegexp    bool // -w, only match whole words
+	LineRegexp    bool // -x, only match whole lines, takes precedence over WordRegexp
+	OnlyMatching  bool // -o, report the matched parts of selected lines
+	MaxCount      int  // -m, stop after this many selected lines of a file, 0 for no limit
+}
+
+// ChunkOptions returns the options a chunk is searched with. In modes settled
+// by the first match the search stops at the first selected line.
+func ChunkOptions(msg *models.ChunkMessage) Options {
+	opts := Options{
+		Regex:         msg.Regex,
+		CaseSensitive: msg.CaseSensitive,
+		InvertMatch:   msg.InvertMatch,
+		WordRegexp:    msg.WordRegexp,
+		LineRegexp:    msg.LineRegexp,
+		OnlyMatching:  msg.OnlyMatching,
+		MaxCount:      msg.MaxCount,
+	}
+	if msg.Mode.SettledByFirstMatch() {
+		opts.MaxCount = 1
+	}
+	return opts
+}
+
+// Selection is what is reported about a selected line
+type Selection struct {
+	Parts  []string // Matched parts of the line in order, only with OnlyMatching
+	Labels []string // Query patterns or fixed strings found on the line, not for a single pattern
+}
+
+// Grep selects the lines of a file the way GNU grep does. It is stateful
+// because of MaxCount, call Reset before moving on to another file.
+type Grep struct {
+	opts     Options
+	single   *finder
+	set      *literalSet
+	query    *Matcher
+	selected int
+}
+
+// NewGrep compiles a pattern, or a query when q is set, with opts
+func NewGrep(pattern string, q *models.Query, opts Options) (*Grep, error) {
+	if opts.MaxCount < 0 {
+		return nil, fmt.Errorf("max count must not be negative")
+	}
+
+	g := &Grep{opts: opts}
+	if q != nil {
+		m, err := compileQuery(q, opts)
+		if err != nil {
+			return nil, err
+		}
+		g.query = m
+		return g, nil
+	}
+
+	re, err := Compile(pattern, opts.Regex, opts.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+	if g.single, err = newFinder(re, opts); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// NewLiteralGrep compiles a list of fixed strings with opts, a line is
+// selected when it contains any of them. The strings found on a line are
+// reported as its labels. Regex is ignored.
+func NewLiteralGrep(literals []string, opts Options) (*Grep, error) {
+	if opts.MaxCount < 0 {
+		return nil, fmt.Errorf("max count must not be negative")
+	}
+
+	set, err := newLiteralSet(literals, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Grep{opts: opts, set: set}, nil
+}
+
+// NewChunkGrep compiles what a chunk is searched for with ChunkOptions
+func NewChunkGrep(msg *models.ChunkMessage) (*Grep, error) {
+	if len(msg.Patterns) > 0 {
+		return NewLiteralGrep(msg.Patterns, ChunkOptions(msg))
+	}
+	return NewGrep(msg.Pattern, msg.Query, ChunkOptions(msg))
+}
+
+// Select reports whether line is selected and what was found on it. Once
+// MaxCount lines were selected no further line is.
+func (g *Grep) Select(line string) (Selection, bool) {
+	if g.Done() {
+		return Selection{}, false
+	}
+
+	locate := g.opts.OnlyMatching && !g.opts.InvertMatch
+
+	var (
+		sel     Selection
+		locs    [][]int
+		matched bool
+	)
+	if g.query != nil {
+		sel.Labels, locs, matched = g.query.match(line, locate)
+	} else if g.set != nil {
+		sel.Labels, locs, matched = g.set.match(line, locate)
+	} else if locate {
+		locs = g.single.find(line)
+		matched = len(locs) > 0 || g.single.match(line)
+	} else {
+		matched = g.single.match(line)
+	}
+
+	if matched == g.opts.InvertMatch {
+		return Selection{}, false
+	}
+	g.selected++
+
+	// Inverted lines matched nothing to report
+	if g.opts.InvertMatch {
+		return Selection{}, true
+	}
+	if locate {
+		sel.Parts = parts(line, locs)
+	}
+	return sel, true
+}
+
+// Done reports whether MaxCount lines of the current file were selected
+func (g *Grep) Done() bool {
+	return g.opts.MaxCount > 0 && g.selected >= g.opts.MaxCount
+}
+
+// Reset starts counting selected lines of a new file
+func (g *Grep) Reset() {
+	g.selected = 0
+}
+
+// finder locates a compiled pattern on a line, honouring -w and -x
+type finder struct {
+	re   *regexp.Regexp
+	word *regexp.Regexp // With -w, the pattern between non-word characters
+}
+
+func newFinder(re *regexp.Regexp, opts Options) (*finder, error) {
+	expr := re.String()
+	f := &finder{re: re}
+
+	var err error
+	switch {
+	case opts.LineRegexp:
+		f.re, err = regexp.Compile(`^(?:` + expr + `)$`)
+	case opts.WordRegexp:
+		f.word, err = regexp.Compile(`(?:^|[^\pL\pN_])(` + expr + `)(?:[^\pL\pN_]|$)`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %q: %w", expr, err)
+	}
+
+	// Report the leftmost-longest match like POSIX grep does
+	f.re.Longest()
+	if f.word != nil {
+		f.word.Longest()
+	}
+	return f, nil
+}
+
+func (f *finder) match(line string) bool {
+	if f.word != nil {
+		return f.word.MatchString(line)
+	}
+	return f.re.MatchString(line)
+}
+
+// find returns the locations of the non-empty matches on line
+func (f *finder) find(line string) [][]int {
+	var locs [][]int
+	for _, loc := range f.re.FindAllStringIndex(line, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		if f.word != nil && !isWord(line, loc[0], loc[1]) {
+			continue
+		}
+		locs = append(locs, loc)
+	}
+
+	// A whole word may hide behind a longer match that is not one
+	if f.word != nil && len(locs) == 0 {
+		if m := f.word.FindStringSubmatchIndex(line); m != nil && m[2] < m[3] {
+			locs = append(locs, m[2:4])
+		}
+	}
+	return locs
+}
+
+// isWord reports whether line[start:end] is neither preceded nor followed by
+// a word constituent, a letter, digit or underscore
+func isWord(line string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(line[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(line) {
+		if r, _ := utf8.DecodeRuneInString(line[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parts returns the text at locs in order of position, matches overlapping an
+// earlier one are dropped as grep continues after the end of each match
+func parts(line string, locs [][]int) []string {
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i][0] != locs[j][0] {
+			return locs[i][0] < locs[j][0]
+		}
+		return locs[i][1] > locs[j][1]
+	})
+
+	var out []string
+	end := 0
+	for _, loc := range locs {
+		if loc[0] < end {
+			continue
+		}
+		out = append(out, line[loc[0]:loc[1]])
+		end = loc[1]
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// selectAll runs every line through g and returns the selected ones
+func selectAll(g *Grep, lines []string) ([]string, []Selection) {
+	var selected []string
+	var selections []Selection
+	for _, line := range lines {
+		if sel, ok := g.Select(line); ok {
+			selected = append(selected, line)
+			selections = append(selections, sel)
+		}
+	}
+	return selected, selections
+}
+
+func TestGrep(t *testing.T) {
+	lines := []string{"foo foo", "foobar foo", "foobar", "a,a", "FOO"}
+
+	// Expectations were taken from GNU grep 3.8
+	tests := []struct {
+		name     string
+		pattern  string
+		opts     Options
+		selected []string
+		parts    [][]string
+	}{
+		{"Plain", "foo", Options{CaseSensitive: true}, []string{"foo foo", "foobar foo", "foobar"}, nil},
+		{"Invert", "foo", Options{CaseSensitive: true, InvertMatch: true}, []string{"a,a", "FOO"}, nil},
+		{"Word", "foo", Options{CaseSensitive: true, WordRegexp: true}, []string{"foo foo", "foobar foo"}, nil},
+		{"Line", "foo", Options{LineRegexp: true}, []string{"FOO"}, nil},
+		{"LineOverWord", "foo", Options{LineRegexp: true, WordRegexp: true}, []string{"FOO"}, nil},
+		{"OnlyMatchingWord", "foo", Options{WordRegexp: true, OnlyMatching: true}, []string{"foo foo", "foobar foo", "FOO"}, [][]string{{"foo", "foo"}, {"foo"}, {"FOO"}}},
+		{"OnlyMatchingAdjacentWords", "a", Options{WordRegexp: true, OnlyMatching: true, CaseSensitive: true}, []string{"a,a"}, [][]string{{"a", "a"}}},
+		{"MaxCount", "foo", Options{MaxCount: 2}, []string{"foo foo", "foobar foo"}, nil},
+		{"InvertMaxCount", "a", Options{InvertMatch: true, MaxCount: 1}, []string{"foo foo"}, nil},
+		{"InvertOnlyMatching", "foo", Options{InvertMatch: true, OnlyMatching: true, CaseSensitive: true}, []string{"a,a", "FOO"}, [][]string{nil, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGrep(tt.pattern, nil, tt.opts)
+			require.NoError(t, err)
+
+			selected, selections := selectAll(g, lines)
+			assert.Equal(t, tt.selected, selected)
+			if tt.parts != nil {
+				var parts [][]string
+				for _, sel := range selections {
+					parts = append(parts, sel.Parts)
+				}
+				assert.Equal(t, tt.parts, parts)
+			}
+		})
+	}
+}
+
+func TestGrepOnlyMatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    Options
+		line    string
+		parts   []string
+	}{
+		{"LeftmostLongest", "ab|abc", Options{Regex: true}, "abcd", []string{"abc"}},
+		{"EmptyMatchesAreSkipped", "x*", Options{Regex: true}, "axxb", []string{"xx"}},
+		{"WordBehindLongerMatch", "foo.*", Options{Regex: true, WordRegexp: true}, "foobar baz", []string{"foobar baz"}},
+		{"WholeLine", "fo+", Options{Regex: true, LineRegexp: true}, "foo", []string{"foo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OnlyMatching = true
+			g, err := NewGrep(tt.pattern, nil, tt.opts)
+			require.NoError(t, err)
+
+			sel, ok := g.Select(tt.line)
+			require.True(t, ok)
+			assert.Equal(t, tt.parts, sel.Parts)
+		})
+	}
+}
+
+func TestGrepMaxCountReset(t *testing.T) {
+	g, err := NewGrep("x", nil, Options{MaxCount: 1})
+	require.NoError(t, err)
+
+	_, ok := g.Select("x")
+	assert.True(t, ok)
+	assert.True(t, g.Done())
+	_, ok = g.Select("x")
+	assert.False(t, ok)
+
+	g.Reset()
+	_, ok = g.Select("x")
+	assert.True(t, ok, "the count starts over for the next file")
+
+	_, err = NewGrep("x", nil, Options{MaxCount: -1})
+	assert.Error(t, err)
+}
+
+func TestGrepQuery(t *testing.T) {
+	// (timeout OR error) AND NOT retry, with whole words only
+	query := &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+		{Op: models.QueryOpOr, Children: []models.Query{{Pattern: "timeout"}, {Pattern: "error"}}},
+		{Op: models.QueryOpNot, Children: []models.Query{{Pattern: "retry"}}},
+	}}
+	g, err := NewGrep("", query, Options{WordRegexp: true, OnlyMatching: true})
+	require.NoError(t, err)
+
+	sel, ok := g.Select("error: read timeout")
+	require.True(t, ok)
+	assert.Equal(t, []string{"timeout", "error"}, sel.Labels)
+	assert.Equal(t, []string{"error", "timeout"}, sel.Parts, "parts are ordered by position")
+
+	_, ok = g.Select("errors: read timeouts")
+	assert.False(t, ok, "only whole words match")
+
+	_, ok = g.Select("error, will retry")
+	assert.False(t, ok)
+
+	g, err = NewGrep("", query, Options{InvertMatch: true})
+	require.NoError(t, err)
+	sel, ok = g.Select("error, will retry")
+	assert.True(t, ok)
+	assert.Empty(t, sel.Labels)
+}
+
+func TestChunkOptions(t *testing.T) {
+	msg := &models.ChunkMessage{Regex: true, InvertMatch: true, WordRegexp: true, OnlyMatching: true, MaxCount: 3}
+	assert.Equal(t, Options{Regex: true, InvertMatch: true, WordRegexp: true, OnlyMatching: true, MaxCount: 3}, ChunkOptions(msg))
+
+	msg = &models.ChunkMessage{Mode: models.SearchModeCount, MaxCount: 3}
+	assert.Equal(t, Options{MaxCount: 3}, ChunkOptions(msg))
+
+	for _, mode := range []models.SearchMode{models.SearchModeFilesWithMatches, models.SearchModeFilesWithoutMatch} {
+		msg = &models.ChunkMessage{Mode: mode}
+		assert.Equal(t, Options{MaxCount: 1}, ChunkOptions(msg), mode)
+	}
+}
@@ -0,0 +1,264 @@
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Budget keeping a list of fixed strings small enough to travel with every
+// chunk message
+const (
+	MaxLiterals     = 10000     // Fixed strings of a job
+	MaxLiteralBytes = 256 << 10 // Bytes of all fixed strings together
+)
+
+// ValidateLiterals checks a list of fixed strings. Invalid strings are
+// reported as *Error with Field set to their position, e.g. patterns[3].
+func ValidateLiterals(literals []string) error {
+	if len(literals) == 0 {
+		return fmt.Errorf("patterns must not be empty")
+	}
+	if len(literals) > MaxLiterals {
+		return fmt.Errorf("at most %d patterns are allowed, got %d", MaxLiterals, len(literals))
+	}
+
+	size := 0
+	for i, literal := range literals {
+		field := fmt.Sprintf("patterns[%d]", i)
+		if literal == "" {
+			return &Error{Field: field, Reason: "pattern must not be empty"}
+		}
+		if len(literal) > MaxLength {
+			return &Error{Field: field, Offset: MaxLength, Reason: fmt.Sprintf("pattern is longer than %d bytes", MaxLength)}
+		}
+		if !utf8.ValidString(literal) {
+			return &Error{Field: field, Offset: invalidUTF8Offset(literal), Reason: "pattern is not valid UTF-8"}
+		}
+		if size += len(literal); size > MaxLiteralBytes {
+			return &Error{Field: field, Reason: fmt.Sprintf("patterns are longer than %d bytes together", MaxLiteralBytes)}
+		}
+	}
+	return nil
+}
+
+// literalSet finds any of a list of fixed strings on a line in a single pass
+// over it, using an Aho-Corasick automaton. It is not safe for concurrent use.
+type literalSet struct {
+	opts     Options
+	literals []string // Deduplicated, in the order given
+	nodes    []acNode // Trie of the literals, nodes[0] is the root
+
+	// Compiled literals honouring -w, -x and -o, built for the few literals
+	// found on a line rather than for all of them upfront
+	finders []*finder
+}
+
+// acNode is a state of the automaton
+type acNode struct {
+	edges []acEdge // Ordered by byte
+	fail  int32    // Longest proper suffix of this state that is also a state
+	dict  int32    // Nearest state on the fail chain ending a literal, 0 for none
+	out   int32    // Literal ending at this state, -1 for none
+}
+
+type acEdge struct {
+	b    byte
+	next int32
+}
+
+func newLiteralSet(literals []string, opts Options) (*literalSet, error) {
+	if err := ValidateLiterals(literals); err != nil {
+		return nil, err
+	}
+
+	s := &literalSet{opts: opts, nodes: []acNode{{out: -1}}}
+	seen := make(map[string]bool, len(literals))
+	for _, literal := range literals {
+		key := s.fold(literal)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		s.insert(key, int32(len(s.literals)))
+		s.literals = append(s.literals, literal)
+	}
+	s.link()
+	s.finders = make([]*finder, len(s.literals))
+
+	return s, nil
+}
+
+// fold returns text the way the automaton sees it
+func (s *literalSet) fold(text string) string {
+	if s.opts.CaseSensitive {
+		return text
+	}
+	return strings.ToLower(text)
+}
+
+func (s *literalSet) insert(key string, literal int32) {
+	state := int32(0)
+	for i := 0; i < len(key); i++ {
+		next, ok := s.nodes[state].step(key[i])
+		if !ok {
+			next = int32(len(s.nodes))
+			s.nodes = append(s.nodes, acNode{out: -1})
+			s.nodes[state].add(key[i], next)
+		}
+		state = next
+	}
+	s.nodes[state].out = literal
+}
+
+// link sets the fail and dictionary links breadth first, so that the links
+// of shorter states are known before they are followed
+func (s *literalSet) link() {
+	queue := make([]int32, 0, len(s.nodes))
+	for _, e := range s.nodes[0].edges {
+		queue = append(queue, e.next)
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for _, e := range s.nodes[state].edges {
+			fail := s.nodes[state].fail
+			next, ok := s.nodes[fail].step(e.b)
+			for !ok && fail != 0 {
+				fail = s.nodes[fail].fail
+				next, ok = s.nodes[fail].step(e.b)
+			}
+			if !ok {
+				next = 0
+			}
+
+			child := &s.nodes[e.next]
+			child.fail = next
+			if s.nodes[next].out >= 0 {
+				child.dict = next
+			} else {
+				child.dict = s.nodes[next].dict
+			}
+			queue = append(queue, e.next)
+		}
+	}
+}
+
+func (n *acNode) step(b byte) (int32, bool) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].b >= b })
+	if i < len(n.edges) && n.edges[i].b == b {
+		return n.edges[i].next, true
+	}
+	return 0, false
+}
+
+func (n *acNode) add(b byte, next int32) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].b >= b })
+	n.edges = append(n.edges, acEdge{})
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = acEdge{b: b, next: next}
+}
+
+// scan returns the indexes of the literals occurring in line, in order
+func (s *literalSet) scan(line string) []int {
+	text := s.fold(line)
+
+	var hits []int
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		next, ok := s.nodes[state].step(text[i])
+		for !ok && state != 0 {
+			state = s.nodes[state].fail
+			next, ok = s.nodes[state].step(text[i])
+		}
+		if !ok {
+			continue
+		}
+
+		state = next
+		for out := state; out != 0; out = s.nodes[out].dict {
+			if s.nodes[out].out >= 0 {
+				hits = append(hits, int(s.nodes[out].out))
+			}
+		}
+	}
+
+	sort.Ints(hits)
+	return dedupe(hits)
+}
+
+// match reports whether any literal is found on line, together with the ones
+// that are and their locations when locate is set. Literals found by the
+// automaton are confirmed against -w and -x by their compiled pattern.
+func (s *literalSet) match(line string, locate bool) ([]string, [][]int, bool) {
+	hits := s.scan(line)
+	if len(hits) == 0 {
+		return nil, nil, false
+	}
+
+	confirm := s.opts.WordRegexp || s.opts.LineRegexp
+	labels := make([]string, 0, len(hits))
+	var locs [][]int
+	for _, hit := range hits {
+		if !confirm && !locate {
+			labels = append(labels, s.literals[hit])
+			continue
+		}
+
+		f, err := s.finder(hit)
+		if err != nil {
+			// Literals were validated upfront, this cannot happen
+			continue
+		}
+		if confirm && !f.match(line) {
+			continue
+		}
+
+		labels = append(labels, s.literals[hit])
+		if locate {
+			locs = append(locs, f.find(line)...)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil, nil, false
+	}
+	return labels, locs, true
+}
+
+func (s *literalSet) finder(literal int) (*finder, error) {
+	if f := s.finders[literal]; f != nil {
+		return f, nil
+	}
+
+	re, err := Compile(s.literals[literal], false, s.opts.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+	f, err := newFinder(re, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.finders[literal] = f
+	return f, nil
+}
+
+// dedupe removes repeated values from a sorted slice in place
+func dedupe(values []int) []int {
+	if len(values) < 2 {
+		return values
+	}
+
+	out := values[:1]
+	for _, v := range values[1:] {
+		if v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestValidateLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		literals []string
+		field    string // Field of the *Error, empty for plain errors
+	}{
+		{"Empty", nil, ""},
+		{"TooMany", make([]string, MaxLiterals+1), ""},
+		{"EmptyLiteral", []string{"a", ""}, "patterns[1]"},
+		{"InvalidUTF8", []string{"a", "b", "\xff"}, "patterns[2]"},
+		{"TooLong", []string{strings.Repeat("a", MaxLength+1)}, "patterns[0]"},
+		{"TooLarge", []string{strings.Repeat("a", MaxLength), strings.Repeat("b", MaxLiteralBytes)}, "patterns[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLiterals(tt.literals)
+			require.Error(t, err)
+
+			var patternErr *Error
+			if tt.field == "" {
+				assert.False(t, errors.As(err, &patternErr), err)
+				return
+			}
+			require.True(t, errors.As(err, &patternErr), err)
+			assert.Equal(t, tt.field, patternErr.Field)
+		})
+	}
+
+	assert.NoError(t, ValidateLiterals([]string{"198.51.100.7", "trace-4bf92f35"}))
+}
+
+func TestLiteralGrep(t *testing.T) {
+	tests := []struct {
+		name     string
+		literals []string
+		opts     Options
+		line     string
+		ok       bool
+		sel      Selection
+	}{
+		{"Overlapping", []string{"he", "she", "his", "hers"}, Options{CaseSensitive: true}, "ushers", true, Selection{Labels: []string{"he", "she", "hers"}}},
+		{"SuffixOfAnother", []string{"abcd", "bc"}, Options{CaseSensitive: true}, "xabcx", true, Selection{Labels: []string{"bc"}}},
+		{"NoMatch", []string{"he", "she"}, Options{CaseSensitive: true}, "hxsx", false, Selection{}},
+		{"CaseSensitive", []string{"Timeout"}, Options{CaseSensitive: true}, "timeout", false, Selection{}},
+		{"CaseInsensitive", []string{"Timeout"}, Options{}, "TIMEOUT after 5s", true, Selection{Labels: []string{"Timeout"}}},
+		{"Metacharacters", []string{"a.b", "(x)"}, Options{CaseSensitive: true}, "axb (x)", true, Selection{Labels: []string{"(x)"}}},
+		{"Duplicates", []string{"id=7", "ID=7", "id=7"}, Options{}, "user id=7", true, Selection{Labels: []string{"id=7"}}},
+		{"WordRegexp", []string{"err", "error"}, Options{WordRegexp: true}, "errors: error", true, Selection{Labels: []string{"error"}}},
+		{"WordRegexpNone", []string{"err"}, Options{WordRegexp: true}, "errors", false, Selection{}},
+		{"LineRegexp", []string{"ok", "done"}, Options{LineRegexp: true}, "done", true, Selection{Labels: []string{"done"}}},
+		{"LineRegexpNone", []string{"ok"}, Options{LineRegexp: true}, "ok then", false, Selection{}},
+		{"OnlyMatching", []string{"he", "she", "hers"}, Options{OnlyMatching: true}, "she says hers", true, Selection{Labels: []string{"he", "she", "hers"}, Parts: []string{"she", "hers"}}},
+		{"OnlyMatchingFolded", []string{"ERR"}, Options{OnlyMatching: true}, "err Err", true, Selection{Labels: []string{"ERR"}, Parts: []string{"err", "Err"}}},
+		{"Invert", []string{"debug", "trace"}, Options{InvertMatch: true}, "TRACE span", false, Selection{}},
+		{"InvertSelected", []string{"debug", "trace"}, Options{InvertMatch: true}, "error", true, Selection{}},
+		{"Unicode", []string{"Grüße"}, Options{}, "GRÜSSE grüße", true, Selection{Labels: []string{"Grüße"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewLiteralGrep(tt.literals, tt.opts)
+			require.NoError(t, err)
+
+			sel, ok := g.Select(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.sel, sel)
+		})
+	}
+}
+
+// TestLiteralSetBruteForce checks the automaton against strings.Contains on
+// random text over a small alphabet, where literals overlap a lot
+func TestLiteralSetBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func(n int) string {
+		b := make([]byte, 1+rng.Intn(n))
+		for i := range b {
+			b[i] = "abc"[rng.Intn(3)]
+		}
+		return string(b)
+	}
+
+	for round := 0; round < 200; round++ {
+		literals := make([]string, 1+rng.Intn(20))
+		for i := range literals {
+			literals[i] = word(5)
+		}
+		set, err := newLiteralSet(literals, Options{CaseSensitive: true})
+		require.NoError(t, err)
+
+		line := word(40)
+		var want []string
+		for _, literal := range set.literals {
+			if strings.Contains(line, literal) {
+				want = append(want, literal)
+			}
+		}
+
+		labels, _, ok := set.match(line, false)
+		assert.Equal(t, len(want) > 0, ok, fmt.Sprintf("%q in %q", literals, line))
+		assert.Equal(t, want, labels, fmt.Sprintf("%q in %q", literals, line))
+	}
+}
+
+func TestLiteralGrepMaxCount(t *testing.T) {
+	literals := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		literals = append(literals, fmt.Sprintf("user-%04d", i))
+	}
+
+	g, err := NewLiteralGrep(literals, Options{MaxCount: 1})
+	require.NoError(t, err)
+
+	sel, ok := g.Select("login by USER-0042 and user-0999")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"user-0042", "user-0999"}, sel.Labels)
+
+	_, ok = g.Select("user-0001")
+	assert.False(t, ok)
+}
+
+func TestNewChunkGrep(t *testing.T) {
+	g, err := NewChunkGrep(&models.ChunkMessage{Patterns: []string{"a.b"}})
+	require.NoError(t, err)
+	_, ok := g.Select("axb")
+	assert.False(t, ok)
+
+	g, err = NewChunkGrep(&models.ChunkMessage{Pattern: "a.b", Regex: true})
+	require.NoError(t, err)
+	_, ok = g.Select("axb")
+	assert.True(t, ok)
+
+	_, err = NewChunkGrep(&models.ChunkMessage{Patterns: []string{""}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// Budget keeping a pattern cheap enough to run against every line of a job
+const (
+	MaxLength      = 4096  // Bytes of pattern text
+	MaxProgramSize = 10000 // Instructions of the compiled regexp program
+)
+
+// Error explains why a pattern was rejected
+type Error struct {
+	Field  string // Query leaf holding the pattern, e.g. query.children[1], empty for a lone pattern
+	Offset int    // Byte offset in the pattern the error points at
+	Reason string // Human-readable explanation
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("invalid pattern in %s at byte %d: %s", e.Field, e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("invalid pattern at byte %d: %s", e.Offset, e.Reason)
+}
+
+// Compile compiles a grep pattern, regex selects RE2 syntax over a fixed
+// string. Rejected patterns are reported as *Error.
+func Compile(pattern string, regex, caseSensitive bool) (*regexp.Regexp, error) {
+	if len(pattern) > MaxLength {
+		return nil, &Error{Offset: MaxLength, Reason: fmt.Sprintf("pattern is longer than %d bytes", MaxLength)}
+	}
+	if !utf8.ValidString(pattern) {
+		return nil, &Error{Offset: invalidUTF8Offset(pattern), Reason: "pattern is not valid UTF-8"}
+	}
+
+	flags := syntax.Perl
+	if !regex {
+		flags = syntax.Literal
+	}
+	if !caseSensitive {
+		flags |= syntax.FoldCase
+	}
+
+	re, err := syntax.Parse(pattern, flags)
+	if err != nil {
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, &Error{Offset: errorOffset(pattern, syntaxErr), Reason: explain(syntaxErr)}
+		}
+		return nil, &Error{Reason: err.Error()}
+	}
+
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil, &Error{Reason: err.Error()}
+	}
+	if len(prog.Inst) > MaxProgramSize {
+		return nil, &Error{Reason: fmt.Sprintf("pattern is too complex, it compiles to %d instructions and at most %d are allowed, reduce repetition counts or alternatives", len(prog.Inst), MaxProgramSize)}
+	}
+
+	expr := pattern
+	if !regex {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	return regexp.Compile(expr)
+}
+
+// explain turns a syntax error into a sentence for users unfamiliar with RE2
+func explain(err *syntax.Error) string {
+	switch err.Code {
+	case syntax.ErrInvalidCharClass:
+		return fmt.Sprintf("invalid character class %s", err.Expr)
+	case syntax.ErrInvalidCharRange:
+		return fmt.Sprintf("invalid character class range %s, the start must not be after the end", err.Expr)
+	case syntax.ErrInvalidEscape:
+		return fmt.Sprintf("invalid escape sequence %s", err.Expr)
+	case syntax.ErrInvalidNamedCapture:
+		return fmt.Sprintf("invalid named capture group %s", err.Expr)
+	case syntax.ErrInvalidPerlOp:
+		return fmt.Sprintf("unsupported group syntax %s, lookarounds and backreferences are not available", err.Expr)
+	case syntax.ErrInvalidRepeatOp:
+		return fmt.Sprintf("repetition operator %s has nothing to repeat", err.Expr)
+	case syntax.ErrInvalidRepeatSize:
+		return fmt.Sprintf("invalid repetition count %s, counts go up to 1000 and the minimum must not exceed the maximum", err.Expr)
+	case syntax.ErrMissingBracket:
+		return "character class is never closed, missing ]"
+	case syntax.ErrMissingParen:
+		return "group is never closed, missing )"
+	case syntax.ErrMissingRepeatArgument:
+		return fmt.Sprintf("repetition operator %s has nothing to repeat", err.Expr)
+	case syntax.ErrTrailingBackslash:
+		return "pattern ends with an unescaped backslash"
+	case syntax.ErrUnexpectedParen:
+		return "unexpected ), there is no group to close"
+	case syntax.ErrNestingDepth:
+		return "pattern nests groups or repetitions too deeply"
+	case syntax.ErrLarge:
+		return "pattern is too large"
+	default:
+		return err.Error()
+	}
+}
+
+// errorOffset locates a syntax error in the pattern. The parser only reports
+// the offending fragment, so unbalanced parentheses are found by scanning.
+func errorOffset(pattern string, err *syntax.Error) int {
+	switch err.Code {
+	case syntax.ErrMissingParen, syntax.ErrUnexpectedParen:
+		if offset, ok := unbalancedParen(pattern); ok {
+			return offset
+		}
+	case syntax.ErrTrailingBackslash:
+		return len(pattern) - 1
+	case syntax.ErrMissingBracket:
+		// The fragment runs from the opening bracket to the end
+		if strings.HasSuffix(pattern, err.Expr) {
+			return len(pattern) - len(err.Expr)
+		}
+	}
+
+	if offset := strings.Index(pattern, err.Expr); offset >= 0 {
+		return offset
+	}
+	return 0
+}
+
+// unbalancedParen returns the offset of the first ')' without a group to
+// close, or else of the innermost '(' that is never closed
+func unbalancedParen(pattern string) (int, bool) {
+	var open []int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if strings.HasPrefix(pattern[i:], `\Q`) {
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					i = len(pattern)
+				} else {
+					i += end + 3
+				}
+				continue
+			}
+			i++
+		case '[':
+			i = classEnd(pattern, i)
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				return i, true
+			}
+			open = open[:len(open)-1]
+		}
+	}
+
+	if len(open) > 0 {
+		return open[len(open)-1], true
+	}
+	return 0, false
+}
+
+// classEnd returns the offset of the ']' closing the class opened at start
+func classEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+
+	for ; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++
+		case strings.HasPrefix(pattern[i:], "[:"):
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				i += end + 3
+			}
+		case pattern[i] == ']':
+			return i
+		}
+	}
+	return len(pattern)
+}
+
+func invalidUTF8Offset(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package pattern
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	re, err := Compile("error.*timeout", true, false)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("ERROR: read timeout"))
+
+	re, err = Compile("error.*timeout", true, true)
+	require.NoError(t, err)
+	assert.False(t, re.MatchString("ERROR: read timeout"))
+
+	re, err = Compile("a+(b", false, false)
+	require.NoError(t, err, "fixed strings are never invalid")
+	assert.True(t, re.MatchString("x A+(B y"))
+	assert.False(t, re.MatchString("aab"))
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		offset  int
+		reason  string
+	}{
+		{"MissingParen", `ok (a|b`, 3, "missing )"},
+		{"InnermostMissingParen", `(a(b)c(d`, 6, "missing )"},
+		{"UnexpectedParen", `a)(b)`, 1, "unexpected )"},
+		{"ParenInClass", `[(]x)`, 4, "unexpected )"},
+		{"EscapedParen", `\((a`, 2, "missing )"},
+		{"MissingBracket", `ab[cd`, 2, "missing ]"},
+		{"InvalidRange", `x[z-a]`, 2, "range z-a"},
+		{"InvalidEscape", `foo\q`, 3, `escape sequence \q`},
+		{"Lookahead", `foo(?=bar)`, 3, "lookarounds"},
+		{"NothingToRepeat", `ab**`, 2, "nothing to repeat"},
+		{"RepeatSize", `a{2000}`, 1, "counts go up to 1000"},
+		{"TrailingBackslash", `abc\`, 3, "unescaped backslash"},
+		{"InvalidUTF8", "ab\xffc", 2, "UTF-8"},
+		{"TooLong", strings.Repeat("a", MaxLength+1), MaxLength, "longer than"},
+		{"TooComplex", `(\w+\s*=\w+\s*){1000}`, 0, "too complex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.pattern, true, false)
+			require.Error(t, err)
+
+			var patternErr *Error
+			require.True(t, errors.As(err, &patternErr), "unexpected error %v", err)
+			assert.Equal(t, tt.offset, patternErr.Offset)
+			assert.Contains(t, patternErr.Reason, tt.reason)
+		})
+	}
+}
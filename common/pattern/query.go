@@ -0,0 +1,197 @@
+package pattern
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Budget keeping a query cheap enough to evaluate against every line of a job
+const (
+	MaxQueryDepth    = 8  // Levels of nested operators below the root
+	MaxQueryPatterns = 32 // Leaves of the whole query
+)
+
+// Matcher evaluates a compiled models.Query against lines
+type Matcher struct {
+	root    node
+	labels  []string  // Label of each leaf, indexed like leaves
+	negated []bool    // Whether each leaf sits below an odd number of NOTs
+	leaves  []*finder // Compiled pattern of each leaf, depth first
+}
+
+// node is a query node whose leaves refer to Matcher.leaves by index
+type node struct {
+	op       models.QueryOp
+	leaf     int
+	children []node
+}
+
+// CompileQuery validates and compiles a query. Invalid leaf patterns are
+// reported as *Error with Field set to the leaf, other mistakes as plain
+// errors naming the offending node.
+func CompileQuery(q *models.Query) (*Matcher, error) {
+	return compileQuery(q, Options{})
+}
+
+// compileQuery compiles a query whose leaves honour the word and line options
+func compileQuery(q *models.Query, opts Options) (*Matcher, error) {
+	if q == nil {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+
+	m := &Matcher{}
+	c := queryCompiler{opts: opts, seen: make(map[string]string)}
+	root, err := c.compile(m, q, "query", 0, false)
+	if err != nil {
+		return nil, err
+	}
+	m.root = root
+
+	return m, nil
+}
+
+// queryCompiler holds the state of compiling one query
+type queryCompiler struct {
+	opts Options
+	seen map[string]string // Field of the leaf using each label
+}
+
+func (c *queryCompiler) compile(m *Matcher, q *models.Query, field string, depth int, negated bool) (node, error) {
+	if depth > MaxQueryDepth {
+		return node{}, fmt.Errorf("%s: query nests operators more than %d levels deep", field, MaxQueryDepth)
+	}
+
+	switch q.Op {
+	case "":
+		return c.compileLeaf(m, q, field, negated)
+	case models.QueryOpAnd, models.QueryOpOr:
+		if len(q.Children) == 0 {
+			return node{}, fmt.Errorf("%s: %s needs at least one operand", field, q.Op)
+		}
+	case models.QueryOpNot:
+		if len(q.Children) != 1 {
+			return node{}, fmt.Errorf("%s: NOT takes exactly one operand", field)
+		}
+	default:
+		return node{}, fmt.Errorf("%s: unknown operator %q, expected AND, OR or NOT", field, q.Op)
+	}
+
+	if q.Pattern != "" || q.ID != "" {
+		return node{}, fmt.Errorf("%s: operators carry neither a pattern nor an id", field)
+	}
+
+	if q.Op == models.QueryOpNot {
+		negated = !negated
+	}
+
+	n := node{op: q.Op, children: make([]node, 0, len(q.Children))}
+	for i := range q.Children {
+		child, err := c.compile(m, &q.Children[i], fmt.Sprintf("%s.children[%d]", field, i), depth+1, negated)
+		if err != nil {
+			return node{}, err
+		}
+		n.children = append(n.children, child)
+	}
+
+	return n, nil
+}
+
+func (c *queryCompiler) compileLeaf(m *Matcher, q *models.Query, field string, negated bool) (node, error) {
+	if len(q.Children) > 0 {
+		return node{}, fmt.Errorf("%s: patterns cannot have operands, set op to combine them", field)
+	}
+	if q.Pattern == "" {
+		return node{}, fmt.Errorf("%s: pattern must not be empty", field)
+	}
+	if len(m.leaves) == MaxQueryPatterns {
+		return node{}, fmt.Errorf("%s: query has more than %d patterns", field, MaxQueryPatterns)
+	}
+
+	label := q.Label()
+	if other, ok := c.seen[label]; ok {
+		return node{}, fmt.Errorf("%s: label %q is already used by %s, give the patterns distinct ids", field, label, other)
+	}
+	c.seen[label] = field
+
+	re, err := Compile(q.Pattern, q.Regex, q.CaseSensitive)
+	if err != nil {
+		var patternErr *Error
+		if errors.As(err, &patternErr) {
+			patternErr.Field = field
+		}
+		return node{}, err
+	}
+
+	f, err := newFinder(re, c.opts)
+	if err != nil {
+		return node{}, err
+	}
+
+	m.leaves = append(m.leaves, f)
+	m.labels = append(m.labels, label)
+	m.negated = append(m.negated, negated)
+	return node{leaf: len(m.leaves) - 1}, nil
+}
+
+// Match reports whether line satisfies the query, together with the labels of
+// every pattern found on it. Negated patterns are included when they occur on
+// a line that matches nonetheless, e.g. through an OR.
+func (m *Matcher) Match(line string) ([]string, bool) {
+	labels, _, ok := m.match(line, false)
+	return labels, ok
+}
+
+// match is Match, also locating the parts of line matched by patterns that
+// are not negated when locate is set
+func (m *Matcher) match(line string, locate bool) ([]string, [][]int, bool) {
+	hits := make([]bool, len(m.leaves))
+	found := 0
+	for i, f := range m.leaves {
+		if f.match(line) {
+			hits[i] = true
+			found++
+		}
+	}
+
+	if !m.root.eval(hits) {
+		return nil, nil, false
+	}
+
+	labels := make([]string, 0, found)
+	var locs [][]int
+	for i, hit := range hits {
+		if !hit {
+			continue
+		}
+		labels = append(labels, m.labels[i])
+		if locate && !m.negated[i] {
+			locs = append(locs, m.leaves[i].find(line)...)
+		}
+	}
+	return labels, locs, true
+}
+
+func (n node) eval(hits []bool) bool {
+	switch n.op {
+	case models.QueryOpAnd:
+		for _, child := range n.children {
+			if !child.eval(hits) {
+				return false
+			}
+		}
+		return true
+	case models.QueryOpOr:
+		for _, child := range n.children {
+			if child.eval(hits) {
+				return true
+			}
+		}
+		return false
+	case models.QueryOpNot:
+		return !n.children[0].eval(hits)
+	default:
+		return hits[n.leaf]
+	}
+}
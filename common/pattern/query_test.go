@@ -0,0 +1,129 @@
+package pattern
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func leaf(pattern string) models.Query {
+	return models.Query{Pattern: pattern}
+}
+
+func TestCompileQuery(t *testing.T) {
+	// timeout AND payment-service AND NOT healthcheck
+	query := &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+		leaf("timeout"),
+		{ID: "service", Pattern: "payment-service", CaseSensitive: true},
+		{Op: models.QueryOpNot, Children: []models.Query{leaf("healthcheck")}},
+	}}
+
+	m, err := CompileQuery(query)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		line   string
+		match  bool
+		labels []string
+	}{
+		{"AllTerms", "ERROR payment-service: upstream Timeout", true, []string{"timeout", "service"}},
+		{"Negated", "payment-service timeout on /healthcheck", false, nil},
+		{"MissingTerm", "payment-service ok", false, nil},
+		{"CaseSensitiveLeaf", "PAYMENT-SERVICE timeout", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, ok := m.Match(tt.line)
+			assert.Equal(t, tt.match, ok)
+			assert.Equal(t, tt.labels, labels)
+		})
+	}
+}
+
+func TestCompileQueryOr(t *testing.T) {
+	query := &models.Query{Op: models.QueryOpOr, Children: []models.Query{
+		{Pattern: `status=5\d\d`, Regex: true},
+		{Op: models.QueryOpNot, Children: []models.Query{leaf("GET")}},
+	}}
+
+	m, err := CompileQuery(query)
+	require.NoError(t, err)
+
+	labels, ok := m.Match("GET /pay status=503")
+	assert.True(t, ok)
+	assert.Equal(t, []string{`status=5\d\d`, "GET"}, labels, "negated patterns found on the line are reported too")
+
+	labels, ok = m.Match("POST /pay status=200")
+	assert.True(t, ok)
+	assert.Empty(t, labels)
+
+	_, ok = m.Match("GET /pay status=200")
+	assert.False(t, ok)
+}
+
+func TestCompileQueryErrors(t *testing.T) {
+	deep := leaf("x")
+	for i := 0; i <= MaxQueryDepth; i++ {
+		deep = models.Query{Op: models.QueryOpNot, Children: []models.Query{deep}}
+	}
+
+	wide := models.Query{Op: models.QueryOpOr}
+	for i := 0; i <= MaxQueryPatterns; i++ {
+		wide.Children = append(wide.Children, leaf(strings.Repeat("a", i+1)))
+	}
+
+	tests := []struct {
+		name  string
+		query *models.Query
+		err   string
+	}{
+		{"Nil", nil, "must not be empty"},
+		{"EmptyPattern", &models.Query{Pattern: ""}, "query: pattern must not be empty"},
+		{"UnknownOp", &models.Query{Op: "XOR", Children: []models.Query{leaf("a")}}, `unknown operator "XOR"`},
+		{"NoOperands", &models.Query{Op: models.QueryOpAnd}, "needs at least one operand"},
+		{"NotTwoOperands", &models.Query{Op: models.QueryOpNot, Children: []models.Query{leaf("a"), leaf("b")}}, "exactly one operand"},
+		{"OperatorPattern", &models.Query{Op: models.QueryOpOr, Pattern: "a", Children: []models.Query{leaf("b")}}, "neither a pattern nor an id"},
+		{"LeafChildren", &models.Query{Pattern: "a", Children: []models.Query{leaf("b")}}, "cannot have operands"},
+		{"DuplicateLabel", &models.Query{Op: models.QueryOpOr, Children: []models.Query{leaf("a"), {Pattern: "a", CaseSensitive: true}}}, `query.children[1]: label "a" is already used by query.children[0]`},
+		{"TooDeep", &deep, "more than 8 levels deep"},
+		{"TooManyPatterns", &wide, "more than 32 patterns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileQuery(tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestCompileQueryWhitespacePattern(t *testing.T) {
+	m, err := CompileQuery(&models.Query{Op: models.QueryOpAnd, Children: []models.Query{leaf("a"), leaf(" ")}})
+	require.NoError(t, err)
+
+	_, ok := m.Match("a b")
+	assert.True(t, ok)
+	_, ok = m.Match("ab")
+	assert.False(t, ok)
+}
+
+func TestCompileQueryInvalidPattern(t *testing.T) {
+	query := &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+		leaf("a"),
+		{Op: models.QueryOpNot, Children: []models.Query{{Pattern: "(b", Regex: true}}},
+	}}
+
+	_, err := CompileQuery(query)
+	var patternErr *Error
+	require.True(t, errors.As(err, &patternErr), "unexpected error %v", err)
+	assert.Equal(t, "query.children[1].children[0]", patternErr.Field)
+	assert.Equal(t, 0, patternErr.Offset)
+	assert.Contains(t, err.Error(), "invalid pattern in query.children[1].children[0] at byte 0")
+}
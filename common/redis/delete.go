@@ -0,0 +1,226 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// ErrJobDeleted is returned for jobs that were deleted, it wraps
+// ErrJobNotFound so callers that do not care can treat both alike
+var ErrJobDeleted = fmt.Errorf("%w: job was deleted", ErrJobNotFound)
+
+// ErrJobExpired is returned for jobs that were purged once their retention
+// was over, it wraps ErrJobNotFound like ErrJobDeleted
+var ErrJobExpired = fmt.Errorf("%w: job expired", ErrJobNotFound)
+
+// Tombstone is left in place of a purged job and tells why it was purged
+type Tombstone string
+
+const (
+	TombstoneDeleted Tombstone = "deleted"
+	TombstoneExpired Tombstone = "expired"
+)
+
+// missingJob explains why a job key does not exist
+func (s *Store) missingJob(ctx context.Context, jobID string) error {
+	tombstone, err := s.client.Get(ctx, s.keys(ctx).JobTombstoneKey(jobID)).Result()
+	if errors.Is(err, goredis.Nil) {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get job %s: %w", jobID, err)
+	}
+	// Tombstones of older versions hold when the job was deleted
+	if Tombstone(tombstone) == TombstoneExpired {
+		return ErrJobExpired
+	}
+	return ErrJobDeleted
+}
+
+// startChunkScript sets the chunk key KEYS[1] to ARGV[1] for ARGV[3]
+// milliseconds and adds the chunk ARGV[2] to the chunks of its job KEYS[2],
+// which is kept for at least as long
+var startChunkScript = goredis.NewScript(`
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+redis.call("SADD", KEYS[2], ARGV[2])
+if redis.call("PTTL", KEYS[2]) < tonumber(ARGV[3]) then
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+end
+return 1
+`)
+
+// StartChunk records that a worker is processing a chunk of a job, the record
+// expires after ttl in case the worker dies before calling FinishChunk
+func (s *Store) StartChunk(ctx context.Context, jobID, chunkID string, ttl time.Duration) error {
+	keys := s.keys(ctx)
+	err := startChunkScript.Run(ctx, s.client, []string{keys.ChunkKey(jobID, chunkID), keys.WorkingChunksKey(jobID)},
+		time.Now().UTC().Format(time.RFC3339Nano), chunkID, ttl.Milliseconds()).Err()
+	if err != nil {
+		return fmt.Errorf("failed to start chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return nil
+}
+
+// FinishChunk removes the record of a chunk being processed
+func (s *Store) FinishChunk(ctx context.Context, jobID, chunkID string) error {
+	keys := s.keys(ctx)
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, keys.ChunkKey(jobID, chunkID))
+		pipe.SRem(ctx, keys.WorkingChunksKey(jobID), chunkID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to finish chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return nil
+}
+
+// CountInFlightChunks returns the number of chunks of a job being processed
+func (s *Store) CountInFlightChunks(ctx context.Context, jobID string) (int, error) {
+	keys, err := s.chunkKeys(ctx, jobID)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	n, err := s.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count chunks of job %s: %w", jobID, err)
+	}
+	return int(n), nil
+}
+
+// chunkKeys returns the ChunkKey of every chunk of a job workers took
+func (s *Store) chunkKeys(ctx context.Context, jobID string) ([]string, error) {
+	keys := s.keys(ctx)
+	ids, err := s.client.SMembers(ctx, keys.WorkingChunksKey(jobID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks of job %s: %w", jobID, err)
+	}
+
+	chunks := make([]string, 0, len(ids))
+	for _, id := range ids {
+		chunks = append(chunks, keys.ChunkKey(jobID, id))
+	}
+	return chunks, nil
+}
+
+// streamKeys returns the JobStreamKey of every stream of a job that sent
+// results
+func (s *Store) streamKeys(ctx context.Context, jobID string) ([]string, error) {
+	keys := s.keys(ctx)
+	ids, err := s.client.SMembers(ctx, keys.JobStreamsKey(jobID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streams of job %s: %w", jobID, err)
+	}
+
+	streams := make([]string, 0, len(ids))
+	for _, id := range ids {
+		streams = append(streams, keys.JobStreamKey(jobID, id))
+	}
+	return streams, nil
+}
+
+// workKeys returns the keys of the chunks and streams of a job along with
+// the sets listing them
+func (s *Store) workKeys(ctx context.Context, jobID string) ([]string, error) {
+	chunks, err := s.chunkKeys(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	streams, err := s.streamKeys(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := s.keys(ctx)
+	return append(append(chunks, streams...), keys.WorkingChunksKey(jobID), keys.JobStreamsKey(jobID)), nil
+}
+
+// PurgeJob removes the state, stats, chunk, stream and matched file records,
+// webhook, dispatch, queue, expiry and index entries of a job and leaves a tombstone for ttl in
+// their place, so that the job is reported as ErrJobDeleted or ErrJobExpired
+// rather than ErrJobNotFound
+func (s *Store) PurgeJob(ctx context.Context, job *models.Job, tombstone Tombstone, ttl time.Duration) error {
+	keys := s.keys(ctx)
+
+	work, err := s.workKeys(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, append(work, keys.JobKey(job.ID), keys.JobStatsKey(job.ID), keys.MatchedFilesKey(job.ID), keys.WebhookKey(job.ID), keys.WebhookAttemptsKey(job.ID))...)
+		pipe.Del(ctx, dispatchKeys(keys, job.ID)...)
+		pipe.SRem(ctx, models.InteractiveJobsKey, tenantMember(tenant.FromContext(ctx), job.ID))
+		pipe.ZRem(ctx, models.PendingWebhooksKey, tenantMember(tenant.FromContext(ctx), job.ID))
+		pipe.ZRem(ctx, models.JobQueueKey, tenantMember(tenant.FromContext(ctx), job.ID))
+		pipe.ZRem(ctx, models.ExpiringJobsKey, tenantMember(tenant.FromContext(ctx), job.ID))
+		pipe.ZRem(ctx, models.UnretiredJobsKey, tenantMember(tenant.FromContext(ctx), job.ID))
+		pipe.ZRem(ctx, keys.JobsIndexKey(), job.ID)
+		pipe.ZRem(ctx, keys.JobsByStatusKey(job.Status), job.ID)
+		if job.RequestID != "" {
+			pipe.ZRem(ctx, keys.JobsByRequestKey(job.RequestID), job.ID)
+		}
+		pipe.Set(ctx, keys.JobTombstoneKey(job.ID), string(tombstone), ttl)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to purge job", "job_id", job.ID, "err", err)
+		return fmt.Errorf("failed to purge job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// PurgeMissingJob is PurgeJob for a job whose keys expired on their own.
+// Without the job its status and request are unknown, so it is removed from
+// the index of every status and the request indexes are scanned for it.
+func (s *Store) PurgeMissingJob(ctx context.Context, jobID string, tombstone Tombstone, ttl time.Duration) error {
+	keys := s.keys(ctx)
+
+	work, err := s.workKeys(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	var requests []string
+	iter := s.client.Scan(ctx, 0, keys.JobsByRequestKey("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		requests = append(requests, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to list request indexes: %w", err)
+	}
+
+	member := tenantMember(tenant.FromContext(ctx), jobID)
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, append(work, keys.JobKey(jobID), keys.JobStatsKey(jobID), keys.MatchedFilesKey(jobID), keys.WebhookKey(jobID), keys.WebhookAttemptsKey(jobID))...)
+		pipe.Del(ctx, dispatchKeys(keys, jobID)...)
+		pipe.SRem(ctx, models.InteractiveJobsKey, member)
+		pipe.ZRem(ctx, models.PendingWebhooksKey, member)
+		pipe.ZRem(ctx, models.JobQueueKey, member)
+		pipe.ZRem(ctx, models.ExpiringJobsKey, member)
+		pipe.ZRem(ctx, models.UnretiredJobsKey, member)
+		pipe.ZRem(ctx, keys.JobsIndexKey(), jobID)
+		for _, status := range jobStatuses {
+			pipe.ZRem(ctx, keys.JobsByStatusKey(status), jobID)
+		}
+		for _, index := range requests {
+			pipe.ZRem(ctx, index, jobID)
+		}
+		pipe.Set(ctx, keys.JobTombstoneKey(jobID), string(tombstone), ttl)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to purge job", "job_id", jobID, "err", err)
+		return fmt.Errorf("failed to purge job %s: %w", jobID, err)
+	}
+
+	return nil
+}
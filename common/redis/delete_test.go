@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestInFlightChunks(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	require.NoError(t, store.StartChunk(ctx, "grep_1", "c1", time.Minute))
+	require.NoError(t, store.StartChunk(ctx, "grep_1", "c2", time.Second))
+	require.NoError(t, store.StartChunk(ctx, "grep_10", "c1", time.Minute))
+
+	n, err := store.CountInFlightChunks(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.NoError(t, store.FinishChunk(ctx, "grep_1", "c1"))
+	mr.FastForward(2 * time.Second) // the worker holding c2 died
+
+	n, err = store.CountInFlightChunks(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = store.CountInFlightChunks(ctx, "grep_10")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPurgeJob(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	job := &models.Job{
+		ID:        "grep_1",
+		RequestID: "req_1",
+		Status:    models.JobStatusCancelled,
+		CreatedAt: time.Now().UTC(),
+	}
+	require.NoError(t, store.SaveJob(ctx, job))
+	require.NoError(t, store.SetStats(ctx, job.ID, models.JobStats{TotalFiles: 1}))
+	require.NoError(t, store.StartChunk(ctx, job.ID, "c1", time.Minute))
+	require.NoError(t, store.AppendStreamResult(ctx, job.ID, "stream_1", "c1", time.Minute))
+	_, err := store.MarkFileMatched(ctx, job.ID, "logs/app.log")
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	require.NoError(t, store.SaveWebhook(ctx, job.ID, &models.Webhook{URL: "http://example.com", State: models.WebhookStatePending, NextAttemptAt: &now}))
+	require.NoError(t, store.AddWebhookAttempt(ctx, job.ID, models.WebhookAttempt{Attempt: 1}))
+	require.NoError(t, store.EnqueueJob(ctx, job))
+	require.NoError(t, store.ScheduleExpiry(ctx, job.ID, now.Add(time.Hour)))
+
+	other := &models.Job{ID: "grep_2", RequestID: "req_1", Status: models.JobStatusCancelled, CreatedAt: time.Now().UTC()}
+	require.NoError(t, store.SaveJob(ctx, other))
+
+	require.NoError(t, store.PurgeJob(ctx, job, TombstoneDeleted, time.Hour))
+
+	_, err = store.GetJob(ctx, job.ID)
+	assert.ErrorIs(t, err, ErrJobDeleted)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	_, err = store.UpdateJob(ctx, job.ID, func(*models.Job) error { return nil })
+	assert.ErrorIs(t, err, ErrJobDeleted)
+
+	for _, key := range []string{"job:grep_1", "job:grep_1:stats", "job:grep_1:chunk:c1", "job:grep_1:chunks:working", "job:grep_1:stream:stream_1", "job:grep_1:streams", "job:grep_1:files:matched", "job:grep_1:webhook", "job:grep_1:webhook:attempts"} {
+		assert.False(t, mr.Exists(key), key)
+	}
+	assert.False(t, mr.Exists(models.PendingWebhooksKey))
+	assert.False(t, mr.Exists(models.JobQueueKey))
+	assert.False(t, mr.Exists(models.ExpiringJobsKey))
+	for _, index := range []string{"jobs:by_created", "jobs:by_status:CANCELLED", "jobs:by_request:req_1"} {
+		members, err := mr.ZMembers(index)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"grep_2"}, members, index)
+	}
+
+	_, err = store.GetJob(ctx, other.ID)
+	assert.NoError(t, err)
+
+	// Once the tombstone expires the job is simply unknown
+	mr.FastForward(2 * time.Hour)
+	_, err = store.GetJob(ctx, job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.NotErrorIs(t, err, ErrJobDeleted)
+}
+
+func TestPurgeMissingJob(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	for _, job := range []*models.Job{
+		{ID: "grep_1", RequestID: "req_1", Status: models.JobStatusCompleted, CreatedAt: time.Now().UTC()},
+		{ID: "grep_2", RequestID: "req_1", Status: models.JobStatusCompleted, CreatedAt: time.Now().UTC()},
+	} {
+		require.NoError(t, store.SaveJob(ctx, job))
+	}
+	require.NoError(t, store.ScheduleExpiry(ctx, "grep_1", time.Now()))
+
+	// The keys of the job expired before it was purged
+	mr.Del("job:grep_1")
+	require.NoError(t, store.PurgeMissingJob(ctx, "grep_1", TombstoneExpired, time.Hour))
+
+	_, err := store.GetJob(ctx, "grep_1")
+	assert.ErrorIs(t, err, ErrJobExpired)
+	assert.NotErrorIs(t, err, ErrJobDeleted)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	assert.False(t, mr.Exists(models.ExpiringJobsKey))
+	for _, index := range []string{"jobs:by_created", "jobs:by_status:COMPLETED", "jobs:by_request:req_1"} {
+		members, err := mr.ZMembers(index)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"grep_2"}, members, index)
+	}
+}
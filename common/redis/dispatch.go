@@ -0,0 +1,500 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// QueuedJob is an entry of the dispatch queue shared by all tenants
+type QueuedJob struct {
+	Tenant   string
+	JobID    string
+	Priority models.JobPriority
+}
+
+// InFlightChunk is a chunk of any tenant handed to a mapper and not reported
+// back yet
+type InFlightChunk struct {
+	Tenant   string
+	JobID    string
+	ChunkID  string
+	Deadline time.Time
+}
+
+// inFlightMember identifies a chunk in InFlightChunksKey
+func inFlightMember(tenantID, jobID, chunkID string) string {
+	return tenantMember(tenantID, jobID+"/"+chunkID)
+}
+
+// dispatchKeys returns the keys the chunks of a job are dispatched with
+func dispatchKeys(keys models.RedisKeys, jobID string) []string {
+	return []string{
+		keys.JobChunksKey(jobID), keys.PendingChunksKey(jobID), keys.DoneChunksKey(jobID),
+		keys.ChunkAttemptsKey(jobID), keys.ChunkMatchesKey(jobID), keys.FileMatchesKey(jobID),
+	}
+}
+
+// SaveChunks records the chunks of a job as pending, in the given order
+func (s *Store) SaveChunks(ctx context.Context, jobID string, chunks []models.Chunk) error {
+	keys := s.keys(ctx)
+
+	plan := make(map[string]any, len(chunks))
+	order := make([]any, 0, len(chunks))
+	for _, chunk := range chunks {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to marshal chunk %s: %w", chunk.ID, err)
+		}
+		plan[chunk.ID] = data
+		order = append(order, chunk.ID)
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, dispatchKeys(keys, jobID)...)
+		if len(chunks) > 0 {
+			pipe.HSet(ctx, keys.JobChunksKey(jobID), plan)
+			pipe.RPush(ctx, keys.PendingChunksKey(jobID), order...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save chunks of job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// HasChunks reports whether chunks of a job are recorded
+func (s *Store) HasChunks(ctx context.Context, jobID string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.keys(ctx).JobChunksKey(jobID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunks of job %s: %w", jobID, err)
+	}
+	return n > 0, nil
+}
+
+// DeleteChunks removes the chunks of a job, the chunks still in flight are
+// ignored when they are reported back
+func (s *Store) DeleteChunks(ctx context.Context, jobID string) error {
+	if err := s.client.Del(ctx, dispatchKeys(s.keys(ctx), jobID)...).Err(); err != nil {
+		return fmt.Errorf("failed to delete chunks of job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// claimChunkScript pops the next pending chunk of KEYS[1] and adds ARGV[3]
+// followed by its ID to the in flight chunks KEYS[3] with the deadline
+// ARGV[1], unless ARGV[2] chunks are in flight already, 0 for no limit. The
+// job ARGV[4] leaves the queue KEYS[4] with its last pending chunk. Returns
+// the chunk from KEYS[2], false if there is no pending chunk and 0 if the
+// limit was reached.
+var claimChunkScript = goredis.NewScript(`
+local limit = tonumber(ARGV[2])
+if limit > 0 and redis.call("ZCARD", KEYS[3]) >= limit then
+	return 0
+end
+while true do
+	local id = redis.call("LPOP", KEYS[1])
+	if not id then
+		redis.call("ZREM", KEYS[4], ARGV[4])
+		return false
+	end
+	local chunk = redis.call("HGET", KEYS[2], id)
+	if chunk then
+		redis.call("ZADD", KEYS[3], ARGV[1], ARGV[3] .. id)
+		if redis.call("LLEN", KEYS[1]) == 0 then
+			redis.call("ZREM", KEYS[4], ARGV[4])
+		end
+		return chunk
+	end
+end
+`)
+
+// ClaimChunk takes the next pending chunk of a job and records it as in
+// flight until deadline, the job leaves the dispatch queue once no chunk is
+// pending anymore. It returns nil and true if maxInFlight chunks of any job
+// are in flight already, nil and false if no chunk of the job is pending.
+func (s *Store) ClaimChunk(ctx context.Context, jobID string, deadline time.Time, maxInFlight int) (*models.Chunk, bool, error) {
+	keys := s.keys(ctx)
+	tenantID := tenant.FromContext(ctx)
+	res, err := claimChunkScript.Run(ctx, s.client,
+		[]string{keys.PendingChunksKey(jobID), keys.JobChunksKey(jobID), models.InFlightChunksKey, models.JobQueueKey},
+		deadline.UnixMilli(), maxInFlight, inFlightMember(tenantID, jobID, ""), tenantMember(tenantID, jobID)).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim chunk of job %s: %w", jobID, err)
+	}
+
+	data, ok := res.(string)
+	if !ok {
+		return nil, true, nil
+	}
+
+	var chunk models.Chunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal chunk of job %s: %w", jobID, err)
+	}
+	return &chunk, false, nil
+}
+
+// ReturnChunk puts a claimed chunk back at the head of the pending chunks of
+// its job, for chunks that could not be handed to a mapper. The job has to be
+// queued again if it left the queue.
+func (s *Store) ReturnChunk(ctx context.Context, jobID, chunkID string) error {
+	keys := s.keys(ctx)
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZRem(ctx, models.InFlightChunksKey, inFlightMember(tenant.FromContext(ctx), jobID, chunkID))
+		pipe.LPush(ctx, keys.PendingChunksKey(jobID), chunkID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to return chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return nil
+}
+
+// reportChunkScript removes ARGV[1] from the in flight chunks KEYS[1] and
+// adds the chunk ARGV[2] to the done chunks KEYS[3] if it is one of KEYS[2].
+// Returns whether it was added, the number of done chunks and the number of
+// chunks, or false for chunks that are unknown.
+var reportChunkScript = goredis.NewScript(`
+redis.call("ZREM", KEYS[1], ARGV[1])
+if redis.call("HEXISTS", KEYS[2], ARGV[2]) == 0 then
+	return false
+end
+local first = redis.call("SADD", KEYS[3], ARGV[2])
+return {first, redis.call("SCARD", KEYS[3]), redis.call("HLEN", KEYS[2])}
+`)
+
+// ChunkReport is the progress of a job after one of its chunks was reported
+type ChunkReport struct {
+	// First is false if the chunks were reported or dropped already, only
+	// the first report of a chunk counts
+	First bool
+	Done  int
+	Total int
+}
+
+// ReportChunk records a chunk as done, ok is false for chunks of jobs that are
+// not dispatched anymore. Chunks may be reported more than once, only the
+// first report counts.
+func (s *Store) ReportChunk(ctx context.Context, jobID, chunkID string) (ChunkReport, bool, error) {
+	keys := s.keys(ctx)
+	res, err := reportChunkScript.Run(ctx, s.client,
+		[]string{models.InFlightChunksKey, keys.JobChunksKey(jobID), keys.DoneChunksKey(jobID)},
+		inFlightMember(tenant.FromContext(ctx), jobID, chunkID), chunkID).Int64Slice()
+	if errors.Is(err, goredis.Nil) {
+		return ChunkReport{}, false, nil
+	}
+	if err != nil {
+		return ChunkReport{}, false, fmt.Errorf("failed to report chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return ChunkReport{First: res[0] == 1, Done: int(res[1]), Total: int(res[2])}, true, nil
+}
+
+// dropChunksScript removes the chunks ARGV from the pending chunks KEYS[1]
+// and adds those that were pending to the done chunks KEYS[3]. Returns the
+// number removed, the number of done chunks and the number of chunks in
+// KEYS[2].
+var dropChunksScript = goredis.NewScript(`
+local dropped = 0
+for _, id in ipairs(ARGV) do
+	if redis.call("LREM", KEYS[1], 0, id) > 0 then
+		redis.call("SADD", KEYS[3], id)
+		dropped = dropped + 1
+	end
+end
+return {dropped, redis.call("SCARD", KEYS[3]), redis.call("HLEN", KEYS[2])}
+`)
+
+// DropPendingChunks records the chunks of a file of a job that were not
+// dispatched yet as done without searching them. It returns the number of
+// chunks dropped and the progress of the job.
+func (s *Store) DropPendingChunks(ctx context.Context, jobID, file string) (int, ChunkReport, error) {
+	pending, err := s.pendingChunks(ctx, jobID, file)
+	if err != nil {
+		return 0, ChunkReport{}, err
+	}
+
+	drop := make([]any, 0, len(pending))
+	for _, chunk := range pending {
+		drop = append(drop, chunk.ID)
+	}
+	return s.dropChunks(ctx, jobID, drop)
+}
+
+// CountChunkMatches records the lines a reported chunk selected, call it once
+// per chunk. It returns the file of the chunk and the lines selected by its
+// chunks reported so far, or an empty file for chunks that are unknown.
+func (s *Store) CountChunkMatches(ctx context.Context, jobID, chunkID string, selected int) (string, int, error) {
+	keys := s.keys(ctx)
+
+	data, err := s.client.HGet(ctx, keys.JobChunksKey(jobID), chunkID).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	var chunk models.Chunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return "", 0, fmt.Errorf("failed to unmarshal chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+
+	var total *goredis.IntCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, keys.ChunkMatchesKey(jobID), chunkID, selected)
+		total = pipe.HIncrBy(ctx, keys.FileMatchesKey(jobID), chunk.FileName, int64(selected))
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to count matches of chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return chunk.FileName, int(total.Val()), nil
+}
+
+// DropCappedChunks records the chunks of a file of a job that were not
+// dispatched yet as done if the reported chunks before them selected max
+// lines already, the lines past those are never returned. It returns the
+// number of chunks dropped and the progress of the job.
+func (s *Store) DropCappedChunks(ctx context.Context, jobID, file string, max int) (int, ChunkReport, error) {
+	keys := s.keys(ctx)
+
+	pending, err := s.pendingChunks(ctx, jobID, file)
+	if err != nil || len(pending) == 0 {
+		return 0, ChunkReport{}, err
+	}
+
+	counts, err := s.client.HGetAll(ctx, keys.ChunkMatchesKey(jobID)).Result()
+	if err != nil {
+		return 0, ChunkReport{}, fmt.Errorf("failed to get matches of job %s: %w", jobID, err)
+	}
+	reported := make([]string, 0, len(counts))
+	for id := range counts {
+		reported = append(reported, id)
+	}
+	done, err := s.chunks(ctx, jobID, reported, file)
+	if err != nil {
+		return 0, ChunkReport{}, err
+	}
+
+	var drop []any
+	for _, chunk := range pending {
+		selected := 0
+		for _, before := range done {
+			if before.StartByte < chunk.StartByte {
+				n, _ := strconv.Atoi(counts[before.ID])
+				selected += n
+			}
+		}
+		if selected >= max {
+			drop = append(drop, chunk.ID)
+		}
+	}
+	return s.dropChunks(ctx, jobID, drop)
+}
+
+// pendingChunks returns the chunks of a file of a job that were not
+// dispatched yet
+func (s *Store) pendingChunks(ctx context.Context, jobID, file string) ([]models.Chunk, error) {
+	pending, err := s.client.LRange(ctx, s.keys(ctx).PendingChunksKey(jobID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending chunks of job %s: %w", jobID, err)
+	}
+	return s.chunks(ctx, jobID, pending, file)
+}
+
+// chunks returns those of the chunks ids of a job that belong to a file,
+// chunks that are unknown are skipped
+func (s *Store) chunks(ctx context.Context, jobID string, ids []string, file string) ([]models.Chunk, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	plan, err := s.client.HMGet(ctx, s.keys(ctx).JobChunksKey(jobID), ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks of job %s: %w", jobID, err)
+	}
+
+	var chunks []models.Chunk
+	for _, data := range plan {
+		data, ok := data.(string)
+		if !ok {
+			continue
+		}
+		var chunk models.Chunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chunk of job %s: %w", jobID, err)
+		}
+		if chunk.FileName == file {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// dropChunks records the chunks drop of a job that are still pending as done
+func (s *Store) dropChunks(ctx context.Context, jobID string, drop []any) (int, ChunkReport, error) {
+	if len(drop) == 0 {
+		return 0, ChunkReport{}, nil
+	}
+
+	keys := s.keys(ctx)
+	res, err := dropChunksScript.Run(ctx, s.client,
+		[]string{keys.PendingChunksKey(jobID), keys.JobChunksKey(jobID), keys.DoneChunksKey(jobID)}, drop...).Int64Slice()
+	if err != nil {
+		return 0, ChunkReport{}, fmt.Errorf("failed to drop chunks of job %s: %w", jobID, err)
+	}
+	return int(res[0]), ChunkReport{First: res[0] > 0, Done: int(res[1]), Total: int(res[2])}, nil
+}
+
+// retryChunkScript removes ARGV[1] from the in flight chunks KEYS[1] and, if
+// it was there and the chunk ARGV[2] is neither done (KEYS[3]) nor unknown
+// (KEYS[2]), counts the timeout in KEYS[4]. The chunk is put back at the head
+// of the pending chunks KEYS[5] unless it timed out ARGV[3] times. Returns the
+// number of timeouts, 0 if the chunk was not retried.
+var retryChunkScript = goredis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0
+	or redis.call("HEXISTS", KEYS[2], ARGV[2]) == 0
+	or redis.call("SISMEMBER", KEYS[3], ARGV[2]) == 1 then
+	return 0
+end
+local attempts = redis.call("HINCRBY", KEYS[4], ARGV[2], 1)
+if attempts < tonumber(ARGV[3]) then
+	redis.call("LPUSH", KEYS[5], ARGV[2])
+end
+return attempts
+`)
+
+// RetryChunk gives a chunk that timed out back to the pending chunks of its
+// job, unless it timed out maxAttempts times already. It returns the number
+// of times the chunk timed out, 0 if it was reported or retried meanwhile.
+func (s *Store) RetryChunk(ctx context.Context, jobID, chunkID string, maxAttempts int) (int, error) {
+	keys := s.keys(ctx)
+	attempts, err := retryChunkScript.Run(ctx, s.client,
+		[]string{models.InFlightChunksKey, keys.JobChunksKey(jobID), keys.DoneChunksKey(jobID), keys.ChunkAttemptsKey(jobID), keys.PendingChunksKey(jobID)},
+		inFlightMember(tenant.FromContext(ctx), jobID, chunkID), chunkID, maxAttempts).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry chunk %s of job %s: %w", chunkID, jobID, err)
+	}
+	return attempts, nil
+}
+
+// ExpiredChunks returns up to limit chunks of any tenant in flight past their
+// deadline at now, the earliest first
+func (s *Store) ExpiredChunks(ctx context.Context, now time.Time, limit int) ([]InFlightChunk, error) {
+	entries, err := s.client.ZRangeByScoreWithScores(ctx, models.InFlightChunksKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired chunks: %w", err)
+	}
+
+	expired := make([]InFlightChunk, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, rest, ok := splitTenantMember(member)
+		jobID, chunkID, found := strings.Cut(rest, "/")
+		if !ok || !found {
+			log.Warn("dropping malformed in flight chunk", "member", member)
+			s.client.ZRem(ctx, models.InFlightChunksKey, member)
+			continue
+		}
+
+		expired = append(expired, InFlightChunk{
+			Tenant:   tenantID,
+			JobID:    jobID,
+			ChunkID:  chunkID,
+			Deadline: time.UnixMilli(int64(entry.Score)).UTC(),
+		})
+	}
+
+	return expired, nil
+}
+
+// QueuedJobs returns up to limit jobs of any tenant from the head of the
+// dispatch queue, all of them for a limit of 0
+func (s *Store) QueuedJobs(ctx context.Context, limit int) ([]QueuedJob, error) {
+	entries, err := s.client.ZRangeWithScores(ctx, models.JobQueueKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued jobs: %w", err)
+	}
+
+	queued := make([]QueuedJob, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, jobID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed queue entry", "member", member)
+			s.client.ZRem(ctx, models.JobQueueKey, member)
+			continue
+		}
+
+		queued = append(queued, QueuedJob{
+			Tenant:   tenantID,
+			JobID:    jobID,
+			Priority: models.QueuePriority(entry.Score),
+		})
+	}
+
+	return queued, nil
+}
+
+// SetInteractive records whether an interactive job is unfinished, batch jobs
+// of every tenant are paused while one is
+func (s *Store) SetInteractive(ctx context.Context, jobID string, active bool) error {
+	member := tenantMember(tenant.FromContext(ctx), jobID)
+
+	var err error
+	if active {
+		err = s.client.SAdd(ctx, models.InteractiveJobsKey, member).Err()
+	} else {
+		err = s.client.SRem(ctx, models.InteractiveJobsKey, member).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update interactive job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// InteractiveJobs returns the unfinished interactive jobs of all tenants
+func (s *Store) InteractiveJobs(ctx context.Context) ([]QueuedJob, error) {
+	members, err := s.client.SMembers(ctx, models.InteractiveJobsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interactive jobs: %w", err)
+	}
+
+	jobs := make([]QueuedJob, 0, len(members))
+	for _, member := range members {
+		tenantID, jobID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed interactive job", "member", member)
+			s.client.SRem(ctx, models.InteractiveJobsKey, member)
+			continue
+		}
+		jobs = append(jobs, QueuedJob{Tenant: tenantID, JobID: jobID, Priority: models.PriorityInteractive})
+	}
+	return jobs, nil
+}
+
+// HasInteractiveJobs reports whether an interactive job of any tenant is
+// unfinished
+func (s *Store) HasInteractiveJobs(ctx context.Context) (bool, error) {
+	n, err := s.client.SCard(ctx, models.InteractiveJobsKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to count interactive jobs: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestDispatchChunks(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	store, mr := newTestStore(t)
+
+	deadline := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	chunks := []models.Chunk{
+		{ID: "c1", JobID: "grep_1", FileName: "app.log", StartLine: 1, EndLine: 10},
+		{ID: "c2", JobID: "grep_1", FileName: "app.log", StartLine: 11, EndLine: 20},
+		{ID: "c3", JobID: "grep_1", FileName: "error.log", StartLine: 1, EndLine: 5},
+	}
+	require.NoError(t, store.SaveChunks(ctx, "grep_1", chunks))
+
+	ok, err := store.HasChunks(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	claim := func(maxInFlight int) (*models.Chunk, bool) {
+		chunk, full, err := store.ClaimChunk(ctx, "grep_1", deadline, maxInFlight)
+		require.NoError(t, err)
+		return chunk, full
+	}
+
+	chunk, full := claim(2)
+	require.NotNil(t, chunk)
+	assert.False(t, full)
+	assert.Equal(t, chunks[0], *chunk)
+
+	chunk, _ = claim(2)
+	require.NotNil(t, chunk)
+	assert.Equal(t, "c2", chunk.ID)
+
+	chunk, full = claim(2)
+	assert.Nil(t, chunk)
+	assert.True(t, full, "in flight chunks of every job count against the limit")
+
+	members, err := mr.ZMembers(models.InFlightChunksKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme/grep_1/c1", "acme/grep_1/c2"}, members)
+
+	t.Run("Report", func(t *testing.T) {
+		report, ok, err := store.ReportChunk(ctx, "grep_1", "c1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, ChunkReport{First: true, Done: 1, Total: 3}, report)
+
+		report, ok, err = store.ReportChunk(ctx, "grep_1", "c1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, report.First, "a chunk should only count once")
+
+		_, ok, err = store.ReportChunk(ctx, "grep_missing", "c1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Return", func(t *testing.T) {
+		require.NoError(t, store.ReturnChunk(ctx, "grep_1", "c2"))
+
+		chunk, _ := claim(0)
+		require.NotNil(t, chunk)
+		assert.Equal(t, "c2", chunk.ID, "returned chunks should be claimed first")
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		expired, err := store.ExpiredChunks(ctx, deadline.Add(-time.Second), 10)
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+
+		expired, err = store.ExpiredChunks(ctx, deadline, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []InFlightChunk{{Tenant: "acme", JobID: "grep_1", ChunkID: "c2", Deadline: deadline}}, expired)
+
+		attempts, err := store.RetryChunk(ctx, "grep_1", "c2", 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+
+		attempts, err = store.RetryChunk(ctx, "grep_1", "c2", 2)
+		require.NoError(t, err)
+		assert.Zero(t, attempts, "chunks no longer in flight should not be retried")
+
+		chunk, _ := claim(0)
+		require.NotNil(t, chunk)
+		assert.Equal(t, "c2", chunk.ID)
+
+		attempts, err = store.RetryChunk(ctx, "grep_1", "c2", 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+
+		chunk, _ = claim(0)
+		require.NotNil(t, chunk)
+		assert.Equal(t, "c3", chunk.ID, "chunks should be given up on after maxAttempts")
+
+		chunk, full := claim(0)
+		assert.Nil(t, chunk)
+		assert.False(t, full)
+	})
+
+	require.NoError(t, store.DeleteChunks(ctx, "grep_1"))
+	ok, err = store.HasChunks(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = store.ReportChunk(ctx, "grep_1", "c3")
+	require.NoError(t, err)
+	assert.False(t, ok, "chunks of deleted jobs should be ignored")
+	assert.False(t, mr.Exists(models.InFlightChunksKey))
+}
+
+func TestDropPendingChunks(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	chunks := []models.Chunk{
+		{ID: "c1", FileName: "app.log"},
+		{ID: "c2", FileName: "app.log"},
+		{ID: "c3", FileName: "error.log"},
+		{ID: "c4", FileName: "app.log"},
+	}
+	require.NoError(t, store.SaveChunks(ctx, "grep_1", chunks))
+
+	chunk, _, err := store.ClaimChunk(ctx, "grep_1", time.Now(), 0)
+	require.NoError(t, err)
+	require.Equal(t, "c1", chunk.ID)
+
+	dropped, report, err := store.DropPendingChunks(ctx, "grep_1", "app.log")
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped, "chunks in flight should not be dropped")
+	assert.Equal(t, ChunkReport{First: true, Done: 2, Total: 4}, report)
+
+	dropped, report, err = store.DropPendingChunks(ctx, "grep_1", "app.log")
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	assert.False(t, report.First)
+
+	chunk, _, err = store.ClaimChunk(ctx, "grep_1", time.Now(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "c3", chunk.ID)
+
+	chunk, _, err = store.ClaimChunk(ctx, "grep_1", time.Now(), 0)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+}
+
+func TestDropCappedChunks(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	chunks := []models.Chunk{
+		{ID: "c1", FileName: "app.log", StartByte: 0},
+		{ID: "c2", FileName: "app.log", StartByte: 100},
+		{ID: "c3", FileName: "error.log", StartByte: 0},
+		{ID: "c4", FileName: "app.log", StartByte: 200},
+	}
+	require.NoError(t, store.SaveChunks(ctx, "grep_1", chunks))
+	for range 2 {
+		_, _, err := store.ClaimChunk(ctx, "grep_1", time.Now(), 0)
+		require.NoError(t, err)
+	}
+
+	file, total, err := store.CountChunkMatches(ctx, "grep_1", "c2", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "app.log", file)
+	assert.Equal(t, 3, total)
+
+	dropped, _, err := store.DropCappedChunks(ctx, "grep_1", "app.log", 4)
+	require.NoError(t, err)
+	assert.Zero(t, dropped, "chunks should be kept until the lines before them reach the max")
+
+	dropped, report, err := store.DropCappedChunks(ctx, "grep_1", "app.log", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped, "only the chunk after the one that reached the max should be dropped")
+	assert.Equal(t, ChunkReport{First: true, Done: 1, Total: 4}, report)
+
+	chunk, _, err := store.ClaimChunk(ctx, "grep_1", time.Now(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "c3", chunk.ID)
+
+	file, total, err = store.CountChunkMatches(ctx, "grep_1", "c1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "app.log", file)
+	assert.Equal(t, 5, total)
+
+	file, _, err = store.CountChunkMatches(ctx, "grep_1", "gone", 2)
+	require.NoError(t, err)
+	assert.Empty(t, file)
+}
+
+func TestQueuedAndInteractiveJobs(t *testing.T) {
+	ctx := context.Background()
+	acme := tenant.NewContext(ctx, "acme")
+	store, _ := newTestStore(t)
+
+	created := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, store.EnqueueJob(ctx, &models.Job{ID: "grep_batch", Priority: models.PriorityBatch, CreatedAt: created}))
+	require.NoError(t, store.EnqueueJob(acme, &models.Job{ID: "grep_normal", CreatedAt: created}))
+	require.NoError(t, store.EnqueueJob(acme, &models.Job{ID: "grep_urgent", Priority: models.PriorityInteractive, CreatedAt: created}))
+
+	queued, err := store.QueuedJobs(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []QueuedJob{
+		{Tenant: "acme", JobID: "grep_urgent", Priority: models.PriorityInteractive},
+		{Tenant: "acme", JobID: "grep_normal", Priority: models.PriorityNormal},
+		{Tenant: "", JobID: "grep_batch", Priority: models.PriorityBatch},
+	}, queued)
+
+	queued, err = store.QueuedJobs(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, queued, 1)
+
+	ok, err := store.HasInteractiveJobs(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.SetInteractive(acme, "grep_urgent", true))
+	ok, err = store.HasInteractiveJobs(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	jobs, err := store.InteractiveJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []QueuedJob{{Tenant: "acme", JobID: "grep_urgent", Priority: models.PriorityInteractive}}, jobs)
+
+	require.NoError(t, store.SetInteractive(acme, "grep_urgent", false))
+	ok, err = store.HasInteractiveJobs(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
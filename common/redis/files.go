@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+)
+
+// MarkFileMatched records that a file of a job has a selected line and
+// reports whether this call was the first to do so, only the first caller
+// needs to tell the others to skip the rest of the file
+func (s *Store) MarkFileMatched(ctx context.Context, jobID, file string) (bool, error) {
+	added, err := s.client.SAdd(ctx, s.keys(ctx).MatchedFilesKey(jobID), file).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark file %s of job %s as matched: %w", file, jobID, err)
+	}
+	return added > 0, nil
+}
+
+// IsFileMatched reports whether a file of a job is known to have a selected
+// line, chunks of such a file are skipped in modes settled by the first match
+func (s *Store) IsFileMatched(ctx context.Context, jobID, file string) (bool, error) {
+	ok, err := s.client.SIsMember(ctx, s.keys(ctx).MatchedFilesKey(jobID), file).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check file %s of job %s: %w", file, jobID, err)
+	}
+	return ok, nil
+}
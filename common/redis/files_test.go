@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestMatchedFiles(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	matched, err := store.IsFileMatched(ctx, "grep_1", "logs/app.log")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	first, err := store.MarkFileMatched(ctx, "grep_1", "logs/app.log")
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	// A mapper searching another chunk of the file got there second
+	first, err = store.MarkFileMatched(ctx, "grep_1", "logs/app.log")
+	require.NoError(t, err)
+	assert.False(t, first)
+
+	matched, err = store.IsFileMatched(ctx, "grep_1", "logs/app.log")
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = store.IsFileMatched(ctx, "grep_1", "logs/error.log")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	matched, err = store.IsFileMatched(tenant.NewContext(ctx, "acme"), "grep_1", "logs/app.log")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	members, err := mr.SMembers("job:grep_1:files:matched")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs/app.log"}, members)
+}
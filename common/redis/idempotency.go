@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// IdempotencyRecord remembers which request claimed an idempotency key and
+// the job it created, JobID is empty while the job is being created
+type IdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	JobID       string `json:"job_id,omitempty"`
+}
+
+// ClaimIdempotencyKey stores record under key unless the key is already
+// claimed, in which case the existing record is returned and claimed is false.
+// The claim lasts for lease, a request that dies before completing the key
+// only blocks retries that long.
+func (s *Store) ClaimIdempotencyKey(ctx context.Context, key string, record IdempotencyRecord, lease time.Duration) (existing *IdempotencyRecord, claimed bool, err error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	redisKey := s.keys(ctx).IdempotencyKey(key)
+	for i := 0; i < maxTxRetries; i++ {
+		ok, err := s.client.SetNX(ctx, redisKey, data, lease).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if ok {
+			return nil, true, nil
+		}
+
+		current, err := s.client.Get(ctx, redisKey).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			// Expired or released in between, try to claim it again
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		var rec IdempotencyRecord
+		if err := json.Unmarshal(current, &rec); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+		}
+		return &rec, false, nil
+	}
+
+	return nil, false, fmt.Errorf("failed to claim idempotency key: too many concurrent claims")
+}
+
+// CompleteIdempotencyKey records the job created for a claimed key, which
+// maps to it for ttl from then on
+func (s *Store) CompleteIdempotencyKey(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.keys(ctx).IdempotencyKey(key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a claim, so the request can be retried
+func (s *Store) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.keys(ctx).IdempotencyKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
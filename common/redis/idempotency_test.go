@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	existing, claimed, err := store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Nil(t, existing)
+	assert.Equal(t, time.Minute, mr.TTL("idempotency:key_1"))
+
+	existing, claimed, err = store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "b"}, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, IdempotencyRecord{Fingerprint: "a"}, *existing)
+
+	require.NoError(t, store.CompleteIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a", JobID: "grep_1"}, time.Hour))
+	assert.Equal(t, time.Hour, mr.TTL("idempotency:key_1"), "completing should extend the claim to the retention window")
+
+	existing, _, err = store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "grep_1", existing.JobID)
+
+	require.NoError(t, store.ReleaseIdempotencyKey(ctx, "key_1"))
+	_, claimed, err = store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "b"}, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	mr.FastForward(2 * time.Hour)
+	_, claimed, err = store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "c"}, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, claimed, "key should be claimable once the retention window passed")
+}
+
+func TestIdempotencyKeyExpiredClaim(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	// Claimed by a request that died before creating its job
+	_, claimed, err := store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	existing, claimed, err := store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Empty(t, existing.JobID)
+
+	mr.FastForward(2 * time.Minute)
+	existing, claimed, err = store.ClaimIdempotencyKey(ctx, "key_1", IdempotencyRecord{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed, "a retry should claim the key once the lease of the dead request passed")
+	assert.Nil(t, existing)
+}
@@ -0,0 +1,273 @@
+package redis
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// ErrInvalidCursor is returned when a listing cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// listBatchSize is the number of index entries fetched per round trip
+const listBatchSize = 100
+
+var jobStatuses = []models.JobStatus{
+	models.JobStatusPending,
+	models.JobStatusProcessing,
+	models.JobStatusCompleted,
+	models.JobStatusFailed,
+	models.JobStatusCancelled,
+}
+
+// JobQuery filters a job listing, zero values match everything
+type JobQuery struct {
+	Status          models.JobStatus
+	RequestID       string
+	PatternContains string // Case-insensitive substring of the pattern or of a query pattern
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	Cursor          string // NextCursor of the previous page
+	Limit           int
+}
+
+// JobPage is one page of a job listing, newest jobs first
+type JobPage struct {
+	Jobs       []*models.Job
+	NextCursor string // Empty on the last page
+}
+
+// indexScore is the score of a job in the listing indexes
+func indexScore(job *models.Job) float64 {
+	return float64(job.CreatedAt.UnixMilli())
+}
+
+// indexJob adds a job to the listing indexes, removing it from the index of
+// any status it no longer has
+func (s *Store) indexJob(ctx context.Context, pipe goredis.Pipeliner, job *models.Job) {
+	z := goredis.Z{Score: indexScore(job), Member: job.ID}
+
+	pipe.ZAdd(ctx, s.keys(ctx).JobsIndexKey(), z)
+	for _, status := range jobStatuses {
+		if status != job.Status {
+			pipe.ZRem(ctx, s.keys(ctx).JobsByStatusKey(status), job.ID)
+		}
+	}
+	pipe.ZAdd(ctx, s.keys(ctx).JobsByStatusKey(job.Status), z)
+	if job.RequestID != "" {
+		pipe.ZAdd(ctx, s.keys(ctx).JobsByRequestKey(job.RequestID), z)
+	}
+}
+
+// ListJobs returns jobs matching the query, newest first. The most selective
+// index is scanned by creation time and the remaining filters are applied to
+// the jobs themselves. Index entries of jobs that no longer exist are dropped.
+func (s *Store) ListJobs(ctx context.Context, query JobQuery) (*JobPage, error) {
+	if query.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", query.Limit)
+	}
+
+	index := s.keys(ctx).JobsIndexKey()
+	switch {
+	case query.RequestID != "":
+		index = s.keys(ctx).JobsByRequestKey(query.RequestID)
+	case query.Status != "":
+		index = s.keys(ctx).JobsByStatusKey(query.Status)
+	}
+
+	max := "+inf"
+	if !query.CreatedBefore.IsZero() {
+		max = "(" + strconv.FormatInt(query.CreatedBefore.UnixMilli(), 10)
+	}
+	min := "-inf"
+	if !query.CreatedAfter.IsZero() {
+		min = strconv.FormatInt(query.CreatedAfter.UnixMilli(), 10)
+	}
+
+	var after *goredis.Z
+	if query.Cursor != "" {
+		z, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = z
+		if query.CreatedBefore.IsZero() || z.Score < float64(query.CreatedBefore.UnixMilli()) {
+			max = strconv.FormatFloat(z.Score, 'f', -1, 64)
+		}
+	}
+
+	// Stale entries are dropped once the scan is over so that removing them
+	// does not shift the offsets used while scanning
+	var stale []interface{}
+	defer func() {
+		if len(stale) == 0 {
+			return
+		}
+		if err := s.client.ZRem(context.WithoutCancel(ctx), index, stale...).Err(); err != nil {
+			log.Warn("failed to drop stale index entries", "index", index, "err", err)
+		}
+	}()
+
+	page := &JobPage{}
+	var offset int64
+	for {
+		entries, err := s.client.ZRevRangeByScoreWithScores(ctx, index, &goredis.ZRangeBy{
+			Max:    max,
+			Min:    min,
+			Offset: offset,
+			Count:  listBatchSize,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job index: %w", err)
+		}
+
+		candidates := make([]goredis.Z, 0, len(entries))
+		for _, z := range entries {
+			// Members with equal scores are returned in reverse lexical order
+			if after != nil && z.Score == after.Score && z.Member.(string) >= after.Member.(string) {
+				continue
+			}
+			candidates = append(candidates, z)
+		}
+
+		jobs, err := s.loadIndexed(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, job := range jobs {
+			if job == nil {
+				stale = append(stale, candidates[i].Member)
+				continue
+			}
+			if !query.matches(job) {
+				continue
+			}
+			if len(page.Jobs) == query.Limit {
+				last := page.Jobs[len(page.Jobs)-1]
+				page.NextCursor = encodeCursor(goredis.Z{Score: indexScore(last), Member: last.ID})
+				return page, nil
+			}
+			page.Jobs = append(page.Jobs, job)
+		}
+
+		if len(entries) < listBatchSize {
+			return page, nil
+		}
+
+		// Continue below the last entry, skipping entries sharing its score
+		// that were already scanned
+		last := entries[len(entries)-1]
+		if strconv.FormatFloat(last.Score, 'f', -1, 64) == max {
+			offset += int64(len(entries))
+			continue
+		}
+		max = strconv.FormatFloat(last.Score, 'f', -1, 64)
+		offset = 0
+		for _, z := range entries {
+			if z.Score == last.Score {
+				offset++
+			}
+		}
+	}
+}
+
+// loadIndexed fetches the jobs of index entries, returning nil for jobs that
+// no longer exist
+func (s *Store) loadIndexed(ctx context.Context, entries []goredis.Z) ([]*models.Job, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(entries))
+	for i, z := range entries {
+		keys[i] = s.keys(ctx).JobKey(z.Member.(string))
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indexed jobs: %w", err)
+	}
+
+	jobs := make([]*models.Job, len(values))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var job models.Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job %s: %w", entries[i].Member, err)
+		}
+		jobs[i] = &job
+	}
+
+	return jobs, nil
+}
+
+// matches applies the filters not covered by the scanned index
+func (q JobQuery) matches(job *models.Job) bool {
+	if q.Status != "" && job.Status != q.Status {
+		return false
+	}
+	if q.RequestID != "" && job.RequestID != q.RequestID {
+		return false
+	}
+	if q.PatternContains != "" && !patternContains(job, strings.ToLower(q.PatternContains)) {
+		return false
+	}
+	return true
+}
+
+// patternContains reports whether the pattern of job, any of its fixed
+// strings or any leaf of its query contains the lowercase substring
+func patternContains(job *models.Job, substr string) bool {
+	if strings.Contains(strings.ToLower(job.Pattern), substr) {
+		return true
+	}
+	for _, p := range job.Patterns {
+		if strings.Contains(strings.ToLower(p), substr) {
+			return true
+		}
+	}
+	for _, p := range job.Query.Patterns() {
+		if strings.Contains(strings.ToLower(p), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor encodes the position of the last returned index entry
+func encodeCursor(z goredis.Z) string {
+	raw := strconv.FormatFloat(z.Score, 'f', -1, 64) + ":" + z.Member.(string)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*goredis.Z, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	score, member, ok := strings.Cut(string(raw), ":")
+	if !ok || member == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	value, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &goredis.Z{Score: value, Member: member}, nil
+}
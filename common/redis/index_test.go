@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// listAll follows cursors until the last page and returns the job IDs in order
+func listAll(t *testing.T, store *Store, query JobQuery) []string {
+	t.Helper()
+
+	var ids []string
+	for {
+		page, err := store.ListJobs(context.Background(), query)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Jobs), query.Limit)
+
+		for _, job := range page.Jobs {
+			ids = append(ids, job.ID)
+		}
+		if page.NextCursor == "" {
+			return ids
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	// More jobs than a scan batch, several of them sharing a creation time
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var newestFirst []string
+	for i := 0; i < 250; i++ {
+		job := &models.Job{
+			ID:        fmt.Sprintf("grep_%03d", i),
+			RequestID: fmt.Sprintf("req_%d", i%2),
+			Pattern:   fmt.Sprintf("Error %d", i),
+			Status:    models.JobStatusPending,
+			CreatedAt: base.Add(time.Duration(i/3) * time.Second),
+		}
+		require.NoError(t, store.SaveJob(ctx, job))
+		newestFirst = append([]string{job.ID}, newestFirst...)
+	}
+
+	t.Run("AllPages", func(t *testing.T) {
+		assert.Equal(t, newestFirst, listAll(t, store, JobQuery{Limit: 7}))
+		assert.Equal(t, newestFirst, listAll(t, store, JobQuery{Limit: 250}))
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		_, err := store.UpdateJob(ctx, "grep_010", func(j *models.Job) error {
+			j.Status = models.JobStatusCompleted
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"grep_010"}, listAll(t, store, JobQuery{Status: models.JobStatusCompleted, Limit: 10}))
+		assert.Len(t, listAll(t, store, JobQuery{Status: models.JobStatusPending, Limit: 50}), 249)
+	})
+
+	t.Run("RequestAndPattern", func(t *testing.T) {
+		assert.Len(t, listAll(t, store, JobQuery{RequestID: "req_1", Limit: 20}), 125)
+		assert.Equal(t, []string{"grep_049"}, listAll(t, store, JobQuery{PatternContains: "ERROR 49", Limit: 2}))
+		assert.Equal(t, []string{"grep_249", "grep_149", "grep_049"}, listAll(t, store, JobQuery{PatternContains: "49", RequestID: "req_1", Limit: 2}))
+	})
+
+	t.Run("QueryPattern", func(t *testing.T) {
+		job := &models.Job{
+			ID:        "grep_query",
+			RequestID: "req_query",
+			Query: &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+				{Pattern: "timeout"},
+				{Op: models.QueryOpNot, Children: []models.Query{{Pattern: "healthcheck"}}},
+			}},
+			Status:    models.JobStatusPending,
+			CreatedAt: base,
+		}
+		require.NoError(t, store.SaveJob(ctx, job))
+
+		assert.Equal(t, []string{"grep_query"}, listAll(t, store, JobQuery{PatternContains: "HealthCheck", Limit: 5}))
+	})
+
+	t.Run("FixedStrings", func(t *testing.T) {
+		job := &models.Job{
+			ID:        "grep_literals",
+			RequestID: "req_literals",
+			Patterns:  []string{"198.51.100.7", "evil.example.com"},
+			Status:    models.JobStatusPending,
+			CreatedAt: base,
+		}
+		require.NoError(t, store.SaveJob(ctx, job))
+
+		assert.Equal(t, []string{"grep_literals"}, listAll(t, store, JobQuery{PatternContains: "EVIL.example", Limit: 5}))
+	})
+
+	t.Run("TimeRange", func(t *testing.T) {
+		ids := listAll(t, store, JobQuery{
+			CreatedAfter:  base.Add(10 * time.Second),
+			CreatedBefore: base.Add(12 * time.Second),
+			Limit:         4,
+		})
+		assert.Equal(t, []string{"grep_035", "grep_034", "grep_033", "grep_032", "grep_031", "grep_030"}, ids)
+	})
+
+	t.Run("StaleEntriesAreDropped", func(t *testing.T) {
+		mr.Del("job:grep_249")
+
+		page, err := store.ListJobs(ctx, JobQuery{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "grep_248", page.Jobs[0].ID)
+
+		members, err := mr.ZMembers("jobs:by_created")
+		require.NoError(t, err)
+		assert.NotContains(t, members, "grep_249", "stale entry should be removed")
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		_, err := store.ListJobs(ctx, JobQuery{Cursor: "not a cursor", Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// EnqueueJob adds a job to the dispatch queue shared by all tenants, ordered
+// by priority and then by creation
+func (s *Store) EnqueueJob(ctx context.Context, job *models.Job) error {
+	member := tenantMember(tenant.FromContext(ctx), job.ID)
+	score := models.QueueScore(job.Priority, job.CreatedAt)
+	if err := s.client.ZAdd(ctx, models.JobQueueKey, goredis.Z{Score: score, Member: member}).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// DequeueJob removes a job from the dispatch queue, it is a no-op for jobs
+// that are not queued
+func (s *Store) DequeueJob(ctx context.Context, jobID string) error {
+	if err := s.client.ZRem(ctx, models.JobQueueKey, tenantMember(tenant.FromContext(ctx), jobID)).Err(); err != nil {
+		return fmt.Errorf("failed to dequeue job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// QueuePosition returns the number of jobs of any tenant ahead of a job in
+// the dispatch queue, false if the job is not queued
+func (s *Store) QueuePosition(ctx context.Context, jobID string) (int, bool, error) {
+	rank, err := s.client.ZRank(ctx, models.JobQueueKey, tenantMember(tenant.FromContext(ctx), jobID)).Result()
+	if errors.Is(err, goredis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get queue position of job %s: %w", jobID, err)
+	}
+	return int(rank), true, nil
+}
+
+// SetChunkSize records the chunk size files are split with
+func (s *Store) SetChunkSize(ctx context.Context, size int64) error {
+	if err := s.client.Set(ctx, models.ChunkSizeKey, size, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set chunk size: %w", err)
+	}
+	return nil
+}
+
+// ChunkSize returns the chunk size files are split with, DefaultChunkSize
+// until a manager recorded another one
+func (s *Store) ChunkSize(ctx context.Context) (int64, error) {
+	value, err := s.client.Get(ctx, models.ChunkSizeKey).Result()
+	if errors.Is(err, goredis.Nil) {
+		return models.DefaultChunkSize, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chunk size: %w", err)
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid chunk size %q", value)
+	}
+	return size, nil
+}
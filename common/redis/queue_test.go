@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestJobQueue(t *testing.T) {
+	ctx := context.Background()
+	acme := tenant.NewContext(ctx, "acme")
+	store, mr := newTestStore(t)
+
+	created := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	jobs := []struct {
+		ctx context.Context
+		job *models.Job
+	}{
+		{ctx, &models.Job{ID: "grep_batch", Priority: models.PriorityBatch, CreatedAt: created}},
+		{ctx, &models.Job{ID: "grep_normal", CreatedAt: created.Add(time.Minute)}},
+		{acme, &models.Job{ID: "grep_old", Priority: models.PriorityNormal, CreatedAt: created}},
+		{acme, &models.Job{ID: "grep_urgent", Priority: models.PriorityInteractive, CreatedAt: created.Add(time.Hour)}},
+	}
+	for _, entry := range jobs {
+		require.NoError(t, store.EnqueueJob(entry.ctx, entry.job))
+	}
+
+	members, err := mr.ZMembers(models.JobQueueKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme/grep_urgent", "acme/grep_old", "/grep_normal", "/grep_batch"}, members)
+
+	position, ok, err := store.QueuePosition(ctx, "grep_batch")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, position)
+
+	// Queued jobs of other tenants are not visible by ID
+	_, ok, err = store.QueuePosition(ctx, "grep_urgent")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.DequeueJob(acme, "grep_urgent"))
+	require.NoError(t, store.DequeueJob(acme, "grep_urgent"))
+
+	position, ok, err = store.QueuePosition(ctx, "grep_batch")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, position)
+
+	position, ok, err = store.QueuePosition(acme, "grep_old")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0, position)
+}
+
+func TestChunkSize(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	size, err := store.ChunkSize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultChunkSize, size)
+
+	require.NoError(t, store.SetChunkSize(ctx, 1<<20))
+	size, err = store.ChunkSize(tenant.NewContext(ctx, "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), size, "the chunk size should be shared by all tenants")
+}
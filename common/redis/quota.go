@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// consumeScript adds ARGV[1] to the counter KEYS[1] unless that would take it
+// over ARGV[2], the counter expires ARGV[3] milliseconds after it is created
+var consumeScript = goredis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+local amount = tonumber(ARGV[1])
+if current + amount > tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("INCRBY", KEYS[1], amount)
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// ConsumeQuota atomically adds amount to the named usage counter, unless the
+// counter would exceed limit. The counter is dropped ttl after its first use,
+// so name should identify the window it counts, e.g. a day.
+func (s *Store) ConsumeQuota(ctx context.Context, name string, amount, limit int64, ttl time.Duration) (bool, error) {
+	ok, err := consumeScript.Run(ctx, s.client, []string{s.keys(ctx).QuotaKey(name)}, amount, limit, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to consume quota %s: %w", name, err)
+	}
+	return ok == 1, nil
+}
+
+// RefundQuota gives back usage consumed for work that did not happen
+func (s *Store) RefundQuota(ctx context.Context, name string, amount int64) error {
+	if err := s.client.DecrBy(ctx, s.keys(ctx).QuotaKey(name), amount).Err(); err != nil {
+		return fmt.Errorf("failed to refund quota %s: %w", name, err)
+	}
+	return nil
+}
+
+// acquireSlotScript adds the job ARGV[1] to the running jobs KEYS[1] unless
+// ARGV[2] jobs are running already, 0 for no limit. Jobs neither pending
+// (KEYS[2]) nor processing (KEYS[3]) are dropped first. Those without a job
+// under the prefix ARGV[5] are only dropped once admitted more than ARGV[4]
+// milliseconds before ARGV[3], as they may not have been saved yet.
+var acquireSlotScript = goredis.NewScript(`
+local admitted = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+for i = 1, #admitted, 2 do
+	local id = admitted[i]
+	if not redis.call("ZSCORE", KEYS[2], id) and not redis.call("ZSCORE", KEYS[3], id)
+		and (redis.call("EXISTS", ARGV[5] .. id) == 1
+			or tonumber(admitted[i + 1]) < tonumber(ARGV[3]) - tonumber(ARGV[4])) then
+		redis.call("ZREM", KEYS[1], id)
+	end
+end
+local limit = tonumber(ARGV[2])
+if limit > 0 and redis.call("ZCARD", KEYS[1]) >= limit then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// slotGrace is how long a job slot is held for a job that was not saved yet
+const slotGrace = time.Minute
+
+// AcquireJobSlot atomically counts jobID against the named running jobs
+// limit, unless limit jobs are pending or processing already. A limit of 0
+// counts the job without refusing it. Slots of jobs that finished are given
+// back on the way.
+func (s *Store) AcquireJobSlot(ctx context.Context, name, jobID string, limit int64) (bool, error) {
+	keys := s.keys(ctx)
+	ok, err := acquireSlotScript.Run(ctx, s.client,
+		[]string{keys.RunningJobsKey(name), keys.JobsByStatusKey(models.JobStatusPending), keys.JobsByStatusKey(models.JobStatusProcessing)},
+		jobID, limit, time.Now().UnixMilli(), slotGrace.Milliseconds(), keys.JobKey("")).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire job slot %s: %w", name, err)
+	}
+	return ok == 1, nil
+}
+
+// ReleaseJobSlot gives back the slot of a job that was not created after all
+func (s *Store) ReleaseJobSlot(ctx context.Context, name, jobID string) error {
+	if err := s.client.ZRem(ctx, s.keys(ctx).RunningJobsKey(name), jobID).Err(); err != nil {
+		return fmt.Errorf("failed to release job slot %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestQuota(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "team-a")
+	store, mr := newTestStore(t)
+
+	ok, err := store.ConsumeQuota(ctx, "bytes:day", 60, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, mr.TTL("tenant:team-a:quota:bytes:day"))
+
+	ok, err = store.ConsumeQuota(ctx, "bytes:day", 50, 100, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok, "consuming over the limit should be refused")
+
+	ok, err = store.ConsumeQuota(ctx, "bytes:day", 40, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok, "a refused request should not count")
+
+	require.NoError(t, store.RefundQuota(ctx, "bytes:day", 40))
+	got, err := mr.Get("tenant:team-a:quota:bytes:day")
+	require.NoError(t, err)
+	assert.Equal(t, "60", got)
+
+	mr.FastForward(time.Hour)
+	ok, err = store.ConsumeQuota(ctx, "bytes:day", 100, 100, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok, "usage should reset with the window")
+}
+
+func TestJobSlots(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "team-a")
+	store, mr := newTestStore(t)
+
+	acquire := func(name, jobID string, limit int64) bool {
+		t.Helper()
+		ok, err := store.AcquireJobSlot(ctx, name, jobID, limit)
+		require.NoError(t, err)
+		return ok
+	}
+
+	assert.True(t, acquire("key:a", "grep_1", 2))
+	assert.True(t, acquire("key:a", "grep_2", 0), "no limit should refuse nothing")
+	assert.False(t, acquire("key:a", "grep_3", 2), "no slot should be left")
+	assert.True(t, acquire("key:b", "grep_3", 2), "other keys should have their own slots")
+
+	require.NoError(t, store.ReleaseJobSlot(ctx, "key:a", "grep_2"))
+	assert.True(t, acquire("key:a", "grep_3", 2))
+
+	// Jobs that finished give back their slot right away, those never saved
+	// once the grace period is over
+	require.NoError(t, store.SaveJob(ctx, &models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: time.Now()}))
+	require.NoError(t, store.SaveJob(ctx, &models.Job{ID: "grep_3", Status: models.JobStatusProcessing, CreatedAt: time.Now()}))
+	assert.True(t, acquire("key:a", "grep_4", 2))
+	assert.False(t, acquire("key:a", "grep_5", 2), "unsaved jobs should be held")
+
+	_, err := mr.ZAdd("tenant:team-a:running:key:a", float64(time.Now().Add(-2*slotGrace).UnixMilli()), "grep_4")
+	require.NoError(t, err)
+	assert.True(t, acquire("key:a", "grep_5", 2))
+
+	members, err := mr.ZMembers("tenant:team-a:running:key:a")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"grep_3", "grep_5"}, members)
+}
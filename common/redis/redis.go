@@ -1 +1,298 @@
 package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// ErrJobNotFound is returned when a job key does not exist
+var ErrJobNotFound = errors.New("job not found")
+
+// maxTxRetries bounds optimistic transaction retries on concurrent updates
+const maxTxRetries = 10
+
+// RedisOptions configures the redis connection
+type RedisOptions struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+// Store represents the redis backed job state store
+type Store struct {
+	options *RedisOptions
+	client  *goredis.Client
+}
+
+// New returns a new Store, call Instantiate before using it
+func New(addr, password string, db int, log *logger.Logger) *Store {
+	InitLogger(log)
+
+	return &Store{
+		options: &RedisOptions{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		},
+	}
+}
+
+// keys returns the key namespace of the tenant ctx is scoped to
+func (s *Store) keys(ctx context.Context) models.RedisKeys {
+	return models.RedisKeys{Tenant: tenant.FromContext(ctx)}
+}
+
+// tenantMember identifies an entry of a sorted set shared by all tenants,
+// tenant IDs cannot contain '/'
+func tenantMember(tenantID, id string) string {
+	return tenantID + "/" + id
+}
+
+func splitTenantMember(member string) (tenantID, id string, ok bool) {
+	return strings.Cut(member, "/")
+}
+
+// Instantiate connects to redis and verifies the connection
+func (s *Store) Instantiate(ctx context.Context) error {
+	s.client = goredis.NewClient(&goredis.Options{
+		Addr:     s.options.Addr,
+		Password: s.options.Password,
+		DB:       s.options.DB,
+	})
+
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		log.Error("failed to ping redis", "addr", s.options.Addr, "err", err)
+		return fmt.Errorf("failed to connect to redis at %s: %w", s.options.Addr, err)
+	}
+
+	log.Info("redis initialized successfully", "addr", s.options.Addr)
+	return nil
+}
+
+// Ping checks that redis is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis at %s: %w", s.options.Addr, err)
+	}
+	return nil
+}
+
+// Close closes the underlying redis client
+func (s *Store) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Close()
+}
+
+// SaveJob stores the job under its job key, overwriting any previous value,
+// and adds it to the listing indexes
+func (s *Store) SaveJob(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, s.keys(ctx).JobKey(job.ID), data, 0)
+		s.indexJob(ctx, pipe, job)
+		s.trackRetirement(ctx, pipe, job)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to save job", "job_id", job.ID, "err", err)
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// trackRetirement lists a finished job in UnretiredJobsKey until its expiry
+// is scheduled, so that it is retired even if the news that it finished is
+// lost
+func (s *Store) trackRetirement(ctx context.Context, pipe goredis.Pipeliner, job *models.Job) {
+	member := tenantMember(tenant.FromContext(ctx), job.ID)
+	if !job.Status.IsTerminal() || job.ExpiresAt != nil {
+		pipe.ZRem(ctx, models.UnretiredJobsKey, member)
+		return
+	}
+
+	finished := time.Now()
+	if job.CompletedAt != nil {
+		finished = *job.CompletedAt
+	}
+	pipe.ZAddNX(ctx, models.UnretiredJobsKey, goredis.Z{Score: float64(finished.UnixMilli()), Member: member})
+}
+
+// GetJob retrieves a job, returns ErrJobNotFound if it does not exist or
+// ErrJobDeleted if it was deleted
+func (s *Store) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	data, err := s.client.Get(ctx, s.keys(ctx).JobKey(jobID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, s.missingJob(ctx, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %w", jobID, err)
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", jobID, err)
+	}
+
+	return &job, nil
+}
+
+// UpdateJob atomically applies fn to the stored job and saves the result,
+// the update is retried if the job was modified concurrently
+func (s *Store) UpdateJob(ctx context.Context, jobID string, fn func(job *models.Job) error) (*models.Job, error) {
+	key := s.keys(ctx).JobKey(jobID)
+	var updated models.Job
+
+	txf := func(tx *goredis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			return s.missingJob(ctx, jobID)
+		}
+		if err != nil {
+			return err
+		}
+
+		var job models.Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return fmt.Errorf("failed to unmarshal job: %w", err)
+		}
+		previous, retired := job.Status, job.ExpiresAt != nil
+
+		if err := fn(&job); err != nil {
+			return err
+		}
+
+		data, err = json.Marshal(&job)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, goredis.KeepTTL)
+			if job.Status != previous {
+				pipe.ZRem(ctx, s.keys(ctx).JobsByStatusKey(previous), job.ID)
+				pipe.ZAdd(ctx, s.keys(ctx).JobsByStatusKey(job.Status), goredis.Z{Score: indexScore(&job), Member: job.ID})
+			}
+			if job.Status != previous || (job.ExpiresAt != nil) != retired {
+				s.trackRetirement(ctx, pipe, &job)
+			}
+			return nil
+		})
+		if err == nil {
+			updated = job
+		}
+		return err
+	}
+
+	for i := 0; i < maxTxRetries; i++ {
+		err := s.client.Watch(ctx, txf, key)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &updated, nil
+	}
+
+	return nil, fmt.Errorf("failed to update job %s: too many concurrent updates", jobID)
+}
+
+// SetStats overwrites the stats of a job
+func (s *Store) SetStats(ctx context.Context, jobID string, stats models.JobStats) error {
+	values := make(map[string]interface{})
+	for field, value := range statsFields(stats) {
+		values[field] = value
+	}
+
+	err := s.client.HSet(ctx, s.keys(ctx).JobStatsKey(jobID), values).Err()
+	if err != nil {
+		log.Error("failed to set job stats", "job_id", jobID, "err", err)
+		return fmt.Errorf("failed to set stats for job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// IncrStats atomically adds delta to the stats of a job
+func (s *Store) IncrStats(ctx context.Context, jobID string, delta models.JobStats) error {
+	key := s.keys(ctx).JobStatsKey(jobID)
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for field, value := range statsFields(delta) {
+			if value != 0 {
+				pipe.HIncrBy(ctx, key, field, value)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to increment job stats", "job_id", jobID, "err", err)
+		return fmt.Errorf("failed to increment stats for job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// GetStats returns the stats of a job, missing stats are reported as zero
+func (s *Store) GetStats(ctx context.Context, jobID string) (*models.JobStats, error) {
+	var raw struct {
+		TotalFiles      int   `redis:"total_files"`
+		ProcessedFiles  int   `redis:"processed_files"`
+		TotalChunks     int   `redis:"total_chunks"`
+		ProcessedChunks int   `redis:"processed_chunks"`
+		TotalMatches    int   `redis:"total_matches"`
+		BytesProcessed  int64 `redis:"bytes_processed"`
+	}
+
+	if err := s.client.HGetAll(ctx, s.keys(ctx).JobStatsKey(jobID)).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to get stats for job %s: %w", jobID, err)
+	}
+
+	return &models.JobStats{
+		TotalFiles:      raw.TotalFiles,
+		ProcessedFiles:  raw.ProcessedFiles,
+		TotalChunks:     raw.TotalChunks,
+		ProcessedChunks: raw.ProcessedChunks,
+		TotalMatches:    raw.TotalMatches,
+		BytesProcessed:  raw.BytesProcessed,
+	}, nil
+}
+
+// statsFields flattens stats into hash fields named after their JSON tags
+func statsFields(stats models.JobStats) map[string]int64 {
+	return map[string]int64{
+		"total_files":      int64(stats.TotalFiles),
+		"processed_files":  int64(stats.ProcessedFiles),
+		"total_chunks":     int64(stats.TotalChunks),
+		"processed_chunks": int64(stats.ProcessedChunks),
+		"total_matches":    int64(stats.TotalMatches),
+		"bytes_processed":  stats.BytesProcessed,
+	}
+}
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	return store, mr
+}
+
+func TestJobOperations(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	job := &models.Job{
+		ID:        "grep_1",
+		RequestID: "req_1",
+		Pattern:   "error.*",
+		Files:     []string{"logs/app.log"},
+		Status:    models.JobStatusPending,
+		CreatedAt: time.Now().UTC(),
+		Regex:     true,
+	}
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		require.NoError(t, store.SaveJob(ctx, job))
+		assert.True(t, mr.Exists("job:grep_1"), "job should be stored under its job key")
+
+		got, err := store.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, job.Pattern, got.Pattern)
+		assert.Equal(t, job.Files, got.Files)
+		assert.Equal(t, job.Status, got.Status)
+		assert.True(t, job.CreatedAt.Equal(got.CreatedAt))
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		_, err := store.GetJob(ctx, "missing")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated, err := store.UpdateJob(ctx, job.ID, func(j *models.Job) error {
+			j.Status = models.JobStatusProcessing
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusProcessing, updated.Status)
+
+		got, err := store.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusProcessing, got.Status)
+	})
+
+	t.Run("UpdateAbortsOnError", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		_, err := store.UpdateJob(ctx, job.ID, func(j *models.Job) error {
+			j.Status = models.JobStatusFailed
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		got, err := store.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusProcessing, got.Status, "aborted update should not be saved")
+	})
+
+	t.Run("UpdateMissing", func(t *testing.T) {
+		_, err := store.UpdateJob(ctx, "missing", func(j *models.Job) error { return nil })
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("ConcurrentUpdates", func(t *testing.T) {
+		require.NoError(t, store.SaveJob(ctx, &models.Job{ID: "grep_2"}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.UpdateJob(ctx, "grep_2", func(j *models.Job) error {
+					j.Progress += 10
+					return nil
+				})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		got, err := store.GetJob(ctx, "grep_2")
+		require.NoError(t, err)
+		assert.Equal(t, float64(50), got.Progress, "no update should be lost")
+	})
+}
+
+func TestStatsOperations(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	t.Run("MissingStatsAreZero", func(t *testing.T) {
+		stats, err := store.GetStats(ctx, "missing")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStats{}, *stats)
+	})
+
+	t.Run("SetAndIncrement", func(t *testing.T) {
+		require.NoError(t, store.SetStats(ctx, "grep_1", models.JobStats{TotalFiles: 3, TotalChunks: 4}))
+		require.NoError(t, store.IncrStats(ctx, "grep_1", models.JobStats{ProcessedChunks: 1, TotalMatches: 7, BytesProcessed: 1024}))
+		require.NoError(t, store.IncrStats(ctx, "grep_1", models.JobStats{ProcessedChunks: 1, TotalMatches: 3, BytesProcessed: 1024}))
+
+		stats, err := store.GetStats(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStats{
+			TotalFiles:      3,
+			TotalChunks:     4,
+			ProcessedChunks: 2,
+			TotalMatches:    10,
+			BytesProcessed:  2048,
+		}, *stats)
+	})
+}
+
+func TestTenantIsolation(t *testing.T) {
+	store, mr := newTestStore(t)
+	teamA := tenant.NewContext(context.Background(), "team-a")
+	teamB := tenant.NewContext(context.Background(), "team-b")
+
+	job := &models.Job{ID: "grep_1", Tenant: "team-a", Status: models.JobStatusPending, CreatedAt: time.Now()}
+	require.NoError(t, store.SaveJob(teamA, job))
+	assert.True(t, mr.Exists("tenant:team-a:job:grep_1"))
+
+	_, err := store.GetJob(teamB, job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = store.GetJob(context.Background(), job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	page, err := store.ListJobs(teamB, JobQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Jobs)
+
+	page, err = store.ListJobs(teamA, JobQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	assert.Equal(t, "team-a", page.Jobs[0].Tenant)
+}
+
+func TestPing(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Ping(ctx))
+
+	mr.Close()
+	assert.Error(t, store.Ping(ctx))
+}
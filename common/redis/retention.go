@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// ExpiryGrace is how long the keys of a job outlive its scheduled expiry, so
+// that a janitor finds the job to purge it. Without a janitor the keys still
+// expire on their own.
+const ExpiryGrace = time.Hour
+
+// ExpiringJob is an entry of the expiry schedule shared by all tenants
+type ExpiringJob struct {
+	Tenant string
+	JobID  string
+	Due    time.Time
+}
+
+// ScheduleExpiry makes a finished job due for removal at the given time and
+// sets the keys of the job to expire ExpiryGrace later, or ExpiryGrace from
+// now for jobs that are overdue already
+func (s *Store) ScheduleExpiry(ctx context.Context, jobID string, at time.Time) error {
+	keys := s.keys(ctx)
+	deadline := at.Add(ExpiryGrace)
+	if earliest := time.Now().Add(ExpiryGrace); deadline.Before(earliest) {
+		deadline = earliest
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZAdd(ctx, models.ExpiringJobsKey, goredis.Z{Score: float64(at.UnixMilli()), Member: tenantMember(tenant.FromContext(ctx), jobID)})
+		for _, key := range append([]string{keys.JobKey(jobID), keys.JobStatsKey(jobID), keys.MatchedFilesKey(jobID), keys.WebhookKey(jobID), keys.WebhookAttemptsKey(jobID)}, dispatchKeys(keys, jobID)...) {
+			pipe.PExpireAt(ctx, key, deadline)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule expiry of job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// DueExpiries returns up to limit jobs of any tenant that are due for removal
+// at now, the earliest first
+func (s *Store) DueExpiries(ctx context.Context, now time.Time, limit int) ([]ExpiringJob, error) {
+	entries, err := s.client.ZRangeByScoreWithScores(ctx, models.ExpiringJobsKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due expiries: %w", err)
+	}
+
+	due := make([]ExpiringJob, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, jobID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed expiry entry", "member", member)
+			s.client.ZRem(ctx, models.ExpiringJobsKey, member)
+			continue
+		}
+
+		due = append(due, ExpiringJob{
+			Tenant: tenantID,
+			JobID:  jobID,
+			Due:    time.UnixMilli(int64(entry.Score)).UTC(),
+		})
+	}
+
+	return due, nil
+}
+
+// CancelExpiry removes a job from the expiry schedule, it is a no-op for jobs
+// that are not scheduled
+func (s *Store) CancelExpiry(ctx context.Context, jobID string) error {
+	if err := s.client.ZRem(ctx, models.ExpiringJobsKey, tenantMember(tenant.FromContext(ctx), jobID)).Err(); err != nil {
+		return fmt.Errorf("failed to cancel expiry of job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// UnretiredJobs returns up to limit jobs of any tenant that finished before
+// before and whose expiry is still not scheduled, the earliest first. Due is
+// when they finished.
+func (s *Store) UnretiredJobs(ctx context.Context, before time.Time, limit int) ([]ExpiringJob, error) {
+	entries, err := s.client.ZRangeByScoreWithScores(ctx, models.UnretiredJobsKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   "(" + strconv.FormatInt(before.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unretired jobs: %w", err)
+	}
+
+	unretired := make([]ExpiringJob, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, jobID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed unretired job", "member", member)
+			s.client.ZRem(ctx, models.UnretiredJobsKey, member)
+			continue
+		}
+
+		unretired = append(unretired, ExpiringJob{
+			Tenant: tenantID,
+			JobID:  jobID,
+			Due:    time.UnixMilli(int64(entry.Score)).UTC(),
+		})
+	}
+
+	return unretired, nil
+}
+
+// ForgetUnretiredJob removes a job that is gone from the unretired jobs
+func (s *Store) ForgetUnretiredJob(ctx context.Context, jobID string) error {
+	if err := s.client.ZRem(ctx, models.UnretiredJobsKey, tenantMember(tenant.FromContext(ctx), jobID)).Err(); err != nil {
+		return fmt.Errorf("failed to forget unretired job %s: %w", jobID, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestScheduleExpiry(t *testing.T) {
+	ctx := context.Background()
+	acme := tenant.NewContext(ctx, "acme")
+	store, mr := newTestStore(t)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	job := &models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: now}
+	require.NoError(t, store.SaveJob(ctx, job))
+	require.NoError(t, store.SetStats(ctx, job.ID, models.JobStats{TotalFiles: 1}))
+	require.NoError(t, store.SaveJob(acme, &models.Job{ID: "grep_2", Status: models.JobStatusFailed, CreatedAt: now}))
+
+	require.NoError(t, store.ScheduleExpiry(ctx, "grep_1", now.Add(time.Hour)))
+	require.NoError(t, store.ScheduleExpiry(acme, "grep_2", now.Add(time.Minute)))
+
+	due, err := store.DueExpiries(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = store.DueExpiries(ctx, now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []ExpiringJob{
+		{Tenant: "acme", JobID: "grep_2", Due: now.Add(time.Minute)},
+		{Tenant: "", JobID: "grep_1", Due: now.Add(time.Hour)},
+	}, due)
+
+	// Updates keep the deadline
+	_, err = store.UpdateJob(ctx, "grep_1", func(job *models.Job) error {
+		job.Progress = 100
+		return nil
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, (time.Hour + ExpiryGrace).Seconds(), mr.TTL("job:grep_1").Seconds(), 5)
+	assert.InDelta(t, (time.Hour + ExpiryGrace).Seconds(), mr.TTL("job:grep_1:stats").Seconds(), 5)
+
+	require.NoError(t, store.CancelExpiry(acme, "grep_2"))
+	due, err = store.DueExpiries(ctx, now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "grep_1", due[0].JobID)
+
+	// Overdue jobs are left for the janitor to find
+	require.NoError(t, store.ScheduleExpiry(acme, "grep_2", now.Add(-24*time.Hour)))
+	assert.InDelta(t, ExpiryGrace.Seconds(), mr.TTL("tenant:acme:job:grep_2").Seconds(), 5)
+
+	// Without a janitor the job still goes away
+	mr.FastForward(time.Hour + ExpiryGrace + time.Second)
+	_, err = store.GetJob(ctx, "grep_1")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
@@ -0,0 +1,311 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// ErrSearchNotFound is returned when a saved search does not exist
+var ErrSearchNotFound = errors.New("saved search not found")
+
+// ErrSearchRunNotFound is returned when a job is not in the run history of a
+// saved search
+var ErrSearchRunNotFound = errors.New("search run not found")
+
+// MaxSearchRuns is the number of runs kept in the history of a saved search
+const MaxSearchRuns = 1000
+
+// ScheduledSearch is an entry of the schedule shared by all tenants
+type ScheduledSearch struct {
+	Tenant string
+	ID     string
+	Due    time.Time
+}
+
+// SaveSearch stores a saved search, overwriting any previous value, and
+// (un)schedules it according to its NextRunAt
+func (s *Store) SaveSearch(ctx context.Context, search *models.SavedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saved search: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, s.keys(ctx).SavedSearchKey(search.ID), data, 0)
+		pipe.ZAdd(ctx, s.keys(ctx).SavedSearchesKey(), goredis.Z{Score: float64(search.CreatedAt.UnixMilli()), Member: search.ID})
+		s.scheduleSearch(ctx, pipe, search)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to save search", "search_id", search.ID, "err", err)
+		return fmt.Errorf("failed to save search %s: %w", search.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) scheduleSearch(ctx context.Context, pipe goredis.Pipeliner, search *models.SavedSearch) {
+	member := tenantMember(tenant.FromContext(ctx), search.ID)
+	if search.NextRunAt == nil {
+		pipe.ZRem(ctx, models.ScheduledSearchesKey, member)
+		return
+	}
+	pipe.ZAdd(ctx, models.ScheduledSearchesKey, goredis.Z{Score: float64(search.NextRunAt.UnixMilli()), Member: member})
+}
+
+// GetSearch retrieves a saved search, returns ErrSearchNotFound if it does
+// not exist
+func (s *Store) GetSearch(ctx context.Context, searchID string) (*models.SavedSearch, error) {
+	data, err := s.client.Get(ctx, s.keys(ctx).SavedSearchKey(searchID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("%w: %s", ErrSearchNotFound, searchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search %s: %w", searchID, err)
+	}
+
+	var search models.SavedSearch
+	if err := json.Unmarshal(data, &search); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search %s: %w", searchID, err)
+	}
+
+	return &search, nil
+}
+
+// ListSearches returns all saved searches, newest first
+func (s *Store) ListSearches(ctx context.Context) ([]*models.SavedSearch, error) {
+	ids, err := s.client.ZRevRange(ctx, s.keys(ctx).SavedSearchesKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list searches: %w", err)
+	}
+
+	searches := make([]*models.SavedSearch, 0, len(ids))
+	for _, id := range ids {
+		search, err := s.GetSearch(ctx, id)
+		if errors.Is(err, ErrSearchNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
+
+// UpdateSearch atomically applies fn to the stored search and saves the
+// result, the update is retried if the search was modified concurrently.
+// Errors returned by fn abort the update and are passed through.
+func (s *Store) UpdateSearch(ctx context.Context, searchID string, fn func(search *models.SavedSearch) error) (*models.SavedSearch, error) {
+	key := s.keys(ctx).SavedSearchKey(searchID)
+	var updated models.SavedSearch
+
+	txf := func(tx *goredis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			return fmt.Errorf("%w: %s", ErrSearchNotFound, searchID)
+		}
+		if err != nil {
+			return err
+		}
+
+		var search models.SavedSearch
+		if err := json.Unmarshal(data, &search); err != nil {
+			return fmt.Errorf("failed to unmarshal search: %w", err)
+		}
+
+		if err := fn(&search); err != nil {
+			return err
+		}
+
+		data, err = json.Marshal(&search)
+		if err != nil {
+			return fmt.Errorf("failed to marshal search: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, 0)
+			s.scheduleSearch(ctx, pipe, &search)
+			return nil
+		})
+		if err == nil {
+			updated = search
+		}
+		return err
+	}
+
+	for i := 0; i < maxTxRetries; i++ {
+		err := s.client.Watch(ctx, txf, key)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+		if errors.Is(err, ErrSearchNotFound) {
+			// Drop a schedule entry left behind by the search
+			s.client.ZRem(ctx, models.ScheduledSearchesKey, tenantMember(tenant.FromContext(ctx), searchID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &updated, nil
+	}
+
+	return nil, fmt.Errorf("failed to update search %s: too many concurrent updates", searchID)
+}
+
+// DeleteSearch removes a saved search, its schedule and its run history
+func (s *Store) DeleteSearch(ctx context.Context, searchID string) error {
+	keys := s.keys(ctx)
+
+	var deleted *goredis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		deleted = pipe.Del(ctx, keys.SavedSearchKey(searchID))
+		pipe.Del(ctx, keys.SearchRunsKey(searchID), keys.SearchRunsIndexKey(searchID))
+		pipe.ZRem(ctx, keys.SavedSearchesKey(), searchID)
+		pipe.ZRem(ctx, models.ScheduledSearchesKey, tenantMember(tenant.FromContext(ctx), searchID))
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to delete search", "search_id", searchID, "err", err)
+		return fmt.Errorf("failed to delete search %s: %w", searchID, err)
+	}
+
+	if deleted.Val() == 0 {
+		return fmt.Errorf("%w: %s", ErrSearchNotFound, searchID)
+	}
+	return nil
+}
+
+// DueSearches returns up to limit searches of any tenant whose next run is
+// at or before now, the most overdue first
+func (s *Store) DueSearches(ctx context.Context, now time.Time, limit int) ([]ScheduledSearch, error) {
+	entries, err := s.client.ZRangeByScoreWithScores(ctx, models.ScheduledSearchesKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due searches: %w", err)
+	}
+
+	due := make([]ScheduledSearch, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, searchID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed schedule entry", "member", member)
+			s.client.ZRem(ctx, models.ScheduledSearchesKey, member)
+			continue
+		}
+
+		due = append(due, ScheduledSearch{
+			Tenant: tenantID,
+			ID:     searchID,
+			Due:    time.UnixMilli(int64(entry.Score)).UTC(),
+		})
+	}
+
+	return due, nil
+}
+
+// SaveSearchRun records a run of a saved search, overwriting an earlier
+// record of the same job. Only the latest MaxSearchRuns runs are kept.
+func (s *Store) SaveSearchRun(ctx context.Context, searchID string, run models.SearchRun) error {
+	keys := s.keys(ctx)
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search run: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, keys.SearchRunsKey(searchID), run.JobID, data)
+		pipe.ZAdd(ctx, keys.SearchRunsIndexKey(searchID), goredis.Z{Score: float64(run.CreatedAt.UnixMilli()), Member: run.JobID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save run of search %s: %w", searchID, err)
+	}
+
+	expired, err := s.client.ZRange(ctx, keys.SearchRunsIndexKey(searchID), 0, -MaxSearchRuns-1).Result()
+	if err != nil || len(expired) == 0 {
+		return nil
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HDel(ctx, keys.SearchRunsKey(searchID), expired...)
+		pipe.ZRem(ctx, keys.SearchRunsIndexKey(searchID), toMembers(expired)...)
+		return nil
+	})
+	if err != nil {
+		log.Warn("failed to trim search runs", "search_id", searchID, "err", err)
+	}
+	return nil
+}
+
+// GetSearchRun returns the run of a saved search for a job, it returns
+// ErrSearchRunNotFound once the run left the history
+func (s *Store) GetSearchRun(ctx context.Context, searchID, jobID string) (*models.SearchRun, error) {
+	data, err := s.client.HGet(ctx, s.keys(ctx).SearchRunsKey(searchID), jobID).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("%w: job %s of search %s", ErrSearchRunNotFound, jobID, searchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run of search %s: %w", searchID, err)
+	}
+
+	var run models.SearchRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal run of search %s: %w", searchID, err)
+	}
+	return &run, nil
+}
+
+// ListSearchRuns returns up to limit runs of a saved search, newest first
+func (s *Store) ListSearchRuns(ctx context.Context, searchID string, limit int) ([]models.SearchRun, error) {
+	keys := s.keys(ctx)
+
+	ids, err := s.client.ZRevRange(ctx, keys.SearchRunsIndexKey(searchID), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs of search %s: %w", searchID, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.client.HMGet(ctx, keys.SearchRunsKey(searchID), ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runs of search %s: %w", searchID, err)
+	}
+
+	runs := make([]models.SearchRun, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var run models.SearchRun
+		if err := json.Unmarshal([]byte(data), &run); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run of search %s: %w", searchID, err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+func toMembers(ids []string) []interface{} {
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	return members
+}
@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestSearchOperations(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	next := created.Add(8 * time.Hour)
+
+	scheduled := &models.SavedSearch{ID: "search_1", Name: "oom", Pattern: "OOMKilled", Files: []string{"logs/*.log"}, Schedule: "0 8 * * *", NextRunAt: &next, CreatedAt: created}
+	manual := &models.SavedSearch{ID: "search_2", Name: "panics", Pattern: "panic:", Files: []string{"logs/*.log"}, CreatedAt: created.Add(time.Minute)}
+	require.NoError(t, store.SaveSearch(ctx, scheduled))
+	require.NoError(t, store.SaveSearch(ctx, manual))
+
+	got, err := store.GetSearch(ctx, "search_1")
+	require.NoError(t, err)
+	assert.Equal(t, "OOMKilled", got.Pattern)
+
+	searches, err := store.ListSearches(ctx)
+	require.NoError(t, err)
+	require.Len(t, searches, 2)
+	assert.Equal(t, "search_2", searches[0].ID)
+
+	due, err := store.DueSearches(ctx, next.Add(-time.Second), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = store.DueSearches(ctx, next, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledSearch{{ID: "search_1", Due: next}}, due)
+
+	// Removing the schedule takes the search off the schedule
+	_, err = store.UpdateSearch(ctx, "search_1", func(search *models.SavedSearch) error {
+		search.Schedule = ""
+		search.NextRunAt = nil
+		return nil
+	})
+	require.NoError(t, err)
+
+	due, err = store.DueSearches(ctx, next, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	errAbort := errors.New("abort")
+	_, err = store.UpdateSearch(ctx, "search_1", func(search *models.SavedSearch) error { return errAbort })
+	assert.ErrorIs(t, err, errAbort)
+
+	require.NoError(t, store.DeleteSearch(ctx, "search_1"))
+	_, err = store.GetSearch(ctx, "search_1")
+	assert.ErrorIs(t, err, ErrSearchNotFound)
+	assert.ErrorIs(t, store.DeleteSearch(ctx, "search_1"), ErrSearchNotFound)
+	_, err = store.UpdateSearch(ctx, "search_1", func(search *models.SavedSearch) error { return nil })
+	assert.ErrorIs(t, err, ErrSearchNotFound)
+}
+
+func TestScheduledSearchesAcrossTenants(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i, id := range []string{"", "team-a", "team-b"} {
+		ctx := tenant.NewContext(context.Background(), id)
+		due := now.Add(time.Duration(-i) * time.Minute)
+		require.NoError(t, store.SaveSearch(ctx, &models.SavedSearch{ID: "search_1", Schedule: "@hourly", NextRunAt: &due}))
+	}
+
+	due, err := store.DueSearches(context.Background(), now, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledSearch{
+		{Tenant: "team-b", ID: "search_1", Due: now.Add(-2 * time.Minute)},
+		{Tenant: "team-a", ID: "search_1", Due: now.Add(-time.Minute)},
+	}, due)
+
+	// A schedule entry of a search deleted behind its back is dropped on update
+	teamA := tenant.NewContext(context.Background(), "team-a")
+	require.NoError(t, store.client.Del(teamA, models.RedisKeys{Tenant: "team-a"}.SavedSearchKey("search_1")).Err())
+	_, err = store.UpdateSearch(teamA, "search_1", func(search *models.SavedSearch) error { return nil })
+	require.ErrorIs(t, err, ErrSearchNotFound)
+
+	due, err = store.DueSearches(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+}
+
+func TestSearchRuns(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxSearchRuns+2; i++ {
+		run := models.SearchRun{JobID: fmt.Sprintf("grep_%d", i), Status: models.JobStatusPending, CreatedAt: start.Add(time.Duration(i) * time.Hour)}
+		require.NoError(t, store.SaveSearchRun(ctx, "search_1", run))
+	}
+
+	// Updating a run keeps its place in the history
+	last := fmt.Sprintf("grep_%d", MaxSearchRuns+1)
+	require.NoError(t, store.SaveSearchRun(ctx, "search_1", models.SearchRun{
+		JobID:        last,
+		Status:       models.JobStatusCompleted,
+		CreatedAt:    start.Add(MaxSearchRuns*time.Hour + time.Hour),
+		TotalMatches: 7,
+	}))
+
+	run, err := store.GetSearchRun(ctx, "search_1", last)
+	require.NoError(t, err)
+	assert.Equal(t, 7, run.TotalMatches)
+
+	runs, err := store.ListSearchRuns(ctx, "search_1", 2)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, last, runs[0].JobID)
+	assert.Equal(t, 7, runs[0].TotalMatches)
+	assert.Equal(t, fmt.Sprintf("grep_%d", MaxSearchRuns), runs[1].JobID)
+
+	runs, err = store.ListSearchRuns(ctx, "search_1", 2*MaxSearchRuns)
+	require.NoError(t, err)
+	assert.Len(t, runs, MaxSearchRuns)
+	assert.Equal(t, "grep_2", runs[len(runs)-1].JobID)
+
+	_, err = store.GetSearchRun(ctx, "search_1", "grep_0")
+	assert.ErrorIs(t, err, ErrSearchRunNotFound, "runs trimmed from the history should be gone")
+
+	n, err := store.client.HLen(ctx, models.RedisKeys{}.SearchRunsKey("search_1")).Result()
+	require.NoError(t, err)
+	assert.EqualValues(t, MaxSearchRuns, n)
+
+	runs, err = store.ListSearchRuns(ctx, "search_2", 10)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// AppendStreamResult records that a stream of a job sent a result, the
+// record of the stream expires ttl after the last result it sent. The stream
+// is listed with the job, so that it is removed along with it.
+func (s *Store) AppendStreamResult(ctx context.Context, jobID, streamID, resultID string, ttl time.Duration) error {
+	keys := s.keys(ctx)
+	key := keys.JobStreamKey(jobID, streamID)
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.RPush(ctx, key, resultID)
+		pipe.PExpire(ctx, key, ttl)
+		pipe.SAdd(ctx, keys.JobStreamsKey(jobID), streamID)
+		pipe.PExpire(ctx, keys.JobStreamsKey(jobID), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record result %s of stream %s: %w", resultID, streamID, err)
+	}
+	return nil
+}
+
+// StreamResults returns the IDs of the results a stream of a job sent, in
+// the order it sent them, none once the stream expired
+func (s *Store) StreamResults(ctx context.Context, jobID, streamID string) ([]string, error) {
+	ids, err := s.client.LRange(ctx, s.keys(ctx).JobStreamKey(jobID, streamID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results of stream %s: %w", streamID, err)
+	}
+	return ids, nil
+}
+
+// TrimStreamResults forgets all but the first n results a stream of a job
+// sent, so that the stream resumes after them
+func (s *Store) TrimStreamResults(ctx context.Context, jobID, streamID string, n int) error {
+	if err := s.client.LTrim(ctx, s.keys(ctx).JobStreamKey(jobID, streamID), 0, int64(n)-1).Err(); err != nil {
+		return fmt.Errorf("failed to trim results of stream %s: %w", streamID, err)
+	}
+	return nil
+}
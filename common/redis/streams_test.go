@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestStreamResults(t *testing.T) {
+	ctx := context.Background()
+	store, mr := newTestStore(t)
+
+	for _, id := range []string{"chunk_2", "chunk_1", "chunk_3"} {
+		require.NoError(t, store.AppendStreamResult(ctx, "grep_1", "stream_1", id, time.Hour))
+	}
+
+	ids, err := store.StreamResults(ctx, "grep_1", "stream_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chunk_2", "chunk_1", "chunk_3"}, ids, "results should be kept in the order they were sent")
+
+	require.NoError(t, store.TrimStreamResults(ctx, "grep_1", "stream_1", 2))
+	require.NoError(t, store.AppendStreamResult(ctx, "grep_1", "stream_1", "chunk_4", time.Hour))
+	ids, err = store.StreamResults(ctx, "grep_1", "stream_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chunk_2", "chunk_1", "chunk_4"}, ids)
+
+	ids, err = store.StreamResults(tenant.NewContext(ctx, "acme"), "grep_1", "stream_1")
+	require.NoError(t, err)
+	assert.Empty(t, ids, "streams should be scoped to their tenant")
+
+	mr.FastForward(time.Hour)
+	ids, err = store.StreamResults(ctx, "grep_1", "stream_1")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
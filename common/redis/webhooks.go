@@ -0,0 +1,209 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// ErrWebhookNotFound is returned when a job has no webhook
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// MaxWebhookAttempts is the number of delivery attempts kept per webhook
+const MaxWebhookAttempts = 100
+
+// PendingWebhook is an entry of the delivery queue shared by all tenants
+type PendingWebhook struct {
+	Tenant string
+	JobID  string
+	Due    time.Time
+}
+
+// SaveWebhook stores the webhook of a job, overwriting any previous value,
+// and queues it for delivery according to its state
+func (s *Store) SaveWebhook(ctx context.Context, jobID string, hook *models.Webhook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, s.keys(ctx).WebhookKey(jobID), data, 0)
+		s.queueWebhook(ctx, pipe, jobID, hook)
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to save webhook", "job_id", jobID, "err", err)
+		return fmt.Errorf("failed to save webhook of job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// queueWebhook keeps the delivery queue in line with the state of a webhook
+func (s *Store) queueWebhook(ctx context.Context, pipe goredis.Pipeliner, jobID string, hook *models.Webhook) {
+	member := tenantMember(tenant.FromContext(ctx), jobID)
+	if hook.State != models.WebhookStatePending || hook.NextAttemptAt == nil {
+		pipe.ZRem(ctx, models.PendingWebhooksKey, member)
+		return
+	}
+	pipe.ZAdd(ctx, models.PendingWebhooksKey, goredis.Z{Score: float64(hook.NextAttemptAt.UnixMilli()), Member: member})
+}
+
+// GetWebhook retrieves the webhook of a job, returns ErrWebhookNotFound if
+// the job has none
+func (s *Store) GetWebhook(ctx context.Context, jobID string) (*models.Webhook, error) {
+	data, err := s.client.Get(ctx, s.keys(ctx).WebhookKey(jobID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("%w: job %s", ErrWebhookNotFound, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook of job %s: %w", jobID, err)
+	}
+
+	var hook models.Webhook
+	if err := json.Unmarshal(data, &hook); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook of job %s: %w", jobID, err)
+	}
+
+	return &hook, nil
+}
+
+// UpdateWebhook atomically applies fn to the webhook of a job and saves the
+// result, the update is retried if the webhook was modified concurrently.
+// Errors returned by fn abort the update and are passed through.
+func (s *Store) UpdateWebhook(ctx context.Context, jobID string, fn func(hook *models.Webhook) error) (*models.Webhook, error) {
+	key := s.keys(ctx).WebhookKey(jobID)
+	var updated models.Webhook
+
+	txf := func(tx *goredis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			return fmt.Errorf("%w: job %s", ErrWebhookNotFound, jobID)
+		}
+		if err != nil {
+			return err
+		}
+
+		var hook models.Webhook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return fmt.Errorf("failed to unmarshal webhook: %w", err)
+		}
+
+		if err := fn(&hook); err != nil {
+			return err
+		}
+
+		data, err = json.Marshal(&hook)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, goredis.KeepTTL)
+			s.queueWebhook(ctx, pipe, jobID, &hook)
+			return nil
+		})
+		if err == nil {
+			updated = hook
+		}
+		return err
+	}
+
+	for i := 0; i < maxTxRetries; i++ {
+		err := s.client.Watch(ctx, txf, key)
+		if errors.Is(err, goredis.TxFailedErr) {
+			continue
+		}
+		if errors.Is(err, ErrWebhookNotFound) {
+			// Drop a queue entry left behind by a purged job
+			s.client.ZRem(ctx, models.PendingWebhooksKey, tenantMember(tenant.FromContext(ctx), jobID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &updated, nil
+	}
+
+	return nil, fmt.Errorf("failed to update webhook of job %s: too many concurrent updates", jobID)
+}
+
+// DueWebhooks returns up to limit webhooks of any tenant whose next attempt
+// is at or before now, the most overdue first
+func (s *Store) DueWebhooks(ctx context.Context, now time.Time, limit int) ([]PendingWebhook, error) {
+	entries, err := s.client.ZRangeByScoreWithScores(ctx, models.PendingWebhooksKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhooks: %w", err)
+	}
+
+	due := make([]PendingWebhook, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		tenantID, jobID, ok := splitTenantMember(member)
+		if !ok {
+			log.Warn("dropping malformed webhook queue entry", "member", member)
+			s.client.ZRem(ctx, models.PendingWebhooksKey, member)
+			continue
+		}
+
+		due = append(due, PendingWebhook{
+			Tenant: tenantID,
+			JobID:  jobID,
+			Due:    time.UnixMilli(int64(entry.Score)).UTC(),
+		})
+	}
+
+	return due, nil
+}
+
+// AddWebhookAttempt appends a delivery attempt to the log of a job's webhook,
+// only the latest MaxWebhookAttempts are kept
+func (s *Store) AddWebhookAttempt(ctx context.Context, jobID string, attempt models.WebhookAttempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook attempt: %w", err)
+	}
+
+	key := s.keys(ctx).WebhookAttemptsKey(jobID)
+	_, err = s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.RPush(ctx, key, data)
+		pipe.LTrim(ctx, key, -MaxWebhookAttempts, -1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add webhook attempt of job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+// ListWebhookAttempts returns the logged delivery attempts of a job's
+// webhook, oldest first
+func (s *Store) ListWebhookAttempts(ctx context.Context, jobID string) ([]models.WebhookAttempt, error) {
+	values, err := s.client.LRange(ctx, s.keys(ctx).WebhookAttemptsKey(jobID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook attempts of job %s: %w", jobID, err)
+	}
+
+	attempts := make([]models.WebhookAttempt, 0, len(values))
+	for _, value := range values {
+		var attempt models.WebhookAttempt
+		if err := json.Unmarshal([]byte(value), &attempt); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook attempt of job %s: %w", jobID, err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, nil
+}
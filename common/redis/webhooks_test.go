@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+func TestWebhookOperations(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "team-a")
+	store, _ := newTestStore(t)
+
+	hook := &models.Webhook{URL: "http://example.com/hook", Secret: "s3cret", State: models.WebhookStateWaiting}
+	require.NoError(t, store.SaveWebhook(ctx, "grep_1", hook))
+
+	got, err := store.GetWebhook(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, hook, got)
+
+	_, err = store.GetWebhook(context.Background(), "grep_1")
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+	// Waiting webhooks are not queued
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	due, err := store.DueWebhooks(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	_, err = store.UpdateWebhook(ctx, "grep_1", func(hook *models.Webhook) error {
+		hook.State = models.WebhookStatePending
+		hook.NextAttemptAt = &now
+		return nil
+	})
+	require.NoError(t, err)
+
+	due, err = store.DueWebhooks(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []PendingWebhook{{Tenant: "team-a", JobID: "grep_1", Due: now}}, due)
+
+	_, err = store.UpdateWebhook(ctx, "grep_1", func(hook *models.Webhook) error {
+		hook.State = models.WebhookStateDelivered
+		return nil
+	})
+	require.NoError(t, err)
+
+	due, err = store.DueWebhooks(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	_, err = store.UpdateWebhook(ctx, "grep_2", func(hook *models.Webhook) error { return nil })
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestWebhookAttempts(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	for i := 1; i <= MaxWebhookAttempts+1; i++ {
+		require.NoError(t, store.AddWebhookAttempt(ctx, "grep_1", models.WebhookAttempt{Attempt: i, StatusCode: 500}))
+	}
+
+	attempts, err := store.ListWebhookAttempts(ctx, "grep_1")
+	require.NoError(t, err)
+	require.Len(t, attempts, MaxWebhookAttempts)
+	assert.Equal(t, 2, attempts[0].Attempt)
+	assert.Equal(t, MaxWebhookAttempts+1, attempts[len(attempts)-1].Attempt)
+
+	attempts, err = store.ListWebhookAttempts(ctx, "grep_2")
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+}
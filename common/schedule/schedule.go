@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MinInterval is the shortest time allowed between two runs of a schedule
+const MinInterval = time.Minute
+
+// ErrInvalidSchedule is wrapped by every error returned by Parse
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Parse parses a five field cron expression or a descriptor such as @daily
+// or @every 1h. Schedules are evaluated in UTC unless the expression starts
+// with CRON_TZ=<zone>.
+func Parse(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("%w: schedule must not be empty", ErrInvalidSchedule)
+	}
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=UTC " + spec
+	}
+
+	sched, err := parser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	// Cron fields have a resolution of one minute, only @every can go below
+	if every, ok := sched.(cron.ConstantDelaySchedule); ok && every.Delay < MinInterval {
+		return nil, fmt.Errorf("%w: runs must be at least %s apart", ErrInvalidSchedule, MinInterval)
+	}
+
+	return sched, nil
+}
+
+// Next returns the first run of the schedule after t
+func Next(spec string, t time.Time) (time.Time, error) {
+	sched, err := Parse(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(t).UTC(), nil
+}
@@ -0,0 +1,41 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	now := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 8 * * *", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 1, 7, 45, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=Europe/Berlin 0 8 * * *", time.Date(2024, 3, 2, 7, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			next, err := Next(tt.spec, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "0 8 * *", "61 * * * *", "@every 30s", "@sometimes", "CRON_TZ=Nowhere/Land 0 8 * * *"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := Parse(spec)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
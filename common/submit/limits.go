@@ -0,0 +1,161 @@
+package submit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// ErrQuotaExceeded is wrapped by every LimitError
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Codes of the limit a job submission ran into
+const (
+	LimitJobRate     = "RATE_LIMITED"
+	LimitRunningJobs = "TOO_MANY_RUNNING_JOBS"
+	LimitScanBytes   = "SCAN_QUOTA_EXCEEDED"
+)
+
+// runningJobsRetryAfter is suggested when waiting for running jobs, there is
+// no telling when they finish
+const runningJobsRetryAfter = 30 * time.Second
+
+// LimitError reports which limit refused a job and when to try again
+type LimitError struct {
+	Code       string
+	RetryAfter time.Duration
+	msg        string
+}
+
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+type limitsKey struct{}
+
+type keyIDKey struct{}
+
+// WithKey returns a copy of ctx whose jobs count against the limits of the
+// API key keyID rather than those shared by every caller of the tenant
+func WithKey(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, keyIDKey{}, keyID)
+}
+
+// KeyFromContext returns the ID of the API key ctx was authenticated with,
+// empty if none
+func KeyFromContext(ctx context.Context) string {
+	keyID, _ := ctx.Value(keyIDKey{}).(string)
+	return keyID
+}
+
+// usageName returns the name usage of jobs submitted with ctx is counted
+// under, within the namespace of its tenant
+func usageName(ctx context.Context) string {
+	if keyID := KeyFromContext(ctx); keyID != "" {
+		return "key:" + keyID
+	}
+	return "tenant"
+}
+
+// WithLimits returns a copy of ctx carrying the limits of the caller, they
+// take precedence over the default limits of the Submitter
+func WithLimits(ctx context.Context, limits models.Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// LimitsFor returns the limits jobs submitted with ctx are held to
+func (s *Submitter) LimitsFor(ctx context.Context) models.Limits {
+	if limits, ok := ctx.Value(limitsKey{}).(models.Limits); ok {
+		return limits
+	}
+	return s.limits
+}
+
+// admit checks the job jobID about to be created against the limits of the
+// caller and records its usage, which is counted per API key. The returned
+// function gives the usage back, for jobs that fail to be created after all.
+func (s *Submitter) admit(ctx context.Context, jobID string, files []models.LogFile) (func(), error) {
+	limits := s.LimitsFor(ctx)
+	usage := usageName(ctx)
+	now := s.now().UTC()
+
+	var refunds []func()
+	refund := func() {
+		for _, fn := range refunds {
+			fn()
+		}
+	}
+
+	consume := func(name string, amount, limit int64, window time.Duration, code, msg string, retryAfter time.Duration) error {
+		name = usage + ":" + name
+		ok, err := s.store.ConsumeQuota(ctx, name, amount, limit, window)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &LimitError{Code: code, RetryAfter: retryAfter, msg: msg}
+		}
+
+		refunds = append(refunds, func() {
+			if err := s.store.RefundQuota(context.WithoutCancel(ctx), name, amount); err != nil {
+				log.Warn("failed to refund quota", "quota", name, "err", err)
+			}
+		})
+		return nil
+	}
+
+	// Every job takes a slot, so that it counts once a limit applies
+	ok, err := s.store.AcquireJobSlot(ctx, usage, jobID, int64(limits.MaxRunningJobs))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &LimitError{
+			Code:       LimitRunningJobs,
+			RetryAfter: runningJobsRetryAfter,
+			msg:        fmt.Sprintf("%d jobs are already running, which is the limit", limits.MaxRunningJobs),
+		}
+	}
+	refunds = append(refunds, func() {
+		if err := s.store.ReleaseJobSlot(context.WithoutCancel(ctx), usage, jobID); err != nil {
+			log.Warn("failed to release job slot", "job_id", jobID, "err", err)
+		}
+	})
+
+	if limits.JobsPerMinute > 0 {
+		minute := now.Truncate(time.Minute)
+		err := consume("jobs:"+strconv.FormatInt(minute.Unix(), 10), 1, int64(limits.JobsPerMinute), 2*time.Minute,
+			LimitJobRate, fmt.Sprintf("at most %d jobs may be submitted per minute", limits.JobsPerMinute),
+			minute.Add(time.Minute).Sub(now))
+		if err != nil {
+			refund()
+			return nil, err
+		}
+	}
+
+	if limits.BytesPerDay > 0 {
+		var size int64
+		for _, file := range files {
+			size += file.Size
+		}
+
+		day := now.Truncate(24 * time.Hour)
+		err := consume("bytes:"+day.Format("20060102"), size, limits.BytesPerDay, 48*time.Hour,
+			LimitScanBytes, fmt.Sprintf("scanning %d bytes would exceed the daily quota of %d bytes", size, limits.BytesPerDay),
+			day.Add(24*time.Hour).Sub(now))
+		if err != nil {
+			refund()
+			return nil, err
+		}
+	}
+
+	return refund, nil
+}
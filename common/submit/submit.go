@@ -0,0 +1,241 @@
+// Package submit admits and creates grep jobs, for the API gateway and the
+// scheduled runs of saved searches alike
+package submit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/glob"
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Errors a job is refused with, besides LimitError
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrFileNotFound   = errors.New("file not found")
+)
+
+// Store keeps jobs and the usage of limits, implemented by redis.Store
+type Store interface {
+	SaveJob(ctx context.Context, job *models.Job) error
+	UpdateJob(ctx context.Context, jobID string, fn func(job *models.Job) error) (*models.Job, error)
+	SetStats(ctx context.Context, jobID string, stats models.JobStats) error
+	SaveWebhook(ctx context.Context, jobID string, hook *models.Webhook) error
+	ConsumeQuota(ctx context.Context, name string, amount, limit int64, ttl time.Duration) (bool, error)
+	RefundQuota(ctx context.Context, name string, amount int64) error
+	AcquireJobSlot(ctx context.Context, name, jobID string, limit int64) (bool, error)
+	ReleaseJobSlot(ctx context.Context, name, jobID string) error
+}
+
+// FileLister lists the log files of the tenant ctx is scoped to, implemented
+// by minio.Storage
+type FileLister interface {
+	ListLogFiles(ctx context.Context) ([]models.LogFile, error)
+}
+
+// Publisher sends messages to the other services, implemented by nats.Bus
+type Publisher interface {
+	Publish(ctx context.Context, subject string, msg any) error
+}
+
+// Submitter validates jobs, holds them to the limits of their caller and
+// hands them over to the manager
+type Submitter struct {
+	store    Store
+	files    FileLister
+	bus      Publisher
+	limits   models.Limits
+	finished func(ctx context.Context, job *models.Job)
+	now      func() time.Time
+}
+
+// New returns a new Submitter without default limits
+func New(store Store, files FileLister, bus Publisher, log *logger.Logger) *Submitter {
+	InitLogger(log)
+
+	s := &Submitter{
+		store: store,
+		files: files,
+		bus:   bus,
+		now:   time.Now,
+	}
+	s.finished = s.announceFinished
+	return s
+}
+
+// SetDefaultLimits sets the limits of callers without limits of their own
+func (s *Submitter) SetDefaultLimits(limits models.Limits) {
+	s.limits = limits
+}
+
+// SetClock changes the clock the windows of the limits are taken from
+func (s *Submitter) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetJobFinished replaces what is done for jobs that failed to be handed
+// over, which by default is announcing them on nats.SubjectJobFinished
+func (s *Submitter) SetJobFinished(fn func(ctx context.Context, job *models.Job)) {
+	s.finished = fn
+}
+
+// ResolveFiles expands the given file names and glob patterns against the
+// files in log storage. Every pattern has to match at least one file.
+func (s *Submitter) ResolveFiles(ctx context.Context, patterns []string) ([]models.LogFile, error) {
+	available, err := s.files.ListLogFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log files: %w", err)
+	}
+
+	resolved, unmatched := glob.Expand(patterns, available)
+	if unmatched != "" {
+		return nil, fmt.Errorf("%w: no log file matches %q", ErrFileNotFound, unmatched)
+	}
+
+	return resolved, nil
+}
+
+// Create validates the requested job, resolves its files, admits it against
+// the limits of the caller, persists it and hands it over to the manager.
+// Pattern or Query, TimeRange, Files, search options, Priority, Retention,
+// RequestID, SearchID and Webhook are taken from job, everything else is
+// filled in. The job belongs to the tenant ctx is scoped to.
+func (s *Submitter) Create(ctx context.Context, job models.Job) (*models.Job, error) {
+	if err := Validate(&job); err != nil {
+		return nil, err
+	}
+
+	files, err := s.ResolveFiles(ctx, job.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = ids.New("grep")
+	refund, err := s.admit(ctx, job.ID, files)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Tenant = tenant.FromContext(ctx)
+	if job.RequestID == "" {
+		job.RequestID = ids.New("req")
+	}
+	job.Files = make([]string, 0, len(files))
+	for _, file := range files {
+		job.Files = append(job.Files, file.Path)
+	}
+	job.Status = models.JobStatusPending
+	job.CreatedAt = time.Now().UTC()
+	job.StartedAt = nil
+	job.CompletedAt = nil
+	job.Error = ""
+	job.Progress = 0
+	job.IsCancelled = false
+	job.ExpiresAt = nil
+
+	if err := s.store.SaveJob(ctx, &job); err != nil {
+		refund()
+		return nil, err
+	}
+	metrics.ObserveJob(job.Status)
+
+	if err := s.store.SetStats(ctx, job.ID, models.JobStats{TotalFiles: len(job.Files)}); err != nil {
+		refund()
+		s.fail(ctx, job.ID, "failed to initialize job stats")
+		return nil, err
+	}
+
+	if job.Webhook != nil {
+		hook := *job.Webhook
+		hook.State = models.WebhookStateWaiting
+		if err := s.store.SaveWebhook(ctx, job.ID, &hook); err != nil {
+			refund()
+			s.fail(ctx, job.ID, "failed to register webhook")
+			return nil, err
+		}
+	}
+
+	if err := s.bus.Publish(ctx, nats.SubjectJobs, models.JobMessage{Job: job}); err != nil {
+		log.Error("failed to hand job over to manager", "job_id", job.ID, "err", err)
+		refund()
+		s.fail(ctx, job.ID, "failed to schedule job")
+		return nil, fmt.Errorf("failed to schedule job %s: %w", job.ID, err)
+	}
+
+	log.Info("job created", "job_id", job.ID, "request_id", job.RequestID, "files", len(job.Files))
+	return &job, nil
+}
+
+// Reject persists job as failed with the reason it was refused, for callers
+// that keep a record of every attempt such as the runs of saved searches
+func (s *Submitter) Reject(ctx context.Context, job models.Job, reason error) (*models.Job, error) {
+	now := s.now().UTC()
+	job.ID = ids.New("grep")
+	job.Tenant = tenant.FromContext(ctx)
+	if job.RequestID == "" {
+		job.RequestID = ids.New("req")
+	}
+	job.Status = models.JobStatusFailed
+	job.Error = reason.Error()
+	job.CreatedAt = now
+	job.CompletedAt = &now
+	job.Webhook = nil
+
+	if err := s.store.SaveJob(ctx, &job); err != nil {
+		return nil, err
+	}
+	metrics.ObserveJob(job.Status)
+	if err := s.store.SetStats(ctx, job.ID, models.JobStats{}); err != nil {
+		return nil, err
+	}
+
+	log.Info("job rejected", "job_id", job.ID, "request_id", job.RequestID, "reason", reason)
+	return &job, nil
+}
+
+// fail marks a job that could not be handed over as failed, errors are only
+// logged since this is already called on an error path
+func (s *Submitter) fail(ctx context.Context, jobID, reason string) {
+	job, err := s.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		now := time.Now().UTC()
+		job.Status = models.JobStatusFailed
+		job.Error = reason
+		job.CompletedAt = &now
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to mark job as failed", "job_id", jobID, "err", err)
+		return
+	}
+	s.finished(ctx, job)
+}
+
+// announceFinished records the final status of a job and tells everyone else
+// about it
+func (s *Submitter) announceFinished(ctx context.Context, job *models.Job) {
+	metrics.ObserveJob(job.Status)
+
+	msg := models.JobFinishedMessage{JobID: job.ID, Tenant: tenant.FromContext(ctx), Status: job.Status}
+	if err := s.bus.Publish(ctx, nats.SubjectJobFinished, msg); err != nil {
+		log.Warn("failed to announce finished job", "job_id", job.ID, "err", err)
+	}
+}
@@ -0,0 +1,183 @@
+package submit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+type fakeFiles []models.LogFile
+
+func (f fakeFiles) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
+	return f, nil
+}
+
+// failingFiles is a log storage that cannot be listed
+type failingFiles struct{}
+
+func (failingFiles) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
+	return nil, errors.New("storage down")
+}
+
+type fakeBus struct {
+	mu       sync.Mutex
+	fail     bool
+	subjects []string
+}
+
+func (b *fakeBus) Publish(ctx context.Context, subject string, msg any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.fail && subject == nats.SubjectJobs {
+		return errors.New("bus down")
+	}
+	b.subjects = append(b.subjects, subject)
+	return nil
+}
+
+func newTestSubmitter(t *testing.T) (*Submitter, *redis.Store, *fakeBus) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	bus := &fakeBus{}
+	files := fakeFiles{{Path: "logs/app.log", Size: 10}, {Path: "logs/kube.log", Size: 10}}
+	return New(store, files, bus, l), store, bus
+}
+
+func TestCreate(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "team-a")
+
+	t.Run("Created", func(t *testing.T) {
+		s, store, bus := newTestSubmitter(t)
+
+		job, err := s.Create(ctx, models.Job{Pattern: "error", Files: []string{"logs/*.log"}, SearchID: "search_1"})
+		require.NoError(t, err)
+		assert.Equal(t, "team-a", job.Tenant)
+		assert.Equal(t, []string{"logs/app.log", "logs/kube.log"}, job.Files)
+		assert.Equal(t, "search_1", job.SearchID)
+		assert.NotEmpty(t, job.RequestID)
+		assert.Equal(t, []string{nats.SubjectJobs}, bus.subjects)
+
+		stored, err := store.GetJob(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusPending, stored.Status)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		tests := []struct {
+			name string
+			job  models.Job
+			err  error
+		}{
+			{"NoPattern", models.Job{Files: []string{"logs/app.log"}}, ErrInvalidRequest},
+			{"InvalidRegex", models.Job{Pattern: "(x", Regex: true, Files: []string{"logs/app.log"}}, ErrInvalidRequest},
+			{"UnknownFile", models.Job{Pattern: "x", Files: []string{"nope.log"}}, ErrFileNotFound},
+		}
+
+		s, _, bus := newTestSubmitter(t)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := s.Create(ctx, tt.job)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+		assert.Empty(t, bus.subjects)
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		s, _, _ := newTestSubmitter(t)
+		s.SetDefaultLimits(models.Limits{BytesPerDay: 30})
+		s.SetClock(func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) })
+		req := models.Job{Pattern: "x", Files: []string{"logs/*.log"}}
+
+		_, err := s.Create(ctx, req)
+		require.NoError(t, err)
+
+		_, err = s.Create(ctx, req)
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitScanBytes, limitErr.Code)
+
+		// Limits carried by ctx take precedence over the defaults
+		_, err = s.Create(WithLimits(ctx, models.Limits{}), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ListingFails", func(t *testing.T) {
+		s, _, bus := newTestSubmitter(t)
+		s.SetDefaultLimits(models.Limits{BytesPerDay: 20})
+		s.SetClock(func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) })
+		req := models.Job{Pattern: "x", Files: []string{"logs/*.log"}}
+
+		// Files that cannot be listed are not admitted as a smaller job
+		files := s.files
+		s.files = failingFiles{}
+		_, err := s.Create(ctx, req)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrFileNotFound)
+		assert.Empty(t, bus.subjects)
+
+		// Nothing of the daily quota was taken
+		s.files = files
+		_, err = s.Create(ctx, req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("NotHandedOver", func(t *testing.T) {
+		s, store, bus := newTestSubmitter(t)
+		bus.fail = true
+		s.SetDefaultLimits(models.Limits{JobsPerMinute: 1})
+
+		var finished []string
+		s.SetJobFinished(func(ctx context.Context, job *models.Job) {
+			finished = append(finished, job.ID)
+		})
+
+		_, err := s.Create(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+		require.Error(t, err)
+		require.Len(t, finished, 1)
+
+		job, err := store.GetJob(ctx, finished[0])
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusFailed, job.Status)
+
+		// The usage of the failed job was given back
+		bus.fail = false
+		_, err = s.Create(ctx, models.Job{Pattern: "x", Files: []string{"logs/app.log"}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestReject(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "team-a")
+	s, store, bus := newTestSubmitter(t)
+
+	job, err := s.Reject(ctx, models.Job{Pattern: "x", Files: []string{"gone/*.log"}, SearchID: "search_1"}, ErrFileNotFound)
+	require.NoError(t, err)
+	assert.Empty(t, bus.subjects)
+
+	stored, err := store.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, stored.Status)
+	assert.Equal(t, ErrFileNotFound.Error(), stored.Error)
+	assert.Equal(t, "search_1", stored.SearchID)
+	assert.NotNil(t, stored.CompletedAt)
+}
@@ -0,0 +1,140 @@
+package submit
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+// Limits on a grep request, kept in sync with the OpenAPI spec
+const (
+	MaxFiles               = 100
+	MaxContextLines        = 10
+	MinRetention           = time.Minute
+	MaxRetention           = 365 * 24 * time.Hour
+	MaxWebhookURLLength    = 2048
+	MaxWebhookSecretLength = 256
+)
+
+// Validate checks a requested job, errors wrap ErrInvalidRequest
+func Validate(job *models.Job) error {
+	sources := 0
+	for _, set := range []bool{job.Pattern != "", job.Patterns != nil, job.Query != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("%w: pattern, patterns and query are mutually exclusive", ErrInvalidRequest)
+	}
+	if job.Patterns == nil && job.Query == nil && job.Pattern == "" {
+		return fmt.Errorf("%w: pattern, patterns or query is required", ErrInvalidRequest)
+	}
+	if job.Patterns != nil && job.Regex {
+		return fmt.Errorf("%w: patterns are fixed strings, regex must be false", ErrInvalidRequest)
+	}
+
+	switch job.Mode {
+	case "", models.SearchModeLines:
+	case models.SearchModeCount, models.SearchModeFilesWithMatches, models.SearchModeFilesWithoutMatch:
+		if job.ContextLines > 0 || job.OnlyMatching {
+			return fmt.Errorf("%w: context_lines and only_matching only apply to mode lines", ErrInvalidRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q, expected lines, count, files_with_matches or files_without_match", ErrInvalidRequest, job.Mode)
+	}
+
+	switch job.Priority {
+	case "", models.PriorityInteractive, models.PriorityNormal, models.PriorityBatch:
+	default:
+		return fmt.Errorf("%w: unknown priority %q, expected interactive, normal or batch", ErrInvalidRequest, job.Priority)
+	}
+
+	if job.Retention != 0 && (job.Retention < MinRetention || job.Retention > MaxRetention) {
+		return fmt.Errorf("%w: retention must be between %s and %s", ErrInvalidRequest, MinRetention, MaxRetention)
+	}
+
+	if job.MaxCount < 0 {
+		return fmt.Errorf("%w: max_count must not be negative", ErrInvalidRequest)
+	}
+	if job.OnlyMatching && job.ContextLines > 0 {
+		return fmt.Errorf("%w: context_lines cannot be combined with only_matching", ErrInvalidRequest)
+	}
+
+	if _, err := newGrep(job); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	if job.TimeRange != nil {
+		if err := timestamp.Validate(job.TimeRange); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+	}
+
+	if len(job.Files) == 0 || len(job.Files) > MaxFiles {
+		return fmt.Errorf("%w: between 1 and %d files are required", ErrInvalidRequest, MaxFiles)
+	}
+
+	for _, file := range job.Files {
+		if strings.TrimSpace(file) == "" {
+			return fmt.Errorf("%w: file names must not be empty", ErrInvalidRequest)
+		}
+	}
+
+	if job.ContextLines < 0 || job.ContextLines > MaxContextLines {
+		return fmt.Errorf("%w: context_lines must be between 0 and %d", ErrInvalidRequest, MaxContextLines)
+	}
+
+	if job.Webhook != nil {
+		if err := ValidateWebhook(job.Webhook); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateWebhook checks the webhook of a requested job, errors wrap
+// ErrInvalidRequest
+func ValidateWebhook(hook *models.Webhook) error {
+	if len(hook.URL) > MaxWebhookURLLength {
+		return fmt.Errorf("%w: webhook url must be at most %d characters", ErrInvalidRequest, MaxWebhookURLLength)
+	}
+
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook url must be an absolute http or https url", ErrInvalidRequest)
+	}
+
+	if hook.Secret == "" || len(hook.Secret) > MaxWebhookSecretLength {
+		return fmt.Errorf("%w: webhook secret must be between 1 and %d characters", ErrInvalidRequest, MaxWebhookSecretLength)
+	}
+
+	return nil
+}
+
+// grepOptions returns the options the mappers search the files of job with
+func grepOptions(job *models.Job) pattern.Options {
+	return pattern.Options{
+		Regex:         job.Regex,
+		CaseSensitive: job.CaseSensitive,
+		InvertMatch:   job.InvertMatch,
+		WordRegexp:    job.WordRegexp,
+		LineRegexp:    job.LineRegexp,
+		OnlyMatching:  job.OnlyMatching,
+		MaxCount:      job.MaxCount,
+	}
+}
+
+// newGrep compiles what the mappers search the files of job for
+func newGrep(job *models.Job) (*pattern.Grep, error) {
+	if job.Patterns != nil {
+		return pattern.NewLiteralGrep(job.Patterns, grepOptions(job))
+	}
+	return pattern.NewGrep(job.Pattern, job.Query, grepOptions(job))
+}
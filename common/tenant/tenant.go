@@ -0,0 +1,39 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Tenants are isolated from each other by namespacing their Redis keys and
+// object names. The empty tenant is the global namespace used when the
+// deployment runs without authentication.
+
+type contextKey struct{}
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Valid reports whether id can be used as a tenant ID, IDs are lowercase
+// alphanumerics, '-' and '_', up to 63 characters
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// NewContext returns a copy of ctx scoped to the tenant
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to, empty for the global namespace
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// ObjectPrefix is prepended to object names of the tenant in every bucket
+func ObjectPrefix(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "tenants/" + id + "/"
+}
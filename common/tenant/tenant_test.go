@@ -0,0 +1,40 @@
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"team-a", true},
+		{"payments_eu1", true},
+		{"0ps", true},
+		{"", false},
+		{"-team", false},
+		{"Team", false},
+		{"team/a", false},
+		{"team:a", false},
+		{strings.Repeat("a", 63), true},
+		{strings.Repeat("a", 64), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Valid(tt.id), tt.id)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, FromContext(ctx))
+	assert.Equal(t, "team-a", FromContext(NewContext(ctx, "team-a")))
+
+	assert.Empty(t, ObjectPrefix(""))
+	assert.Equal(t, "tenants/team-a/", ObjectPrefix("team-a"))
+}
@@ -0,0 +1,75 @@
+package timestamp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// Filter tracks the time of consecutive lines of one file and tells which of
+// them fall within a range. Lines without a timestamp, such as the rest of a
+// stack trace, inherit the one of the line before.
+type Filter struct {
+	parser *Parser
+	since  *time.Time
+	until  *time.Time
+
+	last  time.Time
+	known bool
+}
+
+// Validate checks that the bounds of r are in order and its formats exist
+func Validate(r *models.TimeRange) error {
+	_, err := NewFilter(r, time.Time{})
+	return err
+}
+
+// NewFilter returns a Filter for r, reference is passed on to NewParser
+func NewFilter(r *models.TimeRange, reference time.Time) (*Filter, error) {
+	if r.Since != nil && r.Until != nil && !r.Since.Before(*r.Until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	parser, err := NewParser(r.Formats, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filter{parser: parser, since: r.Since, until: r.Until}, nil
+}
+
+// Line returns the time line was logged at, or inherited, and whether it
+// falls within the range. Lines before the first timestamp of a file have no
+// time, they are only kept when the range is open on both ends.
+func (f *Filter) Line(line string) (t time.Time, ok bool, in bool) {
+	if parsed, found := f.parser.Parse(line); found {
+		f.last, f.known = parsed, true
+	}
+
+	if !f.known {
+		return time.Time{}, false, f.since == nil && f.until == nil
+	}
+	return f.last, true, f.contains(f.last)
+}
+
+// Inherit sets the time of the line before the next one, for a part of a
+// file that starts after lines read elsewhere
+func (f *Filter) Inherit(t time.Time) {
+	f.last, f.known = t, true
+}
+
+// Reset forgets the time of the previous line, call it between files
+func (f *Filter) Reset() {
+	f.last, f.known = time.Time{}, false
+}
+
+func (f *Filter) contains(t time.Time) bool {
+	if f.since != nil && t.Before(*f.since) {
+		return false
+	}
+	if f.until != nil && !t.Before(*f.until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestFilter(t *testing.T) {
+	since := time.Date(2024, 1, 2, 2, 10, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 2, 2, 40, 0, 0, time.UTC)
+	f, err := NewFilter(&models.TimeRange{Since: &since, Until: &until}, until)
+	require.NoError(t, err)
+
+	tests := []struct {
+		line  string
+		known bool
+		in    bool
+	}{
+		{"orphan line before any timestamp", false, false},
+		{"2024-01-02T02:09:59Z ERROR too early", true, false},
+		{"2024-01-02T02:10:00Z ERROR first", true, true},
+		{"java.lang.IllegalStateException: boom", true, true},
+		{"    at com.example.Main.run(Main.java:42)", true, true},
+		{"2024-01-02T02:40:00Z ERROR until is excluded", true, false},
+		{"    at com.example.Main.run(Main.java:42)", true, false},
+	}
+
+	for _, tt := range tests {
+		_, known, in := f.Line(tt.line)
+		assert.Equal(t, tt.known, known, tt.line)
+		assert.Equal(t, tt.in, in, tt.line)
+	}
+
+	f.Reset()
+	_, known, in := f.Line("    at com.example.Main.run(Main.java:42)")
+	assert.False(t, known, "time is not carried over to the next file")
+	assert.False(t, in)
+
+	// A chunk takes the time of the lines before it
+	f.Inherit(since.Add(time.Minute))
+	logged, known, in := f.Line("    at com.example.Main.run(Main.java:42)")
+	assert.True(t, known)
+	assert.True(t, in)
+	assert.Equal(t, since.Add(time.Minute), logged)
+}
+
+func TestFilterOpenRange(t *testing.T) {
+	f, err := NewFilter(&models.TimeRange{Formats: []models.TimestampFormat{models.TimestampSyslog}}, time.Now())
+	require.NoError(t, err)
+
+	_, known, in := f.Line("no timestamp yet")
+	assert.False(t, known)
+	assert.True(t, in, "nothing is filtered without bounds")
+
+	ts, known, in := f.Line("Jan  2 15:04:05 host app: ok")
+	assert.True(t, known)
+	assert.True(t, in)
+	assert.Equal(t, 15, ts.Hour())
+}
+
+func TestValidate(t *testing.T) {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+
+	assert.NoError(t, Validate(&models.TimeRange{Since: &since}))
+	assert.NoError(t, Validate(&models.TimeRange{Since: &since, Until: &until}))
+	assert.ErrorContains(t, Validate(&models.TimeRange{Since: &until, Until: &since}), "since must be before until")
+	assert.ErrorContains(t, Validate(&models.TimeRange{Since: &since, Until: &since}), "since must be before until")
+	assert.ErrorContains(t, Validate(&models.TimeRange{Formats: []models.TimestampFormat{"iso"}}), "unknown timestamp format")
+}
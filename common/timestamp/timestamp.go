@@ -0,0 +1,152 @@
+// Package timestamp finds when log lines were logged and filters them by a
+// models.TimeRange
+package timestamp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+// DefaultFormats are tried when a range does not name any
+var DefaultFormats = []models.TimestampFormat{
+	models.TimestampRFC3339,
+	models.TimestampSyslog,
+	models.TimestampNginx,
+	models.TimestampEpochMillis,
+}
+
+var (
+	rfc3339Re     = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d{1,9})?(Z|[+-]\d{2}:?\d{2})?`)
+	syslogRe      = regexp.MustCompile(`^(?:<\d{1,3}>)?([A-Z][a-z]{2} [ 0-9]\d \d{2}:\d{2}:\d{2})`)
+	nginxRe       = regexp.MustCompile(`\[(\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4})\]`)
+	epochMillisRe = regexp.MustCompile(`(?:^|\D)(\d{13})(?:\D|$)`)
+)
+
+// parseFunc returns the timestamp found on a line, if any
+type parseFunc func(line string) (time.Time, bool)
+
+// Parser finds the timestamp of a log line in one of several formats
+type Parser struct {
+	parsers []parseFunc
+}
+
+// NewParser returns a Parser trying formats in order, DefaultFormats if none
+// are given. Syslog timestamps carry no year, they are placed in the year
+// before reference if they would otherwise be more than a day after it.
+func NewParser(formats []models.TimestampFormat, reference time.Time) (*Parser, error) {
+	if len(formats) == 0 {
+		formats = DefaultFormats
+	}
+
+	p := &Parser{parsers: make([]parseFunc, 0, len(formats))}
+	for _, format := range formats {
+		switch format {
+		case models.TimestampRFC3339:
+			p.parsers = append(p.parsers, parseRFC3339)
+		case models.TimestampSyslog:
+			p.parsers = append(p.parsers, syslogParser(reference.UTC()))
+		case models.TimestampNginx:
+			p.parsers = append(p.parsers, parseNginx)
+		case models.TimestampEpochMillis:
+			p.parsers = append(p.parsers, parseEpochMillis)
+		default:
+			return nil, fmt.Errorf("unknown timestamp format %q, expected one of rfc3339, syslog, nginx or epoch_millis", format)
+		}
+	}
+
+	return p, nil
+}
+
+// Parse returns the timestamp of line in UTC, found by the first format that
+// matches it
+func (p *Parser) Parse(line string) (time.Time, bool) {
+	for _, parse := range p.parsers {
+		if t, ok := parse(line); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseRFC3339(line string) (time.Time, bool) {
+	if !strings.Contains(line, "-") {
+		return time.Time{}, false
+	}
+
+	m := rfc3339Re.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	// Normalize the variants seen in logs to strict RFC 3339
+	value, zone := m[0][:len(m[0])-len(m[1])], m[1]
+	value = strings.Replace(value, " ", "T", 1)
+	value = strings.Replace(value, ",", ".", 1)
+	switch {
+	case zone == "":
+		zone = "Z"
+	case len(zone) == 5:
+		zone = zone[:3] + ":" + zone[3:]
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value+zone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+func syslogParser(reference time.Time) parseFunc {
+	return func(line string) (time.Time, bool) {
+		m := syslogRe.FindStringSubmatch(line)
+		if m == nil {
+			return time.Time{}, false
+		}
+
+		t, err := time.Parse(time.Stamp, m[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		t = time.Date(reference.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		if t.After(reference.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, true
+	}
+}
+
+func parseNginx(line string) (time.Time, bool) {
+	if !strings.Contains(line, "[") {
+		return time.Time{}, false
+	}
+
+	m := nginxRe.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+func parseEpochMillis(line string) (time.Time, bool) {
+	m := epochMillisRe.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	millis, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis).UTC(), true
+}
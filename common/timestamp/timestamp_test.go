@@ -0,0 +1,63 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+)
+
+func TestParse(t *testing.T) {
+	reference := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	p, err := NewParser(nil, reference)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+	}{
+		{"RFC3339", `2024-01-02T15:04:05Z INFO started`, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"RFC3339Offset", `level=info ts=2024-01-02T17:04:05.250+02:00 msg=ok`, time.Date(2024, 1, 2, 15, 4, 5, 250e6, time.UTC)},
+		{"RFC3339Space", `2024-01-02 15:04:05,5 ERROR boom`, time.Date(2024, 1, 2, 15, 4, 5, 500e6, time.UTC)},
+		{"RFC3339CompactOffset", `2024-01-02T10:04:05-0500 WARN slow`, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Syslog", `Jan  2 15:04:05 host sshd[42]: accepted`, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"SyslogLastYear", `<34>Dec 31 23:59:59 host cron: run`, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{"Nginx", `10.0.0.1 - - [02/Jan/2024:16:04:05 +0100] "GET / HTTP/1.1" 200`, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"EpochMillis", `{"ts":1704207845000,"msg":"ok"}`, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.Parse(tt.line)
+			require.True(t, ok)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+			assert.Equal(t, time.UTC, got.Location())
+		})
+	}
+
+	for _, line := range []string{"", "    at com.example.Main.run(Main.java:42)", "id=12345678901234 too long", "2024-13-45T99:99:99Z"} {
+		_, ok := p.Parse(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestParseFormatOrder(t *testing.T) {
+	line := `1704207845000 2023-06-01T00:00:00Z`
+
+	p, err := NewParser([]models.TimestampFormat{models.TimestampEpochMillis, models.TimestampRFC3339}, time.Now())
+	require.NoError(t, err)
+	got, ok := p.Parse(line)
+	require.True(t, ok)
+	assert.Equal(t, 2024, got.Year())
+
+	p, err = NewParser([]models.TimestampFormat{models.TimestampNginx}, time.Now())
+	require.NoError(t, err)
+	_, ok = p.Parse(line)
+	assert.False(t, ok, "formats not asked for are not tried")
+
+	_, err = NewParser([]models.TimestampFormat{"apache"}, time.Now())
+	assert.ErrorContains(t, err, `unknown timestamp format "apache"`)
+}
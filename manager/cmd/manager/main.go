@@ -1 +1,200 @@
 package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/config"
+	"github.com/swarit-pandey/distributed-grep/common/health"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/minio"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/manager/dispatcher"
+	"github.com/swarit-pandey/distributed-grep/manager/janitor"
+	"github.com/swarit-pandey/distributed-grep/manager/scheduler"
+)
+
+// Config holds everything the manager needs to run
+type Config struct {
+	// Addr is where /metrics, /healthz and /readyz are served
+	Addr string `mapstructure:"addr"`
+
+	MinIO   minio.MinOptions     `mapstructure:"minio"`
+	Storage minio.StorageOptions `mapstructure:"storage"`
+	Redis   redis.RedisOptions   `mapstructure:"redis"`
+	NATS    nats.NatsOptions     `mapstructure:"nats"`
+
+	// ScheduleInterval is how often saved searches are checked for due runs
+	ScheduleInterval time.Duration `mapstructure:"scheduleInterval"`
+
+	// Limits hold scheduled runs of searches saved without limits of their own
+	Limits models.Limits `mapstructure:"limits"`
+
+	// Dispatch tunes how the chunks of jobs are handed to the mappers
+	Dispatch dispatcher.Options `mapstructure:"dispatch"`
+
+	// Retention tunes how long the data of finished jobs is kept
+	Retention janitor.Options `mapstructure:"retention"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Addr: ":8081",
+		MinIO: minio.MinOptions{
+			Endpoint: "localhost:9000",
+		},
+		Storage: minio.StorageOptions{
+			Buckets: []minio.BucketOptions{
+				{Name: "logs", Type: minio.TextType, Category: minio.LogStorage},
+				{Name: "chunks", Type: minio.TextType, Category: minio.ChunkStorage},
+				{Name: "results", Type: minio.JSONType, Category: minio.ResultStorage},
+			},
+		},
+		Redis: redis.RedisOptions{
+			Addr: "localhost:6379",
+		},
+		NATS: nats.NatsOptions{
+			URL:  "nats://localhost:4222",
+			Name: "manager",
+		},
+		ScheduleInterval: scheduler.DefaultInterval,
+		Dispatch: dispatcher.Options{
+			MaxInFlight:  dispatcher.DefaultMaxInFlight,
+			ChunkSize:    models.DefaultChunkSize,
+			ChunkTimeout: dispatcher.DefaultChunkTimeout,
+			MaxAttempts:  dispatcher.DefaultMaxAttempts,
+		},
+		Retention: janitor.Options{
+			Retention:    janitor.DefaultRetention,
+			Interval:     janitor.DefaultInterval,
+			TombstoneTTL: janitor.DefaultTombstoneTTL,
+		},
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML config file")
+	flag.Parse()
+
+	log := logger.New()
+
+	cfg := defaultConfig()
+	if err := config.Load(*configPath, "MANAGER", &cfg); err != nil {
+		log.Error("failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, log); err != nil {
+		log.Error("manager exited with error", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg Config, log *logger.Logger) error {
+	storage, err := minio.New(cfg.MinIO.Endpoint, cfg.MinIO.AccessKeyID, cfg.MinIO.SecretAccessKey, cfg.MinIO.SSL, log, &cfg.Storage)
+	if err != nil {
+		return err
+	}
+	if err := storage.Instantiate(ctx); err != nil {
+		return err
+	}
+
+	store := redis.New(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, log)
+	if err := store.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer store.Close()
+
+	bus := nats.New(cfg.NATS.URL, cfg.NATS.Name, log)
+	if err := bus.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	// Every job is split by one of the managers, its chunks are dispatched by
+	// all of them from the queue in Redis
+	disp := dispatcher.New(store, storage, bus, cfg.Dispatch, log)
+	jobSub, err := bus.QueueSubscribe(nats.SubjectJobs, "manager", disp.HandleJobMessage)
+	if err != nil {
+		return err
+	}
+	defer jobSub.Unsubscribe()
+
+	resultSub, err := bus.Subscribe(nats.SubjectResults, disp.HandleResultMessage)
+	if err != nil {
+		return err
+	}
+	defer resultSub.Unsubscribe()
+
+	cancelSub, err := bus.Subscribe(nats.SubjectJobCancel, disp.HandleCancelMessage)
+	if err != nil {
+		return err
+	}
+	defer cancelSub.Unsubscribe()
+
+	matchedSub, err := bus.QueueSubscribe(nats.SubjectFileMatched, "manager", disp.HandleFileMatchedMessage)
+	if err != nil {
+		return err
+	}
+	defer matchedSub.Unsubscribe()
+
+	go disp.Run(ctx)
+
+	// Every finished job has to be retired once, by any of the managers
+	jan := janitor.New(store, storage, cfg.Retention, log)
+	finishedSub, err := bus.QueueSubscribe(nats.SubjectJobFinished, "manager", jan.HandleJobFinishedMessage)
+	if err != nil {
+		return err
+	}
+	defer finishedSub.Unsubscribe()
+
+	go jan.Run(ctx)
+
+	sched := scheduler.New(store, storage, bus, log)
+	sched.SetInterval(cfg.ScheduleInterval)
+	sched.SetDefaultLimits(cfg.Limits)
+	go sched.Run(ctx)
+
+	checker := health.New()
+	checker.Add("minio", storage)
+	checker.Add("redis", store)
+	checker.Add("nats", bus)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	checker.Register(mux)
+	srv := &http.Server{Addr: cfg.Addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("manager listening", "addr", cfg.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down manager")
+	checker.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
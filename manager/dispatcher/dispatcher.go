@@ -0,0 +1,738 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/ids"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Defaults for the zero fields of Options
+const (
+	DefaultMaxInFlight  = 64
+	DefaultChunkTimeout = 5 * time.Minute
+	DefaultMaxAttempts  = 3
+)
+
+// expireBatch is the most timed out chunks handled per tick
+const expireBatch = 100
+
+// errJobFinished aborts dispatching a job that reached a final status
+var errJobFinished = errors.New("job finished")
+
+// Options tune how chunks are handed to the mappers
+type Options struct {
+	// MaxInFlight is the most chunks handed to mappers by all managers and
+	// not yet reported back, further chunks wait in priority order
+	MaxInFlight int `mapstructure:"maxInFlight"`
+
+	// ChunkSize is the most bytes of a file put in one chunk, chunks end at
+	// a line boundary so a single longer line makes up a chunk of its own
+	ChunkSize int64 `mapstructure:"chunkSize"`
+
+	// ChunkTimeout hands a chunk whose result never arrives to another mapper
+	ChunkTimeout time.Duration `mapstructure:"chunkTimeout"`
+
+	// MaxAttempts is how many times a chunk may time out before its job fails
+	MaxAttempts int `mapstructure:"maxAttempts"`
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = DefaultMaxInFlight
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = models.DefaultChunkSize
+	}
+	if o.ChunkTimeout <= 0 {
+		o.ChunkTimeout = DefaultChunkTimeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	return o
+}
+
+// Store keeps jobs, the dispatch queue and the chunks of queued jobs,
+// implemented by redis.Store
+type Store interface {
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	UpdateJob(ctx context.Context, jobID string, fn func(job *models.Job) error) (*models.Job, error)
+	IncrStats(ctx context.Context, jobID string, delta models.JobStats) error
+	EnqueueJob(ctx context.Context, job *models.Job) error
+	DequeueJob(ctx context.Context, jobID string) error
+	QueuedJobs(ctx context.Context, limit int) ([]redis.QueuedJob, error)
+	SetInteractive(ctx context.Context, jobID string, active bool) error
+	InteractiveJobs(ctx context.Context) ([]redis.QueuedJob, error)
+	HasInteractiveJobs(ctx context.Context) (bool, error)
+	SaveChunks(ctx context.Context, jobID string, chunks []models.Chunk) error
+	HasChunks(ctx context.Context, jobID string) (bool, error)
+	DeleteChunks(ctx context.Context, jobID string) error
+	ClaimChunk(ctx context.Context, jobID string, deadline time.Time, maxInFlight int) (*models.Chunk, bool, error)
+	ReturnChunk(ctx context.Context, jobID, chunkID string) error
+	ReportChunk(ctx context.Context, jobID, chunkID string) (redis.ChunkReport, bool, error)
+	DropPendingChunks(ctx context.Context, jobID, file string) (int, redis.ChunkReport, error)
+	CountChunkMatches(ctx context.Context, jobID, chunkID string, selected int) (string, int, error)
+	DropCappedChunks(ctx context.Context, jobID, file string, max int) (int, redis.ChunkReport, error)
+	RetryChunk(ctx context.Context, jobID, chunkID string, maxAttempts int) (int, error)
+	ExpiredChunks(ctx context.Context, now time.Time, limit int) ([]redis.InFlightChunk, error)
+	SetChunkSize(ctx context.Context, size int64) error
+}
+
+// LogFiles looks up and reads the log files of the tenant ctx is scoped to,
+// implemented by minio.Storage
+type LogFiles interface {
+	StatLogFile(ctx context.Context, path string) (*models.LogFile, error)
+	OpenLogFile(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Publisher sends messages to the other services, implemented by nats.Bus
+type Publisher interface {
+	Publish(ctx context.Context, subject string, msg any) error
+}
+
+// Dispatcher splits the jobs it is handed into chunks and feeds them to the
+// mappers, at most MaxInFlight at a time across all managers. Chunks are
+// dispatched by priority of their job and then by its creation, and batch
+// jobs are paused altogether while an interactive job of any manager is
+// unfinished. The queue, the chunks and those in flight live in Redis, so any
+// manager carries on with the jobs of another one, also after a restart.
+type Dispatcher struct {
+	store Store
+	files LogFiles
+	bus   Publisher
+	opts  Options
+	now   func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*models.Job // Started jobs by <tenant>/<job ID>, a cache of the store
+}
+
+// New returns a new Dispatcher, zero fields of opts take their defaults
+func New(store Store, files LogFiles, bus Publisher, opts Options, log *logger.Logger) *Dispatcher {
+	InitLogger(log)
+
+	return &Dispatcher{
+		store: store,
+		files: files,
+		bus:   bus,
+		opts:  opts.withDefaults(),
+		now:   time.Now,
+		jobs:  make(map[string]*models.Job),
+	}
+}
+
+// Run recovers the queue left behind by managers that stopped, then retries
+// timed out chunks and dispatches what they make room for until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.ChunkTimeout / 4)
+	defer ticker.Stop()
+
+	// The gateway estimates jobs with the chunk size of the managers
+	if err := d.store.SetChunkSize(ctx, d.opts.ChunkSize); err != nil {
+		log.Warn("failed to record chunk size", "err", err)
+	}
+
+	log.Info("dispatcher started", "max_in_flight", d.opts.MaxInFlight, "chunk_size", d.opts.ChunkSize)
+	if err := d.Recover(ctx); err != nil {
+		log.Error("failed to recover dispatch queue", "err", err)
+	}
+	d.dispatch(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		d.expire(ctx)
+		d.dispatch(ctx)
+	}
+}
+
+// HandleJobMessage is the NATS handler for newly submitted jobs
+func (d *Dispatcher) HandleJobMessage(data []byte) {
+	var msg models.JobMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed job message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	if err := d.Submit(ctx, &msg.Job); err != nil {
+		log.Error("failed to queue job", "job_id", msg.ID, "err", err)
+	}
+}
+
+// HandleResultMessage is the NATS handler for chunk results. It records the
+// chunk as done, completes the job once every chunk is and dispatches what the
+// slot of the chunk makes room for. Every manager sees every result, only the
+// first report of a chunk counts.
+func (d *Dispatcher) HandleResultMessage(data []byte) {
+	var msg models.ResultMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed result message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	report, ok, err := d.store.ReportChunk(ctx, msg.JobID, msg.ChunkID)
+	if err != nil {
+		log.Error("failed to report chunk", "job_id", msg.JobID, "chunk_id", msg.ChunkID, "err", err)
+		return
+	}
+
+	if ok && report.First {
+		stats := msg.Stats
+		stats.ProcessedChunks = 1
+		counted, capped, max := d.countMatches(ctx, &msg)
+		stats.TotalMatches = counted
+		if err := d.store.IncrStats(ctx, msg.JobID, stats); err != nil {
+			log.Warn("failed to record chunk stats", "job_id", msg.JobID, "chunk_id", msg.ChunkID, "err", err)
+		}
+		if capped != "" {
+			d.dropCapped(ctx, msg.JobID, capped, max)
+		}
+	}
+	if ok {
+		d.progress(ctx, msg.JobID, report)
+	}
+
+	d.dispatch(context.Background())
+}
+
+// countMatches returns the lines of a reported chunk that count towards the
+// matches of its job, those past the first max lines of its file are never
+// returned. The max is MaxCount, or one in modes settled by the first match.
+// Capped is the file of the chunk once it has max lines selected and its
+// chunks past them may be dropped. Errors are only logged, the chunk then
+// counts in full.
+func (d *Dispatcher) countMatches(ctx context.Context, msg *models.ResultMessage) (counted int, capped string, max int) {
+	selected := msg.Stats.TotalMatches
+	if selected == 0 {
+		return 0, "", 0
+	}
+
+	job, err := d.store.GetJob(ctx, msg.JobID)
+	if err != nil {
+		log.Warn("failed to look up job of result", "job_id", msg.JobID, "err", err)
+		return selected, "", 0
+	}
+	max = job.MaxCount
+	if job.Mode.SettledByFirstMatch() {
+		max = 1
+	}
+	if max <= 0 {
+		return selected, "", 0
+	}
+
+	file, total, err := d.store.CountChunkMatches(ctx, msg.JobID, msg.ChunkID, selected)
+	if err != nil {
+		log.Warn("failed to count matches of chunk", "job_id", msg.JobID, "chunk_id", msg.ChunkID, "err", err)
+		return selected, "", 0
+	}
+
+	counted = min(total, max) - min(total-selected, max)
+	// Matched files of settled modes are dropped as a whole once announced
+	if total >= max && !job.Mode.SettledByFirstMatch() {
+		capped = file
+	}
+	return counted, capped, max
+}
+
+// dropCapped drops the chunks of a file not dispatched yet that follow chunks
+// which selected max lines already. Chunks of a file are searched in any
+// order, the lines of those before may still come first. Errors are only
+// logged.
+func (d *Dispatcher) dropCapped(ctx context.Context, jobID, file string, max int) {
+	dropped, report, err := d.store.DropCappedChunks(ctx, jobID, file, max)
+	if err != nil {
+		log.Error("failed to drop chunks of capped file", "job_id", jobID, "file", file, "err", err)
+		return
+	}
+	if dropped == 0 {
+		return
+	}
+
+	log.Info("skipping rest of capped file", "job_id", jobID, "file", file, "chunks", dropped)
+	if err := d.store.IncrStats(ctx, jobID, models.JobStats{ProcessedChunks: dropped}); err != nil {
+		log.Warn("failed to record dropped chunks", "job_id", jobID, "err", err)
+	}
+	d.progress(ctx, jobID, report)
+}
+
+// HandleFileMatchedMessage is the NATS handler for files known to have a
+// selected line. In modes settled by the first match the chunks of the file
+// not dispatched yet need not be searched and are dropped.
+func (d *Dispatcher) HandleFileMatchedMessage(data []byte) {
+	var msg models.FileMatchedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed file matched message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	job, err := d.store.GetJob(ctx, msg.JobID)
+	if err != nil {
+		log.Warn("failed to look up job of matched file", "job_id", msg.JobID, "err", err)
+		return
+	}
+	if !job.Mode.SettledByFirstMatch() {
+		return
+	}
+
+	dropped, report, err := d.store.DropPendingChunks(ctx, msg.JobID, msg.FileName)
+	if err != nil {
+		log.Error("failed to drop chunks of matched file", "job_id", msg.JobID, "file", msg.FileName, "err", err)
+		return
+	}
+	if dropped == 0 {
+		return
+	}
+
+	log.Info("skipping rest of matched file", "job_id", msg.JobID, "file", msg.FileName, "chunks", dropped)
+	if err := d.store.IncrStats(ctx, msg.JobID, models.JobStats{ProcessedChunks: dropped}); err != nil {
+		log.Warn("failed to record dropped chunks", "job_id", msg.JobID, "err", err)
+	}
+	d.progress(ctx, msg.JobID, report)
+}
+
+// progress completes a job once every chunk of it is done, the manager whose
+// report or drop got it there finishes it
+func (d *Dispatcher) progress(ctx context.Context, jobID string, report redis.ChunkReport) {
+	if report.Done < report.Total {
+		return
+	}
+	if report.First {
+		d.finishJob(ctx, jobID, models.JobStatusCompleted, "")
+	}
+	d.forget(ctx, jobID)
+}
+
+// HandleCancelMessage is the NATS handler for cancelled jobs, the chunks of
+// the job not dispatched yet are dropped
+func (d *Dispatcher) HandleCancelMessage(data []byte) {
+	var msg models.CancelMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed cancel message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	d.Cancel(ctx, msg.JobID)
+	d.dispatch(context.Background())
+}
+
+// Submit splits the files of a job into chunks and queues them, the job
+// belongs to the tenant ctx is scoped to. A job whose files cannot be looked
+// up is failed, one without any bytes to search is completed right away.
+func (d *Dispatcher) Submit(ctx context.Context, job *models.Job) error {
+	chunks, err := d.split(ctx, job)
+	if err != nil {
+		d.finishJob(ctx, job.ID, models.JobStatusFailed, err.Error())
+		return err
+	}
+	if len(chunks) == 0 {
+		d.finishJob(ctx, job.ID, models.JobStatusCompleted, "")
+		return nil
+	}
+
+	if err := d.store.SaveChunks(ctx, job.ID, chunks); err != nil {
+		d.finishJob(ctx, job.ID, models.JobStatusFailed, "failed to queue chunks")
+		return err
+	}
+	if err := d.store.IncrStats(ctx, job.ID, models.JobStats{TotalChunks: len(chunks)}); err != nil {
+		return err
+	}
+	if job.Priority == models.PriorityInteractive {
+		if err := d.store.SetInteractive(ctx, job.ID, true); err != nil {
+			return err
+		}
+	}
+	if err := d.store.EnqueueJob(ctx, job); err != nil {
+		return err
+	}
+
+	log.Info("job queued", "job_id", job.ID, "priority", job.Priority, "chunks", len(chunks))
+	d.dispatch(ctx)
+	return nil
+}
+
+// Cancel drops the chunks of a job, those in flight are ignored when they are
+// reported back. It is a no-op for jobs that are not dispatched.
+func (d *Dispatcher) Cancel(ctx context.Context, jobID string) {
+	d.forget(ctx, jobID)
+	d.cleanup(ctx, jobID)
+	log.Info("job dispatch cancelled", "job_id", jobID)
+}
+
+// Recover cleans up after managers that stopped while dispatching. Queued
+// jobs that finished or are gone are removed from the queue, and those whose
+// chunks were never recorded are failed. The chunks of the rest are
+// dispatched as usual, and those in flight time out unless reported back.
+func (d *Dispatcher) Recover(ctx context.Context) error {
+	queued, err := d.store.QueuedJobs(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range queued {
+		jobCtx := tenant.NewContext(ctx, entry.Tenant)
+		job, err := d.store.GetJob(jobCtx, entry.JobID)
+		if err != nil && !errors.Is(err, redis.ErrJobNotFound) {
+			return err
+		}
+		if err != nil || job.Status.IsTerminal() {
+			log.Info("dropping finished job from queue", "job_id", entry.JobID)
+			d.cleanup(jobCtx, entry.JobID)
+			continue
+		}
+
+		ok, err := d.store.HasChunks(jobCtx, entry.JobID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Warn("failing job whose chunks were lost", "job_id", entry.JobID)
+			d.fail(jobCtx, entry.JobID, "chunks of the job were lost when a manager stopped")
+		}
+	}
+
+	interactive, err := d.store.InteractiveJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range interactive {
+		jobCtx := tenant.NewContext(ctx, entry.Tenant)
+		job, err := d.store.GetJob(jobCtx, entry.JobID)
+		if err != nil && !errors.Is(err, redis.ErrJobNotFound) {
+			return err
+		}
+		if err != nil || job.Status.IsTerminal() {
+			d.cleanup(jobCtx, entry.JobID)
+		}
+	}
+
+	log.Info("dispatch queue recovered", "queued", len(queued))
+	return nil
+}
+
+// split cuts every file of a job into chunks of whole lines, of at most
+// ChunkSize bytes unless a single line is longer
+func (d *Dispatcher) split(ctx context.Context, job *models.Job) ([]models.Chunk, error) {
+	now := d.now().UTC()
+
+	// Lines without a timestamp inherit the one before them, a chunk takes
+	// that of the chunk before it. Mappers parse syslog timestamps relative
+	// to the creation of the chunk, so the same reference is used here.
+	opts := spanOptions{contextLines: job.ContextLines}
+	if job.TimeRange != nil {
+		parser, err := timestamp.NewParser(job.TimeRange.Formats, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse time range: %w", err)
+		}
+		opts.parser = parser
+	}
+
+	var chunks []models.Chunk
+	for _, path := range job.Files {
+		file, err := d.files.StatLogFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up file %s: %w", path, err)
+		}
+		if file.Size == 0 {
+			continue
+		}
+
+		spans, err := d.fileSpans(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, span := range spans {
+			chunks = append(chunks, models.Chunk{
+				ID:        ids.New("chunk"),
+				JobID:     job.ID,
+				Tenant:    job.Tenant,
+				FileName:  path,
+				StartByte: span.start,
+				EndByte:   span.end,
+				Size:      span.end - span.start,
+				StartLine: span.startLine,
+				EndLine:   span.endLine,
+				CreatedAt: now,
+
+				ContextBytes: span.context,
+				Inherited:    span.inherited,
+			})
+		}
+	}
+	return chunks, nil
+}
+
+// fileSpans reads a file to find where its chunks end
+func (d *Dispatcher) fileSpans(ctx context.Context, path string, opts spanOptions) ([]span, error) {
+	r, err := d.files.OpenLogFile(ctx, path, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	defer r.Close()
+
+	spans, err := lineSpans(r, d.opts.ChunkSize, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return spans, nil
+}
+
+// dispatch hands queued chunks to the mappers until MaxInFlight is reached or
+// nothing is left that may be dispatched
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		head, ok := d.next(ctx)
+		if !ok {
+			return
+		}
+
+		jobCtx := tenant.NewContext(ctx, head.Tenant)
+		job, ok := d.start(jobCtx, head.JobID)
+		if !ok {
+			if !d.cleanup(jobCtx, head.JobID) {
+				return
+			}
+			continue
+		}
+
+		chunk, full, err := d.store.ClaimChunk(jobCtx, job.ID, d.now().Add(d.opts.ChunkTimeout), d.opts.MaxInFlight)
+		if err != nil {
+			log.Error("failed to claim chunk", "job_id", job.ID, "err", err)
+			return
+		}
+		if full {
+			return
+		}
+		if chunk == nil {
+			// Every chunk is out, the job left the queue
+			continue
+		}
+
+		if err := d.bus.Publish(jobCtx, nats.SubjectChunks, job.ChunkMessage(*chunk)); err != nil {
+			// Keep the chunk queued, the next result or tick tries again
+			log.Error("failed to dispatch chunk", "job_id", job.ID, "chunk_id", chunk.ID, "err", err)
+			if err := d.store.ReturnChunk(jobCtx, job.ID, chunk.ID); err != nil {
+				log.Error("failed to return chunk", "job_id", job.ID, "chunk_id", chunk.ID, "err", err)
+			}
+			d.enqueue(jobCtx, job)
+			return
+		}
+	}
+}
+
+// next returns the job at the head of the shared queue, false if there is
+// none or only batch jobs are left while an interactive one is unfinished
+func (d *Dispatcher) next(ctx context.Context) (redis.QueuedJob, bool) {
+	queued, err := d.store.QueuedJobs(ctx, 1)
+	if err != nil {
+		log.Error("failed to look up queued jobs", "err", err)
+		return redis.QueuedJob{}, false
+	}
+	if len(queued) == 0 {
+		return redis.QueuedJob{}, false
+	}
+
+	head := queued[0]
+	if head.Priority == models.PriorityBatch {
+		busy, err := d.store.HasInteractiveJobs(ctx)
+		if err != nil {
+			log.Error("failed to look up interactive jobs", "err", err)
+			return redis.QueuedJob{}, false
+		}
+		if busy {
+			return redis.QueuedJob{}, false
+		}
+	}
+	return head, true
+}
+
+// start returns a queued job, marking it as processing before its first
+// chunk is dispatched. It reports false if the job reached a final status or
+// is gone.
+func (d *Dispatcher) start(ctx context.Context, jobID string) (*models.Job, bool) {
+	key := tenantKey(tenant.FromContext(ctx), jobID)
+	if job, ok := d.jobs[key]; ok {
+		return job, true
+	}
+
+	now := d.now().UTC()
+	started := false
+	job, err := d.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if job.Status.IsTerminal() {
+			return errJobFinished
+		}
+		if job.StartedAt == nil {
+			job.Status = models.JobStatusProcessing
+			job.StartedAt = &now
+			started = true
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errJobFinished) {
+			log.Error("failed to start job, dropping it", "job_id", jobID, "err", err)
+		}
+		return nil, false
+	}
+
+	if started {
+		metrics.ObserveJob(job.Status)
+	}
+	d.jobs[key] = job
+	return job, true
+}
+
+// forget drops a job from the cache of started jobs
+func (d *Dispatcher) forget(ctx context.Context, jobID string) {
+	d.mu.Lock()
+	delete(d.jobs, tenantKey(tenant.FromContext(ctx), jobID))
+	d.mu.Unlock()
+}
+
+// expire hands chunks dispatched longer than ChunkTimeout ago to the queue
+// again, and fails their job once one timed out MaxAttempts times
+func (d *Dispatcher) expire(ctx context.Context) {
+	expired, err := d.store.ExpiredChunks(ctx, d.now(), expireBatch)
+	if err != nil {
+		log.Error("failed to look up timed out chunks", "err", err)
+		return
+	}
+
+	for _, chunk := range expired {
+		jobCtx := tenant.NewContext(ctx, chunk.Tenant)
+		attempts, err := d.store.RetryChunk(jobCtx, chunk.JobID, chunk.ChunkID, d.opts.MaxAttempts)
+		if err != nil {
+			log.Error("failed to retry chunk", "job_id", chunk.JobID, "chunk_id", chunk.ChunkID, "err", err)
+			continue
+		}
+		if attempts == 0 {
+			continue
+		}
+
+		if attempts >= d.opts.MaxAttempts {
+			log.Error("chunk timed out too often, failing job", "job_id", chunk.JobID, "chunk_id", chunk.ChunkID, "attempts", attempts)
+			d.forget(jobCtx, chunk.JobID)
+			d.fail(jobCtx, chunk.JobID, fmt.Sprintf("chunk %s timed out %d times", chunk.ChunkID, attempts))
+			continue
+		}
+
+		log.Warn("chunk timed out, retrying", "job_id", chunk.JobID, "chunk_id", chunk.ChunkID, "timeout", d.opts.ChunkTimeout, "attempts", attempts)
+		job, err := d.store.GetJob(jobCtx, chunk.JobID)
+		if err != nil {
+			log.Error("failed to requeue job", "job_id", chunk.JobID, "err", err)
+			continue
+		}
+		d.enqueue(jobCtx, job)
+	}
+}
+
+// enqueue puts a job with chunks pending again back in the shared queue,
+// errors are only logged
+func (d *Dispatcher) enqueue(ctx context.Context, job *models.Job) {
+	if err := d.store.EnqueueJob(ctx, job); err != nil {
+		log.Error("failed to requeue job", "job_id", job.ID, "err", err)
+	}
+}
+
+// dequeue removes a job from the shared queue and reports whether it did,
+// errors are only logged
+func (d *Dispatcher) dequeue(ctx context.Context, jobID string) bool {
+	if err := d.store.DequeueJob(ctx, jobID); err != nil {
+		log.Warn("failed to dequeue job", "job_id", jobID, "err", err)
+		return false
+	}
+	return true
+}
+
+// cleanup removes a job that is not dispatched anymore from the queue and
+// drops its chunks, it reports whether it did. Errors are only logged.
+func (d *Dispatcher) cleanup(ctx context.Context, jobID string) bool {
+	if err := d.store.DeleteChunks(ctx, jobID); err != nil {
+		log.Warn("failed to delete chunks", "job_id", jobID, "err", err)
+		return false
+	}
+	if err := d.store.SetInteractive(ctx, jobID, false); err != nil {
+		log.Warn("failed to update interactive job", "job_id", jobID, "err", err)
+		return false
+	}
+	return d.dequeue(ctx, jobID)
+}
+
+// fail moves a job to failed and drops what is left of it
+func (d *Dispatcher) fail(ctx context.Context, jobID, reason string) {
+	d.finishJob(ctx, jobID, models.JobStatusFailed, reason)
+	d.cleanup(ctx, jobID)
+}
+
+// finishJob moves a job to a final status and announces it, the chunks of
+// completed jobs are dropped. Errors are only logged.
+func (d *Dispatcher) finishJob(ctx context.Context, jobID string, status models.JobStatus, reason string) {
+	now := d.now().UTC()
+	_, err := d.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if job.Status.IsTerminal() {
+			return errJobFinished
+		}
+		job.Status = status
+		job.Error = reason
+		job.CompletedAt = &now
+		if status == models.JobStatusCompleted {
+			job.Progress = 100
+		}
+		return nil
+	})
+	if errors.Is(err, errJobFinished) {
+		return
+	}
+	if err != nil {
+		log.Error("failed to finish job", "job_id", jobID, "status", status, "err", err)
+		return
+	}
+	metrics.ObserveJob(status)
+	if status == models.JobStatusCompleted {
+		d.cleanup(ctx, jobID)
+	}
+
+	msg := models.JobFinishedMessage{JobID: jobID, Tenant: tenant.FromContext(ctx), Status: status}
+	if err := d.bus.Publish(ctx, nats.SubjectJobFinished, msg); err != nil {
+		log.Warn("failed to announce finished job", "job_id", jobID, "err", err)
+	}
+}
+
+func tenantKey(tenantID, jobID string) string {
+	return tenantID + "/" + jobID
+}
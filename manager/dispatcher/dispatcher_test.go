@@ -0,0 +1,500 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+type fakeFiles map[string]string
+
+func (f fakeFiles) StatLogFile(ctx context.Context, path string) (*models.LogFile, error) {
+	content, ok := f[path]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return &models.LogFile{Path: path, Size: int64(len(content))}, nil
+}
+
+func (f fakeFiles) OpenLogFile(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	content, ok := f[path]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	content = content[offset:]
+	if length > 0 {
+		content = content[:length]
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+// lines returns n lines of 10 bytes each
+func lines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %04d\n", i)
+	}
+	return b.String()
+}
+
+type fakeBus struct {
+	mu       sync.Mutex
+	chunks   []models.ChunkMessage
+	finished []models.JobFinishedMessage
+}
+
+func (b *fakeBus) Publish(ctx context.Context, subject string, msg any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch subject {
+	case nats.SubjectChunks:
+		b.chunks = append(b.chunks, msg.(models.ChunkMessage))
+	case nats.SubjectJobFinished:
+		b.finished = append(b.finished, msg.(models.JobFinishedMessage))
+	}
+	return nil
+}
+
+// take returns the chunks dispatched since the last call
+func (b *fakeBus) take() []models.ChunkMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chunks := b.chunks
+	b.chunks = nil
+	return chunks
+}
+
+func newTestDispatcher(t *testing.T, opts Options) (*Dispatcher, *redis.Store, *fakeBus) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	files := fakeFiles{"logs/app.log": lines(25), "logs/error.log": lines(10), "logs/empty.log": ""}
+	bus := &fakeBus{}
+
+	return New(store, files, bus, opts, l), store, bus
+}
+
+// peer returns another manager dispatching from the same store as d
+func peer(d *Dispatcher) *Dispatcher {
+	return New(d.store, d.files, d.bus, d.opts, log)
+}
+
+// submit saves a pending job like the API does and hands it to d
+func submit(t *testing.T, d *Dispatcher, store *redis.Store, ctx context.Context, job models.Job) {
+	job.Tenant = tenant.FromContext(ctx)
+	job.Status = models.JobStatusPending
+	require.NoError(t, store.SaveJob(ctx, &job))
+	require.NoError(t, store.SetStats(ctx, job.ID, models.JobStats{TotalFiles: len(job.Files)}))
+
+	data, err := json.Marshal(models.JobMessage{Job: job})
+	require.NoError(t, err)
+	d.HandleJobMessage(data)
+}
+
+// report hands the results of chunks back to d
+func report(t *testing.T, d *Dispatcher, chunks ...models.ChunkMessage) {
+	for _, chunk := range chunks {
+		data, err := json.Marshal(models.ResultMessage{
+			ID: chunk.ID, JobID: chunk.JobID, Tenant: chunk.Tenant, ChunkID: chunk.ID, MatchCount: 2,
+			Stats: models.JobStats{TotalMatches: 2, BytesProcessed: chunk.Size},
+		})
+		require.NoError(t, err)
+		d.HandleResultMessage(data)
+	}
+}
+
+func jobIDs(chunks []models.ChunkMessage) []string {
+	ids := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		ids = append(ids, chunk.JobID)
+	}
+	return ids
+}
+
+func TestSubmit(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	d, store, bus := newTestDispatcher(t, Options{ChunkSize: 100})
+
+	created := time.Now().UTC()
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", Pattern: "ERROR", Regex: true, MaxCount: 10, Files: []string{"logs/app.log", "logs/empty.log"}, CreatedAt: created})
+
+	chunks := bus.take()
+	require.Len(t, chunks, 3)
+	for i, chunk := range chunks {
+		assert.Equal(t, "grep_1", chunk.JobID)
+		assert.Equal(t, "acme", chunk.Tenant)
+		assert.Equal(t, "logs/app.log", chunk.FileName)
+		assert.Equal(t, int64(i*100), chunk.StartByte)
+		assert.Equal(t, i*10+1, chunk.StartLine)
+		assert.Equal(t, "ERROR", chunk.Pattern)
+		assert.True(t, chunk.Regex)
+		assert.Equal(t, 10, chunk.MaxCount)
+	}
+	assert.Equal(t, int64(250), chunks[2].EndByte)
+	assert.Equal(t, int64(50), chunks[2].Size)
+	assert.Equal(t, 25, chunks[2].EndLine)
+
+	job, err := store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusProcessing, job.Status)
+	assert.NotNil(t, job.StartedAt)
+
+	stats, err := store.GetStats(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalChunks)
+
+	// Every chunk is out, so the job left the queue
+	_, ok, err := store.QueuePosition(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	report(t, d, chunks[:2]...)
+	report(t, d, chunks[1])
+	job, err = store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusProcessing, job.Status)
+
+	report(t, d, chunks[2])
+	job, err = store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	assert.Equal(t, float64(100), job.Progress)
+	require.Len(t, bus.finished, 1)
+	assert.Equal(t, models.JobStatusCompleted, bus.finished[0].Status)
+
+	stats, err = store.GetStats(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.ProcessedChunks)
+	assert.Equal(t, 6, stats.TotalMatches, "a chunk reported twice should count once")
+	assert.Equal(t, int64(250), stats.BytesProcessed)
+
+	ok, err = store.HasChunks(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSubmitWithoutChunks(t *testing.T) {
+	ctx := context.Background()
+	d, store, bus := newTestDispatcher(t, Options{})
+
+	submit(t, d, store, ctx, models.Job{ID: "grep_empty", Files: []string{"logs/empty.log"}})
+	submit(t, d, store, ctx, models.Job{ID: "grep_missing", Files: []string{"logs/gone.log"}})
+
+	assert.Empty(t, bus.take())
+	require.Len(t, bus.finished, 2)
+
+	job, err := store.GetJob(ctx, "grep_empty")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	assert.Equal(t, float64(100), job.Progress)
+
+	job, err = store.GetJob(ctx, "grep_missing")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+	assert.Contains(t, job.Error, "logs/gone.log")
+}
+
+func TestDispatchOrder(t *testing.T) {
+	ctx := context.Background()
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100})
+
+	created := time.Now().UTC()
+	// Takes the only slot before anything else is queued
+	submit(t, d, store, ctx, models.Job{ID: "grep_first", Files: []string{"logs/error.log"}, CreatedAt: created})
+	submit(t, d, store, ctx, models.Job{ID: "grep_batch", Priority: models.PriorityBatch, Files: []string{"logs/error.log"}, CreatedAt: created})
+	submit(t, d, store, ctx, models.Job{ID: "grep_normal", Files: []string{"logs/error.log"}, CreatedAt: created.Add(time.Second)})
+	submit(t, d, store, ctx, models.Job{ID: "grep_early", Priority: models.PriorityNormal, Files: []string{"logs/error.log"}, CreatedAt: created.Add(-time.Second)})
+	submit(t, d, store, ctx, models.Job{ID: "grep_urgent", Priority: models.PriorityInteractive, Files: []string{"logs/error.log"}, CreatedAt: created.Add(time.Minute)})
+
+	position, ok, err := store.QueuePosition(ctx, "grep_batch")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, position)
+
+	var order []string
+	for chunks := bus.take(); len(chunks) > 0; chunks = bus.take() {
+		order = append(order, jobIDs(chunks)...)
+		report(t, d, chunks...)
+	}
+	assert.Equal(t, []string{"grep_first", "grep_urgent", "grep_early", "grep_normal", "grep_batch"}, order)
+}
+
+func TestBatchPausedForInteractive(t *testing.T) {
+	ctx := context.Background()
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 4, ChunkSize: 50})
+	other := peer(d)
+
+	created := time.Now().UTC()
+	submit(t, d, store, ctx, models.Job{ID: "grep_batch", Priority: models.PriorityBatch, Files: []string{"logs/app.log"}, CreatedAt: created})
+	batch := bus.take()
+	assert.Len(t, batch, 4)
+
+	// Another manager takes the interactive job
+	submit(t, other, store, ctx, models.Job{ID: "grep_urgent", Priority: models.PriorityInteractive, Files: []string{"logs/error.log"}, CreatedAt: created})
+	assert.Empty(t, bus.take(), "every slot of all managers is taken")
+
+	// The slots freed by the batch job go to the interactive job
+	report(t, d, batch[:2]...)
+	urgent := bus.take()
+	assert.Equal(t, []string{"grep_urgent", "grep_urgent"}, jobIDs(urgent))
+
+	// Slots are free, but the batch job waits until the interactive one is done
+	report(t, d, batch[2:]...)
+	assert.Empty(t, bus.take())
+
+	position, ok, err := store.QueuePosition(ctx, "grep_batch")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0, position)
+
+	report(t, other, urgent[0])
+	assert.Empty(t, bus.take())
+	report(t, other, urgent[1])
+	assert.Equal(t, []string{"grep_batch"}, jobIDs(bus.take()))
+}
+
+func TestCancel(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 50})
+
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", Files: []string{"logs/app.log"}, CreatedAt: time.Now().UTC()})
+	submit(t, d, store, ctx, models.Job{ID: "grep_2", Files: []string{"logs/error.log"}, CreatedAt: time.Now().UTC()})
+	first := bus.take()
+	require.Len(t, first, 1)
+
+	data, err := json.Marshal(models.CancelMessage{JobID: "grep_1", Tenant: "acme"})
+	require.NoError(t, err)
+	d.HandleCancelMessage(data)
+
+	_, ok, err := store.QueuePosition(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// The chunk already out still holds its slot
+	assert.Empty(t, bus.take())
+	report(t, d, first...)
+	assert.Equal(t, []string{"grep_2"}, jobIDs(bus.take()))
+}
+
+func TestChunkTimeout(t *testing.T) {
+	ctx := context.Background()
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100, ChunkTimeout: time.Minute, MaxAttempts: 2})
+
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", Files: []string{"logs/app.log"}, CreatedAt: now.UTC()})
+	first := bus.take()
+	require.Len(t, first, 1)
+
+	d.expire(ctx)
+	d.dispatch(ctx)
+	assert.Empty(t, bus.take())
+
+	now = now.Add(2 * time.Minute)
+	d.expire(ctx)
+	d.dispatch(ctx)
+	retried := bus.take()
+	require.Len(t, retried, 1)
+	assert.Equal(t, first[0].ID, retried[0].ID, "the chunk that timed out should be retried first")
+
+	now = now.Add(2 * time.Minute)
+	d.expire(ctx)
+	d.dispatch(ctx)
+	assert.Empty(t, bus.take())
+
+	job, err := store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+	assert.Contains(t, job.Error, "timed out 2 times")
+
+	_, ok, err := store.QueuePosition(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRecover(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100})
+
+	created := time.Now().UTC()
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", Files: []string{"logs/app.log"}, CreatedAt: created})
+	first := bus.take()
+	require.Len(t, first, 1)
+
+	// Left in the queue by a manager that knew nothing of chunks in Redis
+	lost := models.Job{ID: "grep_lost", Status: models.JobStatusProcessing, CreatedAt: created}
+	require.NoError(t, store.SaveJob(ctx, &lost))
+	require.NoError(t, store.EnqueueJob(ctx, &lost))
+
+	// Finished, but its manager stopped before removing it from the queue
+	done := models.Job{ID: "grep_done", Status: models.JobStatusCompleted, CreatedAt: created}
+	require.NoError(t, store.SaveJob(ctx, &done))
+	require.NoError(t, store.EnqueueJob(ctx, &done))
+	require.NoError(t, store.SetInteractive(ctx, "grep_done", true))
+
+	// The manager restarts
+	restarted := peer(d)
+	require.NoError(t, restarted.Recover(ctx))
+
+	job, err := store.GetJob(ctx, "grep_lost")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+
+	for _, id := range []string{"grep_lost", "grep_done"} {
+		_, ok, err := store.QueuePosition(ctx, id)
+		require.NoError(t, err)
+		assert.False(t, ok, id)
+	}
+	ok, err := store.HasInteractiveJobs(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	position, ok, err := store.QueuePosition(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Zero(t, position, "jobs ahead in the queue should not be left behind")
+
+	// The chunk in flight before the restart is reported to the new manager
+	var order []models.ChunkMessage
+	for chunks := first; len(chunks) > 0; chunks = bus.take() {
+		order = append(order, chunks...)
+		report(t, restarted, chunks...)
+	}
+	assert.Len(t, order, 3)
+
+	job, err = store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+}
+
+func TestFileMatched(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100})
+
+	matched := func(jobID string) {
+		data, err := json.Marshal(models.FileMatchedMessage{JobID: jobID, Tenant: "acme", FileName: "logs/app.log"})
+		require.NoError(t, err)
+		d.HandleFileMatchedMessage(data)
+	}
+
+	tests := []struct {
+		name    string
+		mode    models.SearchMode
+		chunks  int
+		matches int
+	}{
+		{"FilesWithMatches", models.SearchModeFilesWithMatches, 2, 2},
+		{"FilesWithoutMatch", models.SearchModeFilesWithoutMatch, 2, 2},
+		{"Lines", models.SearchModeLines, 4, 8},
+		{"Count", models.SearchModeCount, 4, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobID := "grep_" + string(tt.mode)
+			submit(t, d, store, ctx, models.Job{ID: jobID, Mode: tt.mode, Files: []string{"logs/app.log", "logs/error.log"}, CreatedAt: time.Now().UTC()})
+
+			first := bus.take()
+			require.Len(t, first, 1)
+			assert.Equal(t, "logs/app.log", first[0].FileName)
+
+			// A mapper found a selected line in the first chunk of the file
+			matched(jobID)
+
+			var files []string
+			for chunks := first; len(chunks) > 0; chunks = bus.take() {
+				for _, chunk := range chunks {
+					files = append(files, chunk.FileName)
+				}
+				report(t, d, chunks...)
+			}
+			assert.Len(t, files, tt.chunks)
+			assert.Equal(t, "logs/error.log", files[len(files)-1])
+
+			job, err := store.GetJob(ctx, jobID)
+			require.NoError(t, err)
+			assert.Equal(t, models.JobStatusCompleted, job.Status)
+
+			stats, err := store.GetStats(ctx, jobID)
+			require.NoError(t, err)
+			assert.Equal(t, 4, stats.ProcessedChunks)
+			assert.Equal(t, tt.matches, stats.TotalMatches, "files of settled modes should count once")
+		})
+	}
+}
+
+func TestMaxCount(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100})
+
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", MaxCount: 3, Files: []string{"logs/app.log", "logs/error.log"}, CreatedAt: time.Now().UTC()})
+
+	var files []string
+	for chunks := bus.take(); len(chunks) > 0; chunks = bus.take() {
+		for _, chunk := range chunks {
+			files = append(files, chunk.FileName)
+		}
+		report(t, d, chunks...)
+	}
+	assert.Equal(t, []string{"logs/app.log", "logs/app.log", "logs/error.log"}, files,
+		"chunks after the ones that selected max_count lines should be skipped")
+
+	job, err := store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+
+	stats, err := store.GetStats(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.ProcessedChunks)
+	assert.Equal(t, 5, stats.TotalMatches, "matches should be capped per file")
+}
+
+func TestFileMatchedFinishesJob(t *testing.T) {
+	ctx := context.Background()
+	d, store, bus := newTestDispatcher(t, Options{MaxInFlight: 1, ChunkSize: 100})
+
+	submit(t, d, store, ctx, models.Job{ID: "grep_1", Mode: models.SearchModeFilesWithMatches, Files: []string{"logs/app.log"}, CreatedAt: time.Now().UTC()})
+	first := bus.take()
+	require.Len(t, first, 1)
+
+	// Recorded by another manager, before it dispatched the next chunk
+	_, ok, err := store.ReportChunk(ctx, "grep_1", first[0].ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	data, err := json.Marshal(models.FileMatchedMessage{JobID: "grep_1", FileName: "logs/app.log"})
+	require.NoError(t, err)
+	d.HandleFileMatchedMessage(data)
+
+	job, err := store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status, "dropping the last chunks should complete the job")
+	require.Len(t, bus.finished, 1)
+
+	d.dispatch(ctx)
+	assert.Empty(t, bus.take())
+}
@@ -0,0 +1,113 @@
+package dispatcher
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+// span is a run of whole lines of a file, lines are numbered from 1
+type span struct {
+	start, end         int64      // Byte offsets, end exclusive
+	startLine, endLine int        // Inclusive
+	context            int64      // Bytes of the lines before the span read as context of its first lines
+	inherited          *time.Time // Time of the last timestamped line before the span
+}
+
+// spanOptions tell lineSpans what a span needs to know of the lines before it
+type spanOptions struct {
+	contextLines int               // Lines of context before a span
+	parser       *timestamp.Parser // Finds the time of lines, nil unless lines are filtered by time
+}
+
+// lineSpans cuts the content of r into spans of at most size bytes that end
+// at a line boundary. A line longer than size makes up a span of its own.
+func lineSpans(r io.Reader, size int64, opts spanOptions) ([]span, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+
+	var (
+		spans    []span
+		starts   []int64    // Offsets of the last contextLines lines
+		inherit  *time.Time // Time of the last timestamped line
+		head     string     // Start of the line pos is in, for its timestamp
+		startsAt = true     // Whether the next segment starts a line
+	)
+	current := span{startLine: 1}
+	cut := func(end int64, endLine int) {
+		current.end, current.endLine = end, endLine
+		spans = append(spans, current)
+		current = span{start: end, startLine: endLine + 1, inherited: inherit}
+		if len(starts) > 0 {
+			current.context = end - starts[0]
+		}
+	}
+	// seen records a line that ends before the next span starts
+	seen := func(start int64) {
+		if opts.contextLines > 0 {
+			if len(starts) == opts.contextLines {
+				starts = starts[1:]
+			}
+			starts = append(starts, start)
+		}
+		if opts.parser != nil {
+			if t, ok := opts.parser.Parse(strings.TrimSuffix(head, "\n")); ok {
+				inherit = &t
+			}
+		}
+	}
+
+	var pos, boundary int64 // Offset read up to and the end of the last line
+	line := 1               // Number of the line pos is in
+	open := false           // Whether pos is within that line
+
+	for {
+		segment, err := br.ReadSlice('\n')
+		pos += int64(len(segment))
+		if len(segment) > 0 {
+			if startsAt && opts.parser != nil {
+				head = string(segment)
+			}
+			open = segment[len(segment)-1] != '\n'
+			startsAt = !open
+		}
+
+		if len(segment) > 0 && !open {
+			if pos-current.start > size && boundary > current.start {
+				cut(boundary, line-1)
+			}
+			seen(boundary)
+			if pos-current.start >= size {
+				cut(pos, line)
+			}
+			boundary = pos
+			line++
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The rest of the file, whose last line may lack a newline
+	if pos > current.start {
+		if pos-current.start > size && boundary > current.start {
+			cut(boundary, line-1)
+		}
+		current.end, current.endLine = pos, line-1
+		if open {
+			current.endLine = line
+		}
+		spans = append(spans, current)
+	}
+	return spans, nil
+}
@@ -0,0 +1,62 @@
+package dispatcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+func TestLineSpans(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		size    int64
+		spans   []span
+	}{
+		{"Empty", "", 10, nil},
+		{"SingleSpan", "a\nb\n", 10, []span{{0, 4, 1, 2, 0, nil}}},
+		{"CutBeforeBoundary", "aaaa\nbb\ncccc\nd\n", 8, []span{{0, 8, 1, 2, 0, nil}, {8, 15, 3, 4, 0, nil}}},
+		{"CutOnBoundary", "aaaa\nbbbb\n", 5, []span{{0, 5, 1, 1, 0, nil}, {5, 10, 2, 2, 0, nil}}},
+		{"NoTrailingNewline", "aaaa\nbbbb\ncc", 5, []span{{0, 5, 1, 1, 0, nil}, {5, 10, 2, 2, 0, nil}, {10, 12, 3, 3, 0, nil}}},
+		{"LastLineOverBoundary", "aa\nbbbb", 5, []span{{0, 3, 1, 1, 0, nil}, {3, 7, 2, 2, 0, nil}}},
+		{"LongLine", "aaaaaaaaaaaaaaaa\nb\n", 4, []span{{0, 17, 1, 1, 0, nil}, {17, 19, 2, 2, 0, nil}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, err := lineSpans(strings.NewReader(tt.content), tt.size, spanOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.spans, spans)
+		})
+	}
+
+	t.Run("LinesLongerThanTheBuffer", func(t *testing.T) {
+		long := strings.Repeat("x", 200<<10)
+		spans, err := lineSpans(strings.NewReader(long+"\n"+long+"\nend"), 1, spanOptions{})
+		require.NoError(t, err)
+
+		end := int64(len(long) + 1)
+		assert.Equal(t, []span{{0, end, 1, 1, 0, nil}, {end, 2 * end, 2, 2, 0, nil}, {2 * end, 2*end + 3, 3, 3, 0, nil}}, spans)
+
+		spans, err = lineSpans(strings.NewReader("a\n"+long), 1, spanOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []span{{0, 2, 1, 1, 0, nil}, {2, end + 1, 2, 2, 0, nil}}, spans)
+	})
+
+	t.Run("LinesBeforeSpan", func(t *testing.T) {
+		parser, err := timestamp.NewParser(nil, time.Time{})
+		require.NoError(t, err)
+
+		content := "2024-01-02T02:05:00Z start\n\tat one\n\tat two\nnext\n"
+		spans, err := lineSpans(strings.NewReader(content), 43, spanOptions{contextLines: 2, parser: parser})
+		require.NoError(t, err)
+
+		logged := time.Date(2024, 1, 2, 2, 5, 0, 0, time.UTC)
+		assert.Equal(t, []span{{0, 43, 1, 3, 0, nil}, {43, 48, 4, 4, 16, &logged}}, spans,
+			"the second span should start with its two lines of context and the time of the first line")
+	})
+}
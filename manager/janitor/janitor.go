@@ -0,0 +1,277 @@
+package janitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// Defaults for the zero fields of Options
+const (
+	DefaultRetention    = 7 * 24 * time.Hour
+	DefaultInterval     = time.Minute
+	DefaultTombstoneTTL = 30 * 24 * time.Hour
+)
+
+// batchSize is the number of due jobs fetched per round trip
+const batchSize = 100
+
+// errNotFinished aborts retiring a job that is still running
+var errNotFinished = errors.New("job not finished")
+
+// Options tune how long the data of finished jobs is kept
+type Options struct {
+	// Retention is how long finished jobs are kept unless they ask for
+	// another retention themselves
+	Retention time.Duration `mapstructure:"retention"`
+
+	// Interval is how often expired jobs are looked for
+	Interval time.Duration `mapstructure:"interval"`
+
+	// DeleteChunks removes the chunk objects of a job as soon as it
+	// finished, its results are kept until it expires
+	DeleteChunks bool `mapstructure:"deleteChunks"`
+
+	// TombstoneTTL is how long expired jobs are reported as expired rather
+	// than unknown
+	TombstoneTTL time.Duration `mapstructure:"tombstoneTtl"`
+}
+
+func (o Options) withDefaults() Options {
+	if o.Retention <= 0 {
+		o.Retention = DefaultRetention
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultInterval
+	}
+	if o.TombstoneTTL <= 0 {
+		o.TombstoneTTL = DefaultTombstoneTTL
+	}
+	return o
+}
+
+// Store keeps jobs and the expiry schedule, implemented by redis.Store
+type Store interface {
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	UpdateJob(ctx context.Context, jobID string, fn func(job *models.Job) error) (*models.Job, error)
+	ScheduleExpiry(ctx context.Context, jobID string, at time.Time) error
+	DueExpiries(ctx context.Context, now time.Time, limit int) ([]redis.ExpiringJob, error)
+	PurgeJob(ctx context.Context, job *models.Job, tombstone redis.Tombstone, ttl time.Duration) error
+	PurgeMissingJob(ctx context.Context, jobID string, tombstone redis.Tombstone, ttl time.Duration) error
+	UnretiredJobs(ctx context.Context, before time.Time, limit int) ([]redis.ExpiringJob, error)
+	ForgetUnretiredJob(ctx context.Context, jobID string) error
+}
+
+// Storage holds the chunks and results of jobs, implemented by minio.Storage
+type Storage interface {
+	DeleteJob(ctx context.Context, jobID string) error
+	DeleteJobChunks(ctx context.Context, jobID string) error
+}
+
+// Janitor removes finished jobs once their retention is over. A job is
+// retired when it finishes, which sets its expiry and the TTLs of its keys,
+// and purged together with its chunks and results once the expiry is due.
+// Jobs whose end was not announced are retired an interval later.
+// Several managers may run a Janitor against the same store, purging a job
+// twice is harmless.
+type Janitor struct {
+	store   Store
+	storage Storage
+	opts    Options
+	now     func() time.Time
+}
+
+// New returns a new Janitor, zero fields of opts take their defaults
+func New(store Store, storage Storage, opts Options, log *logger.Logger) *Janitor {
+	InitLogger(log)
+
+	return &Janitor{
+		store:   store,
+		storage: storage,
+		opts:    opts.withDefaults(),
+		now:     time.Now,
+	}
+}
+
+// Run purges expired jobs every interval until ctx is done
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.opts.Interval)
+	defer ticker.Stop()
+
+	log.Info("janitor started", "interval", j.opts.Interval, "retention", j.opts.Retention, "delete_chunks", j.opts.DeleteChunks)
+	for {
+		if _, err := j.Sweep(ctx); err != nil && ctx.Err() == nil {
+			log.Error("failed to retire finished jobs", "err", err)
+		}
+		if _, err := j.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Error("failed to purge expired jobs", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleJobFinishedMessage is the NATS handler for finished jobs
+func (j *Janitor) HandleJobFinishedMessage(data []byte) {
+	var msg models.JobFinishedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed job finished message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	if err := j.Retire(ctx, msg.JobID); err != nil {
+		log.Error("failed to retire job", "job_id", msg.JobID, "err", err)
+	}
+}
+
+// Retire schedules the expiry of a finished job, its retention counts from
+// when it completed. It is a no-op for jobs that are unknown or still
+// running, and keeps the expiry of jobs that were retired before.
+func (j *Janitor) Retire(ctx context.Context, jobID string) error {
+	now := j.now().UTC()
+	job, err := j.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if !job.Status.IsTerminal() {
+			return errNotFinished
+		}
+		if job.ExpiresAt != nil {
+			return nil
+		}
+
+		retention := job.Retention
+		if retention <= 0 {
+			retention = j.opts.Retention
+		}
+		finished := now
+		if job.CompletedAt != nil {
+			finished = *job.CompletedAt
+		}
+
+		expires := finished.Add(retention)
+		job.ExpiresAt = &expires
+		return nil
+	})
+	if errors.Is(err, errNotFinished) || errors.Is(err, redis.ErrJobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := j.store.ScheduleExpiry(ctx, jobID, *job.ExpiresAt); err != nil {
+		return err
+	}
+
+	if j.opts.DeleteChunks {
+		if err := j.storage.DeleteJobChunks(ctx, jobID); err != nil {
+			return err
+		}
+	}
+
+	log.Info("job retired", "job_id", jobID, "expires_at", job.ExpiresAt)
+	return nil
+}
+
+// Sweep retires the jobs that finished over an interval ago and were never
+// retired, the message telling that they finished is sent at most once.
+// It returns how many it retired.
+func (j *Janitor) Sweep(ctx context.Context) (int, error) {
+	unretired, err := j.store.UnretiredJobs(ctx, j.now().Add(-j.opts.Interval), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	retired := 0
+	for _, entry := range unretired {
+		jobCtx := tenant.NewContext(ctx, entry.Tenant)
+		_, err := j.store.GetJob(jobCtx, entry.JobID)
+		if errors.Is(err, redis.ErrJobNotFound) {
+			err = j.store.ForgetUnretiredJob(jobCtx, entry.JobID)
+		} else if err == nil {
+			log.Info("retiring job whose end was missed", "job_id", entry.JobID)
+			if err = j.Retire(jobCtx, entry.JobID); err == nil {
+				retired++
+			}
+		}
+		if err != nil {
+			log.Error("failed to retire job", "tenant", entry.Tenant, "job_id", entry.JobID, "err", err)
+		}
+	}
+	return retired, nil
+}
+
+// Tick purges every job whose expiry is due and returns how many it purged
+func (j *Janitor) Tick(ctx context.Context) (int, error) {
+	now := j.now().UTC()
+	purged := 0
+
+	for {
+		due, err := j.store.DueExpiries(ctx, now, batchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		n := 0
+		for _, entry := range due {
+			if err := j.purge(tenant.NewContext(ctx, entry.Tenant), entry.JobID); err != nil {
+				log.Error("failed to purge expired job", "tenant", entry.Tenant, "job_id", entry.JobID, "err", err)
+				continue
+			}
+			n++
+		}
+		purged += n
+
+		// Entries that failed stay due, they are retried on the next tick
+		if len(due) < batchSize || n == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// purge removes the chunks, results and state of an expired job. Data goes
+// first, a failure leaves the job scheduled so the purge is retried.
+func (j *Janitor) purge(ctx context.Context, jobID string) error {
+	job, err := j.store.GetJob(ctx, jobID)
+	if err != nil && !errors.Is(err, redis.ErrJobNotFound) {
+		return err
+	}
+
+	if err := j.storage.DeleteJob(ctx, jobID); err != nil {
+		return err
+	}
+
+	// Deleted meanwhile or its keys expired on their own, the entries in the
+	// queue, schedule and indexes are still left to remove
+	if job == nil {
+		err = j.store.PurgeMissingJob(ctx, jobID, redis.TombstoneExpired, j.opts.TombstoneTTL)
+	} else {
+		err = j.store.PurgeJob(ctx, job, redis.TombstoneExpired, j.opts.TombstoneTTL)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("expired job purged", "job_id", jobID)
+	return nil
+}
@@ -0,0 +1,262 @@
+package janitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// fakeStorage records which data was deleted
+type fakeStorage struct {
+	mu      sync.Mutex
+	err     error
+	deleted []string // Job IDs, with a chunks: prefix for chunks only
+}
+
+func (s *fakeStorage) DeleteJob(ctx context.Context, jobID string) error {
+	return s.record(jobID)
+}
+
+func (s *fakeStorage) DeleteJobChunks(ctx context.Context, jobID string) error {
+	return s.record("chunks:" + jobID)
+}
+
+func (s *fakeStorage) record(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func newTestJanitor(t *testing.T, opts Options, now time.Time) (*Janitor, *redis.Store, *fakeStorage, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	storage := &fakeStorage{}
+	j := New(store, storage, opts, l)
+	j.now = func() time.Time { return now }
+	return j, store, storage, mr
+}
+
+func saveJob(t *testing.T, store *redis.Store, ctx context.Context, job models.Job) {
+	job.Tenant = tenant.FromContext(ctx)
+	require.NoError(t, store.SaveJob(ctx, &job))
+	require.NoError(t, store.SetStats(ctx, job.ID, models.JobStats{TotalFiles: 1}))
+}
+
+func finished(t *testing.T, j *Janitor, ctx context.Context, jobID string) {
+	data, err := json.Marshal(models.JobFinishedMessage{JobID: jobID, Tenant: tenant.FromContext(ctx), Status: models.JobStatusCompleted})
+	require.NoError(t, err)
+	j.HandleJobFinishedMessage(data)
+}
+
+func TestRetire(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	completed := now.Add(-time.Minute)
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	t.Run("DefaultRetention", func(t *testing.T) {
+		j, store, storage, _ := newTestJanitor(t, Options{Retention: 24 * time.Hour}, now)
+		saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: completed, CompletedAt: &completed})
+
+		finished(t, j, ctx, "grep_1")
+
+		job, err := store.GetJob(ctx, "grep_1")
+		require.NoError(t, err)
+		require.NotNil(t, job.ExpiresAt)
+		assert.Equal(t, completed.Add(24*time.Hour), *job.ExpiresAt)
+		assert.Empty(t, storage.deleted)
+
+		due, err := store.DueExpiries(ctx, completed.Add(24*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []redis.ExpiringJob{{Tenant: "acme", JobID: "grep_1", Due: completed.Add(24 * time.Hour)}}, due)
+
+		// Retiring again keeps the expiry
+		j.now = func() time.Time { return now.Add(time.Hour) }
+		require.NoError(t, j.Retire(ctx, "grep_1"))
+		job, err = store.GetJob(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, completed.Add(24*time.Hour), *job.ExpiresAt)
+	})
+
+	t.Run("JobRetention", func(t *testing.T) {
+		j, store, _, _ := newTestJanitor(t, Options{}, now)
+		saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusFailed, Retention: time.Hour, CreatedAt: completed, CompletedAt: &completed})
+
+		require.NoError(t, j.Retire(ctx, "grep_1"))
+
+		job, err := store.GetJob(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Equal(t, completed.Add(time.Hour), *job.ExpiresAt)
+	})
+
+	t.Run("DeleteChunks", func(t *testing.T) {
+		j, store, storage, _ := newTestJanitor(t, Options{DeleteChunks: true}, now)
+		saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: completed, CompletedAt: &completed})
+
+		require.NoError(t, j.Retire(ctx, "grep_1"))
+		assert.Equal(t, []string{"chunks:grep_1"}, storage.deleted)
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		j, store, storage, _ := newTestJanitor(t, Options{DeleteChunks: true}, now)
+		saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusProcessing, CreatedAt: completed})
+
+		require.NoError(t, j.Retire(ctx, "grep_1"))
+		require.NoError(t, j.Retire(ctx, "grep_missing"))
+
+		job, err := store.GetJob(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Nil(t, job.ExpiresAt)
+		assert.Empty(t, storage.deleted)
+	})
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	completed := now.Add(-time.Hour)
+	acme := tenant.NewContext(context.Background(), "acme")
+
+	j, store, _, mr := newTestJanitor(t, Options{Retention: 24 * time.Hour, Interval: time.Minute}, now)
+	saveJob(t, store, acme, models.Job{ID: "grep_lost", Status: models.JobStatusProcessing, CreatedAt: completed})
+	saveJob(t, store, acme, models.Job{ID: "grep_gone", Status: models.JobStatusProcessing, CreatedAt: completed})
+	saveJob(t, store, acme, models.Job{ID: "grep_running", Status: models.JobStatusProcessing, CreatedAt: completed})
+	for _, id := range []string{"grep_lost", "grep_gone"} {
+		_, err := store.UpdateJob(acme, id, func(job *models.Job) error {
+			job.Status = models.JobStatusCompleted
+			job.CompletedAt = &completed
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	// The job key vanished without the job being purged
+	mr.Del("tenant:acme:job:grep_gone")
+
+	// Nobody was told that the jobs finished
+	retired, err := j.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, retired)
+	assert.False(t, mr.Exists(models.UnretiredJobsKey), "jobs that are gone should be forgotten")
+
+	job, err := store.GetJob(acme, "grep_lost")
+	require.NoError(t, err)
+	require.NotNil(t, job.ExpiresAt)
+	assert.Equal(t, completed.Add(24*time.Hour), *job.ExpiresAt)
+
+	job, err = store.GetJob(acme, "grep_running")
+	require.NoError(t, err)
+	assert.Nil(t, job.ExpiresAt)
+
+	due, err := store.DueExpiries(acme, completed.Add(24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []redis.ExpiringJob{{Tenant: "acme", JobID: "grep_lost", Due: completed.Add(24 * time.Hour)}}, due)
+
+	// Retired jobs are not swept again
+	retired, err = j.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, retired)
+}
+
+func TestTick(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	completed := now.Add(-2 * time.Hour)
+	ctx := context.Background()
+	acme := tenant.NewContext(ctx, "acme")
+
+	j, store, storage, _ := newTestJanitor(t, Options{TombstoneTTL: time.Hour}, now)
+	saveJob(t, store, ctx, models.Job{ID: "grep_old", RequestID: "req_1", Status: models.JobStatusCompleted, Retention: time.Hour, CreatedAt: completed, CompletedAt: &completed})
+	saveJob(t, store, acme, models.Job{ID: "grep_new", Status: models.JobStatusCompleted, CreatedAt: completed, CompletedAt: &completed})
+	require.NoError(t, j.Retire(ctx, "grep_old"))
+	require.NoError(t, j.Retire(acme, "grep_new"))
+
+	purged, err := j.Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, []string{"grep_old"}, storage.deleted)
+
+	_, err = store.GetJob(ctx, "grep_old")
+	assert.ErrorIs(t, err, redis.ErrJobExpired)
+	page, err := store.ListJobs(ctx, redis.JobQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Jobs)
+
+	_, err = store.GetJob(acme, "grep_new")
+	assert.NoError(t, err)
+
+	// Nothing is left to do
+	purged, err = j.Tick(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
+
+func TestTickRetriesFailures(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	completed := now.Add(-2 * time.Hour)
+	ctx := context.Background()
+
+	j, store, storage, _ := newTestJanitor(t, Options{Retention: time.Hour}, now)
+	saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: completed, CompletedAt: &completed})
+	require.NoError(t, j.Retire(ctx, "grep_1"))
+
+	storage.err = errors.New("minio down")
+	purged, err := j.Tick(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	_, err = store.GetJob(ctx, "grep_1")
+	require.NoError(t, err)
+
+	storage.err = nil
+	purged, err = j.Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestTickExpiredKeys(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	completed := now.Add(-2 * time.Hour)
+	ctx := context.Background()
+
+	j, store, storage, mr := newTestJanitor(t, Options{Retention: time.Hour}, now)
+	saveJob(t, store, ctx, models.Job{ID: "grep_1", Status: models.JobStatusCompleted, CreatedAt: completed, CompletedAt: &completed})
+	require.NoError(t, j.Retire(ctx, "grep_1"))
+
+	// No janitor ran before the keys expired, the data is still removed
+	mr.Del("job:grep_1")
+
+	purged, err := j.Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, []string{"grep_1"}, storage.deleted)
+
+	due, err := store.DueExpiries(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	_, err = store.GetJob(ctx, "grep_1")
+	assert.ErrorIs(t, err, redis.ErrJobExpired)
+	for _, index := range []string{"jobs:by_created", "jobs:by_status:COMPLETED"} {
+		assert.False(t, mr.Exists(index), index)
+	}
+}
@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/schedule"
+	"github.com/swarit-pandey/distributed-grep/common/submit"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// DefaultInterval is how often the schedule is checked for due searches
+const DefaultInterval = 15 * time.Second
+
+// batchSize is the number of due searches fetched per round trip
+const batchSize = 100
+
+// errNotDue aborts claiming a search another manager already ran
+var errNotDue = errors.New("search not due")
+
+// Store keeps saved searches and jobs, implemented by redis.Store
+type Store interface {
+	submit.Store
+	DueSearches(ctx context.Context, now time.Time, limit int) ([]redis.ScheduledSearch, error)
+	UpdateSearch(ctx context.Context, searchID string, fn func(search *models.SavedSearch) error) (*models.SavedSearch, error)
+	SaveSearchRun(ctx context.Context, searchID string, run models.SearchRun) error
+}
+
+// Scheduler creates jobs for saved searches whenever their schedule is due.
+// Several managers may run a Scheduler against the same store, each run is
+// claimed by exactly one of them.
+type Scheduler struct {
+	store    Store
+	submit   *submit.Submitter
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New returns a new Scheduler
+func New(store Store, files submit.FileLister, bus submit.Publisher, log *logger.Logger) *Scheduler {
+	InitLogger(log)
+
+	s := &Scheduler{
+		store:    store,
+		submit:   submit.New(store, files, bus, log),
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+	s.submit.SetClock(func() time.Time { return s.now() })
+	return s
+}
+
+// SetInterval changes how often the schedule is checked
+func (s *Scheduler) SetInterval(interval time.Duration) {
+	s.interval = interval
+}
+
+// SetDefaultLimits sets the limits runs of searches saved without limits of
+// their own are held to
+func (s *Scheduler) SetDefaultLimits(limits models.Limits) {
+	s.submit.SetDefaultLimits(limits)
+}
+
+// Run checks the schedule every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	log.Info("scheduler started", "interval", s.interval)
+	for {
+		if _, err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Error("failed to run scheduled searches", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs every search that is due and returns how many jobs it created.
+// A search that was due several times while no manager was running is only
+// run once, its next run is taken from the schedule after now.
+func (s *Scheduler) Tick(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	created := 0
+
+	for {
+		due, err := s.store.DueSearches(ctx, now, batchSize)
+		if err != nil {
+			return created, err
+		}
+
+		for _, entry := range due {
+			ok, err := s.runSearch(tenant.NewContext(ctx, entry.Tenant), entry.ID, now)
+			if err != nil {
+				log.Error("failed to run scheduled search", "tenant", entry.Tenant, "search_id", entry.ID, "err", err)
+				continue
+			}
+			if ok {
+				created++
+			}
+		}
+
+		// Every entry fetched was either moved past now or dropped
+		if len(due) < batchSize {
+			return created, nil
+		}
+	}
+}
+
+// runSearch claims the due run of a search and creates its job, it reports
+// false if another manager got to it first
+func (s *Scheduler) runSearch(ctx context.Context, searchID string, now time.Time) (bool, error) {
+	claimed := false
+	search, err := s.store.UpdateSearch(ctx, searchID, func(search *models.SavedSearch) error {
+		claimed = false
+		if search.NextRunAt == nil || search.NextRunAt.After(now) {
+			return errNotDue
+		}
+
+		next, err := schedule.Next(search.Schedule, now)
+		if err != nil {
+			// Stop scheduling it instead of retrying on every tick
+			log.Warn("unscheduling search with invalid schedule", "search_id", search.ID, "schedule", search.Schedule, "err", err)
+			search.NextRunAt = nil
+			return nil
+		}
+
+		search.NextRunAt = &next
+		search.LastRunAt = &now
+		claimed = true
+		return nil
+	})
+	if errors.Is(err, errNotDue) || errors.Is(err, redis.ErrSearchNotFound) {
+		return false, nil
+	}
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	job, err := s.createJob(ctx, search)
+	if err != nil {
+		return false, err
+	}
+
+	run := models.SearchRun{JobID: job.ID, Scheduled: true, Status: job.Status, CreatedAt: job.CreatedAt, CompletedAt: job.CompletedAt}
+	if err := s.store.SaveSearchRun(ctx, search.ID, run); err != nil {
+		return true, err
+	}
+
+	log.Info("scheduled search run", "tenant", search.Tenant, "search_id", search.ID, "job_id", job.ID, "status", job.Status)
+	return true, nil
+}
+
+// createJob creates a job for a run of the search through the same checks as
+// jobs submitted through the API, held to the limits saved with the search and
+// counted against the API key that saved it. A
+// run that is refused is recorded as failed so that it shows up in the history
+// of the search.
+func (s *Scheduler) createJob(ctx context.Context, search *models.SavedSearch) (*models.Job, error) {
+	if search.Limits != nil {
+		ctx = submit.WithLimits(ctx, *search.Limits)
+	}
+	if search.KeyID != "" {
+		ctx = submit.WithKey(ctx, search.KeyID)
+	}
+
+	job, err := s.submit.Create(ctx, search.Job())
+	if err == nil {
+		return job, nil
+	}
+
+	var limitErr *submit.LimitError
+	if !errors.Is(err, submit.ErrInvalidRequest) && !errors.Is(err, submit.ErrFileNotFound) && !errors.As(err, &limitErr) {
+		return nil, err
+	}
+	return s.submit.Reject(ctx, search.Job(), err)
+}
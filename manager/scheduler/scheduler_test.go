@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+type fakeFiles []models.LogFile
+
+func (f fakeFiles) ListLogFiles(ctx context.Context) ([]models.LogFile, error) {
+	return f, nil
+}
+
+type fakeBus struct {
+	mu   sync.Mutex
+	jobs []models.Job
+}
+
+func (b *fakeBus) Publish(ctx context.Context, subject string, msg any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if subject == nats.SubjectJobs {
+		b.jobs = append(b.jobs, msg.(models.JobMessage).Job)
+	}
+	return nil
+}
+
+func (b *fakeBus) published() []models.Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]models.Job(nil), b.jobs...)
+}
+
+func newTestScheduler(t *testing.T, now time.Time) (*Scheduler, *redis.Store, *fakeBus) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	files := fakeFiles{{Path: "logs/app.log"}, {Path: "logs/kube.log"}}
+	bus := &fakeBus{}
+
+	s := New(store, files, bus, l)
+	s.now = func() time.Time { return now }
+	return s, store, bus
+}
+
+func saveSearch(t *testing.T, store *redis.Store, ctx context.Context, id string, files []string, next time.Time) {
+	require.NoError(t, store.SaveSearch(ctx, &models.SavedSearch{
+		ID:        id,
+		Tenant:    tenant.FromContext(ctx),
+		Name:      id,
+		Pattern:   "OOMKilled",
+		Files:     files,
+		Schedule:  "0 8 * * *",
+		NextRunAt: &next,
+	}))
+}
+
+func TestTick(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 5, 0, time.UTC)
+	s, store, bus := newTestScheduler(t, now)
+
+	teamA := tenant.NewContext(context.Background(), "team-a")
+	saveSearch(t, store, teamA, "search_due", []string{"logs/*.log"}, now.Add(-5*time.Second))
+	saveSearch(t, store, teamA, "search_later", []string{"logs/*.log"}, now.Add(time.Hour))
+
+	created, err := s.Tick(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	jobs := bus.published()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "team-a", jobs[0].Tenant)
+	assert.Equal(t, "search_due", jobs[0].SearchID)
+	assert.Equal(t, []string{"logs/app.log", "logs/kube.log"}, jobs[0].Files)
+
+	stored, err := store.GetJob(teamA, jobs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusPending, stored.Status)
+
+	search, err := store.GetSearch(teamA, "search_due")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC), *search.NextRunAt)
+	assert.Equal(t, now, *search.LastRunAt)
+
+	runs, err := store.ListSearchRuns(teamA, "search_due", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, jobs[0].ID, runs[0].JobID)
+	assert.True(t, runs[0].Scheduled)
+
+	// Nothing is due anymore
+	created, err = s.Tick(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, created)
+}
+
+func TestTickUnresolvedFiles(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	s, store, bus := newTestScheduler(t, now)
+
+	ctx := context.Background()
+	saveSearch(t, store, ctx, "search_1", []string{"gone/*.log"}, now)
+
+	created, err := s.Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+	assert.Empty(t, bus.published())
+
+	runs, err := store.ListSearchRuns(ctx, "search_1", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobStatusFailed, runs[0].Status)
+
+	job, err := store.GetJob(ctx, runs[0].JobID)
+	require.NoError(t, err)
+	assert.Contains(t, job.Error, "gone/*.log")
+}
+
+func TestTickClaimsEachRunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	s, store, bus := newTestScheduler(t, now)
+
+	ctx := context.Background()
+	for _, id := range []string{"search_1", "search_2", "search_3"} {
+		saveSearch(t, store, ctx, id, []string{"logs/app.log"}, now)
+	}
+
+	// A second manager sharing the store and the bus
+	other := New(store, fakeFiles{{Path: "logs/app.log"}}, bus, log)
+	other.now = s.now
+
+	var wg sync.WaitGroup
+	for _, sched := range []*Scheduler{s, other} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sched.Tick(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, bus.published(), 3)
+}
+
+func TestTickHoldsRunsToSavedLimits(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	s, store, bus := newTestScheduler(t, now)
+
+	ctx := context.Background()
+	for _, id := range []string{"search_1", "search_2"} {
+		require.NoError(t, store.SaveSearch(ctx, &models.SavedSearch{
+			ID:        id,
+			Name:      id,
+			Pattern:   "OOMKilled",
+			Files:     []string{"logs/app.log"},
+			Schedule:  "0 8 * * *",
+			NextRunAt: &now,
+			Limits:    &models.Limits{JobsPerMinute: 1},
+		}))
+	}
+
+	created, err := s.Tick(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+	assert.Len(t, bus.published(), 1)
+
+	var statuses []models.JobStatus
+	for _, id := range []string{"search_1", "search_2"} {
+		runs, err := store.ListSearchRuns(ctx, id, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		statuses = append(statuses, runs[0].Status)
+
+		if runs[0].Status == models.JobStatusFailed {
+			job, err := store.GetJob(ctx, runs[0].JobID)
+			require.NoError(t, err)
+			assert.Contains(t, job.Error, "jobs may be submitted per minute")
+		}
+	}
+	assert.ElementsMatch(t, []models.JobStatus{models.JobStatusPending, models.JobStatusFailed}, statuses)
+
+	// A search saved without limits is held to the defaults of the manager
+	s.SetDefaultLimits(models.Limits{MaxRunningJobs: 1})
+	saveSearch(t, store, ctx, "search_3", []string{"logs/app.log"}, now)
+
+	_, err = s.Tick(ctx)
+	require.NoError(t, err)
+
+	runs, err := store.ListSearchRuns(ctx, "search_3", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobStatusFailed, runs[0].Status)
+}
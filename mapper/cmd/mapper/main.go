@@ -1 +1,146 @@
 package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/config"
+	"github.com/swarit-pandey/distributed-grep/common/health"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/minio"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/mapper/worker"
+)
+
+// Config holds everything the mapper needs to run
+type Config struct {
+	// Addr is where /metrics, /healthz and /readyz are served
+	Addr string `mapstructure:"addr"`
+
+	MinIO   minio.MinOptions     `mapstructure:"minio"`
+	Storage minio.StorageOptions `mapstructure:"storage"`
+	Redis   redis.RedisOptions   `mapstructure:"redis"`
+	NATS    nats.NatsOptions     `mapstructure:"nats"`
+
+	// Worker tunes how chunks are searched
+	Worker worker.Options `mapstructure:"worker"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Addr: ":8082",
+		MinIO: minio.MinOptions{
+			Endpoint: "localhost:9000",
+		},
+		Storage: minio.StorageOptions{
+			Buckets: []minio.BucketOptions{
+				{Name: "logs", Type: minio.TextType, Category: minio.LogStorage},
+				{Name: "chunks", Type: minio.TextType, Category: minio.ChunkStorage},
+				{Name: "results", Type: minio.JSONType, Category: minio.ResultStorage},
+			},
+		},
+		Redis: redis.RedisOptions{
+			Addr: "localhost:6379",
+		},
+		NATS: nats.NatsOptions{
+			URL:  "nats://localhost:4222",
+			Name: "mapper",
+		},
+		Worker: worker.Options{
+			ChunkTimeout: worker.DefaultChunkTimeout,
+		},
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to the YAML config file")
+	flag.Parse()
+
+	log := logger.New()
+
+	cfg := defaultConfig()
+	if err := config.Load(*configPath, "MAPPER", &cfg); err != nil {
+		log.Error("failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, log); err != nil {
+		log.Error("mapper exited with error", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg Config, log *logger.Logger) error {
+	storage, err := minio.New(cfg.MinIO.Endpoint, cfg.MinIO.AccessKeyID, cfg.MinIO.SecretAccessKey, cfg.MinIO.SSL, log, &cfg.Storage)
+	if err != nil {
+		return err
+	}
+	if err := storage.Instantiate(ctx); err != nil {
+		return err
+	}
+
+	store := redis.New(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, log)
+	if err := store.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer store.Close()
+
+	bus := nats.New(cfg.NATS.URL, cfg.NATS.Name, log)
+	if err := bus.Instantiate(ctx); err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	// Every chunk is searched by one of the mappers, each subscription
+	// searches one chunk at a time
+	w := worker.New(store, storage, bus, cfg.Worker, log)
+	for range w.Concurrency() {
+		sub, err := bus.QueueSubscribe(nats.SubjectChunks, "mapper", w.HandleChunkMessage)
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+	}
+
+	checker := health.New()
+	checker.Add("minio", storage)
+	checker.Add("redis", store)
+	checker.Add("nats", bus)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	checker.Register(mux)
+	srv := &http.Server{Addr: cfg.Addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("mapper listening", "addr", cfg.Addr, "concurrency", w.Concurrency())
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down mapper")
+	checker.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+// outcome is what searching a chunk found
+type outcome struct {
+	matches  []models.Match // Not collected in counting modes
+	selected int            // Lines selected
+	lines    int            // Lines of the chunk read
+}
+
+// search selects the lines of a chunk read from r, which starts ContextBytes
+// before the first byte of the chunk. With a filter only lines logged within
+// its range are selected. Lines before and past the end of the chunk are only
+// read as context of its first and last matches. Reading stops once MaxCount
+// lines were selected and took their context, so the lines after them are
+// not counted as read.
+func search(r io.Reader, msg *models.ChunkMessage, grep *pattern.Grep, filter *timestamp.Filter) (outcome, error) {
+	var (
+		out      outcome
+		before   []string // Last ContextLines lines, for the next match
+		open     []int    // Matches still taking lines after them
+		consumed int64
+	)
+
+	counting := msg.Mode.Counts()
+	lineNumber := msg.StartLine
+	br := bufio.NewReaderSize(r, 64<<10)
+
+	// keep remembers a line as context of the next match
+	keep := func(line string) {
+		if msg.ContextLines == 0 {
+			return
+		}
+		if len(before) == msg.ContextLines {
+			before = before[1:]
+		}
+		before = append(before, line)
+	}
+
+	// The lines before the chunk are context of its first matches
+	for consumed < msg.ContextBytes {
+		text, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return outcome{}, err
+		}
+		if text == "" {
+			break
+		}
+		consumed += int64(len(text))
+		keep(strings.TrimSuffix(text, "\n"))
+	}
+	consumed = 0
+
+	for consumed < msg.Size || len(open) > 0 {
+		if grep.Done() && len(open) == 0 {
+			break
+		}
+		text, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return outcome{}, err
+		}
+		if text == "" {
+			break
+		}
+		inChunk := consumed < msg.Size
+		consumed += int64(len(text))
+		line := strings.TrimSuffix(text, "\n")
+
+		// The line follows the matches before it
+		kept := open[:0]
+		for _, i := range open {
+			m := &out.matches[i]
+			m.Context.After = append(m.Context.After, line)
+			if len(m.Context.After) < msg.ContextLines {
+				kept = append(kept, i)
+			}
+		}
+		open = kept
+
+		if !inChunk {
+			continue
+		}
+		out.lines++
+
+		var (
+			logged *time.Time
+			sel    pattern.Selection
+			ok     = true
+		)
+		if filter != nil {
+			var t time.Time
+			var known bool
+			t, known, ok = filter.Line(line)
+			if known {
+				logged = &t
+			}
+		}
+		if ok {
+			// Lines logged out of range do not count towards MaxCount
+			sel, ok = grep.Select(line)
+		}
+
+		if ok {
+			out.selected++
+			if !counting {
+				out.matches = append(out.matches, models.Match{
+					LineNumber:      lineNumber,
+					Content:         line,
+					FileName:        msg.FileName,
+					Context:         models.Context{Before: append([]string(nil), before...)},
+					Timestamp:       logged,
+					MatchedPatterns: sel.Labels,
+					Parts:           sel.Parts,
+				})
+				if msg.ContextLines > 0 {
+					open = append(open, len(out.matches)-1)
+				}
+			}
+		}
+
+		keep(line)
+		lineNumber++
+	}
+
+	return out, nil
+}
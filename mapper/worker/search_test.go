@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+)
+
+const testLog = `boot ok
+request error: timeout
+retrying
+request error: refused
+giving up
+shutdown`
+
+const timedLog = `2024-01-02T02:05:00Z request error: timeout
+2024-01-02T02:15:00Z request ok
+java.lang.RuntimeException
+	at handler error wrapped
+2024-01-02T02:45:00Z request error: refused`
+
+func timeAt(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      models.ChunkMessage
+		content  string
+		selected int
+		lines    int
+		matches  []models.Match
+	}{
+		{
+			name:     "Lines",
+			msg:      models.ChunkMessage{Pattern: "error"},
+			content:  testLog,
+			selected: 2,
+			lines:    6,
+			matches: []models.Match{
+				{LineNumber: 2, Content: "request error: timeout", FileName: "app.log"},
+				{LineNumber: 4, Content: "request error: refused", FileName: "app.log"},
+			},
+		},
+		{
+			name:     "NumberedFromStartLine",
+			msg:      models.ChunkMessage{Chunk: models.Chunk{StartLine: 101}, Pattern: "giving"},
+			content:  testLog,
+			selected: 1,
+			lines:    6,
+			matches:  []models.Match{{LineNumber: 105, Content: "giving up", FileName: "app.log"}},
+		},
+		{
+			name:     "Context",
+			msg:      models.ChunkMessage{Pattern: "refused", ContextLines: 2},
+			content:  testLog,
+			selected: 1,
+			lines:    6,
+			matches: []models.Match{{
+				LineNumber: 4, Content: "request error: refused", FileName: "app.log",
+				Context: models.Context{Before: []string{"request error: timeout", "retrying"}, After: []string{"giving up", "shutdown"}},
+			}},
+		},
+		{
+			name:     "ContextPastChunkEnd",
+			msg:      models.ChunkMessage{Chunk: models.Chunk{Size: 40}, Pattern: "timeout", ContextLines: 3},
+			content:  testLog,
+			selected: 1,
+			lines:    3,
+			matches: []models.Match{{
+				LineNumber: 2, Content: "request error: timeout", FileName: "app.log",
+				Context: models.Context{Before: []string{"boot ok"}, After: []string{"retrying", "request error: refused", "giving up"}},
+			}},
+		},
+		{
+			name: "ContextFromChunkBefore",
+			msg: models.ChunkMessage{
+				Chunk:   models.Chunk{StartLine: 3, Size: 50, ContextBytes: 31},
+				Pattern: "refused", ContextLines: 2,
+			},
+			content:  testLog,
+			selected: 1,
+			lines:    4,
+			matches: []models.Match{{
+				LineNumber: 4, Content: "request error: refused", FileName: "app.log",
+				Context: models.Context{Before: []string{"request error: timeout", "retrying"}, After: []string{"giving up", "shutdown"}},
+			}},
+		},
+		{
+			name:     "ChunkEnd",
+			msg:      models.ChunkMessage{Chunk: models.Chunk{Size: 40}, Pattern: "error"},
+			content:  testLog,
+			selected: 1,
+			lines:    3,
+			matches:  []models.Match{{LineNumber: 2, Content: "request error: timeout", FileName: "app.log"}},
+		},
+		{
+			name: "Query",
+			msg: models.ChunkMessage{Query: &models.Query{Op: models.QueryOpAnd, Children: []models.Query{
+				{ID: "error", Pattern: "error"},
+				{Op: models.QueryOpNot, Children: []models.Query{{Pattern: "timeout"}}},
+			}}},
+			content:  testLog,
+			selected: 1,
+			lines:    6,
+			matches:  []models.Match{{LineNumber: 4, Content: "request error: refused", FileName: "app.log", MatchedPatterns: []string{"error"}}},
+		},
+		{
+			name:     "OnlyMatching",
+			msg:      models.ChunkMessage{Pattern: "re[a-z]+", Regex: true, OnlyMatching: true},
+			content:  "request refused\nok",
+			selected: 1,
+			lines:    2,
+			matches:  []models.Match{{LineNumber: 1, Content: "request refused", FileName: "app.log", Parts: []string{"request", "refused"}}},
+		},
+		{
+			name: "TimeRange",
+			msg: models.ChunkMessage{Pattern: "error", MaxCount: 1, TimeRange: &models.TimeRange{
+				Since: timeAt("2024-01-02T02:10:00Z"),
+				Until: timeAt("2024-01-02T02:40:00Z"),
+			}},
+			content:  timedLog,
+			selected: 1,
+			lines:    4,
+			matches: []models.Match{{
+				LineNumber: 4, Content: "\tat handler error wrapped", FileName: "app.log",
+				Timestamp: timeAt("2024-01-02T02:15:00Z"),
+			}},
+		},
+		{
+			name:     "MaxCount",
+			msg:      models.ChunkMessage{Pattern: "error", MaxCount: 1, ContextLines: 1},
+			content:  testLog,
+			selected: 1,
+			lines:    3,
+			matches: []models.Match{{
+				LineNumber: 2, Content: "request error: timeout", FileName: "app.log",
+				Context: models.Context{Before: []string{"boot ok"}, After: []string{"retrying"}},
+			}},
+		},
+		{
+			name:     "SettledByFirstMatch",
+			msg:      models.ChunkMessage{Pattern: "error", Mode: models.SearchModeFilesWithMatches},
+			content:  testLog,
+			selected: 1,
+			lines:    2,
+		},
+		{
+			name: "InheritedTime",
+			msg: models.ChunkMessage{
+				Chunk:   models.Chunk{StartLine: 3, Inherited: timeAt("2024-01-02T02:15:00Z")},
+				Pattern: "error",
+				TimeRange: &models.TimeRange{
+					Since: timeAt("2024-01-02T02:10:00Z"),
+					Until: timeAt("2024-01-02T02:40:00Z"),
+				},
+			},
+			content:  timedLog[strings.Index(timedLog, "java"):],
+			selected: 1,
+			lines:    3,
+			matches: []models.Match{{
+				LineNumber: 4, Content: "\tat handler error wrapped", FileName: "app.log",
+				Timestamp: timeAt("2024-01-02T02:15:00Z"),
+			}},
+		},
+		{
+			name:     "Count",
+			msg:      models.ChunkMessage{Pattern: "re", Mode: models.SearchModeCount},
+			content:  testLog,
+			selected: 3,
+			lines:    6,
+		},
+		{
+			name:     "InvertMatch",
+			msg:      models.ChunkMessage{Pattern: "error", InvertMatch: true, Mode: models.SearchModeCount},
+			content:  testLog,
+			selected: 4,
+			lines:    6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.msg
+			msg.FileName = "app.log"
+			if msg.StartLine == 0 {
+				msg.StartLine = 1
+			}
+			if msg.Size == 0 {
+				msg.Size = int64(len(tt.content))
+			}
+
+			grep, err := pattern.NewChunkGrep(&msg)
+			require.NoError(t, err)
+
+			filter, err := newFilter(&msg)
+			require.NoError(t, err)
+
+			out, err := search(strings.NewReader(tt.content), &msg, grep, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.selected, out.selected)
+			assert.Equal(t, tt.lines, out.lines)
+			assert.Equal(t, tt.matches, out.matches)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/metrics"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/pattern"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+	"github.com/swarit-pandey/distributed-grep/common/timestamp"
+)
+
+// Setting up logger
+var log *logger.Logger
+
+func InitLogger(l *logger.Logger) {
+	if l != nil {
+		log = l
+	} else {
+		log = logger.New()
+	}
+}
+
+// DefaultChunkTimeout matches the chunk timeout of the manager
+const DefaultChunkTimeout = 5 * time.Minute
+
+// Options tune how chunks are searched
+type Options struct {
+	// Concurrency is the number of chunks searched at a time, by default one
+	// per CPU
+	Concurrency int `mapstructure:"concurrency"`
+
+	// ChunkTimeout is how long a chunk is recorded as being searched when
+	// the mapper dies before it is done, keep it at the manager's
+	ChunkTimeout time.Duration `mapstructure:"chunkTimeout"`
+}
+
+func (o Options) withDefaults() Options {
+	if o.Concurrency <= 0 {
+		o.Concurrency = runtime.NumCPU()
+	}
+	if o.ChunkTimeout <= 0 {
+		o.ChunkTimeout = DefaultChunkTimeout
+	}
+	return o
+}
+
+// Store keeps job state and the chunks being searched, implemented by
+// redis.Store
+type Store interface {
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	StartChunk(ctx context.Context, jobID, chunkID string, ttl time.Duration) error
+	FinishChunk(ctx context.Context, jobID, chunkID string) error
+	MarkFileMatched(ctx context.Context, jobID, file string) (bool, error)
+	IsFileMatched(ctx context.Context, jobID, file string) (bool, error)
+}
+
+// Storage reads log files and keeps results, implemented by minio.Storage
+type Storage interface {
+	OpenLogFile(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+	StoreResult(ctx context.Context, result models.Result) error
+}
+
+// Publisher sends messages to the other services, implemented by nats.Bus
+type Publisher interface {
+	Publish(ctx context.Context, subject string, msg any) error
+}
+
+// Worker searches the chunks dispatched by the manager. The result of a chunk
+// is stored before it is published, and carries the ID of the chunk so that
+// a chunk searched twice after a timeout has a single result. Chunks are
+// recorded while they are searched, deleting a job waits for them.
+type Worker struct {
+	store   Store
+	storage Storage
+	bus     Publisher
+	opts    Options
+	now     func() time.Time
+}
+
+// New returns a new Worker, zero fields of opts take their defaults
+func New(store Store, storage Storage, bus Publisher, opts Options, log *logger.Logger) *Worker {
+	InitLogger(log)
+
+	return &Worker{
+		store:   store,
+		storage: storage,
+		bus:     bus,
+		opts:    opts.withDefaults(),
+		now:     time.Now,
+	}
+}
+
+// Concurrency returns the number of chunks searched at a time, subscribe
+// HandleChunkMessage to nats.SubjectChunks that many times
+func (w *Worker) Concurrency() int {
+	return w.opts.Concurrency
+}
+
+// HandleChunkMessage is the NATS handler for dispatched chunks
+func (w *Worker) HandleChunkMessage(data []byte) {
+	var msg models.ChunkMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn("dropping malformed chunk message", "err", err)
+		return
+	}
+
+	ctx := tenant.NewContext(context.Background(), msg.Tenant)
+	if err := w.Process(ctx, &msg); err != nil {
+		// The manager hands the chunk out again once it times out
+		log.Error("failed to process chunk", "job_id", msg.JobID, "chunk_id", msg.ID, "err", err)
+	}
+}
+
+// newFilter returns the filter of the time range of a chunk, nil without a
+// range. Syslog timestamps, which lack a year, are placed in the year leading
+// up to the creation of the chunk. The first lines of the chunk inherit the
+// time of the lines before it.
+func newFilter(msg *models.ChunkMessage) (*timestamp.Filter, error) {
+	if msg.TimeRange == nil {
+		return nil, nil
+	}
+
+	filter, err := timestamp.NewFilter(msg.TimeRange, msg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Inherited != nil {
+		filter.Inherit(*msg.Inherited)
+	}
+	return filter, nil
+}
+
+// Process searches a chunk, stores its result and publishes it to
+// nats.SubjectResults. The chunk belongs to the tenant ctx is scoped to.
+// Chunks of jobs that finished or are gone are dropped. In modes settled by
+// the first match, the first mapper to find a line of a file announces it on
+// nats.SubjectFileMatched and later chunks of the file are reported without
+// being searched.
+func (w *Worker) Process(ctx context.Context, msg *models.ChunkMessage) error {
+	start := time.Now()
+
+	// Recorded before the job is checked, a delete either sees the chunk or
+	// has cancelled the job by the time it is checked
+	if err := w.store.StartChunk(ctx, msg.JobID, msg.ID, w.opts.ChunkTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.store.FinishChunk(context.WithoutCancel(ctx), msg.JobID, msg.ID); err != nil {
+			log.Warn("failed to finish chunk", "job_id", msg.JobID, "chunk_id", msg.ID, "err", err)
+		}
+	}()
+
+	if active, err := w.active(ctx, msg); err != nil || !active {
+		return err
+	}
+
+	settled := msg.Mode.SettledByFirstMatch()
+	if settled {
+		matched, err := w.store.IsFileMatched(ctx, msg.JobID, msg.FileName)
+		if err != nil {
+			return err
+		}
+		if matched {
+			log.Debug("skipping chunk of matched file", "job_id", msg.JobID, "chunk_id", msg.ID, "file", msg.FileName)
+			return w.report(ctx, msg, outcome{}, start)
+		}
+	}
+
+	grep, err := pattern.NewChunkGrep(msg)
+	if err != nil {
+		return fmt.Errorf("failed to compile pattern: %w", err)
+	}
+
+	filter, err := newFilter(msg)
+	if err != nil {
+		return fmt.Errorf("failed to parse time range: %w", err)
+	}
+
+	// Context before the first lines of the chunk is read from the one before
+	// it, context after its last lines from the next one
+	length := msg.ContextBytes + msg.Size
+	if msg.ContextLines > 0 {
+		length = 0
+	}
+	r, err := w.storage.OpenLogFile(ctx, msg.FileName, msg.StartByte-msg.ContextBytes, length)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", msg.FileName, err)
+	}
+	defer r.Close()
+
+	out, err := search(r, msg, grep, filter)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", msg.FileName, err)
+	}
+
+	if err := w.report(ctx, msg, out, start); err != nil {
+		return err
+	}
+
+	if settled && out.selected > 0 {
+		w.announce(ctx, msg)
+	}
+	return nil
+}
+
+// report stores the result of a chunk and publishes it
+func (w *Worker) report(ctx context.Context, msg *models.ChunkMessage, out outcome, start time.Time) error {
+	result := models.Result{
+		ID:             msg.ID,
+		JobID:          msg.JobID,
+		Tenant:         msg.Tenant,
+		ChunkID:        msg.ID,
+		Matches:        out.matches,
+		CreatedAt:      w.now().UTC(),
+		ProcessedBytes: msg.Size,
+		ProcessedLines: out.lines,
+		MatchCount:     out.selected,
+	}
+	if msg.Mode.Counts() {
+		result.Counts = map[string]int{msg.FileName: out.selected}
+	}
+
+	// The job may have been cancelled or deleted while the chunk was searched,
+	// a result stored after its job was purged would never be removed
+	if active, err := w.active(ctx, msg); err != nil || !active {
+		return err
+	}
+	if err := w.storage.StoreResult(ctx, result); err != nil {
+		return err
+	}
+	if active, err := w.active(ctx, msg); err != nil || !active {
+		return err
+	}
+
+	stats := models.JobStats{TotalMatches: out.selected, BytesProcessed: msg.Size}
+	if err := w.bus.Publish(ctx, nats.SubjectResults, result.Message(stats)); err != nil {
+		return fmt.Errorf("failed to publish result of chunk %s: %w", msg.ID, err)
+	}
+
+	metrics.ObserveChunk(msg.Size, out.selected, time.Since(start))
+	log.Debug("chunk processed", "job_id", msg.JobID, "chunk_id", msg.ID, "selected", out.selected)
+	return nil
+}
+
+// active reports whether the job of a chunk is still around and not
+// finished, chunks of other jobs are dropped
+func (w *Worker) active(ctx context.Context, msg *models.ChunkMessage) (bool, error) {
+	job, err := w.store.GetJob(ctx, msg.JobID)
+	if errors.Is(err, redis.ErrJobNotFound) {
+		log.Debug("dropping chunk of missing job", "job_id", msg.JobID, "chunk_id", msg.ID)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if job.Status.IsTerminal() {
+		log.Debug("dropping chunk of finished job", "job_id", msg.JobID, "chunk_id", msg.ID, "status", job.Status)
+		return false, nil
+	}
+	return true, nil
+}
+
+// announce tells the manager and the other mappers that the file of a chunk
+// has a selected line, unless another chunk of the file did first. Failing to
+// do so only costs searching the rest of the file.
+func (w *Worker) announce(ctx context.Context, msg *models.ChunkMessage) {
+	first, err := w.store.MarkFileMatched(ctx, msg.JobID, msg.FileName)
+	if err != nil {
+		log.Warn("failed to mark file as matched", "job_id", msg.JobID, "file", msg.FileName, "err", err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	matched := models.FileMatchedMessage{JobID: msg.JobID, Tenant: msg.Tenant, FileName: msg.FileName}
+	if err := w.bus.Publish(ctx, nats.SubjectFileMatched, matched); err != nil {
+		log.Warn("failed to announce matched file", "job_id", msg.JobID, "file", msg.FileName, "err", err)
+	}
+}
@@ -0,0 +1,332 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swarit-pandey/distributed-grep/common/logger"
+	"github.com/swarit-pandey/distributed-grep/common/models"
+	"github.com/swarit-pandey/distributed-grep/common/nats"
+	"github.com/swarit-pandey/distributed-grep/common/redis"
+	"github.com/swarit-pandey/distributed-grep/common/tenant"
+)
+
+// events records what the fakes were asked to do, in order
+type events struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.log = append(e.log, event)
+}
+
+type fakeStorage struct {
+	events  *events
+	files   map[string]string
+	lengths []int64
+	results map[string]models.Result
+	onOpen  func(ctx context.Context)
+}
+
+func (s *fakeStorage) OpenLogFile(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	content, ok := s.files[tenant.FromContext(ctx)+"/"+path]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	s.lengths = append(s.lengths, length)
+	if s.onOpen != nil {
+		s.onOpen(ctx)
+	}
+
+	content = content[offset:]
+	if length > 0 {
+		content = content[:length]
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (s *fakeStorage) StoreResult(ctx context.Context, result models.Result) error {
+	s.events.add("store " + result.ID)
+	s.results[result.ID] = result
+	return nil
+}
+
+type fakeBus struct {
+	events  *events
+	results []models.ResultMessage
+	matched []models.FileMatchedMessage
+}
+
+func (b *fakeBus) Publish(ctx context.Context, subject string, msg any) error {
+	switch subject {
+	case nats.SubjectResults:
+		result := msg.(models.ResultMessage)
+		b.events.add("publish " + result.ID)
+		b.results = append(b.results, result)
+	case nats.SubjectFileMatched:
+		b.matched = append(b.matched, msg.(models.FileMatchedMessage))
+	}
+	return nil
+}
+
+func newTestWorker(t *testing.T) (*Worker, *redis.Store, *fakeStorage, *fakeBus, *events) {
+	mr := miniredis.RunT(t)
+
+	l := logger.New()
+	l.SetLevel(logger.SILENT)
+
+	store := redis.New(mr.Addr(), "", 0, l)
+	require.NoError(t, store.Instantiate(context.Background()))
+	t.Cleanup(func() { store.Close() })
+
+	ctx := tenant.NewContext(context.Background(), "acme")
+	require.NoError(t, store.SaveJob(ctx, &models.Job{ID: "grep_1", Tenant: "acme", Status: models.JobStatusProcessing}))
+
+	ev := &events{}
+	storage := &fakeStorage{
+		events:  ev,
+		files:   map[string]string{"acme/logs/app.log": testLog},
+		results: make(map[string]models.Result),
+	}
+	bus := &fakeBus{events: ev}
+
+	w := New(store, storage, bus, Options{Concurrency: 2}, l)
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	return w, store, storage, bus, ev
+}
+
+// chunkMessage returns a message for the chunk of app.log holding lines 2-4
+func chunkMessage() models.ChunkMessage {
+	return models.ChunkMessage{
+		Chunk: models.Chunk{
+			ID: "chunk_1", JobID: "grep_1", Tenant: "acme", FileName: "logs/app.log",
+			StartByte: 8, EndByte: 63, Size: 55, StartLine: 2, EndLine: 4,
+		},
+		Pattern: "error",
+	}
+}
+
+func TestHandleChunkMessage(t *testing.T) {
+	w, _, storage, bus, ev := newTestWorker(t)
+
+	data, err := json.Marshal(chunkMessage())
+	require.NoError(t, err)
+	w.HandleChunkMessage(data)
+
+	assert.Equal(t, []string{"store chunk_1", "publish chunk_1"}, ev.log, "results should be stored before they are published")
+	assert.Equal(t, []int64{55}, storage.lengths)
+
+	require.Len(t, bus.results, 1)
+	assert.Equal(t, models.ResultMessage{
+		ID: "chunk_1", JobID: "grep_1", Tenant: "acme", ChunkID: "chunk_1", MatchCount: 2,
+		Stats: models.JobStats{TotalMatches: 2, BytesProcessed: 55},
+	}, bus.results[0])
+
+	result := storage.results["chunk_1"]
+	assert.Equal(t, "grep_1", result.JobID)
+	assert.Equal(t, "acme", result.Tenant)
+	assert.Equal(t, "chunk_1", result.ChunkID)
+	assert.Equal(t, 3, result.ProcessedLines)
+	assert.Equal(t, 2, result.MatchCount)
+	assert.Equal(t, []models.Match{
+		{LineNumber: 2, Content: "request error: timeout", FileName: "logs/app.log"},
+		{LineNumber: 4, Content: "request error: refused", FileName: "logs/app.log"},
+	}, result.Matches)
+	assert.Nil(t, result.Counts)
+}
+
+func TestProcess(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	t.Run("Count", func(t *testing.T) {
+		w, _, storage, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.Mode = models.SearchModeCount
+		require.NoError(t, w.Process(ctx, &msg))
+
+		require.Len(t, bus.results, 1)
+		assert.Equal(t, 2, bus.results[0].MatchCount)
+		assert.Empty(t, storage.results["chunk_1"].Matches)
+		assert.Equal(t, map[string]int{"logs/app.log": 2}, storage.results["chunk_1"].Counts)
+	})
+
+	t.Run("ContextReadsPastChunk", func(t *testing.T) {
+		w, _, storage, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.Pattern = "refused"
+		msg.ContextLines = 1
+		require.NoError(t, w.Process(ctx, &msg))
+
+		assert.Equal(t, []int64{0}, storage.lengths)
+		require.Len(t, bus.results, 1)
+		result := storage.results["chunk_1"]
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, models.Context{Before: []string{"retrying"}, After: []string{"giving up"}}, result.Matches[0].Context)
+		assert.Equal(t, 3, result.ProcessedLines)
+	})
+
+	t.Run("ContextReadsBeforeChunk", func(t *testing.T) {
+		w, _, storage, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.Pattern = "timeout"
+		msg.ContextLines = 1
+		msg.ContextBytes = 8
+		require.NoError(t, w.Process(ctx, &msg))
+
+		require.Len(t, bus.results, 1)
+		result := storage.results["chunk_1"]
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, models.Context{Before: []string{"boot ok"}, After: []string{"retrying"}}, result.Matches[0].Context)
+		assert.Equal(t, 3, result.ProcessedLines)
+	})
+
+	t.Run("LargeResult", func(t *testing.T) {
+		w, _, storage, bus, _ := newTestWorker(t)
+
+		// More matches than fit in a NATS message, 1MB by default
+		line := "request error: " + strings.Repeat("x", 100) + "\n"
+		content := strings.Repeat(line, 20000)
+		storage.files["acme/logs/big.log"] = content
+
+		msg := chunkMessage()
+		msg.FileName = "logs/big.log"
+		msg.StartByte, msg.Size, msg.StartLine = 0, int64(len(content)), 1
+		require.NoError(t, w.Process(ctx, &msg))
+
+		require.Len(t, bus.results, 1)
+		assert.Equal(t, 20000, bus.results[0].MatchCount)
+		assert.Len(t, storage.results["chunk_1"].Matches, 20000)
+
+		data, err := json.Marshal(bus.results[0])
+		require.NoError(t, err)
+		assert.Less(t, len(data), 1<<10, "result messages should not carry matches")
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		w, _, _, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.FileName = "logs/gone.log"
+		assert.Error(t, w.Process(ctx, &msg))
+		assert.Empty(t, bus.results, "nothing should be reported, the chunk is retried once it times out")
+	})
+
+	t.Run("FileMatched", func(t *testing.T) {
+		w, store, storage, bus, _ := newTestWorker(t)
+
+		first := chunkMessage()
+		first.Mode = models.SearchModeFilesWithMatches
+		require.NoError(t, w.Process(ctx, &first))
+		assert.Equal(t, []models.FileMatchedMessage{{JobID: "grep_1", Tenant: "acme", FileName: "logs/app.log"}}, bus.matched)
+
+		matched, err := store.IsFileMatched(ctx, "grep_1", "logs/app.log")
+		require.NoError(t, err)
+		assert.True(t, matched)
+
+		// Later chunks of the file are reported without being read
+		second := first
+		second.ID = "chunk_2"
+		require.NoError(t, w.Process(ctx, &second))
+		assert.Len(t, storage.lengths, 1)
+		assert.Len(t, bus.matched, 1)
+
+		require.Len(t, bus.results, 2)
+		assert.Equal(t, "chunk_2", bus.results[1].ChunkID)
+		assert.Zero(t, bus.results[1].MatchCount)
+		assert.Equal(t, map[string]int{"logs/app.log": 0}, storage.results["chunk_2"].Counts)
+	})
+
+	t.Run("FileWithoutMatch", func(t *testing.T) {
+		w, _, _, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.Pattern = "panic"
+		msg.Mode = models.SearchModeFilesWithoutMatch
+		require.NoError(t, w.Process(ctx, &msg))
+		assert.Len(t, bus.results, 1)
+		assert.Empty(t, bus.matched)
+	})
+
+	t.Run("TracksChunk", func(t *testing.T) {
+		w, store, storage, bus, _ := newTestWorker(t)
+
+		inFlight := -1
+		storage.onOpen = func(ctx context.Context) {
+			n, err := store.CountInFlightChunks(ctx, "grep_1")
+			require.NoError(t, err)
+			inFlight = n
+		}
+
+		msg := chunkMessage()
+		require.NoError(t, w.Process(ctx, &msg))
+		assert.Len(t, bus.results, 1)
+		assert.Equal(t, 1, inFlight, "the chunk should be recorded while it is searched")
+
+		n, err := store.CountInFlightChunks(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("FinishedJob", func(t *testing.T) {
+		w, store, storage, bus, _ := newTestWorker(t)
+
+		_, err := store.UpdateJob(ctx, "grep_1", func(job *models.Job) error {
+			job.Status = models.JobStatusCancelled
+			return nil
+		})
+		require.NoError(t, err)
+
+		msg := chunkMessage()
+		require.NoError(t, w.Process(ctx, &msg))
+		assert.Empty(t, storage.lengths, "chunks of finished jobs should not be read")
+		assert.Empty(t, bus.results)
+
+		n, err := store.CountInFlightChunks(ctx, "grep_1")
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("CancelledWhileSearched", func(t *testing.T) {
+		w, store, storage, bus, _ := newTestWorker(t)
+
+		storage.onOpen = func(ctx context.Context) {
+			_, err := store.UpdateJob(ctx, "grep_1", func(job *models.Job) error {
+				job.Status = models.JobStatusCancelled
+				return nil
+			})
+			require.NoError(t, err)
+		}
+
+		msg := chunkMessage()
+		require.NoError(t, w.Process(ctx, &msg))
+		assert.Len(t, storage.lengths, 1)
+		assert.Empty(t, storage.results, "results of jobs that went away should not be stored")
+		assert.Empty(t, bus.results)
+	})
+
+	t.Run("MissingJob", func(t *testing.T) {
+		w, _, storage, bus, _ := newTestWorker(t)
+
+		msg := chunkMessage()
+		msg.JobID = "grep_gone"
+		require.NoError(t, w.Process(ctx, &msg))
+		assert.Empty(t, storage.lengths)
+		assert.Empty(t, bus.results)
+	})
+}